@@ -68,12 +68,19 @@ Darwin*)
     -e "s/LONG_APP_VERSION/$($CONTENTS/MacOS/gcs -V | tr -d "\n")/" \
     -e "s/COPYRIGHT_YEARS/$($CONTENTS/MacOS/gcs --copyright-date | tr -d "\n")/" \
     bundle/Info.plist >"$CONTENTS/Info.plist"
+  "$CONTENTS/MacOS/gcs" --write-schemas "$CONTENTS/Resources/schemas"
   ;;
 Linux*)
-  go build $STD_FLAGS -ldflags all="$LDFLAGS_ALL" .
+  /bin/rm -rf GCS
+  mkdir -p GCS/Resources
+  go build $STD_FLAGS -ldflags all="$LDFLAGS_ALL" -o GCS/ .
+  GCS/gcs --write-schemas GCS/Resources/schemas
   ;;
 MINGW*)
-  go build $STD_FLAGS -ldflags all="$LDFLAGS_ALL -H windowsgui" .
+  /bin/rm -rf GCS
+  mkdir -p GCS/Resources
+  go build $STD_FLAGS -ldflags all="$LDFLAGS_ALL -H windowsgui" -o GCS/ .
+  GCS/gcs.exe --write-schemas GCS/Resources/schemas
   ;;
 *)
   echo "Unsupported OS"
@@ -17,6 +17,8 @@ import "github.com/richardwilkes/unison"
 const (
 	NewSheetItemID = unison.UserBaseID + iota
 	NewTemplateItemID
+	NewEncounterItemID
+	NewCampaignItemID
 	NewTraitsLibraryItemID
 	NewTraitModifiersLibraryItemID
 	NewEquipmentLibraryItemID
@@ -29,6 +31,9 @@ const (
 	RecentFilesMenuID
 	SaveItemID
 	SaveAsItemID
+	ConvertToSplitFilesItemID
+	ConvertToSingleFileItemID
+	RevisionHistoryItemID
 	ExportToMenuID
 	PrintItemID
 	UndoItemID
@@ -48,11 +53,13 @@ const (
 	ItemMenuID
 	AddNaturalAttacksItemID
 	OpenEditorItemID
+	BulkEditItemID
 	CopyToSheetItemID
 	CopyToTemplateItemID
 	ApplyTemplateItemID
 	OpenOnePageReferenceItemID
 	OpenEachPageReferenceItemID
+	RollHistoryItemID
 	LibraryMenuID
 	SettingsMenuID
 	PerSheetSettingsItemID
@@ -74,6 +81,10 @@ const (
 	WebSiteItemID
 	MailingListItemID
 	ChangeLibraryLocationsItemID
+	RefreshLibrariesItemID
+	SearchLibrariesItemID
+	CheckForLibraryUpdatesItemID
+	FindLibraryConflictsItemID
 
 	FirstNonContainerMarker // Keep this block grouped together
 	NewCarriedEquipmentItemID
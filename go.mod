@@ -3,6 +3,7 @@ module github.com/richardwilkes/gcs
 go 1.18
 
 require (
+	github.com/fsnotify/fsnotify v1.5.4
 	github.com/google/uuid v1.3.0
 	github.com/richardwilkes/json v0.1.0
 	github.com/richardwilkes/pdf v0.0.0-20220615155114-1bb7b941867c
@@ -13,9 +13,12 @@ package main
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/richardwilkes/gcs/model/export"
+	"github.com/richardwilkes/gcs/model/filter"
 	"github.com/richardwilkes/gcs/model/library"
+	"github.com/richardwilkes/gcs/model/schema"
 	"github.com/richardwilkes/gcs/model/settings"
 	"github.com/richardwilkes/gcs/setup"
 	"github.com/richardwilkes/gcs/ui"
@@ -43,18 +46,67 @@ func main() {
 	unison.AttachConsole()
 	cl := cmdline.New(true)
 	var textTmplPath string
+	var toPDF bool
 	var showCopyrightDateAndExit bool
+	var checkLibraries bool
+	var regenerateLibraryIDs bool
+	var schemaDir string
+	var searchText string
+	var filterText string
 	cl.NewGeneralOption(&textTmplPath).SetName("text").SetSingle('x').SetArg("file").
 		SetUsage(i18n.Text("Export sheets using the specified template file"))
+	cl.NewGeneralOption(&toPDF).SetName("pdf").
+		SetUsage(i18n.Text("Print sheets, templates and lists to PDF files alongside them"))
+	cl.NewGeneralOption(&checkLibraries).SetName("check-libraries").
+		SetUsage(i18n.Text("Report duplicate and conflicting items found across the libraries"))
+	cl.NewGeneralOption(&regenerateLibraryIDs).SetName("regenerate-library-ids").
+		SetUsage(i18n.Text("Assign new IDs to library items whose IDs collide with others"))
+	cl.NewGeneralOption(&schemaDir).SetName("write-schemas").SetArg("dir").
+		SetUsage(i18n.Text("Write the JSON Schemas for the data files into the specified directory"))
+	cl.NewGeneralOption(&searchText).SetName("search").SetArg("text").
+		SetUsage(i18n.Text("Search the libraries for items containing the text"))
+	cl.NewGeneralOption(&filterText).SetName("filter").SetArg("filter").
+		SetUsage(i18n.Text("Search the libraries for items matching a saved filter or filter expression, e.g. \"tl <= 4, weight < 5 lb, tag = Weapon\""))
 	cl.NewGeneralOption(&showCopyrightDateAndExit).SetName("copyright-date")
 	fileList := jotrotate.ParseAndSetup(cl)
 	if showCopyrightDateAndExit {
 		fmt.Print(cmdline.ResolveCopyrightYears())
 		atexit.Exit(0)
 	}
+	if schemaDir != "" {
+		if err := schema.WriteAll(schemaDir); err != nil {
+			cl.FatalMsg(err.Error())
+		}
+		atexit.Exit(0)
+	}
 	setup.Setup()
 	settings.Global() // Here to force early initialization
-	if textTmplPath != "" {
+	if checkLibraries || regenerateLibraryIDs {
+		libs := settings.Global().LibrarySet
+		report := library.FindConflicts(libs)
+		if regenerateLibraryIDs && report.IDCollisions() != 0 {
+			count, err := library.RegenerateCollidingIDs(libs, report)
+			if err != nil {
+				cl.FatalMsg(err.Error())
+			}
+			fmt.Printf(i18n.Text("Regenerated %d IDs.\n"), count)
+			report = library.FindConflicts(libs)
+		}
+		if err := report.Write(os.Stdout, libs); err != nil {
+			cl.FatalMsg(err.Error())
+		}
+	} else if searchText != "" || filterText != "" {
+		filters, err := filter.Resolve(settings.Global().Filters, filterText)
+		if err != nil {
+			cl.FatalMsg(err.Error())
+		}
+		libs := settings.Global().LibrarySet
+		idx := library.GlobalIndex()
+		idx.Update(libs)
+		if err = filter.Search(os.Stdout, idx, libs, searchText, filters); err != nil {
+			cl.FatalMsg(err.Error())
+		}
+	} else if textTmplPath != "" {
 		if len(fileList) == 0 {
 			cl.FatalMsg(i18n.Text("No files to process."))
 		}
@@ -66,6 +118,13 @@ func main() {
 		if err := export.ToText(textTmplPath, fileList); err != nil {
 			cl.FatalMsg(err.Error())
 		}
+	} else if toPDF {
+		if len(fileList) == 0 {
+			cl.FatalMsg(i18n.Text("No files to process."))
+		}
+		if err := export.ToPDF(fileList); err != nil {
+			cl.FatalMsg(err.Error())
+		}
 	} else {
 		ui.Start(fileList) // Never returns
 	}
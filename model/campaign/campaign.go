@@ -0,0 +1,188 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package campaign
+
+import (
+	"bytes"
+	"context"
+	"io/fs"
+	"path/filepath"
+
+	"github.com/google/uuid"
+	"github.com/richardwilkes/gcs/model/crc"
+	"github.com/richardwilkes/gcs/model/gurps"
+	"github.com/richardwilkes/gcs/model/gurps/gid"
+	gsettings "github.com/richardwilkes/gcs/model/gurps/settings"
+	"github.com/richardwilkes/gcs/model/id"
+	"github.com/richardwilkes/gcs/model/jio"
+	"github.com/richardwilkes/gcs/model/library"
+	"github.com/richardwilkes/gcs/model/settings"
+	"github.com/richardwilkes/toolbox/errs"
+)
+
+const campaignTypeKey = "campaign"
+
+// Campaign groups a set of character sheets that share the same sheet settings, page reference mappings and calendar.
+type Campaign struct {
+	Type          string               `json:"type"`
+	Version       int                  `json:"version"`
+	ID            uuid.UUID            `json:"id"`
+	Members       []string             `json:"members,omitempty"`
+	SheetSettings *gurps.SheetSettings `json:"sheet_settings,omitempty"`
+	PageRefs      settings.PageRefs    `json:"page_refs,omitempty"`
+	CalendarName  string               `json:"calendar_ref,omitempty"`
+}
+
+// NewCampaignFromFile loads a Campaign from a file.
+func NewCampaignFromFile(fileSystem fs.FS, filePath string) (*Campaign, error) {
+	var c Campaign
+	if err := jio.LoadFromFS(context.Background(), fileSystem, filePath, &c); err != nil {
+		return nil, gid.InvalidFileData(err)
+	}
+	if c.Type != campaignTypeKey {
+		return nil, errs.New(gid.UnexpectedFileDataMsg)
+	}
+	if err := gid.CheckVersion(c.Version); err != nil {
+		return nil, err
+	}
+	c.Version = gid.CurrentDataVersion
+	if c.SheetSettings == nil {
+		c.SheetSettings = gurps.FactorySheetSettings()
+	}
+	c.SheetSettings.SetOwningEntity(nil)
+	return &c, nil
+}
+
+// NewCampaign creates a new Campaign with factory sheet settings.
+func NewCampaign() *Campaign {
+	return &Campaign{
+		Type:          campaignTypeKey,
+		Version:       gid.CurrentDataVersion,
+		ID:            id.NewUUID(),
+		SheetSettings: gurps.FactorySheetSettings(),
+	}
+}
+
+// Save the Campaign to a file as JSON.
+func (c *Campaign) Save(filePath string) error {
+	return jio.SaveToFile(context.Background(), filePath, c)
+}
+
+// CRC64 computes a CRC-64 value for the canonical disk format of the data.
+func (c *Campaign) CRC64() uint64 {
+	var buffer bytes.Buffer
+	if err := jio.Save(context.Background(), &buffer, c); err != nil {
+		return 0
+	}
+	return crc.Bytes(0, buffer.Bytes())
+}
+
+// MemberPaths returns the full paths of the member sheets. Members are stored relative to the campaign file, so the path
+// of the campaign file must be provided.
+func (c *Campaign) MemberPaths(campaignPath string) []string {
+	dir := filepath.Dir(campaignPath)
+	list := make([]string, len(c.Members))
+	for i, one := range c.Members {
+		if one = filepath.FromSlash(one); filepath.IsAbs(one) {
+			list[i] = one
+		} else {
+			list[i] = filepath.Join(dir, one)
+		}
+	}
+	return list
+}
+
+// IsMember returns true if the sheet at the given path is a member of the campaign.
+func (c *Campaign) IsMember(campaignPath, sheetPath string) bool {
+	return c.memberIndex(campaignPath, sheetPath) != -1
+}
+
+// AddMember adds the sheet at the given path to the campaign. Returns false if it was already a member.
+func (c *Campaign) AddMember(campaignPath, sheetPath string) bool {
+	if c.IsMember(campaignPath, sheetPath) {
+		return false
+	}
+	memberPath := sheetPath
+	if rel, err := filepath.Rel(filepath.Dir(campaignPath), sheetPath); err == nil {
+		memberPath = rel
+	}
+	c.Members = append(c.Members, filepath.ToSlash(memberPath))
+	return true
+}
+
+// RemoveMember removes the sheet at the given path from the campaign. Returns false if it wasn't a member.
+func (c *Campaign) RemoveMember(campaignPath, sheetPath string) bool {
+	i := c.memberIndex(campaignPath, sheetPath)
+	if i == -1 {
+		return false
+	}
+	c.Members = append(c.Members[:i], c.Members[i+1:]...)
+	return true
+}
+
+// RebaseMembers adjusts the stored member paths, which are relative to the campaign file, for a campaign file that is
+// being moved from one path to another.
+func (c *Campaign) RebaseMembers(oldCampaignPath, newCampaignPath string) {
+	paths := c.MemberPaths(oldCampaignPath)
+	c.Members = nil
+	for _, one := range paths {
+		c.AddMember(newCampaignPath, one)
+	}
+}
+
+func (c *Campaign) memberIndex(campaignPath, sheetPath string) int {
+	target := filepath.Clean(sheetPath)
+	for i, one := range c.MemberPaths(campaignPath) {
+		if filepath.Clean(one) == target {
+			return i
+		}
+	}
+	return -1
+}
+
+// AdoptSheetSettings replaces the shared sheet settings with a copy of the provided ones.
+func (c *Campaign) AdoptSheetSettings(s *gurps.SheetSettings) {
+	c.SheetSettings = s.Clone(nil)
+	c.SheetSettings.SetOwningEntity(nil)
+}
+
+// AdoptPageRefs replaces the shared page reference mappings with a copy of the provided ones.
+func (c *Campaign) AdoptPageRefs(pageRefs *settings.PageRefs) {
+	c.PageRefs = settings.PageRefs{}
+	for _, one := range pageRefs.List() {
+		c.PageRefs.Set(one)
+	}
+}
+
+// ApplyTo replaces the entity's attribute definitions, body type and damage progression with copies of the shared ones.
+// The remainder of the entity's sheet settings, such as its page and block layout, are left alone.
+func (c *Campaign) ApplyTo(entity *gurps.Entity) {
+	s := entity.SheetSettings.Clone(entity)
+	s.Attributes = c.SheetSettings.Attributes
+	s.HitLocations = c.SheetSettings.HitLocations
+	s.DamageProgression = c.SheetSettings.DamageProgression
+	entity.AdoptSheetSettings(s)
+}
+
+// LookupPageRef returns the campaign's page reference mapping for the key, or nil if the campaign doesn't have one.
+func (c *Campaign) LookupPageRef(key string) *settings.PageRef {
+	return c.PageRefs.Lookup(key)
+}
+
+// CalendarRef returns the calendar used by the campaign, or nil if it uses the current calendar or its calendar can no
+// longer be found.
+func (c *Campaign) CalendarRef(libraries library.Libraries) *gsettings.CalendarRef {
+	if c.CalendarName == "" {
+		return nil
+	}
+	return gsettings.LookupCalendarRef(c.CalendarName, libraries)
+}
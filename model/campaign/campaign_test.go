@@ -0,0 +1,91 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package campaign_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/richardwilkes/gcs/model/campaign"
+	"github.com/richardwilkes/gcs/model/fxp"
+	"github.com/richardwilkes/gcs/model/gurps"
+	"github.com/richardwilkes/gcs/model/gurps/attribute"
+	"github.com/richardwilkes/gcs/model/gurps/datafile"
+	"github.com/richardwilkes/gcs/model/gurps/gid"
+	"github.com/richardwilkes/gcs/model/settings"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCampaign(t *testing.T) {
+	if gurps.SettingsProvider == nil {
+		s := settings.Default()
+		s.General.AutoFillProfile = false
+		gurps.SettingsProvider = s
+	}
+	dir := t.TempDir()
+	campaignPath := filepath.Join(dir, "party", "campaign.gcp")
+	alice := filepath.Join(dir, "party", "alice.gcs")
+	bob := filepath.Join(dir, "bob.gcs")
+
+	c := campaign.NewCampaign()
+	assert.True(t, c.AddMember(campaignPath, alice))
+	assert.True(t, c.AddMember(campaignPath, bob))
+	assert.False(t, c.AddMember(campaignPath, alice))
+	assert.Equal(t, []string{"alice.gcs", "../bob.gcs"}, c.Members)
+	assert.Equal(t, []string{alice, bob}, c.MemberPaths(campaignPath))
+
+	c.RebaseMembers(campaignPath, filepath.Join(dir, "campaign.gcp"))
+	assert.Equal(t, []string{"party/alice.gcs", "bob.gcs"}, c.Members)
+	campaignPath = filepath.Join(dir, "campaign.gcp")
+	assert.True(t, c.RemoveMember(campaignPath, bob))
+	assert.False(t, c.IsMember(campaignPath, bob))
+
+	// Shared attributes, body type and damage progression replace the entity's own, retaining the values of attributes
+	// that remain defined, while the entity's layout is left alone
+	s := gurps.FactorySheetSettings()
+	s.DamageProgression = attribute.KnowingYourOwnStrength
+	s.HitLocations.Name = "Shared"
+	s.UseTitleInFooter = true
+	delete(s.Attributes.Set, gid.Dexterity)
+	c.AdoptSheetSettings(s)
+	entity := gurps.NewEntity(datafile.PC)
+	entity.Attributes.Set[gid.Strength].Adjustment = fxp.Two
+	entity.SheetSettings.SetColumnLayout("skills", gurps.NewColumnLayout(1, 2))
+	c.ApplyTo(entity)
+	assert.Equal(t, attribute.KnowingYourOwnStrength, entity.SheetSettings.DamageProgression)
+	assert.Equal(t, "Shared", entity.SheetSettings.HitLocations.Name)
+	assert.NotSame(t, c.SheetSettings.HitLocations, entity.SheetSettings.HitLocations)
+	assert.False(t, entity.SheetSettings.UseTitleInFooter)
+	assert.NotNil(t, entity.SheetSettings.ColumnLayout("skills"))
+	assert.Nil(t, entity.Attributes.Set[gid.Dexterity])
+	require.NotNil(t, entity.Attributes.Set[gid.Strength])
+	assert.Equal(t, fxp.Two, entity.Attributes.Set[gid.Strength].Adjustment)
+
+	// Page reference mappings and the calendar stay with the campaign
+	pdfPath := filepath.Join(dir, "basic.pdf")
+	require.NoError(t, os.WriteFile(pdfPath, []byte("%PDF-1.4\n"), 0o640))
+	c.PageRefs.Set(&settings.PageRef{ID: "B", Path: pdfPath, Offset: 2})
+	c.CalendarName = "Imperial"
+	require.NotNil(t, c.LookupPageRef("B"))
+	assert.Equal(t, 2, c.LookupPageRef("B").Offset)
+	assert.Nil(t, c.LookupPageRef("MA"))
+
+	require.NoError(t, c.Save(campaignPath))
+	loaded, err := campaign.NewCampaignFromFile(os.DirFS(dir), filepath.Base(campaignPath))
+	require.NoError(t, err)
+	assert.Equal(t, c.CRC64(), loaded.CRC64())
+	assert.Equal(t, []string{alice}, loaded.MemberPaths(campaignPath))
+	assert.Equal(t, attribute.KnowingYourOwnStrength, loaded.SheetSettings.DamageProgression)
+	assert.Equal(t, "Imperial", loaded.CalendarName)
+}
@@ -0,0 +1,81 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package export
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/richardwilkes/gcs/model/gurps"
+	"github.com/richardwilkes/gcs/model/library"
+	"github.com/richardwilkes/gcs/model/printing"
+	"github.com/richardwilkes/toolbox/log/jot"
+	xfs "github.com/richardwilkes/toolbox/xio/fs"
+)
+
+// ToPDF prints the files to PDF files alongside them.
+func ToPDF(fileList []string) error {
+	for _, one := range fileList {
+		fileSystem := os.DirFS(filepath.Dir(one))
+		name := filepath.Base(one)
+		title := xfs.BaseName(one)
+		var doc *printing.Document
+		var err error
+		switch strings.ToLower(filepath.Ext(one)) {
+		case library.SheetExt:
+			var entity *gurps.Entity
+			if entity, err = gurps.NewEntityFromFile(fileSystem, name); err == nil {
+				doc = printing.Sheet(entity)
+			}
+		case library.TemplatesExt:
+			var tmpl *gurps.Template
+			if tmpl, err = gurps.NewTemplateFromFile(fileSystem, name); err == nil {
+				doc = printing.Template(tmpl, title)
+			}
+		case library.TraitsExt:
+			doc, err = listDocument(title, fileSystem, name, gurps.NewTraitsFromFile)
+		case library.TraitModifiersExt:
+			doc, err = listDocument(title, fileSystem, name, gurps.NewTraitModifiersFromFile)
+		case library.SkillsExt:
+			doc, err = listDocument(title, fileSystem, name, gurps.NewSkillsFromFile)
+		case library.SpellsExt:
+			doc, err = listDocument(title, fileSystem, name, gurps.NewSpellsFromFile)
+		case library.EquipmentExt:
+			doc, err = listDocument(title, fileSystem, name, gurps.NewEquipmentFromFile)
+		case library.EquipmentModifiersExt:
+			doc, err = listDocument(title, fileSystem, name, gurps.NewEquipmentModifiersFromFile)
+		case library.NotesExt:
+			doc, err = listDocument(title, fileSystem, name, gurps.NewNotesFromFile)
+		default:
+			jot.Warn("ignoring: " + one)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err = doc.Save(xfs.TrimExtension(one) + ".pdf"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func listDocument[T gurps.NodeConstraint[T]](title string, fileSystem fs.FS, filePath string,
+	loader func(fs.FS, string) ([]T, error)) (*printing.Document, error) {
+	rows, err := loader(fileSystem, filePath)
+	if err != nil {
+		return nil, err
+	}
+	return printing.List(title, rows), nil
+}
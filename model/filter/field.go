@@ -0,0 +1,226 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package filter
+
+import (
+	"strings"
+
+	"github.com/richardwilkes/gcs/model/fxp"
+	"github.com/richardwilkes/gcs/model/gurps"
+	"github.com/richardwilkes/gcs/model/gurps/measure"
+	"github.com/richardwilkes/gcs/model/library"
+	"github.com/richardwilkes/toolbox/i18n"
+)
+
+// Possible Kind values.
+const (
+	TextKind Kind = iota
+	NumberKind
+	WeightKind
+)
+
+// Kind identifies the type of value a Field provides.
+type Kind uint8
+
+// Field describes a value within the rows of a list that conditions may be placed upon.
+type Field struct {
+	Key    string
+	Title  string
+	Kind   Kind
+	text   func(row any) []string
+	number func(row any) (fxp.Int, bool)
+	weight func(row any) (measure.Weight, bool)
+}
+
+var fieldsByExt = map[string][]*Field{
+	library.TraitsExt: {
+		textField("name", i18n.Text("Name"), func(t *gurps.Trait) []string { return []string{t.Name} }),
+		textField("tag", i18n.Text("Tag"), func(t *gurps.Trait) []string { return t.Tags }),
+		numberField("points", i18n.Text("Points"), func(t *gurps.Trait) (fxp.Int, bool) { return t.AdjustedPoints(), true }),
+		numberField("levels", i18n.Text("Levels"), func(t *gurps.Trait) (fxp.Int, bool) {
+			return t.Levels, !t.Container() && t.PointsPerLevel != 0
+		}),
+		textField("notes", i18n.Text("Notes"), func(t *gurps.Trait) []string { return []string{t.Notes()} }),
+		textField("reference", i18n.Text("Reference"), func(t *gurps.Trait) []string { return []string{t.PageRef} }),
+	},
+	library.TraitModifiersExt: {
+		textField("name", i18n.Text("Name"), func(m *gurps.TraitModifier) []string { return []string{m.Name} }),
+		textField("tag", i18n.Text("Tag"), func(m *gurps.TraitModifier) []string { return m.Tags }),
+		numberField("cost", i18n.Text("Cost"), func(m *gurps.TraitModifier) (fxp.Int, bool) { return m.Cost, !m.Container() }),
+		numberField("levels", i18n.Text("Levels"), func(m *gurps.TraitModifier) (fxp.Int, bool) { return m.Levels, !m.Container() }),
+		textField("notes", i18n.Text("Notes"), func(m *gurps.TraitModifier) []string { return []string{m.LocalNotes} }),
+		textField("reference", i18n.Text("Reference"), func(m *gurps.TraitModifier) []string { return []string{m.PageRef} }),
+	},
+	library.SkillsExt: {
+		textField("name", i18n.Text("Name"), func(s *gurps.Skill) []string { return []string{s.Name} }),
+		textField("specialization", i18n.Text("Specialization"), func(s *gurps.Skill) []string { return []string{s.Specialization} }),
+		textField("tag", i18n.Text("Tag"), func(s *gurps.Skill) []string { return s.Tags }),
+		numberField("tl", i18n.Text("Tech Level"), func(s *gurps.Skill) (fxp.Int, bool) { return techLevel(s.TechLevel) }),
+		textField("difficulty", i18n.Text("Difficulty"), func(s *gurps.Skill) []string {
+			if s.Container() {
+				return nil
+			}
+			return []string{s.Difficulty.Description(s.Entity)}
+		}),
+		numberField("points", i18n.Text("Points"), func(s *gurps.Skill) (fxp.Int, bool) { return s.Points, !s.Container() }),
+		textField("notes", i18n.Text("Notes"), func(s *gurps.Skill) []string { return []string{s.Notes()} }),
+		textField("reference", i18n.Text("Reference"), func(s *gurps.Skill) []string { return []string{s.PageRef} }),
+	},
+	library.SpellsExt: {
+		textField("name", i18n.Text("Name"), func(s *gurps.Spell) []string { return []string{s.Name} }),
+		textField("tag", i18n.Text("Tag"), func(s *gurps.Spell) []string { return s.Tags }),
+		textField("college", i18n.Text("College"), func(s *gurps.Spell) []string { return s.College }),
+		textField("power_source", i18n.Text("Power Source"), func(s *gurps.Spell) []string { return []string{s.PowerSource} }),
+		textField("class", i18n.Text("Class"), func(s *gurps.Spell) []string { return []string{s.Class} }),
+		textField("resist", i18n.Text("Resist"), func(s *gurps.Spell) []string { return []string{s.Resist} }),
+		textField("cost", i18n.Text("Casting Cost"), func(s *gurps.Spell) []string { return []string{s.CastingCost} }),
+		textField("duration", i18n.Text("Duration"), func(s *gurps.Spell) []string { return []string{s.Duration} }),
+		numberField("tl", i18n.Text("Tech Level"), func(s *gurps.Spell) (fxp.Int, bool) { return techLevel(s.TechLevel) }),
+		textField("difficulty", i18n.Text("Difficulty"), func(s *gurps.Spell) []string {
+			if s.Container() {
+				return nil
+			}
+			return []string{s.Difficulty.Description(s.Entity)}
+		}),
+		numberField("points", i18n.Text("Points"), func(s *gurps.Spell) (fxp.Int, bool) { return s.Points, !s.Container() }),
+		textField("notes", i18n.Text("Notes"), func(s *gurps.Spell) []string { return []string{s.Notes()} }),
+		textField("reference", i18n.Text("Reference"), func(s *gurps.Spell) []string { return []string{s.PageRef} }),
+	},
+	library.EquipmentExt: {
+		textField("name", i18n.Text("Name"), func(e *gurps.Equipment) []string { return []string{e.Name} }),
+		textField("tag", i18n.Text("Tag"), func(e *gurps.Equipment) []string { return e.Tags }),
+		numberField("tl", i18n.Text("Tech Level"), func(e *gurps.Equipment) (fxp.Int, bool) { return techLevel(&e.TechLevel) }),
+		numberField("lc", i18n.Text("Legality Class"), func(e *gurps.Equipment) (fxp.Int, bool) {
+			return number(e.LegalityClass)
+		}),
+		numberField("quantity", i18n.Text("Quantity"), func(e *gurps.Equipment) (fxp.Int, bool) { return e.Quantity, true }),
+		numberField("value", i18n.Text("Value"), func(e *gurps.Equipment) (fxp.Int, bool) { return e.AdjustedValue(), true }),
+		numberField("extended_value", i18n.Text("Extended Value"), func(e *gurps.Equipment) (fxp.Int, bool) {
+			return e.ExtendedValue(), true
+		}),
+		weightField("weight", i18n.Text("Weight"), func(e *gurps.Equipment) (measure.Weight, bool) {
+			return e.AdjustedWeight(false, gurps.SheetSettingsFor(e.Entity).DefaultWeightUnits), true
+		}),
+		weightField("extended_weight", i18n.Text("Extended Weight"), func(e *gurps.Equipment) (measure.Weight, bool) {
+			return e.ExtendedWeight(false, gurps.SheetSettingsFor(e.Entity).DefaultWeightUnits), true
+		}),
+		numberField("uses", i18n.Text("Uses"), func(e *gurps.Equipment) (fxp.Int, bool) {
+			return fxp.From(e.Uses), e.MaxUses > 0
+		}),
+		numberField("max_uses", i18n.Text("Maximum Uses"), func(e *gurps.Equipment) (fxp.Int, bool) {
+			return fxp.From(e.MaxUses), true
+		}),
+		textField("notes", i18n.Text("Notes"), func(e *gurps.Equipment) []string { return []string{e.Notes()} }),
+		textField("reference", i18n.Text("Reference"), func(e *gurps.Equipment) []string { return []string{e.PageRef} }),
+	},
+	library.EquipmentModifiersExt: {
+		textField("name", i18n.Text("Name"), func(m *gurps.EquipmentModifier) []string { return []string{m.Name} }),
+		textField("tag", i18n.Text("Tag"), func(m *gurps.EquipmentModifier) []string { return m.Tags }),
+		numberField("tl", i18n.Text("Tech Level"), func(m *gurps.EquipmentModifier) (fxp.Int, bool) {
+			return techLevel(&m.TechLevel)
+		}),
+		textField("cost", i18n.Text("Cost Adjustment"), func(m *gurps.EquipmentModifier) []string {
+			return []string{m.CostAmount}
+		}),
+		textField("weight", i18n.Text("Weight Adjustment"), func(m *gurps.EquipmentModifier) []string {
+			return []string{m.WeightAmount}
+		}),
+		textField("notes", i18n.Text("Notes"), func(m *gurps.EquipmentModifier) []string { return []string{m.LocalNotes} }),
+		textField("reference", i18n.Text("Reference"), func(m *gurps.EquipmentModifier) []string {
+			return []string{m.PageRef}
+		}),
+	},
+	library.NotesExt: {
+		textField("text", i18n.Text("Text"), func(n *gurps.Note) []string { return []string{n.Text} }),
+		textField("reference", i18n.Text("Reference"), func(n *gurps.Note) []string { return []string{n.PageRef} }),
+	},
+}
+
+func (f *Field) String() string {
+	return f.Title
+}
+
+// FieldsFor returns the fields available for the list type with the given file extension.
+func FieldsFor(ext string) []*Field {
+	return fieldsByExt[strings.ToLower(ext)]
+}
+
+// FieldFor returns the field with the given key for the list type with the given file extension, or nil.
+func FieldFor(ext, key string) *Field {
+	for _, f := range FieldsFor(ext) {
+		if strings.EqualFold(f.Key, key) {
+			return f
+		}
+	}
+	return nil
+}
+
+func textField[T any](key, title string, f func(T) []string) *Field {
+	return &Field{
+		Key:   key,
+		Title: title,
+		Kind:  TextKind,
+		text: func(row any) []string {
+			if t, ok := row.(T); ok {
+				return f(t)
+			}
+			return nil
+		},
+	}
+}
+
+func numberField[T any](key, title string, f func(T) (fxp.Int, bool)) *Field {
+	return &Field{
+		Key:   key,
+		Title: title,
+		Kind:  NumberKind,
+		number: func(row any) (fxp.Int, bool) {
+			if t, ok := row.(T); ok {
+				return f(t)
+			}
+			return 0, false
+		},
+	}
+}
+
+func weightField[T any](key, title string, f func(T) (measure.Weight, bool)) *Field {
+	return &Field{
+		Key:   key,
+		Title: title,
+		Kind:  WeightKind,
+		weight: func(row any) (measure.Weight, bool) {
+			if t, ok := row.(T); ok {
+				return f(t)
+			}
+			return 0, false
+		},
+	}
+}
+
+// techLevel returns the tech level found within the text. Rows without a tech level, or whose tech level does not
+// contain a number, have no value.
+func techLevel(tl *string) (fxp.Int, bool) {
+	if tl == nil {
+		return 0, false
+	}
+	value, start, _ := gurps.ExtractTechLevel(*tl)
+	return value, start != -1
+}
+
+func number(text string) (fxp.Int, bool) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return 0, false
+	}
+	value, err := fxp.FromString(text)
+	return value, err == nil
+}
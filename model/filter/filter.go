@@ -0,0 +1,391 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package filter
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/richardwilkes/gcs/model/criteria"
+	"github.com/richardwilkes/gcs/model/fxp"
+	"github.com/richardwilkes/gcs/model/gurps"
+	"github.com/richardwilkes/gcs/model/gurps/measure"
+	"github.com/richardwilkes/gcs/model/library"
+	"github.com/richardwilkes/toolbox/errs"
+	"github.com/richardwilkes/toolbox/i18n"
+	"github.com/richardwilkes/toolbox/txt"
+)
+
+type operator struct {
+	symbol  string
+	numeric NumericCompareType
+	text    criteria.StringCompareType
+}
+
+// The operators understood by Parse. Longer symbols must precede any of their prefixes. The first symbol for a given
+// comparison is the one used when generating text.
+var operators = []operator{
+	{symbol: "<=", numeric: AtMost},
+	{symbol: "≤", numeric: AtMost},
+	{symbol: ">=", numeric: AtLeast},
+	{symbol: "≥", numeric: AtLeast},
+	{symbol: "!=", numeric: NotEquals, text: criteria.IsNot},
+	{symbol: "≠", numeric: NotEquals, text: criteria.IsNot},
+	{symbol: "!~", text: criteria.DoesNotContain},
+	{symbol: "!^", text: criteria.DoesNotStartWith},
+	{symbol: "!$", text: criteria.DoesNotEndWith},
+	{symbol: "<", numeric: LessThan},
+	{symbol: ">", numeric: GreaterThan},
+	{symbol: "=", numeric: Equals, text: criteria.Is},
+	{symbol: "~", text: criteria.Contains},
+	{symbol: "^", text: criteria.StartsWith},
+	{symbol: "$", text: criteria.EndsWith},
+}
+
+// Condition holds the criteria a single field of a row must satisfy. Only the criteria appropriate for the kind of
+// field is used.
+type Condition struct {
+	Field   string          `json:"field"`
+	String  criteria.String `json:"string,omitempty"`
+	Numeric Numeric         `json:"numeric,omitempty"`
+	Weight  Weight          `json:"weight,omitempty"`
+}
+
+// Filter holds a set of conditions, all of which must be satisfied by a row for it to match. Filters apply to a single
+// type of list, identified by its file extension.
+type Filter struct {
+	Name       string       `json:"name,omitempty"`
+	Type       string       `json:"type"`
+	Conditions []*Condition `json:"conditions,omitempty"`
+}
+
+// Parse the text into a Filter for the list type with the given file extension. The text consists of comma-separated
+// conditions, each of which is a field key, an operator and a value, e.g. "tl <= 4, weight < 5 lb, tag = Weapon". Values
+// containing commas or leading or trailing spaces may be enclosed in double quotes. For text fields, the operator may be
+// omitted to mean "=".
+func Parse(ext, text string) (*Filter, error) {
+	if len(FieldsFor(ext)) == 0 {
+		return nil, errs.Newf(i18n.Text("%s files do not support filtering"), ext)
+	}
+	f := &Filter{Type: strings.ToLower(ext)}
+	parts, err := split(text)
+	if err != nil {
+		return nil, err
+	}
+	for _, part := range parts {
+		var c *Condition
+		if c, err = parseCondition(f.Type, part); err != nil {
+			return nil, err
+		}
+		f.Conditions = append(f.Conditions, c)
+	}
+	return f, nil
+}
+
+func split(text string) ([]string, error) {
+	var parts []string
+	var buffer strings.Builder
+	inQuote := false
+	escaped := false
+	for _, ch := range text {
+		switch {
+		case escaped:
+			escaped = false
+		case inQuote && ch == '\\':
+			escaped = true
+		case ch == '"':
+			inQuote = !inQuote
+		case !inQuote && ch == ',':
+			if part := strings.TrimSpace(buffer.String()); part != "" {
+				parts = append(parts, part)
+			}
+			buffer.Reset()
+			continue
+		}
+		buffer.WriteRune(ch)
+	}
+	if inQuote {
+		return nil, errs.New(i18n.Text("unterminated quote in filter"))
+	}
+	if part := strings.TrimSpace(buffer.String()); part != "" {
+		parts = append(parts, part)
+	}
+	return parts, nil
+}
+
+func parseCondition(ext, text string) (*Condition, error) {
+	i := strings.IndexFunc(text, func(ch rune) bool { return !unicode.IsLetter(ch) && ch != '_' })
+	if i == -1 {
+		i = len(text)
+	}
+	key := text[:i]
+	field := FieldFor(ext, key)
+	if field == nil {
+		return nil, errs.Newf(i18n.Text("unknown field %q; expected one of: %s"), key, fieldKeys(ext))
+	}
+	rest := strings.TrimSpace(text[i:])
+	var op *operator
+	for j := range operators {
+		if strings.HasPrefix(rest, operators[j].symbol) {
+			op = &operators[j]
+			rest = strings.TrimSpace(rest[len(op.symbol):])
+			break
+		}
+	}
+	value := rest
+	if strings.HasPrefix(rest, `"`) {
+		var err error
+		if value, err = strconv.Unquote(rest); err != nil {
+			return nil, errs.Newf(i18n.Text("invalid quoted value in %q"), text)
+		}
+	}
+	if op == nil {
+		if field.Kind != TextKind {
+			return nil, errs.Newf(i18n.Text("%s requires one of the operators =, !=, <, <=, > or >="), field.Key)
+		}
+		op = &operator{text: criteria.Is}
+	}
+	if field.Kind == TextKind && op.text == criteria.Any || field.Kind != TextKind && op.numeric == AnyNumber {
+		return nil, errs.Newf(i18n.Text("operator %s may not be used with %s"), op.symbol, field.Key)
+	}
+	return NewCondition(ext, field.Key, op.text, op.numeric, value)
+}
+
+// NewCondition creates a new condition for the field with the given key within the list type with the given file
+// extension. Only the comparison appropriate for the kind of field is used. The value is parsed according to the kind
+// of field.
+func NewCondition(ext, key string, textCompare criteria.StringCompareType, numericCompare NumericCompareType, value string) (*Condition, error) {
+	field := FieldFor(ext, key)
+	if field == nil {
+		return nil, errs.Newf(i18n.Text("unknown field %q; expected one of: %s"), key, fieldKeys(ext))
+	}
+	c := &Condition{Field: field.Key}
+	switch field.Kind {
+	case TextKind:
+		c.String.Compare = textCompare.EnsureValid()
+		c.String.Qualifier = value
+	case WeightKind:
+		w, err := measure.WeightFromString(value, gurps.SheetSettingsFor(nil).DefaultWeightUnits)
+		if err != nil {
+			return nil, errs.Newf(i18n.Text("invalid weight %q for %s"), value, field.Key)
+		}
+		c.Weight.Compare = numericCompare.EnsureValid()
+		c.Weight.Qualifier = w
+	default:
+		n, err := fxp.FromString(strings.TrimSpace(value))
+		if err != nil {
+			return nil, errs.Newf(i18n.Text("invalid number %q for %s"), value, field.Key)
+		}
+		c.Numeric.Compare = numericCompare.EnsureValid()
+		c.Numeric.Qualifier = n
+	}
+	return c, nil
+}
+
+func fieldKeys(ext string) string {
+	fields := FieldsFor(ext)
+	keys := make([]string, len(fields))
+	for i, f := range fields {
+		keys[i] = f.Key
+	}
+	return strings.Join(keys, ", ")
+}
+
+// Matches returns true if the row satisfies all of the conditions.
+func (f *Filter) Matches(row any) bool {
+	for _, c := range f.Conditions {
+		if !c.Matches(f.Type, row) {
+			return false
+		}
+	}
+	return true
+}
+
+// Empty returns true if the filter has no conditions.
+func (f *Filter) Empty() bool {
+	return len(f.Conditions) == 0
+}
+
+// String returns the text form of the filter, suitable for passing to Parse.
+func (f *Filter) String() string {
+	parts := make([]string, 0, len(f.Conditions))
+	for _, c := range f.Conditions {
+		parts = append(parts, c.text(f.Type))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Matches returns true if the row satisfies this condition. Rows that have no value for a numeric field never match.
+func (c *Condition) Matches(ext string, row any) bool {
+	field := FieldFor(ext, c.Field)
+	if field == nil {
+		return false
+	}
+	switch field.Kind {
+	case TextKind:
+		values := field.text(row)
+		switch c.String.Compare {
+		case criteria.IsNot, criteria.DoesNotContain, criteria.DoesNotStartWith, criteria.DoesNotEndWith:
+			// A negated comparison must hold for every value, e.g. a row whose tags include "Weapon" should not
+			// match "tag != Weapon" just because it also has other tags.
+			for _, one := range values {
+				if !c.String.Compare.Matches(c.String.Qualifier, one) {
+					return false
+				}
+			}
+			return true
+		default:
+			return c.String.Matches(values...)
+		}
+	case WeightKind:
+		w, ok := field.weight(row)
+		return ok && c.Weight.Matches(w)
+	default:
+		n, ok := field.number(row)
+		return ok && c.Numeric.Matches(n)
+	}
+}
+
+func (c *Condition) text(ext string) string {
+	field := FieldFor(ext, c.Field)
+	if field == nil {
+		return c.Field
+	}
+	switch field.Kind {
+	case TextKind:
+		return fmt.Sprintf("%s %s %s", field.Key, textSymbol(c.String.Compare), quote(c.String.Qualifier))
+	case WeightKind:
+		return fmt.Sprintf("%s %s %s", field.Key, numericSymbol(c.Weight.Compare), c.Weight.Qualifier.String())
+	default:
+		return fmt.Sprintf("%s %s %s", field.Key, numericSymbol(c.Numeric.Compare), c.Numeric.Qualifier.String())
+	}
+}
+
+// Description returns a human-readable description of the condition.
+func (c *Condition) Description(ext string) string {
+	field := FieldFor(ext, c.Field)
+	if field == nil {
+		return c.Field
+	}
+	switch field.Kind {
+	case TextKind:
+		return field.Title + " " + c.String.String()
+	case WeightKind:
+		return field.Title + " " + c.Weight.String()
+	default:
+		return field.Title + " " + c.Numeric.String()
+	}
+}
+
+func textSymbol(compare criteria.StringCompareType) string {
+	for _, op := range operators {
+		if op.text == compare {
+			return op.symbol
+		}
+	}
+	return "="
+}
+
+func numericSymbol(compare NumericCompareType) string {
+	for _, op := range operators {
+		if op.numeric == compare {
+			return op.symbol
+		}
+	}
+	return "="
+}
+
+func quote(value string) string {
+	if value == "" || strings.TrimSpace(value) != value || strings.ContainsAny(value, `,"`) {
+		return strconv.Quote(value)
+	}
+	return value
+}
+
+// Filters holds a set of named filters.
+type Filters []*Filter
+
+// For returns the filters for the list type with the given file extension, sorted by name.
+func (f Filters) For(ext string) []*Filter {
+	ext = strings.ToLower(ext)
+	var list []*Filter
+	for _, one := range f {
+		if one.Type == ext {
+			list = append(list, one)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return txt.NaturalLess(list[i].Name, list[j].Name, true) })
+	return list
+}
+
+// Lookup returns the filter with the given name for the list type with the given file extension, or nil.
+func (f Filters) Lookup(ext, name string) *Filter {
+	ext = strings.ToLower(ext)
+	for _, one := range f {
+		if one.Type == ext && strings.EqualFold(one.Name, name) {
+			return one
+		}
+	}
+	return nil
+}
+
+// Put the filter into the set, replacing any existing one of the same type and name.
+func (f Filters) Put(filter *Filter) Filters {
+	for i, one := range f {
+		if one.Type == filter.Type && strings.EqualFold(one.Name, filter.Name) {
+			f[i] = filter
+			return f
+		}
+	}
+	return append(f, filter)
+}
+
+// Remove the filter with the given name for the list type with the given file extension.
+func (f Filters) Remove(ext, name string) Filters {
+	ext = strings.ToLower(ext)
+	for i, one := range f {
+		if one.Type == ext && strings.EqualFold(one.Name, name) {
+			return append(f[:i], f[i+1:]...)
+		}
+	}
+	return f
+}
+
+// Resolve returns the filters to use for the text, which may be either the name of one or more saved filters or a
+// filter expression. An expression is parsed for each list type, keeping those for which it is valid.
+func Resolve(saved Filters, text string) ([]*Filter, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, nil
+	}
+	var list []*Filter
+	for _, one := range saved {
+		if strings.EqualFold(one.Name, text) {
+			list = append(list, one)
+		}
+	}
+	if len(list) != 0 {
+		return list, nil
+	}
+	for _, ext := range library.IndexedExtensions {
+		if f, err := Parse(ext, text); err == nil {
+			list = append(list, f)
+		}
+	}
+	if len(list) == 0 {
+		return nil, errs.Newf(i18n.Text("%q is neither the name of a saved filter nor a valid filter for any list type"), text)
+	}
+	return list, nil
+}
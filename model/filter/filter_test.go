@@ -0,0 +1,92 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package filter_test
+
+import (
+	"testing"
+
+	"github.com/richardwilkes/gcs/model/criteria"
+	"github.com/richardwilkes/gcs/model/filter"
+	"github.com/richardwilkes/gcs/model/fxp"
+	"github.com/richardwilkes/gcs/model/gurps"
+	"github.com/richardwilkes/gcs/model/gurps/measure"
+	"github.com/richardwilkes/gcs/model/library"
+	"github.com/richardwilkes/gcs/model/settings"
+	"github.com/richardwilkes/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newEquipment(name, tl, lc string, weight measure.Weight, tags ...string) *gurps.Equipment {
+	e := gurps.NewEquipment(nil, nil, false)
+	e.Name = name
+	e.TechLevel = tl
+	e.LegalityClass = lc
+	e.Weight = weight
+	e.Tags = tags
+	return e
+}
+
+func TestFilter(t *testing.T) {
+	if gurps.SettingsProvider == nil {
+		gurps.SettingsProvider = settings.Default()
+	}
+	f, err := filter.Parse(library.EquipmentExt, "TL ≤ 4, weight < 5 lb, tag Weapon, lc >= 3")
+	require.NoError(t, err)
+	require.Len(t, f.Conditions, 4)
+	assert.Equal(t, "tl <= 4, weight < 5 lb, tag = Weapon, lc >= 3", f.String())
+
+	dagger := newEquipment("Dagger", "0", "4", measure.Weight(fxp.Half), "Weapon", "Melee")
+	assert.True(t, f.Matches(dagger))
+	assert.False(t, f.Matches(newEquipment("Pistol", "6", "3", measure.Weight(fxp.Two), "Weapon")))
+	assert.False(t, f.Matches(newEquipment("Halberd", "2", "4", measure.Weight(fxp.From(12)), "Weapon")))
+	assert.False(t, f.Matches(newEquipment("Rope", "0", "4", measure.Weight(fxp.One), "Gear")))
+	assert.False(t, f.Matches(newEquipment("Club", "", "4", measure.Weight(fxp.Two), "Weapon")), "no tech level")
+	assert.False(t, f.Matches(gurps.NewTrait(nil, nil, false)), "wrong row type")
+
+	// Strict comparisons survive being saved, even though the comparisons used elsewhere do not offer them
+	data, err := json.Marshal(f)
+	require.NoError(t, err)
+	var loaded filter.Filter
+	require.NoError(t, json.Unmarshal(data, &loaded))
+	assert.Equal(t, f.String(), loaded.String())
+	assert.NotContains(t, criteria.AllNumericCompareTypes, criteria.NumericCompareType(filter.LessThan))
+
+	f, err = filter.Parse(library.EquipmentExt, `tag != Melee, name ~ "dag, "`)
+	require.NoError(t, err)
+	assert.False(t, f.Matches(dagger), "negated comparisons must hold for every tag")
+	assert.Equal(t, `tag != Melee, name ~ "dag, "`, f.String())
+	roundTrip, err := filter.Parse(library.EquipmentExt, f.String())
+	require.NoError(t, err)
+	assert.Equal(t, f, roundTrip)
+
+	for _, text := range []string{"bogus = 1", "tl ~ 3", "weight < heavy", "tl 3", `name = "oops`} {
+		_, err = filter.Parse(library.EquipmentExt, text)
+		assert.Error(t, err, text)
+	}
+	_, err = filter.Parse(library.SheetExt, "name = x")
+	assert.Error(t, err)
+
+	saved := filter.Filters{}.Put(&filter.Filter{Name: "Cheap", Type: library.EquipmentExt})
+	saved = saved.Put(&filter.Filter{Name: "Arcane", Type: library.SpellsExt})
+	assert.Len(t, saved.For(library.EquipmentExt), 1)
+	assert.NotNil(t, saved.Lookup(library.SpellsExt, "arcane"))
+	list, err := filter.Resolve(saved, "cheap")
+	require.NoError(t, err)
+	assert.Equal(t, []*filter.Filter{saved[0]}, list)
+	list, err = filter.Resolve(saved, "weight < 5")
+	require.NoError(t, err)
+	require.Len(t, list, 1, "only equipment has a numeric weight")
+	assert.Equal(t, library.EquipmentExt, list[0].Type)
+	saved = saved.Remove(library.EquipmentExt, "Cheap")
+	assert.Empty(t, saved.For(library.EquipmentExt))
+}
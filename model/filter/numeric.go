@@ -0,0 +1,145 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package filter
+
+import (
+	"github.com/richardwilkes/gcs/model/criteria"
+	"github.com/richardwilkes/gcs/model/fxp"
+	"github.com/richardwilkes/gcs/model/gurps/measure"
+	"github.com/richardwilkes/json"
+	"github.com/richardwilkes/toolbox/i18n"
+)
+
+// Possible NumericCompareType values. Besides the comparisons offered by criteria.NumericCompareType, filters also
+// support strict comparisons.
+const (
+	AnyNumber   = NumericCompareType(criteria.AnyNumber)
+	Equals      = NumericCompareType(criteria.Equals)
+	NotEquals   = NumericCompareType(criteria.NotEquals)
+	AtLeast     = NumericCompareType(criteria.AtLeast)
+	AtMost      = NumericCompareType(criteria.AtMost)
+	LessThan    = NumericCompareType("less_than")
+	GreaterThan = NumericCompareType("greater_than")
+)
+
+// AllNumericCompareTypes is the complete set of NumericCompareType values.
+var AllNumericCompareTypes = []NumericCompareType{
+	AnyNumber,
+	Equals,
+	NotEquals,
+	AtLeast,
+	AtMost,
+	LessThan,
+	GreaterThan,
+}
+
+// NumericCompareType holds the type for a numeric comparison within a filter.
+type NumericCompareType string
+
+// EnsureValid ensures this is of a known value.
+func (n NumericCompareType) EnsureValid() NumericCompareType {
+	for _, one := range AllNumericCompareTypes {
+		if one == n {
+			return n
+		}
+	}
+	return AllNumericCompareTypes[0]
+}
+
+// String implements fmt.Stringer.
+func (n NumericCompareType) String() string {
+	switch n {
+	case LessThan:
+		return i18n.Text("is less than")
+	case GreaterThan:
+		return i18n.Text("is greater than")
+	default:
+		return criteria.NumericCompareType(n).String()
+	}
+}
+
+// Describe returns a description of this NumericCompareType using a qualifier.
+func (n NumericCompareType) Describe(qualifier string) string {
+	v := n.EnsureValid()
+	if v == AnyNumber {
+		return v.String()
+	}
+	return v.String() + " " + qualifier
+}
+
+// Matches performs a comparison and returns true if the data matches.
+func (n NumericCompareType) Matches(qualifier, data fxp.Int) bool {
+	switch n {
+	case LessThan:
+		return data < qualifier
+	case GreaterThan:
+		return data > qualifier
+	default:
+		return criteria.NumericCompareType(n).Matches(qualifier, data)
+	}
+}
+
+// Numeric holds the criteria for matching a number.
+type Numeric struct {
+	Compare   NumericCompareType `json:"compare,omitempty"`
+	Qualifier fxp.Int            `json:"qualifier,omitempty"`
+}
+
+// ShouldOmit implements json.Omitter.
+func (n Numeric) ShouldOmit() bool {
+	return n.Compare.EnsureValid() == AnyNumber
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (n *Numeric) UnmarshalJSON(data []byte) error {
+	type numeric Numeric
+	err := json.Unmarshal(data, (*numeric)(n))
+	n.Compare = n.Compare.EnsureValid()
+	return err
+}
+
+// Matches performs a comparison and returns true if the data matches.
+func (n Numeric) Matches(value fxp.Int) bool {
+	return n.Compare.Matches(n.Qualifier, value)
+}
+
+func (n Numeric) String() string {
+	return n.Compare.Describe(n.Qualifier.String())
+}
+
+// Weight holds the criteria for matching a weight.
+type Weight struct {
+	Compare   NumericCompareType `json:"compare,omitempty"`
+	Qualifier measure.Weight     `json:"qualifier,omitempty"`
+}
+
+// ShouldOmit implements json.Omitter.
+func (w Weight) ShouldOmit() bool {
+	return w.Compare.EnsureValid() == AnyNumber
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (w *Weight) UnmarshalJSON(data []byte) error {
+	type weight Weight
+	err := json.Unmarshal(data, (*weight)(w))
+	w.Compare = w.Compare.EnsureValid()
+	return err
+}
+
+// Matches performs a comparison and returns true if the data matches.
+func (w Weight) Matches(value measure.Weight) bool {
+	return w.Compare.Matches(fxp.Int(w.Qualifier), fxp.Int(value))
+}
+
+func (w Weight) String() string {
+	return w.Compare.Describe(w.Qualifier.String())
+}
@@ -0,0 +1,105 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package filter
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/richardwilkes/gcs/model/gurps"
+	"github.com/richardwilkes/gcs/model/library"
+	"github.com/richardwilkes/toolbox/errs"
+	"github.com/richardwilkes/toolbox/i18n"
+	"github.com/richardwilkes/toolbox/log/jot"
+)
+
+var loaders = map[string]func(filePath string) (map[uuid.UUID]any, error){
+	library.TraitsExt:             loader(gurps.NewTraitsFromFile),
+	library.TraitModifiersExt:     loader(gurps.NewTraitModifiersFromFile),
+	library.SkillsExt:             loader(gurps.NewSkillsFromFile),
+	library.SpellsExt:             loader(gurps.NewSpellsFromFile),
+	library.EquipmentExt:          loader(gurps.NewEquipmentFromFile),
+	library.EquipmentModifiersExt: loader(gurps.NewEquipmentModifiersFromFile),
+	library.NotesExt:              loader(gurps.NewNotesFromFile),
+}
+
+func loader[T gurps.Node[T]](load func(fs.FS, string) ([]T, error)) func(filePath string) (map[uuid.UUID]any, error) {
+	return func(filePath string) (map[uuid.UUID]any, error) {
+		rows, err := load(os.DirFS(filepath.Dir(filePath)), filepath.Base(filePath))
+		if err != nil {
+			return nil, err
+		}
+		m := make(map[uuid.UUID]any)
+		var collect func(list []T)
+		collect = func(list []T) {
+			for _, one := range list {
+				m[one.UUID()] = one
+				collect(one.NodeChildren())
+			}
+		}
+		collect(rows)
+		return m, nil
+	}
+}
+
+// Search the library index for rows containing each of the whitespace-separated terms in the text and which satisfy
+// the filter for their list type, writing one line per match to w. Only list types with a filter are searched, unless
+// no filters are provided, in which case all list types are searched.
+func Search(w io.Writer, idx *library.Index, libs library.Libraries, text string, filters []*Filter) error {
+	query := library.IndexQuery{Text: text}
+	byExt := make(map[string]*Filter, len(filters))
+	for _, f := range filters {
+		byExt[f.Type] = f
+		query.Extensions = append(query.Extensions, f.Type)
+	}
+	rowsByPath := make(map[string]map[uuid.UUID]any)
+	count := 0
+	for _, entry := range idx.Search(query) {
+		if f, ok := byExt[entry.Extension()]; ok && !f.Empty() {
+			rows, loaded := rowsByPath[entry.Path]
+			if !loaded {
+				var err error
+				if rows, err = loaders[entry.Extension()](entry.Path); err != nil {
+					jot.Warn(errs.NewWithCause("unable to load "+entry.Path, err))
+				}
+				rowsByPath[entry.Path] = rows
+			}
+			row, exists := rows[entry.ID]
+			if !exists || !f.Matches(row) {
+				continue
+			}
+		}
+		libTitle := entry.LibraryKey
+		rel := entry.Path
+		if lib, ok := libs[entry.LibraryKey]; ok {
+			libTitle = lib.Title
+			if r, err := filepath.Rel(lib.PathOnDisk, entry.Path); err == nil {
+				rel = filepath.ToSlash(r)
+			}
+		}
+		name := entry.Name
+		if strings.TrimSpace(name) == "" {
+			name = i18n.Text("(unnamed)")
+		}
+		if _, err := fmt.Fprintf(w, "%s\t%s: %s\n", name, libTitle, rel); err != nil {
+			return errs.Wrap(err)
+		}
+		count++
+	}
+	_, err := fmt.Fprintf(w, i18n.Text("%d matches.\n"), count)
+	return errs.Wrap(err)
+}
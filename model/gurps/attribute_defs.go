@@ -87,7 +87,7 @@ func NewAttributeDefsFromFile(fileSystem fs.FS, filePath string) (*AttributeDefs
 		OldKey2 *AttributeDefs `json:"attributes"`
 	}
 	if err := jio.LoadFromFS(context.Background(), fileSystem, filePath, &data); err != nil {
-		return nil, errs.NewWithCause(gid.InvalidFileDataMsg, err)
+		return nil, gid.InvalidFileData(err)
 	}
 	if data.Type != attributeSettingsListTypeKey {
 		return nil, errs.New(gid.UnexpectedFileDataMsg)
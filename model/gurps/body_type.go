@@ -87,7 +87,7 @@ func NewBodyTypeFromFile(fileSystem fs.FS, filePath string) (*BodyType, error) {
 		HitLocations []*HitLocation `json:"hit_locations"`
 	}
 	if err := jio.LoadFromFS(context.Background(), fileSystem, filePath, &data); err != nil {
-		return nil, errs.NewWithCause(gid.InvalidFileDataMsg, err)
+		return nil, gid.InvalidFileData(err)
 	}
 	if data.Type != bodyTypeListTypeKey {
 		return nil, errs.New(gid.UnexpectedFileDataMsg)
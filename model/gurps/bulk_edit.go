@@ -0,0 +1,234 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package gurps
+
+import (
+	"strings"
+
+	"github.com/richardwilkes/gcs/model/fxp"
+	"github.com/richardwilkes/gcs/model/gurps/measure"
+	"github.com/richardwilkes/toolbox/txt"
+	"golang.org/x/exp/slices"
+)
+
+// Possible BulkEditCapability values.
+const (
+	BulkEditTags BulkEditCapability = 1 << iota
+	BulkEditTechLevel
+	BulkEditCost
+	BulkEditWeight
+	BulkEditModifiers
+)
+
+// BulkEditCapability holds flags indicating which portions of a BulkEdit are applicable to a type of row. Searching and
+// replacing text is applicable to all types of rows.
+type BulkEditCapability uint8
+
+// BulkEdit holds a set of changes that may be applied to many rows at once. Fields left at their zero value make no
+// change.
+type BulkEdit struct {
+	// Tags are added to the existing tags of each row, or replace them entirely if ReplaceTags is true.
+	Tags        []string
+	ReplaceTags bool
+	// Find is replaced by Replace within the name, notes and page reference of each row.
+	Find    string
+	Replace string
+	// TechLevelDelta is added to the tech level of each row that has one.
+	TechLevelDelta fxp.Int
+	// CostFactor and WeightFactor scale the cost and weight of each row. Zero and one both leave them unchanged.
+	CostFactor   fxp.Int
+	WeightFactor fxp.Int
+	// AddModifierName, if not empty, adds a new modifier with this name and a cost of AddModifierCost to each row.
+	AddModifierName string
+	AddModifierCost string
+	// RemoveModifierName, if not empty, removes any modifiers with this name from each row.
+	RemoveModifierName string
+}
+
+// BulkEditCapabilitiesFor returns the capabilities applicable to the row's type.
+func BulkEditCapabilitiesFor(row any) BulkEditCapability {
+	switch row.(type) {
+	case *Trait:
+		return BulkEditTags | BulkEditCost | BulkEditModifiers
+	case *Skill, *Spell, *EquipmentModifier:
+		return BulkEditTags | BulkEditTechLevel
+	case *Equipment:
+		return BulkEditTags | BulkEditTechLevel | BulkEditCost | BulkEditWeight | BulkEditModifiers
+	case *TraitModifier:
+		return BulkEditTags
+	default:
+		return 0
+	}
+}
+
+// Empty returns true if applying this would make no changes.
+func (b *BulkEdit) Empty() bool {
+	return len(b.Tags) == 0 && !b.ReplaceTags && b.Find == "" && b.TechLevelDelta == 0 &&
+		!b.scales(b.CostFactor) && !b.scales(b.WeightFactor) && strings.TrimSpace(b.AddModifierName) == "" &&
+		strings.TrimSpace(b.RemoveModifierName) == ""
+}
+
+// Apply the changes to the row, which may be any of the types of rows found in lists. Returns true if the row was
+// altered.
+func (b *BulkEdit) Apply(row any) bool {
+	var altered bool
+	switch r := row.(type) {
+	case *Trait:
+		altered = b.applyText(&r.Name, &r.LocalNotes, &r.PageRef)
+		altered = b.applyTags(&r.Tags) || altered
+		if !r.Container() && b.scales(b.CostFactor) {
+			r.BasePoints = r.BasePoints.Mul(b.CostFactor)
+			r.PointsPerLevel = r.PointsPerLevel.Mul(b.CostFactor)
+			altered = true
+		}
+		if name := strings.TrimSpace(b.AddModifierName); name != "" {
+			mod := NewTraitModifier(r.Entity, nil, false)
+			mod.Name = name
+			mod.Cost = fxp.FromStringForced(strings.TrimSuffix(strings.TrimSpace(b.AddModifierCost), "%"))
+			r.Modifiers = append(r.Modifiers, mod)
+			altered = true
+		}
+		if list, removed := removeModifiers(r.Modifiers, b.RemoveModifierName); removed {
+			r.Modifiers = list
+			altered = true
+		}
+	case *TraitModifier:
+		altered = b.applyText(&r.Name, &r.LocalNotes, &r.PageRef)
+		altered = b.applyTags(&r.Tags) || altered
+	case *Skill:
+		altered = b.applyText(&r.Name, &r.LocalNotes, &r.PageRef)
+		altered = b.applyTags(&r.Tags) || altered
+		if r.TechLevel != nil {
+			altered = b.applyTechLevel(r.TechLevel) || altered
+		}
+	case *Spell:
+		altered = b.applyText(&r.Name, &r.LocalNotes, &r.PageRef)
+		altered = b.applyTags(&r.Tags) || altered
+		if r.TechLevel != nil {
+			altered = b.applyTechLevel(r.TechLevel) || altered
+		}
+	case *Equipment:
+		altered = b.applyText(&r.Name, &r.LocalNotes, &r.PageRef)
+		altered = b.applyTags(&r.Tags) || altered
+		altered = b.applyTechLevel(&r.TechLevel) || altered
+		if b.scales(b.CostFactor) {
+			r.Value = r.Value.Mul(b.CostFactor)
+			altered = true
+		}
+		if b.scales(b.WeightFactor) {
+			r.Weight = measure.Weight(fxp.Int(r.Weight).Mul(b.WeightFactor))
+			altered = true
+		}
+		if name := strings.TrimSpace(b.AddModifierName); name != "" {
+			mod := NewEquipmentModifier(r.Entity, nil, false)
+			mod.Name = name
+			mod.CostAmount = mod.CostType.Format(b.AddModifierCost)
+			r.Modifiers = append(r.Modifiers, mod)
+			altered = true
+		}
+		if list, removed := removeModifiers(r.Modifiers, b.RemoveModifierName); removed {
+			r.Modifiers = list
+			altered = true
+		}
+	case *EquipmentModifier:
+		altered = b.applyText(&r.Name, &r.LocalNotes, &r.PageRef)
+		altered = b.applyTags(&r.Tags) || altered
+		altered = b.applyTechLevel(&r.TechLevel) || altered
+	case *Note:
+		altered = b.applyText(&r.Text, &r.PageRef)
+	}
+	return altered
+}
+
+func (b *BulkEdit) scales(factor fxp.Int) bool {
+	return factor != 0 && factor != fxp.One
+}
+
+func (b *BulkEdit) applyText(fields ...*string) bool {
+	if b.Find == "" {
+		return false
+	}
+	altered := false
+	for _, field := range fields {
+		if s := strings.ReplaceAll(*field, b.Find, b.Replace); s != *field {
+			*field = s
+			altered = true
+		}
+	}
+	return altered
+}
+
+func (b *BulkEdit) applyTags(tags *[]string) bool {
+	var list []string
+	if !b.ReplaceTags {
+		if len(b.Tags) == 0 {
+			return false
+		}
+		list = txt.CloneStringSlice(*tags)
+	}
+	for _, tag := range b.Tags {
+		if tag = strings.TrimSpace(tag); tag != "" && !slices.Contains(list, tag) {
+			list = append(list, tag)
+		}
+	}
+	if slices.Equal(list, *tags) {
+		return false
+	}
+	*tags = list
+	return true
+}
+
+func (b *BulkEdit) applyTechLevel(tl *string) bool {
+	if b.TechLevelDelta == 0 {
+		return false
+	}
+	if _, start, _ := ExtractTechLevel(*tl); start == -1 {
+		return false
+	}
+	s, changed := AdjustTechLevel(*tl, b.TechLevelDelta)
+	if changed {
+		*tl = s
+	}
+	return changed
+}
+
+func removeModifiers[T NodeConstraint[T]](list []T, name string) (revised []T, removed bool) {
+	if name = strings.TrimSpace(name); name == "" {
+		return list, false
+	}
+	revised = make([]T, 0, len(list))
+	for _, one := range list {
+		if strings.EqualFold(modifierName(one), name) {
+			removed = true
+			continue
+		}
+		if one.Container() {
+			if children, childRemoved := removeModifiers(one.NodeChildren(), name); childRemoved {
+				one.SetChildren(children)
+				removed = true
+			}
+		}
+		revised = append(revised, one)
+	}
+	return revised, removed
+}
+
+func modifierName(mod any) string {
+	switch m := mod.(type) {
+	case *TraitModifier:
+		return m.Name
+	case *EquipmentModifier:
+		return m.Name
+	default:
+		return ""
+	}
+}
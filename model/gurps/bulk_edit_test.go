@@ -0,0 +1,71 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package gurps_test
+
+import (
+	"testing"
+
+	"github.com/richardwilkes/gcs/model/fxp"
+	"github.com/richardwilkes/gcs/model/gurps"
+	"github.com/richardwilkes/gcs/model/gurps/measure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBulkEdit(t *testing.T) {
+	edit := &gurps.BulkEdit{}
+	assert.True(t, edit.Empty())
+	edit.CostFactor = fxp.One
+	assert.True(t, edit.Empty(), "a factor of one makes no change")
+
+	trait := gurps.NewTrait(nil, nil, false)
+	trait.Name = "Old Trait"
+	trait.Tags = []string{"Advantage"}
+	trait.BasePoints = fxp.From(10)
+	edit = &gurps.BulkEdit{
+		Tags:            []string{"Advantage", "Mental"},
+		Find:            "Old",
+		Replace:         "New",
+		CostFactor:      fxp.Half,
+		AddModifierName: "Extra",
+		AddModifierCost: "+20%",
+	}
+	assert.True(t, edit.Apply(trait))
+	assert.Equal(t, "New Trait", trait.Name)
+	assert.Equal(t, []string{"Advantage", "Mental"}, trait.Tags)
+	assert.Equal(t, fxp.From(5), trait.BasePoints)
+	if assert.Len(t, trait.Modifiers, 1) {
+		assert.Equal(t, fxp.From(20), trait.Modifiers[0].Cost)
+	}
+	assert.True(t, (&gurps.BulkEdit{RemoveModifierName: "extra"}).Apply(trait))
+	assert.Empty(t, trait.Modifiers)
+	assert.False(t, (&gurps.BulkEdit{RemoveModifierName: "extra"}).Apply(trait))
+
+	eqp := gurps.NewEquipment(nil, nil, false)
+	eqp.TechLevel = "3"
+	eqp.Value = fxp.From(10)
+	eqp.Weight = measure.Weight(fxp.From(4))
+	edit = &gurps.BulkEdit{
+		Tags:           []string{"Gear"},
+		ReplaceTags:    true,
+		TechLevelDelta: fxp.One,
+		CostFactor:     fxp.Two,
+		WeightFactor:   fxp.Half,
+	}
+	assert.True(t, edit.Apply(eqp))
+	assert.Equal(t, []string{"Gear"}, eqp.Tags)
+	assert.Equal(t, "4", eqp.TechLevel)
+	assert.Equal(t, fxp.From(20), eqp.Value)
+	assert.Equal(t, measure.Weight(fxp.From(2)), eqp.Weight)
+
+	eqp.TechLevel = ""
+	assert.False(t, (&gurps.BulkEdit{TechLevelDelta: fxp.One}).Apply(eqp), "rows without a tech level are left alone")
+}
@@ -11,7 +11,11 @@
 
 package gurps
 
-import "github.com/richardwilkes/unison"
+import (
+	"github.com/richardwilkes/gcs/model/crc"
+	"github.com/richardwilkes/gcs/model/rolls"
+	"github.com/richardwilkes/unison"
+)
 
 // PageRefCellAlias is used an alias to request the page reference cell, if any.
 const PageRefCellAlias = -10
@@ -27,6 +31,10 @@ type CellData struct {
 	Secondary         string
 	Tooltip           string
 	UnsatisfiedReason string
+	Roll              rolls.Request
+	// RollLabel, if not empty, causes the roll to be offered via a separate label with this text, rather than by
+	// clicking anywhere within the cell.
+	RollLabel string
 }
 
 // ForSort returns a string that can be used to sort or search against for this data.
@@ -46,3 +54,27 @@ func (c *CellData) ForSort() string {
 	}
 	return ""
 }
+
+// CRC64 returns the CRC-64 value for the cell data starting with the given value. Two cells with the same CRC-64 will
+// have identical visual representations.
+func (c *CellData) CRC64(value uint64) uint64 {
+	value = crc.Byte(value, byte(c.Type))
+	value = crc.Byte(value, boolByte(c.Disabled)|boolByte(c.Dim)<<1|boolByte(c.Checked)<<2)
+	value = crc.Byte(value, byte(c.Alignment))
+	value = crc.Byte(value, byte(c.Roll.Kind))
+	value = crc.Number(value, c.Roll.Target)
+	for _, s := range []string{c.Primary, c.Secondary, c.Tooltip, c.UnsatisfiedReason, c.Roll.Who, c.Roll.What,
+		c.Roll.Damage, c.RollLabel} {
+		// Include the length so that text moving from one field to an adjacent one is still seen as a change
+		value = crc.Number(value, len(s))
+		value = crc.String(value, s)
+	}
+	return value
+}
+
+func boolByte(b bool) byte {
+	if b {
+		return 1
+	}
+	return 0
+}
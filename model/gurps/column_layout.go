@@ -0,0 +1,115 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package gurps
+
+import "golang.org/x/exp/slices"
+
+// ColumnLayout holds the columns a list should show, in the order they should be shown. The column IDs are the column
+// constants used with the .CellData() method of the list's rows.
+type ColumnLayout struct {
+	Columns []*ColumnState `json:"columns"`
+}
+
+// ColumnState holds the state of a single column within a ColumnLayout. A Width of zero means the width should be
+// determined automatically.
+type ColumnState struct {
+	ID    int     `json:"id"`
+	Width float32 `json:"width,omitempty"`
+}
+
+// NewColumnLayout creates a new ColumnLayout with the given column IDs.
+func NewColumnLayout(ids ...int) *ColumnLayout {
+	layout := &ColumnLayout{Columns: make([]*ColumnState, len(ids))}
+	for i, id := range ids {
+		layout.Columns[i] = &ColumnState{ID: id}
+	}
+	return layout
+}
+
+// NewColumnLayoutFromColMap creates a new ColumnLayout from a column map, which maps column indexes to column IDs.
+func NewColumnLayoutFromColMap(colMap map[int]int) *ColumnLayout {
+	ids := make([]int, len(colMap))
+	for i := range ids {
+		ids[i] = colMap[i]
+	}
+	return NewColumnLayout(ids...)
+}
+
+// Clone creates a copy of this.
+func (l *ColumnLayout) Clone() *ColumnLayout {
+	if l == nil {
+		return nil
+	}
+	clone := &ColumnLayout{Columns: make([]*ColumnState, len(l.Columns))}
+	for i, one := range l.Columns {
+		state := *one
+		clone.Columns[i] = &state
+	}
+	return clone
+}
+
+// EnsureValidity removes any columns that aren't in the list of available column IDs, as well as any duplicates. Pass
+// nil for 'available' to only remove duplicates.
+func (l *ColumnLayout) EnsureValidity(available []int) {
+	seen := make(map[int]bool, len(l.Columns))
+	columns := make([]*ColumnState, 0, len(l.Columns))
+	for _, one := range l.Columns {
+		if one == nil || seen[one.ID] || (available != nil && !slices.Contains(available, one.ID)) {
+			continue
+		}
+		seen[one.ID] = true
+		if one.Width < 0 {
+			one.Width = 0
+		}
+		columns = append(columns, one)
+	}
+	l.Columns = columns
+}
+
+// Index returns the index of the column with the given ID, or -1 if it isn't present.
+func (l *ColumnLayout) Index(id int) int {
+	for i, one := range l.Columns {
+		if one.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// Toggle adds the column with the given ID to the end of the layout if it isn't present, otherwise removes it.
+func (l *ColumnLayout) Toggle(id int) {
+	if i := l.Index(id); i != -1 {
+		l.Columns = slices.Delete(l.Columns, i, i+1)
+	} else {
+		l.Columns = append(l.Columns, &ColumnState{ID: id})
+	}
+}
+
+// Move the column at the given index by the specified number of positions. Returns false if this isn't possible.
+func (l *ColumnLayout) Move(index, delta int) bool {
+	target := index + delta
+	if index < 0 || index >= len(l.Columns) || target < 0 || target >= len(l.Columns) || delta == 0 {
+		return false
+	}
+	column := l.Columns[index]
+	l.Columns = slices.Insert(slices.Delete(l.Columns, index, index+1), target, column)
+	return true
+}
+
+// ColMap returns a column map, which maps column indexes to column IDs, for this layout.
+func (l *ColumnLayout) ColMap() map[int]int {
+	m := make(map[int]int, len(l.Columns))
+	for i, one := range l.Columns {
+		m[i] = one.ID
+	}
+	return m
+}
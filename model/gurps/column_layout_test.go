@@ -0,0 +1,57 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package gurps_test
+
+import (
+	"testing"
+
+	"github.com/richardwilkes/gcs/model/gurps"
+	"github.com/richardwilkes/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestColumnLayout(t *testing.T) {
+	layout := gurps.NewColumnLayout(gurps.TraitDescriptionColumn, gurps.TraitPointsColumn, gurps.TraitReferenceColumn)
+	layout.Toggle(gurps.TraitIDColumn)
+	layout.Toggle(gurps.TraitPointsColumn)
+	assert.Equal(t, map[int]int{
+		0: gurps.TraitDescriptionColumn,
+		1: gurps.TraitReferenceColumn,
+		2: gurps.TraitIDColumn,
+	}, layout.ColMap())
+	assert.True(t, layout.Move(2, -2))
+	assert.False(t, layout.Move(0, -1))
+	assert.Equal(t, 0, layout.Index(gurps.TraitIDColumn))
+	assert.Equal(t, -1, layout.Index(gurps.TraitPointsColumn))
+
+	layout.Columns = append(layout.Columns, &gurps.ColumnState{ID: gurps.TraitIDColumn}, &gurps.ColumnState{ID: 99})
+	layout.EnsureValidity([]int{gurps.TraitDescriptionColumn, gurps.TraitReferenceColumn, gurps.TraitIDColumn})
+	assert.Len(t, layout.Columns, 3, "duplicates and unavailable columns should be removed")
+
+	s := gurps.FactorySheetSettings()
+	layout.Columns[1].Width = 150
+	s.SetColumnLayout("traits_list", layout)
+	layout.Columns[1].Width = 10
+	clone := s.Clone(nil)
+	s.SetColumnLayout("traits_list", nil)
+	assert.Nil(t, s.ColumnLayout("traits_list"))
+	stored := clone.ColumnLayout("traits_list")
+	require.NotNil(t, stored)
+	assert.Equal(t, float32(150), stored.Columns[1].Width, "layouts should be copied, not shared")
+
+	data, err := json.Marshal(clone)
+	require.NoError(t, err)
+	var loaded gurps.SheetSettings
+	require.NoError(t, json.Unmarshal(data, &loaded))
+	assert.Equal(t, stored, loaded.ColumnLayout("traits_list"))
+}
@@ -0,0 +1,385 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package gurps
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/richardwilkes/gcs/model/crc"
+	"github.com/richardwilkes/gcs/model/fxp"
+	"github.com/richardwilkes/gcs/model/gurps/gid"
+	"github.com/richardwilkes/gcs/model/id"
+	"github.com/richardwilkes/gcs/model/jio"
+	"github.com/richardwilkes/toolbox/errs"
+	"github.com/richardwilkes/toolbox/i18n"
+)
+
+const encounterTypeKey = "encounter"
+
+// Encounter holds the combatants participating in a fight, along with the current round and whose turn it is.
+type Encounter struct {
+	Type       string       `json:"type"`
+	Version    int          `json:"version"`
+	ID         uuid.UUID    `json:"id"`
+	Round      int          `json:"round"`
+	Turn       int          `json:"turn"`
+	Combatants []*Combatant `json:"combatants,omitempty"`
+}
+
+// Combatant holds a participant in an Encounter. The entity is a snapshot taken when the combatant was added, so damage
+// tracked here does not alter the sheet it came from.
+type Combatant struct {
+	ID     uuid.UUID `json:"id"`
+	Name   string    `json:"name"`
+	Source string    `json:"source,omitempty"`
+	Notes  string    `json:"notes,omitempty"`
+	Entity *Entity   `json:"entity"`
+}
+
+// NewEncounterFromFile loads an Encounter from a file.
+func NewEncounterFromFile(fileSystem fs.FS, filePath string) (*Encounter, error) {
+	var encounter Encounter
+	if err := jio.LoadFromFS(context.Background(), fileSystem, filePath, &encounter); err != nil {
+		return nil, gid.InvalidFileData(err)
+	}
+	if encounter.Type != encounterTypeKey {
+		return nil, errs.New(gid.UnexpectedFileDataMsg)
+	}
+	if err := gid.CheckVersion(encounter.Version); err != nil {
+		return nil, err
+	}
+	encounter.Version = gid.CurrentDataVersion
+	list := encounter.Combatants[:0]
+	for _, one := range encounter.Combatants {
+		if one != nil && one.Entity != nil {
+			list = append(list, one)
+		}
+	}
+	encounter.Combatants = list
+	encounter.normalizeTurn()
+	return &encounter, nil
+}
+
+// NewEncounter creates a new Encounter.
+func NewEncounter() *Encounter {
+	return &Encounter{
+		Type:    encounterTypeKey,
+		Version: gid.CurrentDataVersion,
+		ID:      id.NewUUID(),
+		Round:   1,
+	}
+}
+
+// Save the Encounter to a file as JSON.
+func (e *Encounter) Save(filePath string) error {
+	return jio.SaveToFile(context.Background(), filePath, e)
+}
+
+// CRC64 computes a CRC-64 value for the canonical disk format of the data.
+func (e *Encounter) CRC64() uint64 {
+	var buffer bytes.Buffer
+	if err := jio.Save(context.Background(), &buffer, e); err != nil {
+		return 0
+	}
+	return crc.Bytes(0, buffer.Bytes())
+}
+
+// Current returns the combatant whose turn it is, or nil if there are no combatants.
+func (e *Encounter) Current() *Combatant {
+	if e.Turn < 0 || e.Turn >= len(e.Combatants) {
+		return nil
+	}
+	return e.Combatants[e.Turn]
+}
+
+// AddCombatant adds a snapshot of the entity to the encounter. The source is the path of the file the entity came from,
+// if any. Combatants sharing a name are numbered so they can be told apart.
+func (e *Encounter) AddCombatant(entity *Entity, source string) (*Combatant, error) {
+	snapshot, err := entity.Clone()
+	if err != nil {
+		return nil, err
+	}
+	// Proposals are of no use during an encounter and would otherwise add a full copy of the character for each one
+	snapshot.Proposals = nil
+	c := &Combatant{
+		ID:     id.NewUUID(),
+		Name:   e.uniqueName(entity.RollerName()),
+		Source: source,
+		Entity: snapshot,
+	}
+	e.Combatants = append(e.Combatants, c)
+	return c, nil
+}
+
+func (e *Encounter) uniqueName(name string) string {
+	used := make(map[string]bool, len(e.Combatants))
+	for _, one := range e.Combatants {
+		used[one.Name] = true
+	}
+	if !used[name] {
+		return name
+	}
+	for i := 2; ; i++ {
+		if candidate := fmt.Sprintf("%s %d", name, i); !used[candidate] {
+			return candidate
+		}
+	}
+}
+
+// RemoveCombatant removes the combatant from the encounter. If it was that combatant's turn, the turn passes to the
+// next one in line.
+func (e *Encounter) RemoveCombatant(c *Combatant) {
+	for i, one := range e.Combatants {
+		if one == c {
+			e.Combatants = append(e.Combatants[:i], e.Combatants[i+1:]...)
+			if i < e.Turn {
+				e.Turn--
+			}
+			e.normalizeTurn()
+			return
+		}
+	}
+}
+
+// SortByInitiative orders the combatants by Basic Speed, using DX to break ties (B363). Combatants that are still tied
+// retain their relative order. The combatant whose turn it is remains the current combatant.
+func (e *Encounter) SortByInitiative() {
+	current := e.Current()
+	sort.SliceStable(e.Combatants, func(i, j int) bool {
+		si := e.Combatants[i].BasicSpeed()
+		sj := e.Combatants[j].BasicSpeed()
+		if si != sj {
+			return si > sj
+		}
+		return e.Combatants[i].DX() > e.Combatants[j].DX()
+	})
+	for i, one := range e.Combatants {
+		if one == current {
+			e.Turn = i
+			break
+		}
+	}
+}
+
+// NextTurn passes the turn to the next combatant, starting a new round once everyone has acted.
+func (e *Encounter) NextTurn() {
+	if len(e.Combatants) == 0 {
+		return
+	}
+	e.Turn++
+	if e.Turn >= len(e.Combatants) {
+		e.Turn = 0
+		e.Round++
+	}
+}
+
+// PreviousTurn returns the turn to the prior combatant, backing up into the previous round if necessary.
+func (e *Encounter) PreviousTurn() {
+	if len(e.Combatants) == 0 {
+		return
+	}
+	e.Turn--
+	if e.Turn < 0 {
+		if e.Round > 1 {
+			e.Round--
+			e.Turn = len(e.Combatants) - 1
+		} else {
+			e.Turn = 0
+		}
+	}
+}
+
+// Restart returns the encounter to the first combatant's turn in round 1.
+func (e *Encounter) Restart() {
+	e.Round = 1
+	e.Turn = 0
+}
+
+func (e *Encounter) normalizeTurn() {
+	if e.Round < 1 {
+		e.Round = 1
+	}
+	if e.Turn >= len(e.Combatants) {
+		e.Turn = 0
+	}
+	if e.Turn < 0 {
+		e.Turn = 0
+	}
+}
+
+// BasicSpeed returns the combatant's Basic Speed.
+func (c *Combatant) BasicSpeed() fxp.Int {
+	return c.attributeValue(gid.BasicSpeed)
+}
+
+// DX returns the combatant's DX.
+func (c *Combatant) DX() fxp.Int {
+	return c.attributeValue(gid.Dexterity)
+}
+
+func (c *Combatant) attributeValue(attrID string) fxp.Int {
+	if v := c.Entity.ResolveAttributeCurrent(attrID); v != fxp.Min {
+		return v
+	}
+	return 0
+}
+
+// HP returns the combatant's hit points pool, or nil if it doesn't have one.
+func (c *Combatant) HP() *Attribute {
+	return c.Entity.ResolveAttribute(gid.HitPoints)
+}
+
+// FP returns the combatant's fatigue points pool, or nil if it doesn't have one.
+func (c *Combatant) FP() *Attribute {
+	return c.Entity.ResolveAttribute(gid.FatiguePoints)
+}
+
+// WoundingModifier describes a type of damage and how much injury its penetrating damage inflicts (B379).
+type WoundingModifier struct {
+	Key        string
+	Name       string
+	Multiplier fxp.Int
+	Fatigue    bool
+}
+
+// WoundingModifiers holds the standard damage types.
+var WoundingModifiers = []*WoundingModifier{
+	{Key: "cr", Name: i18n.Text("Crushing"), Multiplier: fxp.One},
+	{Key: "cut", Name: i18n.Text("Cutting"), Multiplier: fxp.OneAndAHalf},
+	{Key: "imp", Name: i18n.Text("Impaling"), Multiplier: fxp.Two},
+	{Key: "pi-", Name: i18n.Text("Small Piercing"), Multiplier: fxp.Half},
+	{Key: "pi", Name: i18n.Text("Piercing"), Multiplier: fxp.One},
+	{Key: "pi+", Name: i18n.Text("Large Piercing"), Multiplier: fxp.OneAndAHalf},
+	{Key: "pi++", Name: i18n.Text("Huge Piercing"), Multiplier: fxp.Two},
+	{Key: "burn", Name: i18n.Text("Burning"), Multiplier: fxp.One},
+	{Key: "cor", Name: i18n.Text("Corrosion"), Multiplier: fxp.One},
+	{Key: "tox", Name: i18n.Text("Toxic"), Multiplier: fxp.One},
+	{Key: "fat", Name: i18n.Text("Fatigue"), Multiplier: fxp.One, Fatigue: true},
+}
+
+func (w *WoundingModifier) String() string {
+	return fmt.Sprintf("%s (%s)", w.Name, w.Key)
+}
+
+// Damage describes an attack to be applied to a Combatant.
+type Damage struct {
+	Amount       int
+	Type         *WoundingModifier
+	LocationID   string
+	ArmorDivisor fxp.Int
+}
+
+// DamageResult describes the outcome of applying Damage to a Combatant.
+type DamageResult struct {
+	DR          int
+	Penetrating int
+	Injury      int
+	Fatigue     bool
+}
+
+func (r DamageResult) String() string {
+	var pool string
+	if r.Fatigue {
+		pool = i18n.Text("FP")
+	} else {
+		pool = i18n.Text("HP")
+	}
+	return fmt.Sprintf(i18n.Text("DR %d, %d penetrated, inflicting %d %s of injury"), r.DR, r.Penetrating, r.Injury,
+		pool)
+}
+
+// DR returns the combatant's DR at the given hit location against the given damage type.
+func (c *Combatant) DR(locationID, damageType string) int {
+	loc := BodyTypeFor(c.Entity).LookupLocationByID(c.Entity, locationID)
+	if loc == nil {
+		return 0
+	}
+	drMap := loc.DR(c.Entity, nil, nil)
+	dr := drMap[gid.All]
+	if damageType != gid.All {
+		dr += drMap[damageType]
+	}
+	return dr
+}
+
+// ApplyDamage reduces the damage by the DR at the hit location, applies the wounding modifier for the damage type and hit
+// location to what penetrates, and adds the resulting injury to the combatant's HP, or FP for fatigue damage.
+func (c *Combatant) ApplyDamage(damage Damage) DamageResult {
+	var result DamageResult
+	if damage.Type == nil {
+		damage.Type = WoundingModifiers[0]
+	}
+	result.Fatigue = damage.Type.Fatigue
+	result.DR = c.DR(damage.LocationID, damage.Type.Key)
+	dr := fxp.From(result.DR)
+	if damage.ArmorDivisor > 0 && damage.ArmorDivisor != fxp.One {
+		dr = dr.Div(damage.ArmorDivisor).Trunc()
+	}
+	result.Penetrating = damage.Amount - fxp.As[int](dr)
+	if result.Penetrating <= 0 {
+		result.Penetrating = 0
+		return result
+	}
+	injury := fxp.From(result.Penetrating).Mul(woundingMultiplier(damage.LocationID, damage.Type)).Trunc()
+	result.Injury = fxp.As[int](injury)
+	if result.Injury < 1 {
+		// Any penetrating damage inflicts at least 1 point of injury
+		result.Injury = 1
+	}
+	var pool *Attribute
+	if result.Fatigue {
+		pool = c.FP()
+	} else {
+		pool = c.HP()
+	}
+	if pool != nil {
+		pool.Damage += fxp.From(result.Injury)
+	}
+	return result
+}
+
+// woundingMultiplier returns the wounding modifier, adjusted for the hit location (B398-B400).
+func woundingMultiplier(locationID string, damageType *WoundingModifier) fxp.Int {
+	switch locationID {
+	case "skull", "eye":
+		if damageType.Key != "tox" && !damageType.Fatigue {
+			return fxp.Four
+		}
+	case "vitals":
+		switch damageType.Key {
+		case "imp", "pi-", "pi", "pi+", "pi++":
+			return fxp.Three
+		}
+	case "neck":
+		switch damageType.Key {
+		case "cr", "cor":
+			return fxp.OneAndAHalf
+		case "cut":
+			return fxp.Two
+		}
+	case "face":
+		if damageType.Key == "cor" {
+			return fxp.OneAndAHalf
+		}
+	case "arm", "leg", "hand", "foot":
+		switch damageType.Key {
+		case "imp", "pi+", "pi++":
+			return fxp.One
+		}
+	}
+	return damageType.Multiplier
+}
@@ -0,0 +1,98 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package gurps_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/richardwilkes/gcs/model/fxp"
+	"github.com/richardwilkes/gcs/model/gurps"
+	"github.com/richardwilkes/gcs/model/gurps/datafile"
+	"github.com/richardwilkes/gcs/model/gurps/gid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCombatantEntity(name string, dx, ht int) *gurps.Entity {
+	entity := gurps.NewEntity(datafile.PC)
+	entity.Profile.Name = name
+	entity.Attributes.Set[gid.Dexterity].Adjustment = fxp.From(dx - 10)
+	entity.Attributes.Set["ht"].Adjustment = fxp.From(ht - 10)
+	entity.Recalculate()
+	return entity
+}
+
+func TestEncounter(t *testing.T) {
+	ensureSettingsProvider()
+	e := gurps.NewEncounter()
+	slow, err := e.AddCombatant(newCombatantEntity("Slow", 10, 10), "")
+	require.NoError(t, err)
+	fast, err := e.AddCombatant(newCombatantEntity("Fast", 12, 12), "")
+	require.NoError(t, err)
+	deft, err := e.AddCombatant(newCombatantEntity("Deft", 13, 11), "")
+	require.NoError(t, err)
+	twin, err := e.AddCombatant(newCombatantEntity("Slow", 10, 10), "")
+	require.NoError(t, err)
+	assert.Equal(t, "Slow 2", twin.Name)
+
+	// Fast and Deft share a Basic Speed of 6, so DX breaks the tie; the two Slows retain their order
+	e.SortByInitiative()
+	assert.Equal(t, []*gurps.Combatant{deft, fast, slow, twin}, e.Combatants)
+	assert.Equal(t, slow, e.Current())
+	e.Restart()
+	assert.Equal(t, deft, e.Current())
+	for i := 0; i < 4; i++ {
+		e.NextTurn()
+	}
+	assert.Equal(t, 2, e.Round)
+	assert.Equal(t, deft, e.Current())
+	e.PreviousTurn()
+	assert.Equal(t, 1, e.Round)
+	assert.Equal(t, twin, e.Current())
+	e.RemoveCombatant(twin)
+	assert.Equal(t, deft, e.Current())
+
+	result := slow.ApplyDamage(gurps.Damage{Amount: 5, Type: gurps.WoundingModifiers[1], LocationID: "torso"})
+	assert.Equal(t, gurps.DamageResult{Penetrating: 5, Injury: 7}, result)
+	assert.Equal(t, fxp.Three, slow.HP().Current())
+
+	// The skull provides DR 2 and quadruples the injury
+	result = fast.ApplyDamage(gurps.Damage{Amount: 3, LocationID: "skull"})
+	assert.Equal(t, gurps.DamageResult{DR: 2, Penetrating: 1, Injury: 4}, result)
+
+	result = deft.ApplyDamage(gurps.Damage{Amount: 1, Type: gurps.WoundingModifiers[len(gurps.WoundingModifiers)-1]})
+	assert.Equal(t, gurps.DamageResult{Penetrating: 1, Injury: 1, Fatigue: true}, result)
+	assert.Equal(t, fxp.Ten, deft.FP().Current())
+
+	p := filepath.Join(t.TempDir(), "test.gce")
+	require.NoError(t, e.Save(p))
+	loaded, err := gurps.NewEncounterFromFile(os.DirFS(filepath.Dir(p)), filepath.Base(p))
+	require.NoError(t, err)
+	assert.Equal(t, e.CRC64(), loaded.CRC64())
+	require.Len(t, loaded.Combatants, 3)
+	assert.Equal(t, "Slow", loaded.Combatants[2].Name)
+	assert.Equal(t, fxp.Three, loaded.Combatants[2].HP().Current())
+}
+
+func TestEncounterDropsProposals(t *testing.T) {
+	ensureSettingsProvider()
+	entity := newCombatantEntity("Planner", 10, 10)
+	proposal, err := gurps.NewProposal("Next Session", entity)
+	require.NoError(t, err)
+	entity.SetProposal(proposal)
+	c, err := gurps.NewEncounter().AddCombatant(entity, "")
+	require.NoError(t, err)
+	assert.Empty(t, c.Entity.Proposals)
+	assert.Len(t, entity.Proposals, 1)
+}
@@ -37,7 +37,6 @@ import (
 	"github.com/richardwilkes/gcs/model/jio"
 	"github.com/richardwilkes/json"
 	"github.com/richardwilkes/rpgtools/dice"
-	"github.com/richardwilkes/toolbox/errs"
 	"github.com/richardwilkes/toolbox/eval"
 	"github.com/richardwilkes/toolbox/i18n"
 	"github.com/richardwilkes/toolbox/log/jot"
@@ -73,6 +72,7 @@ type EntityData struct {
 	CreatedOn        jio.Time       `json:"created_date"`
 	ModifiedOn       jio.Time       `json:"modified_date"`
 	ThirdParty       map[string]any `json:"third_party,omitempty"`
+	Proposals        []*Proposal    `json:"proposals,omitempty"`
 }
 
 // Entity holds the base information for various types of entities: PC, NPC, Creature, etc.
@@ -92,7 +92,7 @@ type Entity struct {
 func NewEntityFromFile(fileSystem fs.FS, filePath string) (*Entity, error) {
 	var entity Entity
 	if err := jio.LoadFromFS(context.Background(), fileSystem, filePath, &entity); err != nil {
-		return nil, errs.NewWithCause(gid.InvalidFileDataMsg, err)
+		return nil, gid.InvalidFileData(err)
 	}
 	if err := gid.CheckVersion(entity.Version); err != nil {
 		return nil, err
@@ -131,6 +131,19 @@ func (e *Entity) Save(filePath string) error {
 	return jio.SaveToFile(context.Background(), filePath, e)
 }
 
+// Clone creates a deep copy of the entity.
+func (e *Entity) Clone() (*Entity, error) {
+	var buffer bytes.Buffer
+	if err := jio.Save(context.Background(), &buffer, e); err != nil {
+		return nil, err
+	}
+	var clone Entity
+	if err := jio.Load(context.Background(), &buffer, &clone); err != nil {
+		return nil, err
+	}
+	return &clone, nil
+}
+
 // MarshalJSON implements json.Marshaler.
 func (e *Entity) MarshalJSON() ([]byte, error) {
 	e.Recalculate()
@@ -193,6 +206,33 @@ func (e *Entity) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// RollerName returns the name to use when recording rolls made for this entity.
+func (e *Entity) RollerName() string {
+	if e.Profile != nil && e.Profile.Name != "" {
+		return e.Profile.Name
+	}
+	return i18n.Text("Unnamed")
+}
+
+// AdoptSheetSettings replaces this entity's sheet settings with a copy of the provided ones. Attributes that are still
+// defined retain their current values, newly defined attributes are added and those no longer defined are dropped.
+func (e *Entity) AdoptSheetSettings(s *SheetSettings) {
+	e.SheetSettings = s.Clone(e)
+	e.SheetSettings.SetOwningEntity(e)
+	attributes := &Attributes{Set: make(map[string]*Attribute)}
+	for i, def := range e.SheetSettings.Attributes.List() {
+		attrID := def.ID()
+		if existing, ok := e.Attributes.Set[attrID]; ok {
+			existing.Order = i
+			attributes.Set[attrID] = existing
+		} else {
+			attributes.Set[attrID] = NewAttribute(e, attrID, i)
+		}
+	}
+	e.Attributes = attributes
+	e.Recalculate()
+}
+
 // Recalculate the statistics.
 func (e *Entity) Recalculate() {
 	e.ensureAttachments()
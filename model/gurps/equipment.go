@@ -51,6 +51,9 @@ const (
 	EquipmentExtendedWeightColumn
 	EquipmentTagsColumn
 	EquipmentReferenceColumn
+	EquipmentVTTNotesColumn
+	EquipmentLibrarySourceColumn
+	EquipmentIDColumn
 )
 
 var (
@@ -87,6 +90,7 @@ type Equipment struct {
 	EquipmentData
 	Entity            *Entity
 	UnsatisfiedReason string
+	Unknown           UnknownFields
 }
 
 type equipmentListData struct {
@@ -99,7 +103,7 @@ type equipmentListData struct {
 func NewEquipmentFromFile(fileSystem fs.FS, filePath string) ([]*Equipment, error) {
 	var data equipmentListData
 	if err := jio.LoadFromFS(context.Background(), fileSystem, filePath, &data); err != nil {
-		return nil, errs.NewWithCause(gid.InvalidFileDataMsg, err)
+		return nil, gid.InvalidFileData(err)
 	}
 	if data.Type != equipmentListTypeKey {
 		return nil, errs.New(gid.UnexpectedFileDataMsg)
@@ -145,6 +149,7 @@ func (e *Equipment) Clone(entity *Entity, parent *Equipment, preserveID bool) *E
 	}
 	other.IsOpen = e.IsOpen
 	other.EquipmentEditData.CopyFrom(e)
+	other.Unknown = e.Unknown.Clone()
 	if e.HasChildren() {
 		other.Children = make([]*Equipment, 0, len(e.Children))
 		for _, child := range e.Children {
@@ -178,7 +183,7 @@ func (e *Equipment) MarshalJSON() ([]byte, error) {
 		w := e.ExtendedWeight(true, defUnits)
 		data.Calc.ExtendedWeightForSkills = &w
 	}
-	return json.Marshal(&data)
+	return marshalWithUnknownFields(&data, e.Unknown)
 }
 
 // UnmarshalJSON implements json.Unmarshaler.
@@ -191,8 +196,13 @@ func (e *Equipment) UnmarshalJSON(data []byte) error {
 	if err := json.Unmarshal(data, &localData); err != nil {
 		return err
 	}
+	unknown, err := extractUnknownFields(data, &localData, calcKey)
+	if err != nil {
+		return err
+	}
 	localData.ClearUnusedFieldsForType()
 	e.EquipmentData = localData.EquipmentData
+	e.Unknown = unknown
 	e.Tags = convertOldCategoriesToTags(e.Tags, localData.Categories)
 	slices.Sort(e.Tags)
 	if e.Container() {
@@ -274,6 +284,17 @@ func (e *Equipment) CellData(column int, data *CellData) {
 		data.Type = PageRef
 		data.Primary = e.PageRef
 		data.Secondary = e.Name
+	case EquipmentVTTNotesColumn:
+		data.Type = Text
+		data.Primary = e.VTTNotes
+	case EquipmentLibrarySourceColumn:
+		if e.Source != nil {
+			data.Type = Text
+			data.Primary = e.Source.String()
+		}
+	case EquipmentIDColumn:
+		data.Type = Text
+		data.Primary = e.ID.String()
 	}
 }
 
@@ -22,6 +22,7 @@ var _ EditorData[*Equipment] = &EquipmentEditData{}
 
 // EquipmentEditData holds the Equipment data that can be edited by the UI detail editor.
 type EquipmentEditData struct {
+	LibrarySourceData
 	Name                   string               `json:"description,omitempty"`
 	PageRef                string               `json:"reference,omitempty"`
 	LocalNotes             string               `json:"notes,omitempty"`
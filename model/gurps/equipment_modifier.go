@@ -39,6 +39,8 @@ const (
 	EquipmentModifierWeightColumn
 	EquipmentModifierTagsColumn
 	EquipmentModifierReferenceColumn
+	EquipmentModifierVTTNotesColumn
+	EquipmentModifierIDColumn
 )
 
 const (
@@ -49,7 +51,8 @@ const (
 // EquipmentModifier holds a modifier to a piece of Equipment.
 type EquipmentModifier struct {
 	EquipmentModifierData
-	Entity *Entity
+	Entity  *Entity
+	Unknown UnknownFields
 }
 
 type equipmentModifierListData struct {
@@ -62,7 +65,7 @@ type equipmentModifierListData struct {
 func NewEquipmentModifiersFromFile(fileSystem fs.FS, filePath string) ([]*EquipmentModifier, error) {
 	var data equipmentModifierListData
 	if err := jio.LoadFromFS(context.Background(), fileSystem, filePath, &data); err != nil {
-		return nil, errs.NewWithCause(gid.InvalidFileDataMsg, err)
+		return nil, gid.InvalidFileData(err)
 	}
 	if data.Type != equipmentModifierListTypeKey {
 		return nil, errs.New(gid.UnexpectedFileDataMsg)
@@ -103,6 +106,7 @@ func (e *EquipmentModifier) Clone(entity *Entity, parent *EquipmentModifier, pre
 	}
 	other.IsOpen = e.IsOpen
 	other.EquipmentModifierEditData.CopyFrom(e)
+	other.Unknown = e.Unknown.Clone()
 	if e.HasChildren() {
 		other.Children = make([]*EquipmentModifier, 0, len(e.Children))
 		for _, child := range e.Children {
@@ -115,7 +119,7 @@ func (e *EquipmentModifier) Clone(entity *Entity, parent *EquipmentModifier, pre
 // MarshalJSON implements json.Marshaler.
 func (e *EquipmentModifier) MarshalJSON() ([]byte, error) {
 	e.ClearUnusedFieldsForType()
-	return json.Marshal(&e.EquipmentModifierData)
+	return marshalWithUnknownFields(&e.EquipmentModifierData, e.Unknown)
 }
 
 // UnmarshalJSON implements json.Unmarshaler.
@@ -128,8 +132,13 @@ func (e *EquipmentModifier) UnmarshalJSON(data []byte) error {
 	if err := json.Unmarshal(data, &localData); err != nil {
 		return err
 	}
+	unknown, err := extractUnknownFields(data, &localData)
+	if err != nil {
+		return err
+	}
 	localData.ClearUnusedFieldsForType()
 	e.EquipmentModifierData = localData.EquipmentModifierData
+	e.Unknown = unknown
 	e.Tags = convertOldCategoriesToTags(e.Tags, localData.Categories)
 	slices.Sort(e.Tags)
 	if e.Container() {
@@ -175,6 +184,12 @@ func (e *EquipmentModifier) CellData(column int, data *CellData) {
 		data.Type = PageRef
 		data.Primary = e.PageRef
 		data.Secondary = e.Name
+	case EquipmentModifierVTTNotesColumn:
+		data.Type = Text
+		data.Primary = e.VTTNotes
+	case EquipmentModifierIDColumn:
+		data.Type = Text
+		data.Primary = e.ID.String()
 	}
 }
 
@@ -11,10 +11,26 @@
 
 package gid
 
-import "github.com/richardwilkes/toolbox/i18n"
+import (
+	"errors"
+
+	"github.com/richardwilkes/gcs/model/schema"
+	"github.com/richardwilkes/toolbox/errs"
+	"github.com/richardwilkes/toolbox/i18n"
+)
 
 // Common messages
 var (
 	InvalidFileDataMsg    = i18n.Text("Invalid file data.")
 	UnexpectedFileDataMsg = i18n.Text("This file does not contain the expected data.")
 )
+
+// InvalidFileData returns an error reporting that the file data is invalid. When the cause identifies where within the
+// file the problems lie, they are included in the message.
+func InvalidFileData(cause error) error {
+	var problems *schema.Error
+	if errors.As(cause, &problems) {
+		return errs.NewWithCause(InvalidFileDataMsg+"\n\n"+problems.Error(), cause)
+	}
+	return errs.NewWithCause(InvalidFileDataMsg, cause)
+}
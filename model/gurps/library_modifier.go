@@ -0,0 +1,126 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package gurps
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/richardwilkes/gcs/model/library"
+	"github.com/richardwilkes/toolbox/errs"
+	"github.com/richardwilkes/toolbox/log/jot"
+	"github.com/richardwilkes/toolbox/txt"
+)
+
+// LibraryModifier holds a modifier found within a library, along with where it was found.
+type LibraryModifier[T NodeConstraint[T]] struct {
+	Modifier T
+	Name     string
+	Notes    string
+	Tags     []string
+	Library  *library.Library
+	FilePath string
+}
+
+// LibraryTraitModifiers returns the trait modifiers found within the trait modifier files of the libraries, sorted by
+// name. Containers are omitted, although the modifiers within them are not.
+func LibraryTraitModifiers(libs library.Libraries) []*LibraryModifier[*TraitModifier] {
+	return libraryModifiers(libs, library.TraitModifiersExt, NewTraitModifiersFromFile,
+		func(m *TraitModifier) (name, notes string, tags []string) {
+			return m.String(), m.LocalNotes, m.Tags
+		})
+}
+
+// LibraryEquipmentModifiers returns the equipment modifiers found within the equipment modifier files of the
+// libraries, sorted by name. Containers are omitted, although the modifiers within them are not.
+func LibraryEquipmentModifiers(libs library.Libraries) []*LibraryModifier[*EquipmentModifier] {
+	return libraryModifiers(libs, library.EquipmentModifiersExt, NewEquipmentModifiersFromFile,
+		func(m *EquipmentModifier) (name, notes string, tags []string) {
+			return m.String(), m.LocalNotes, m.Tags
+		})
+}
+
+func libraryModifiers[T NodeConstraint[T]](libs library.Libraries, ext string, loader func(fs.FS, string) ([]T, error), describe func(T) (name, notes string, tags []string)) []*LibraryModifier[T] {
+	// Nested libraries visit the same file more than once, so each file is only loaded once, attributing it to the most
+	// specific library that contains it
+	var paths []string
+	owners := make(map[string]*library.Library)
+	library.ForEachFile(libs, []string{ext}, func(lib *library.Library, p string) {
+		if owner, exists := owners[p]; !exists {
+			paths = append(paths, p)
+			owners[p] = lib
+		} else if len(lib.PathOnDisk) > len(owner.PathOnDisk) {
+			owners[p] = lib
+		}
+	})
+	var list []*LibraryModifier[T]
+	for _, p := range paths {
+		lib := owners[p]
+		rows, err := loader(os.DirFS(filepath.Dir(p)), filepath.Base(p))
+		if err != nil {
+			jot.Warn(errs.NewWithCause("unable to load "+p, err))
+			continue
+		}
+		Traverse(func(m T) bool {
+			name, notes, tags := describe(m)
+			list = append(list, &LibraryModifier[T]{
+				Modifier: m,
+				Name:     name,
+				Notes:    notes,
+				Tags:     tags,
+				Library:  lib,
+				FilePath: p,
+			})
+			return false
+		}, true, false, rows...)
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		return txt.NaturalLess(list[i].Name, list[j].Name, true)
+	})
+	return list
+}
+
+// RelevantTo returns true if one of the modifier's tags matches, ignoring case, either one of the given tags or the
+// given item name.
+func (m *LibraryModifier[T]) RelevantTo(name string, tags []string) bool {
+	name = strings.TrimSpace(name)
+	for _, tag := range m.Tags {
+		if name != "" && strings.EqualFold(tag, name) {
+			return true
+		}
+		for _, one := range tags {
+			if strings.EqualFold(tag, one) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Matches returns true if each of the whitespace-separated terms in the text appears, ignoring case, in the modifier's
+// name, notes or tags.
+func (m *LibraryModifier[T]) Matches(text string) bool {
+	terms := strings.Fields(strings.ToLower(text))
+	if len(terms) == 0 {
+		return true
+	}
+	haystack := strings.ToLower(m.Name + "\n" + m.Notes + "\n" + strings.Join(m.Tags, "\n"))
+	for _, term := range terms {
+		if !strings.Contains(haystack, term) {
+			return false
+		}
+	}
+	return true
+}
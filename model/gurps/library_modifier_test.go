@@ -0,0 +1,67 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package gurps_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/richardwilkes/gcs/model/gurps"
+	"github.com/richardwilkes/gcs/model/library"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLibraryModifiers(t *testing.T) {
+	libs := library.Libraries{}
+	lib := libs.Master()
+	lib.PathOnDisk = t.TempDir()
+	listPath := filepath.Join(lib.PathOnDisk, "Traits", "Modifiers.adm")
+	require.NoError(t, os.MkdirAll(filepath.Dir(listPath), 0o750))
+
+	container := gurps.NewTraitModifier(nil, nil, true)
+	container.Name = "Affliction"
+	cumulative := gurps.NewTraitModifier(nil, container, false)
+	cumulative.Name = "Cumulative"
+	cumulative.Tags = []string{"Affliction"}
+	container.Children = []*gurps.TraitModifier{cumulative}
+	area := gurps.NewTraitModifier(nil, nil, false)
+	area.Name = "Area Effect"
+	area.LocalNotes = "@Radius@ yards"
+	area.Tags = []string{"Attack Enhancements"}
+	require.NoError(t, gurps.SaveTraitModifiers([]*gurps.TraitModifier{container, area}, listPath))
+
+	list := gurps.LibraryTraitModifiers(libs)
+	require.Len(t, list, 2)
+	assert.Equal(t, "Area Effect", list[0].Name)
+	assert.Equal(t, "Cumulative", list[1].Name)
+	assert.Equal(t, listPath, list[1].FilePath)
+	assert.Same(t, lib, list[1].Library)
+	assert.Empty(t, gurps.LibraryEquipmentModifiers(libs))
+
+	assert.True(t, list[1].RelevantTo("affliction", nil))
+	assert.True(t, list[0].RelevantTo("Innate Attack", []string{"Advantage", "attack enhancements"}))
+	assert.False(t, list[0].RelevantTo("Affliction", []string{"Advantage"}))
+
+	assert.True(t, list[0].Matches(""))
+	assert.True(t, list[0].Matches("radius AREA"))
+	assert.False(t, list[0].Matches("area cone"))
+
+	// A library nested within another sees the same file, but it is only listed once, under the nested library
+	nested := libs.User()
+	nested.PathOnDisk = filepath.Join(lib.PathOnDisk, "Traits")
+	list = gurps.LibraryTraitModifiers(libs)
+	require.Len(t, list, 2)
+	assert.Same(t, nested, list[0].Library)
+	assert.Same(t, nested, list[1].Library)
+}
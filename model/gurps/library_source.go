@@ -0,0 +1,121 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package gurps
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/richardwilkes/gcs/model/library"
+)
+
+// LibrarySource holds the location of the library data a node was copied from.
+type LibrarySource struct {
+	Library string    `json:"library"`
+	Path    string    `json:"path"`
+	ID      uuid.UUID `json:"id"`
+	Version string    `json:"version,omitempty"`
+}
+
+// LibrarySourced defines the methods a node must implement if it can track the library data it was copied from.
+type LibrarySourced interface {
+	LibrarySource() *LibrarySource
+	SetLibrarySource(source *LibrarySource)
+}
+
+// LibrarySourceData holds the library source information for a node. It is intended to be embedded within the editable
+// data of those nodes that support it.
+type LibrarySourceData struct {
+	Source *LibrarySource `json:"source,omitempty"`
+}
+
+// LibrarySource returns the library data this was copied from, if any.
+func (d *LibrarySourceData) LibrarySource() *LibrarySource {
+	return d.Source
+}
+
+// SetLibrarySource sets the library data this was copied from.
+func (d *LibrarySourceData) SetLibrarySource(source *LibrarySource) {
+	d.Source = source
+}
+
+// NewLibrarySource creates a new LibrarySource for the row with the given ID within the file at filePath. Returns nil if
+// the file does not reside within one of the libraries. When libraries are nested, the innermost one is used.
+func NewLibrarySource(libs library.Libraries, filePath string, id uuid.UUID) *LibrarySource {
+	var source *LibrarySource
+	var best *library.Library
+	for _, lib := range libs.List() {
+		rel, err := filepath.Rel(lib.PathOnDisk, filePath)
+		if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) ||
+			filepath.IsAbs(rel) {
+			continue
+		}
+		if best == nil || len(lib.PathOnDisk) > len(best.PathOnDisk) {
+			best = lib
+			source = &LibrarySource{
+				Library: lib.Key(),
+				Path:    filepath.ToSlash(rel),
+				ID:      id,
+			}
+		}
+	}
+	if source != nil {
+		source.Version = best.VersionOnDisk()
+	}
+	return source
+}
+
+// String returns a description of the library file, using the library's title when it is available.
+func (s *LibrarySource) String() string {
+	title := s.Library
+	if SettingsProvider != nil {
+		if lib, ok := SettingsProvider.Libraries()[s.Library]; ok && lib.Title != "" {
+			title = lib.Title
+		}
+	}
+	return title + ": " + s.Path
+}
+
+// FilePath returns the full path to the library file, or an empty string if the library is not present.
+func (s *LibrarySource) FilePath(libs library.Libraries) string {
+	lib, ok := libs[s.Library]
+	if !ok {
+		return ""
+	}
+	return filepath.Join(lib.PathOnDisk, filepath.FromSlash(s.Path))
+}
+
+// RecordLibrarySources records the library file that 'original' came from as the source of 'copied', which must be a
+// clone of 'original'. Any children are processed as well. Nothing is recorded if the file is not within a library or
+// the nodes do not support tracking their source.
+func RecordLibrarySources[T Node[T]](libs library.Libraries, filePath string, original, copied T) {
+	if source := NewLibrarySource(libs, filePath, original.UUID()); source != nil {
+		recordLibrarySources(*source, original, copied)
+	}
+}
+
+func recordLibrarySources[T Node[T]](source LibrarySource, original, copied T) {
+	sourced, ok := any(copied).(LibrarySourced)
+	if !ok {
+		return
+	}
+	source.ID = original.UUID()
+	sourced.SetLibrarySource(&source)
+	originalChildren := original.NodeChildren()
+	copiedChildren := copied.NodeChildren()
+	if len(originalChildren) == len(copiedChildren) {
+		for i, child := range originalChildren {
+			recordLibrarySources(source, child, copiedChildren[i])
+		}
+	}
+}
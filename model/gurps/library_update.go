@@ -0,0 +1,422 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package gurps
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/richardwilkes/gcs/model/library"
+	"github.com/richardwilkes/json"
+	"github.com/richardwilkes/toolbox/errs"
+	"github.com/richardwilkes/toolbox/i18n"
+	"github.com/richardwilkes/toolbox/txt"
+)
+
+// Keys that hold data which is specific to the character rather than defined by the library, and so are never updated
+// from the library.
+var (
+	commonPlayerKeys    = []string{"source", "notes", "vtt_notes", "disabled"}
+	traitPlayerKeys     = append([]string{"levels", "userdesc", "cr", "cr_adj"}, commonPlayerKeys...)
+	skillPlayerKeys     = append([]string{"points", "tech_level", "defaulted_from"}, commonPlayerKeys...)
+	spellPlayerKeys     = append([]string{"points", "tech_level"}, commonPlayerKeys...)
+	equipmentPlayerKeys = append([]string{"quantity", "uses", "equipped", "ignore_weight_for_skills"}, commonPlayerKeys...)
+	modifierPlayerKeys  = []string{"disabled", "levels"}
+)
+
+// Fields that hold rows of their own, mapped to the keys within those rows that hold data specific to the character.
+// Rows nested within a "children" field use the same keys as their parent row.
+var nestedPlayerKeys = map[string][]string{
+	"modifiers": modifierPlayerKeys,
+}
+
+// Keys that are ignored at any depth when comparing values, since they are either regenerated or calculated.
+var ignoredComparisonKeys = map[string]bool{
+	"id":   true,
+	"calc": true,
+}
+
+// LibraryFieldDifference describes a field whose value differs between a node and the library data it was copied from.
+type LibraryFieldDifference struct {
+	Key          string
+	Title        string
+	CurrentValue string
+	LibraryValue string
+}
+
+// LibraryUpdate holds the differences between a node and the library data it was copied from.
+type LibraryUpdate struct {
+	Kind           string
+	Description    string
+	Source         *LibrarySource
+	LibraryVersion string
+	// Problem is set when the library data could not be located, in which case there will be no differences.
+	Problem     string
+	Differences []*LibraryFieldDifference
+	apply       func(keys []string) (undo, redo func(), err error)
+}
+
+// Apply updates the node with the library values of the fields with the given keys, also recording the current library
+// version as the source version. Returns functions that restore the node to its prior state and re-apply the update.
+func (u *LibraryUpdate) Apply(keys []string) (undo, redo func(), err error) {
+	if u.apply == nil {
+		return nil, nil, errs.New("no update is available")
+	}
+	return u.apply(keys)
+}
+
+type libraryFileCache struct {
+	libs  library.Libraries
+	files map[string]any
+}
+
+func (c *libraryFileCache) load(filePath string, loader func(filePath string) (any, error)) (any, error) {
+	if data, ok := c.files[filePath]; ok {
+		if err, isErr := data.(error); isErr {
+			return nil, err
+		}
+		return data, nil
+	}
+	data, err := loader(filePath)
+	if err != nil {
+		c.files[filePath] = err
+		return nil, err
+	}
+	c.files[filePath] = data
+	return data, nil
+}
+
+// LibraryUpdates compares each trait, skill, spell and piece of equipment within the entity that was copied from a
+// library against its current library definition, returning those that differ or whose library definition can no
+// longer be found.
+func (e *Entity) LibraryUpdates(libs library.Libraries) []*LibraryUpdate {
+	cache := &libraryFileCache{
+		libs:  libs,
+		files: make(map[string]any),
+	}
+	var updates []*LibraryUpdate
+	updates = collectLibraryUpdates(cache, updates, e.Traits,
+		func(fp string) ([]*Trait, error) {
+			return NewTraitsFromFile(os.DirFS(filepath.Dir(fp)), filepath.Base(fp))
+		},
+		func() EditorData[*Trait] { return &TraitEditData{} }, traitPlayerKeys)
+	updates = collectLibraryUpdates(cache, updates, e.Skills,
+		func(fp string) ([]*Skill, error) {
+			return NewSkillsFromFile(os.DirFS(filepath.Dir(fp)), filepath.Base(fp))
+		},
+		func() EditorData[*Skill] { return &SkillEditData{} }, skillPlayerKeys)
+	updates = collectLibraryUpdates(cache, updates, e.Spells,
+		func(fp string) ([]*Spell, error) {
+			return NewSpellsFromFile(os.DirFS(filepath.Dir(fp)), filepath.Base(fp))
+		},
+		func() EditorData[*Spell] { return &SpellEditData{} }, spellPlayerKeys)
+	equipmentLoader := func(fp string) ([]*Equipment, error) {
+		return NewEquipmentFromFile(os.DirFS(filepath.Dir(fp)), filepath.Base(fp))
+	}
+	equipmentData := func() EditorData[*Equipment] { return &EquipmentEditData{} }
+	updates = collectLibraryUpdates(cache, updates, e.CarriedEquipment, equipmentLoader, equipmentData,
+		equipmentPlayerKeys)
+	return collectLibraryUpdates(cache, updates, e.OtherEquipment, equipmentLoader, equipmentData, equipmentPlayerKeys)
+}
+
+func collectLibraryUpdates[T NodeConstraint[T]](cache *libraryFileCache, updates []*LibraryUpdate, nodes []T, loader func(filePath string) ([]T, error), newData func() EditorData[T], playerKeys []string) []*LibraryUpdate {
+	for _, node := range nodes {
+		if sourced, ok := any(node).(LibrarySourced); ok {
+			if source := sourced.LibrarySource(); source != nil {
+				if update := newLibraryUpdate(cache, node, source, loader, newData, playerKeys); update != nil {
+					updates = append(updates, update)
+				}
+			}
+		}
+		if node.HasChildren() {
+			updates = collectLibraryUpdates(cache, updates, node.NodeChildren(), loader, newData, playerKeys)
+		}
+	}
+	return updates
+}
+
+func newLibraryUpdate[T NodeConstraint[T]](cache *libraryFileCache, node T, source *LibrarySource, loader func(filePath string) ([]T, error), newData func() EditorData[T], playerKeys []string) *LibraryUpdate {
+	update := &LibraryUpdate{
+		Kind:        node.Kind(),
+		Description: fmt.Sprint(node),
+		Source:      source,
+	}
+	lib, ok := cache.libs[source.Library]
+	if !ok {
+		update.Problem = i18n.Text("The library is no longer configured")
+		return update
+	}
+	update.LibraryVersion = lib.VersionOnDisk()
+	data, err := cache.load(source.FilePath(cache.libs), func(filePath string) (any, error) { return loader(filePath) })
+	if err != nil {
+		update.Problem = i18n.Text("The library file is missing or invalid")
+		return update
+	}
+	libNode, found := findNodeByID(data.([]T), source.ID)
+	if !found {
+		update.Problem = i18n.Text("The item no longer exists within the library file")
+		return update
+	}
+	if libNode.Container() != node.Container() {
+		update.Problem = i18n.Text("The library item is no longer of the same type")
+		return update
+	}
+	current := newData()
+	current.CopyFrom(node)
+	libData := newData()
+	libData.CopyFrom(libNode)
+	if update.Differences, err = libraryDifferences(current, libData, playerKeys); err != nil {
+		update.Problem = err.Error()
+		return update
+	}
+	if len(update.Differences) == 0 {
+		return nil
+	}
+	version := update.LibraryVersion
+	update.apply = func(keys []string) (undo, redo func(), err error) {
+		before := newData()
+		before.CopyFrom(node)
+		after := newData()
+		if err = mergeLibraryFields(before, libData, keys, playerKeys, after); err != nil {
+			return nil, nil, err
+		}
+		if sourced, isSourced := any(after).(LibrarySourced); isSourced {
+			updatedSource := *source
+			updatedSource.Version = version
+			sourced.SetLibrarySource(&updatedSource)
+		}
+		after.ApplyTo(node)
+		return func() { before.ApplyTo(node) }, func() { after.ApplyTo(node) }, nil
+	}
+	return update
+}
+
+func findNodeByID[T NodeConstraint[T]](nodes []T, id uuid.UUID) (T, bool) {
+	for _, node := range nodes {
+		if node.UUID() == id {
+			return node, true
+		}
+		if node.HasChildren() {
+			if found, ok := findNodeByID(node.NodeChildren(), id); ok {
+				return found, true
+			}
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+func libraryDifferences(current, lib any, playerKeys []string) ([]*LibraryFieldDifference, error) {
+	currentFields, err := fieldMap(current)
+	if err != nil {
+		return nil, err
+	}
+	var libFields map[string]json.RawMessage
+	if libFields, err = fieldMap(lib); err != nil {
+		return nil, err
+	}
+	keys := make(map[string]bool)
+	for k := range currentFields {
+		keys[k] = true
+	}
+	for k := range libFields {
+		keys[k] = true
+	}
+	for _, k := range playerKeys {
+		delete(keys, k)
+	}
+	var diffs []*LibraryFieldDifference
+	for k := range keys {
+		currentValue := normalizedValue(currentFields[k], nestedKeys(k, playerKeys))
+		libValue := normalizedValue(libFields[k], nestedKeys(k, playerKeys))
+		if currentValue != libValue {
+			diffs = append(diffs, &LibraryFieldDifference{
+				Key:          k,
+				Title:        txt.FirstToUpper(strings.ReplaceAll(k, "_", " ")),
+				CurrentValue: currentValue,
+				LibraryValue: libValue,
+			})
+		}
+	}
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Key < diffs[j].Key })
+	return diffs, nil
+}
+
+func mergeLibraryFields(current, lib any, keys, playerKeys []string, result any) error {
+	currentFields, err := fieldMap(current)
+	if err != nil {
+		return err
+	}
+	var libFields map[string]json.RawMessage
+	if libFields, err = fieldMap(lib); err != nil {
+		return err
+	}
+	for _, k := range keys {
+		if v, ok := libFields[k]; ok {
+			if rowKeys := nestedKeys(k, playerKeys); len(rowKeys) != 0 {
+				if v, err = mergeNestedRows(currentFields[k], v, rowKeys); err != nil {
+					return err
+				}
+			}
+			currentFields[k] = v
+		} else {
+			delete(currentFields, k)
+		}
+	}
+	var data []byte
+	if data, err = json.Marshal(currentFields); err != nil {
+		return errs.Wrap(err)
+	}
+	if err = json.Unmarshal(data, result); err != nil {
+		return errs.Wrap(err)
+	}
+	return nil
+}
+
+func fieldMap(data any) (map[string]json.RawMessage, error) {
+	buffer, err := json.Marshal(data)
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+	var m map[string]json.RawMessage
+	if err = json.Unmarshal(buffer, &m); err != nil {
+		return nil, errs.Wrap(err)
+	}
+	return m, nil
+}
+
+// nestedKeys returns the player keys that apply to the rows held by the field with the given key, if any.
+func nestedKeys(key string, playerKeys []string) []string {
+	if key == "children" {
+		return playerKeys
+	}
+	return nestedPlayerKeys[key]
+}
+
+// mergeNestedRows returns the library rows, with the values of the player keys carried over from the current rows that
+// share the same ID, at any depth.
+func mergeNestedRows(current, lib json.RawMessage, playerKeys []string) (json.RawMessage, error) {
+	var libRows []any
+	if err := json.Unmarshal(lib, &libRows); err != nil {
+		return nil, errs.Wrap(err)
+	}
+	var currentRows []any
+	if len(current) != 0 {
+		if err := json.Unmarshal(current, &currentRows); err != nil {
+			return nil, errs.Wrap(err)
+		}
+	}
+	carryPlayerValues(libRows, currentRows, playerKeys)
+	data, err := json.Marshal(libRows)
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+	return data, nil
+}
+
+func carryPlayerValues(libRows, currentRows []any, playerKeys []string) {
+	byID := make(map[string]map[string]any)
+	indexRowsByID(currentRows, byID)
+	carryPlayerValuesByID(libRows, byID, playerKeys)
+}
+
+func carryPlayerValuesByID(libRows []any, byID map[string]map[string]any, playerKeys []string) {
+	for _, one := range libRows {
+		row, ok := one.(map[string]any)
+		if !ok {
+			continue
+		}
+		if id, hasID := row["id"].(string); hasID {
+			if current, exists := byID[id]; exists {
+				for _, k := range playerKeys {
+					if v, has := current[k]; has {
+						row[k] = v
+					} else {
+						delete(row, k)
+					}
+				}
+				for k, keys := range nestedPlayerKeys {
+					libNested, isLibList := row[k].([]any)
+					currentNested, isCurrentList := current[k].([]any)
+					if isLibList && isCurrentList {
+						carryPlayerValues(libNested, currentNested, keys)
+					}
+				}
+			}
+		}
+		if children, isList := row["children"].([]any); isList {
+			carryPlayerValuesByID(children, byID, playerKeys)
+		}
+	}
+}
+
+func indexRowsByID(rows []any, byID map[string]map[string]any) {
+	for _, one := range rows {
+		if row, ok := one.(map[string]any); ok {
+			if id, hasID := row["id"].(string); hasID {
+				byID[id] = row
+			}
+			if children, isList := row["children"].([]any); isList {
+				indexRowsByID(children, byID)
+			}
+		}
+	}
+}
+
+// normalizedValue returns a string form of the raw JSON value suitable for comparison and display. Strings are returned
+// as-is, while other values have their ignored keys and the given player keys removed at any depth and are returned in
+// compact JSON form.
+func normalizedValue(raw json.RawMessage, playerKeys []string) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var v any
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return string(raw)
+	}
+	switch value := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	}
+	data, err := json.Marshal(stripIgnoredKeys(v, playerKeys))
+	if err != nil {
+		return string(raw)
+	}
+	return string(data)
+}
+
+func stripIgnoredKeys(v any, playerKeys []string) any {
+	switch value := v.(type) {
+	case map[string]any:
+		for _, k := range playerKeys {
+			delete(value, k)
+		}
+		for k, one := range value {
+			if ignoredComparisonKeys[k] {
+				delete(value, k)
+			} else {
+				value[k] = stripIgnoredKeys(one, nestedKeys(k, playerKeys))
+			}
+		}
+	case []any:
+		for i, one := range value {
+			value[i] = stripIgnoredKeys(one, playerKeys)
+		}
+	}
+	return v
+}
@@ -0,0 +1,131 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package gurps_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/richardwilkes/gcs/model/fxp"
+	"github.com/richardwilkes/gcs/model/gurps"
+	"github.com/richardwilkes/gcs/model/library"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLibraryUpdates(t *testing.T) {
+	libs := library.Libraries{}
+	lib := libs.Master()
+	lib.PathOnDisk = t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(lib.PathOnDisk, "release.txt"), []byte("4.1\n"), 0o640))
+	listPath := filepath.Join(lib.PathOnDisk, "Traits", "Basic.adq")
+	require.NoError(t, os.MkdirAll(filepath.Dir(listPath), 0o750))
+
+	original := gurps.NewTrait(nil, nil, false)
+	original.Name = "Acute Vision"
+	original.PointsPerLevel = fxp.Two
+	original.Levels = fxp.One
+	require.NoError(t, gurps.SaveTraits([]*gurps.Trait{original}, listPath))
+
+	entity := &gurps.Entity{}
+	copied := original.Clone(entity, nil, false)
+	gurps.RecordLibrarySources(libs, listPath, original, copied)
+	require.NotNil(t, copied.LibrarySource())
+	assert.Equal(t, lib.Key(), copied.LibrarySource().Library)
+	assert.Equal(t, "Traits/Basic.adq", copied.LibrarySource().Path)
+	assert.Equal(t, original.ID, copied.LibrarySource().ID)
+	assert.Equal(t, "4.1", copied.LibrarySource().Version)
+	copied.Levels = fxp.Three
+	copied.LocalNotes = "Only in daylight"
+	entity.Traits = []*gurps.Trait{copied}
+	assert.Empty(t, entity.LibraryUpdates(libs))
+
+	// Errata arrives in a new library release
+	original.Name = "Acute Vision (Errata)"
+	original.PointsPerLevel = fxp.Three
+	require.NoError(t, gurps.SaveTraits([]*gurps.Trait{original}, listPath))
+	require.NoError(t, os.WriteFile(filepath.Join(lib.PathOnDisk, "release.txt"), []byte("4.2\n"), 0o640))
+	updates := entity.LibraryUpdates(libs)
+	require.Len(t, updates, 1)
+	update := updates[0]
+	assert.Empty(t, update.Problem)
+	assert.Equal(t, "4.2", update.LibraryVersion)
+	require.Len(t, update.Differences, 2)
+	assert.Equal(t, "name", update.Differences[0].Key)
+	assert.Equal(t, "Acute Vision", update.Differences[0].CurrentValue)
+	assert.Equal(t, "Acute Vision (Errata)", update.Differences[0].LibraryValue)
+	assert.Equal(t, "points_per_level", update.Differences[1].Key)
+
+	undo, redo, err := update.Apply([]string{"points_per_level"})
+	require.NoError(t, err)
+	assert.Equal(t, fxp.Three, copied.PointsPerLevel)
+	assert.Equal(t, "Acute Vision", copied.Name)
+	assert.Equal(t, fxp.Three, copied.Levels)
+	assert.Equal(t, "Only in daylight", copied.LocalNotes)
+	assert.Equal(t, "4.2", copied.LibrarySource().Version)
+	undo()
+	assert.Equal(t, fxp.Two, copied.PointsPerLevel)
+	assert.Equal(t, "4.1", copied.LibrarySource().Version)
+	redo()
+	assert.Equal(t, fxp.Three, copied.PointsPerLevel)
+
+	// Removing the item from the library is reported as a problem
+	require.NoError(t, gurps.SaveTraits(nil, listPath))
+	updates = entity.LibraryUpdates(libs)
+	require.Len(t, updates, 1)
+	assert.NotEmpty(t, updates[0].Problem)
+	assert.Empty(t, updates[0].Differences)
+}
+
+func TestLibraryUpdatesKeepModifierState(t *testing.T) {
+	ensureSettingsProvider()
+	libs := library.Libraries{}
+	lib := libs.Master()
+	lib.PathOnDisk = t.TempDir()
+	listPath := filepath.Join(lib.PathOnDisk, "Traits", "Basic.adq")
+	require.NoError(t, os.MkdirAll(filepath.Dir(listPath), 0o750))
+
+	original := gurps.NewTrait(nil, nil, false)
+	original.Name = "Innate Attack"
+	original.PointsPerLevel = fxp.Four
+	original.Levels = fxp.One
+	ranged := gurps.NewTraitModifier(nil, nil, false)
+	ranged.Name = "Ranged"
+	ranged.Cost = fxp.Ten
+	ranged.Levels = fxp.One
+	original.Modifiers = []*gurps.TraitModifier{ranged}
+	require.NoError(t, gurps.SaveTraits([]*gurps.Trait{original}, listPath))
+
+	entity := &gurps.Entity{}
+	copied := original.Clone(entity, nil, false)
+	gurps.RecordLibrarySources(libs, listPath, original, copied)
+	copied.Modifiers[0].Disabled = true
+	copied.Modifiers[0].Levels = fxp.Two
+	entity.Traits = []*gurps.Trait{copied}
+	assert.Empty(t, entity.LibraryUpdates(libs))
+
+	ranged.Cost = fxp.Twenty
+	require.NoError(t, gurps.SaveTraits([]*gurps.Trait{original}, listPath))
+	updates := entity.LibraryUpdates(libs)
+	require.Len(t, updates, 1)
+	require.Len(t, updates[0].Differences, 1)
+	assert.Equal(t, "modifiers", updates[0].Differences[0].Key)
+
+	_, _, err := updates[0].Apply([]string{"modifiers"})
+	require.NoError(t, err)
+	require.Len(t, copied.Modifiers, 1)
+	assert.Equal(t, fxp.Twenty, copied.Modifiers[0].Cost)
+	assert.True(t, copied.Modifiers[0].Disabled)
+	assert.Equal(t, fxp.Two, copied.Modifiers[0].Levels)
+	assert.Empty(t, entity.LibraryUpdates(libs))
+}
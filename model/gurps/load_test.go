@@ -0,0 +1,66 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package gurps_test
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/richardwilkes/gcs/model/fxp"
+	"github.com/richardwilkes/gcs/model/gurps"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBundledDataLoads(t *testing.T) {
+	ensureSettingsProvider()
+	fileSystem := os.DirFS("data")
+	count := 0
+	require.NoError(t, fs.WalkDir(fileSystem, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		switch path.Ext(p) {
+		case ".body":
+			_, err = gurps.NewBodyTypeFromFile(fileSystem, p)
+		case ".attr":
+			_, err = gurps.NewAttributeDefsFromFile(fileSystem, p)
+		default:
+			return nil
+		}
+		assert.NoError(t, err, p)
+		count++
+		return nil
+	}))
+	assert.NotZero(t, count)
+}
+
+func TestLegacyDataLoads(t *testing.T) {
+	ensureSettingsProvider()
+	fileSystem := os.DirFS("testdata/legacy")
+	traits, err := gurps.NewTraitsFromFile(fileSystem, "traits.adq")
+	require.NoError(t, err)
+	require.Len(t, traits, 2)
+	assert.Equal(t, "Acute Vision", traits[0].Name)
+	assert.Equal(t, fxp.Int(0), traits[0].Levels)
+	assert.Contains(t, traits[0].Tags, "Mental")
+	require.Len(t, traits[1].Children, 1)
+	assert.Equal(t, -fxp.One, traits[1].Children[0].BasePoints)
+
+	skills, err := gurps.NewSkillsFromFile(fileSystem, "skills.skl")
+	require.NoError(t, err)
+	require.Len(t, skills, 1)
+	assert.Equal(t, fxp.Int(0), skills[0].Points)
+	assert.Contains(t, skills[0].Tags, "Athletic")
+}
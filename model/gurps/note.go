@@ -27,6 +27,7 @@ var _ Node[*Note] = &Note{}
 const (
 	NoteTextColumn = iota
 	NoteReferenceColumn
+	NoteIDColumn
 )
 
 const (
@@ -37,7 +38,8 @@ const (
 // Note holds a note.
 type Note struct {
 	NoteData
-	Entity *Entity
+	Entity  *Entity
+	Unknown UnknownFields
 }
 
 type noteListData struct {
@@ -50,7 +52,7 @@ type noteListData struct {
 func NewNotesFromFile(fileSystem fs.FS, filePath string) ([]*Note, error) {
 	var data noteListData
 	if err := jio.LoadFromFS(context.Background(), fileSystem, filePath, &data); err != nil {
-		return nil, errs.NewWithCause(gid.InvalidFileDataMsg, err)
+		return nil, gid.InvalidFileData(err)
 	}
 	if data.Type != noteListTypeKey {
 		return nil, errs.New(gid.UnexpectedFileDataMsg)
@@ -91,6 +93,7 @@ func (n *Note) Clone(entity *Entity, parent *Note, preserveID bool) *Note {
 	}
 	other.IsOpen = n.IsOpen
 	other.NoteEditData.CopyFrom(n)
+	other.Unknown = n.Unknown.Clone()
 	if n.HasChildren() {
 		other.Children = make([]*Note, 0, len(n.Children))
 		for _, child := range n.Children {
@@ -103,7 +106,7 @@ func (n *Note) Clone(entity *Entity, parent *Note, preserveID bool) *Note {
 // MarshalJSON implements json.Marshaler.
 func (n *Note) MarshalJSON() ([]byte, error) {
 	n.ClearUnusedFieldsForType()
-	return json.Marshal(&n.NoteData)
+	return marshalWithUnknownFields(&n.NoteData, n.Unknown)
 }
 
 // UnmarshalJSON implements json.Unmarshaler.
@@ -112,6 +115,11 @@ func (n *Note) UnmarshalJSON(data []byte) error {
 	if err := json.Unmarshal(data, &n.NoteData); err != nil {
 		return err
 	}
+	unknown, err := extractUnknownFields(data, &n.NoteData)
+	if err != nil {
+		return err
+	}
+	n.Unknown = unknown
 	n.ClearUnusedFieldsForType()
 	if n.Container() {
 		for _, one := range n.Children {
@@ -131,6 +139,9 @@ func (n *Note) CellData(column int, data *CellData) {
 		data.Type = PageRef
 		data.Primary = n.PageRef
 		data.Secondary = n.Text
+	case NoteIDColumn:
+		data.Type = Text
+		data.Primary = n.ID.String()
 	}
 }
 
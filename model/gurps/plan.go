@@ -0,0 +1,239 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package gurps
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/richardwilkes/gcs/model/gurps/datafile"
+	"github.com/richardwilkes/gcs/model/id"
+	"github.com/richardwilkes/gcs/model/jio"
+	"github.com/richardwilkes/toolbox/i18n"
+)
+
+// Possible PlanChangeKind values.
+const (
+	PointsPlanChange PlanChangeKind = iota
+	AttributePlanChange
+	DerivedPlanChange
+	TraitPlanChange
+	SkillPlanChange
+	SpellPlanChange
+)
+
+// PlanChangeKind identifies the kind of value a PlanChange describes.
+type PlanChangeKind uint8
+
+// Proposal holds a named plan for advancing a character that has not been applied to the character yet.
+type Proposal struct {
+	ID      uuid.UUID `json:"id"`
+	Name    string    `json:"name"`
+	Created jio.Time  `json:"created"`
+	Entity  *Entity   `json:"entity"`
+}
+
+// PlanChange describes a single difference between a character and a plan for it. Before is empty for things the plan
+// adds and After is empty for things the plan removes.
+type PlanChange struct {
+	Kind   PlanChangeKind
+	Name   string
+	Before string
+	After  string
+}
+
+// String implements fmt.Stringer.
+func (k PlanChangeKind) String() string {
+	switch k {
+	case PointsPlanChange:
+		return i18n.Text("Points")
+	case AttributePlanChange:
+		return i18n.Text("Attributes")
+	case DerivedPlanChange:
+		return i18n.Text("Derived")
+	case TraitPlanChange:
+		return i18n.Text("Traits")
+	case SkillPlanChange:
+		return i18n.Text("Skills")
+	case SpellPlanChange:
+		return i18n.Text("Spells")
+	default:
+		return ""
+	}
+}
+
+// NewPlan creates a copy of the entity that changes can be tried out on without affecting the entity.
+func (e *Entity) NewPlan() (*Entity, error) {
+	plan, err := e.Clone()
+	if err != nil {
+		return nil, err
+	}
+	plan.Proposals = nil
+	return plan, nil
+}
+
+// NewProposal creates a new Proposal with the given name from a snapshot of the plan.
+func NewProposal(name string, plan *Entity) (*Proposal, error) {
+	snapshot, err := plan.NewPlan()
+	if err != nil {
+		return nil, err
+	}
+	return &Proposal{
+		ID:      id.NewUUID(),
+		Name:    name,
+		Created: jio.Now(),
+		Entity:  snapshot,
+	}, nil
+}
+
+// Plan returns a copy of the proposal's entity that can be edited without altering the proposal.
+func (p *Proposal) Plan() (*Entity, error) {
+	return p.Entity.NewPlan()
+}
+
+// SetProposal adds the proposal to the entity, replacing any existing proposal with the same ID.
+func (e *Entity) SetProposal(proposal *Proposal) {
+	for i, one := range e.Proposals {
+		if one.ID == proposal.ID {
+			e.Proposals[i] = proposal
+			return
+		}
+	}
+	e.Proposals = append(e.Proposals, proposal)
+}
+
+// RemoveProposal removes the proposal with the given ID from the entity.
+func (e *Entity) RemoveProposal(proposalID uuid.UUID) {
+	for i, one := range e.Proposals {
+		if one.ID == proposalID {
+			e.Proposals = append(e.Proposals[:i], e.Proposals[i+1:]...)
+			return
+		}
+	}
+}
+
+// ApplyPlan replaces the entity's data with that of the plan. The entity's proposals, identity and creation date are
+// retained.
+func (e *Entity) ApplyPlan(plan *Entity) error {
+	var buffer bytes.Buffer
+	if err := jio.Save(context.Background(), &buffer, plan); err != nil {
+		return err
+	}
+	proposals := e.Proposals
+	entityID := e.ID
+	created := e.CreatedOn
+	if err := jio.Load(context.Background(), &buffer, e); err != nil {
+		return err
+	}
+	e.Proposals = proposals
+	e.ID = entityID
+	e.CreatedOn = created
+	e.ModifiedOn = jio.Now()
+	return nil
+}
+
+// ComparePlan returns the differences in points spent, attributes, derived values, traits, skill levels and spell
+// levels between the entity and a plan for it.
+func ComparePlan(entity, plan *Entity) []*PlanChange {
+	var changes []*PlanChange
+	add := func(kind PlanChangeKind, name, before, after string) {
+		if before != after {
+			changes = append(changes, &PlanChange{
+				Kind:   kind,
+				Name:   name,
+				Before: before,
+				After:  after,
+			})
+		}
+	}
+
+	add(PointsPlanChange, i18n.Text("Spent"), entity.SpentPoints().String(), plan.SpentPoints().String())
+	add(PointsPlanChange, i18n.Text("Unspent"), entity.UnspentPoints().String(), plan.UnspentPoints().String())
+	add(PointsPlanChange, i18n.Text("Attributes"), entity.AttributePoints().String(), plan.AttributePoints().String())
+	beforeAd, beforeDisad, beforeRace, beforeQuirk := entity.TraitPoints()
+	afterAd, afterDisad, afterRace, afterQuirk := plan.TraitPoints()
+	add(PointsPlanChange, i18n.Text("Advantages"), beforeAd.String(), afterAd.String())
+	add(PointsPlanChange, i18n.Text("Disadvantages"), beforeDisad.String(), afterDisad.String())
+	add(PointsPlanChange, i18n.Text("Ancestry"), beforeRace.String(), afterRace.String())
+	add(PointsPlanChange, i18n.Text("Quirks"), beforeQuirk.String(), afterQuirk.String())
+	add(PointsPlanChange, i18n.Text("Skills"), entity.SkillPoints().String(), plan.SkillPoints().String())
+	add(PointsPlanChange, i18n.Text("Spells"), entity.SpellPoints().String(), plan.SpellPoints().String())
+
+	seen := make(map[string]bool)
+	for _, def := range plan.SheetSettings.Attributes.List() {
+		attrID := def.ID()
+		seen[attrID] = true
+		add(AttributePlanChange, def.Name, attributeValue(entity, attrID), attributeValue(plan, attrID))
+	}
+	for _, def := range entity.SheetSettings.Attributes.List() {
+		if attrID := def.ID(); !seen[attrID] {
+			add(AttributePlanChange, def.Name, attributeValue(entity, attrID), "")
+		}
+	}
+
+	add(DerivedPlanChange, i18n.Text("Basic Lift"), entity.SheetSettings.DefaultWeightUnits.Format(entity.BasicLift()),
+		plan.SheetSettings.DefaultWeightUnits.Format(plan.BasicLift()))
+	add(DerivedPlanChange, i18n.Text("Thrust"), entity.Thrust().String(), plan.Thrust().String())
+	add(DerivedPlanChange, i18n.Text("Swing"), entity.Swing().String(), plan.Swing().String())
+	add(DerivedPlanChange, i18n.Text("Dodge"), strconv.Itoa(entity.Dodge(datafile.None)),
+		strconv.Itoa(plan.Dodge(datafile.None)))
+	add(DerivedPlanChange, i18n.Text("Move"), strconv.Itoa(entity.Move(datafile.None)),
+		strconv.Itoa(plan.Move(datafile.None)))
+
+	comparePlanNodes(TraitPlanChange, entity.Traits, plan.Traits, add,
+		func(t *Trait) string { return t.AdjustedPoints().String() })
+	comparePlanNodes(SkillPlanChange, entity.Skills, plan.Skills, add,
+		func(s *Skill) string { return s.LevelData.LevelAsString(false) })
+	comparePlanNodes(SpellPlanChange, entity.Spells, plan.Spells, add,
+		func(s *Spell) string { return s.LevelData.LevelAsString(false) })
+	return changes
+}
+
+func attributeValue(entity *Entity, attrID string) string {
+	if attr, ok := entity.Attributes.Set[attrID]; ok {
+		return attr.Maximum().String()
+	}
+	return ""
+}
+
+// comparePlanNodes compares the nodes of the entity with those of the plan. Nodes are matched by their IDs, which are
+// preserved when a plan is created.
+func comparePlanNodes[T interface {
+	Node[T]
+	fmt.Stringer
+}](kind PlanChangeKind, entityList, planList []T, add func(kind PlanChangeKind, name, before, after string),
+	value func(T) string) {
+	before := make(map[uuid.UUID]T)
+	Traverse(func(node T) bool {
+		before[node.UUID()] = node
+		return false
+	}, true, true, entityList...)
+	matched := make(map[uuid.UUID]bool)
+	Traverse(func(node T) bool {
+		var beforeValue string
+		if existing, ok := before[node.UUID()]; ok {
+			beforeValue = value(existing)
+			matched[node.UUID()] = true
+		}
+		add(kind, node.String(), beforeValue, value(node))
+		return false
+	}, true, true, planList...)
+	Traverse(func(node T) bool {
+		if !matched[node.UUID()] {
+			add(kind, node.String(), value(node), "")
+		}
+		return false
+	}, true, true, entityList...)
+}
@@ -0,0 +1,74 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package gurps_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/richardwilkes/gcs/model/fxp"
+	"github.com/richardwilkes/gcs/model/gurps"
+	"github.com/richardwilkes/gcs/model/gurps/datafile"
+	"github.com/richardwilkes/gcs/model/gurps/gid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlan(t *testing.T) {
+	ensureSettingsProvider()
+	entity := gurps.NewEntity(datafile.PC)
+	entity.Profile.Name = "Planner"
+	s := gurps.NewSkill(entity, nil, false)
+	s.Name = "Stealth"
+	entity.Skills = append(entity.Skills, s)
+	entity.Recalculate()
+
+	plan, err := entity.NewPlan()
+	require.NoError(t, err)
+	assert.Empty(t, gurps.ComparePlan(entity, plan))
+
+	// Raising DX raises the skill based on it, but leaves Basic Lift alone
+	plan.Attributes.Set[gid.Dexterity].Adjustment = fxp.One
+	plan.Recalculate()
+	assert.Equal(t, fxp.From(10), entity.Attributes.Set[gid.Dexterity].Maximum())
+	changes := make(map[string]*gurps.PlanChange)
+	for _, one := range gurps.ComparePlan(entity, plan) {
+		changes[one.Kind.String()+":"+one.Name] = one
+	}
+	require.Contains(t, changes, "Points:Spent")
+	assert.Equal(t, "20", changes["Points:Attributes"].After)
+	assert.Equal(t, &gurps.PlanChange{Kind: gurps.AttributePlanChange, Name: "DX", Before: "10", After: "11"},
+		changes["Attributes:DX"])
+	assert.Equal(t, &gurps.PlanChange{Kind: gurps.SkillPlanChange, Name: "Stealth", Before: "9", After: "10"},
+		changes["Skills:Stealth"])
+	assert.NotContains(t, changes, "Derived:Basic Lift")
+
+	proposal, err := gurps.NewProposal("Nimble", plan)
+	require.NoError(t, err)
+	entity.SetProposal(proposal)
+	p := filepath.Join(t.TempDir(), "planner.gcs")
+	require.NoError(t, entity.Save(p))
+	loaded, err := gurps.NewEntityFromFile(os.DirFS(filepath.Dir(p)), filepath.Base(p))
+	require.NoError(t, err)
+	require.Len(t, loaded.Proposals, 1)
+	assert.Equal(t, "Nimble", loaded.Proposals[0].Name)
+	assert.Equal(t, fxp.From(11), loaded.Proposals[0].Entity.Attributes.Set[gid.Dexterity].Maximum())
+
+	require.NoError(t, entity.ApplyPlan(plan))
+	assert.Equal(t, fxp.From(11), entity.Attributes.Set[gid.Dexterity].Maximum())
+	assert.Equal(t, fxp.From(10), entity.Skills[0].LevelData.Level)
+	assert.Same(t, entity, entity.Skills[0].Entity)
+	require.Len(t, entity.Proposals, 1)
+	entity.RemoveProposal(proposal.ID)
+	assert.Empty(t, entity.Proposals)
+}
@@ -43,6 +43,9 @@ var (
 	InitialUIScaleMax      = 400
 	InitialListUIScaleDef  = 100
 	InitialSheetUIScaleDef = 133
+	RevisionsToKeepDef     = 10
+	RevisionsToKeepMin     = 1
+	RevisionsToKeepMax     = 100
 )
 
 // General holds settings for a sheet.
@@ -57,6 +60,7 @@ type General struct {
 	InitialListUIScale          int     `json:"initial_list_scale"`
 	InitialSheetUIScale         int     `json:"initial_sheet_scale"`
 	ImageResolution             int     `json:"image_resolution"`
+	RevisionsToKeep             int     `json:"revisions_to_keep"`
 	AutoFillProfile             bool    `json:"auto_fill_profile,omitempty"`
 	IncludeUnspentPointsInTotal bool    `json:"include_unspent_points_in_total,omitempty"`
 }
@@ -78,6 +82,7 @@ func NewGeneral() *General {
 		InitialListUIScale:          InitialListUIScaleDef,
 		InitialSheetUIScale:         InitialSheetUIScaleDef,
 		ImageResolution:             ImageResolutionDef,
+		RevisionsToKeep:             RevisionsToKeepDef,
 		AutoFillProfile:             true,
 		IncludeUnspentPointsInTotal: true,
 	}
@@ -133,4 +138,5 @@ func (s *General) EnsureValidity() {
 	s.ImageResolution = fxp.ResetIfOutOfRangeInt(s.ImageResolution, ImageResolutionMin, ImageResolutionMax, ImageResolutionDef)
 	s.InitialListUIScale = fxp.ResetIfOutOfRangeInt(s.InitialListUIScale, InitialUIScaleMin, InitialUIScaleMax, InitialListUIScaleDef)
 	s.InitialSheetUIScale = fxp.ResetIfOutOfRangeInt(s.InitialSheetUIScale, InitialUIScaleMin, InitialUIScaleMax, InitialSheetUIScaleDef)
+	s.RevisionsToKeep = fxp.ResetIfOutOfRangeInt(s.RevisionsToKeep, RevisionsToKeepMin, RevisionsToKeepMax, RevisionsToKeepDef)
 }
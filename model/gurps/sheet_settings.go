@@ -61,6 +61,7 @@ type SheetSettingsData struct {
 	ShowEquipmentModifierAdj   bool                        `json:"show_equipment_modifier_adj,omitempty"`
 	ShowSpellAdj               bool                        `json:"show_spell_adj,omitempty"`
 	UseTitleInFooter           bool                        `json:"use_title_in_footer,omitempty"`
+	ColumnLayouts              map[string]*ColumnLayout    `json:"column_layouts,omitempty"`
 }
 
 // SheetSettings holds sheet settings.
@@ -149,6 +150,14 @@ func (s *SheetSettings) EnsureValidity() {
 	s.ModifiersDisplay = s.ModifiersDisplay.EnsureValid()
 	s.NotesDisplay = s.NotesDisplay.EnsureValid()
 	s.SkillLevelAdjDisplay = s.SkillLevelAdjDisplay.EnsureValid()
+	for k, v := range s.ColumnLayouts {
+		if v != nil {
+			v.EnsureValidity(nil)
+		}
+		if v == nil || len(v.Columns) == 0 {
+			delete(s.ColumnLayouts, k)
+		}
+	}
 }
 
 // MarshalJSON implements json.Marshaler.
@@ -173,9 +182,33 @@ func (s *SheetSettings) Clone(entity *Entity) *SheetSettings {
 	clone.BlockLayout = s.BlockLayout.Clone()
 	clone.Attributes = s.Attributes.Clone()
 	clone.HitLocations = s.HitLocations.Clone(entity, nil)
+	if s.ColumnLayouts != nil {
+		clone.ColumnLayouts = make(map[string]*ColumnLayout, len(s.ColumnLayouts))
+		for k, v := range s.ColumnLayouts {
+			clone.ColumnLayouts[k] = v.Clone()
+		}
+	}
 	return &clone
 }
 
+// ColumnLayout returns a copy of the column layout for the list with the given key, or nil if the list should use its
+// default columns.
+func (s *SheetSettings) ColumnLayout(key string) *ColumnLayout {
+	return s.ColumnLayouts[key].Clone()
+}
+
+// SetColumnLayout sets the column layout for the list with the given key. Pass nil to revert to the default columns.
+func (s *SheetSettings) SetColumnLayout(key string, layout *ColumnLayout) {
+	if layout == nil || len(layout.Columns) == 0 {
+		delete(s.ColumnLayouts, key)
+		return
+	}
+	if s.ColumnLayouts == nil {
+		s.ColumnLayouts = make(map[string]*ColumnLayout)
+	}
+	s.ColumnLayouts[key] = layout.Clone()
+}
+
 // SetOwningEntity sets the owning entity and configures any sub-components as needed.
 func (s *SheetSettings) SetOwningEntity(entity *Entity) {
 	s.Entity = entity
@@ -23,6 +23,7 @@ import (
 	"github.com/richardwilkes/gcs/model/gurps/gid"
 	"github.com/richardwilkes/gcs/model/gurps/skill"
 	"github.com/richardwilkes/gcs/model/jio"
+	"github.com/richardwilkes/gcs/model/rolls"
 	"github.com/richardwilkes/json"
 	"github.com/richardwilkes/toolbox/errs"
 	"github.com/richardwilkes/toolbox/i18n"
@@ -46,6 +47,10 @@ const (
 	SkillLevelColumn
 	SkillRelativeLevelColumn
 	SkillPointsColumn
+	SkillTLColumn
+	SkillVTTNotesColumn
+	SkillLibrarySourceColumn
+	SkillIDColumn
 )
 
 const skillListTypeKey = "skill_list"
@@ -56,6 +61,7 @@ type Skill struct {
 	Entity            *Entity
 	LevelData         skill.Level
 	UnsatisfiedReason string
+	Unknown           UnknownFields
 }
 
 type skillListData struct {
@@ -68,7 +74,7 @@ type skillListData struct {
 func NewSkillsFromFile(fileSystem fs.FS, filePath string) ([]*Skill, error) {
 	var data skillListData
 	if err := jio.LoadFromFS(context.Background(), fileSystem, filePath, &data); err != nil {
-		return nil, errs.NewWithCause(gid.InvalidFileDataMsg, err)
+		return nil, gid.InvalidFileData(err)
 	}
 	if data.Type != skillListTypeKey {
 		return nil, errs.New(gid.UnexpectedFileDataMsg)
@@ -131,6 +137,7 @@ func (s *Skill) Clone(entity *Entity, parent *Skill, preserveID bool) *Skill {
 	}
 	other.IsOpen = s.IsOpen
 	other.SkillEditData.CopyFrom(s)
+	other.Unknown = s.Unknown.Clone()
 	if s.HasChildren() {
 		other.Children = make([]*Skill, 0, len(s.Children))
 		for _, child := range s.Children {
@@ -144,13 +151,13 @@ func (s *Skill) Clone(entity *Entity, parent *Skill, preserveID bool) *Skill {
 func (s *Skill) MarshalJSON() ([]byte, error) {
 	s.ClearUnusedFieldsForType()
 	if s.Container() || s.LevelData.Level <= 0 {
-		return json.Marshal(&s.SkillData)
+		return marshalWithUnknownFields(&s.SkillData, s.Unknown)
 	}
 	type calc struct {
 		Level              fxp.Int `json:"level"`
 		RelativeSkillLevel string  `json:"rsl"`
 	}
-	return json.Marshal(&struct {
+	return marshalWithUnknownFields(&struct {
 		SkillData
 		Calc calc `json:"calc"`
 	}{
@@ -159,7 +166,7 @@ func (s *Skill) MarshalJSON() ([]byte, error) {
 			Level:              s.LevelData.Level,
 			RelativeSkillLevel: s.RelativeLevel(),
 		},
-	})
+	}, s.Unknown)
 }
 
 // UnmarshalJSON implements json.Unmarshaler.
@@ -172,8 +179,13 @@ func (s *Skill) UnmarshalJSON(data []byte) error {
 	if err := json.Unmarshal(data, &localData); err != nil {
 		return err
 	}
+	unknown, err := extractUnknownFields(data, &localData, calcKey)
+	if err != nil {
+		return err
+	}
 	localData.ClearUnusedFieldsForType()
 	s.SkillData = localData.SkillData
+	s.Unknown = unknown
 	s.Tags = convertOldCategoriesToTags(s.Tags, localData.Categories)
 	slices.Sort(s.Tags)
 	if s.Container() {
@@ -213,6 +225,9 @@ func (s *Skill) CellData(column int, data *CellData) {
 				data.Tooltip = IncludesModifiersFrom + ":" + level.Tooltip
 			}
 			data.Alignment = unison.EndAlignment
+			if s.Entity != nil && level.Level > 0 {
+				data.Roll = rolls.NewSuccessRequest(s.Entity.RollerName(), s.String(), fxp.As[int](level.Level.Trunc()))
+			}
 		}
 	case SkillRelativeLevelColumn:
 		if !s.Container() {
@@ -227,6 +242,23 @@ func (s *Skill) CellData(column int, data *CellData) {
 		if tooltip.Len() != 0 {
 			data.Tooltip = IncludesModifiersFrom + ":" + tooltip.String()
 		}
+	case SkillTLColumn:
+		if !s.Container() && s.TechLevel != nil {
+			data.Type = Text
+			data.Primary = *s.TechLevel
+			data.Alignment = unison.EndAlignment
+		}
+	case SkillVTTNotesColumn:
+		data.Type = Text
+		data.Primary = s.VTTNotes
+	case SkillLibrarySourceColumn:
+		if s.Source != nil {
+			data.Type = Text
+			data.Primary = s.Source.String()
+		}
+	case SkillIDColumn:
+		data.Type = Text
+		data.Primary = s.ID.String()
 	}
 }
 
@@ -673,6 +705,54 @@ func (s *Skill) inDefaultChain(def *SkillDefault, lookedAt map[*Skill]bool) bool
 	return !hadOne
 }
 
+// hasDefaultTo returns true if one of this skill's defaults is based on the other skill.
+func (s *Skill) hasDefaultTo(other *Skill) bool {
+	for _, def := range s.Defaults {
+		if def.SkillBased() && strings.EqualFold(def.Name, other.Name) &&
+			(def.Specialization == "" || strings.EqualFold(def.Specialization, other.Specialization)) {
+			return true
+		}
+	}
+	return false
+}
+
+// SwappableDefaultSkill returns the skill this skill is currently defaulting from, if the two may have their defaults
+// swapped. Only skills (not techniques) that default from another skill that, in turn, has a default back to this skill
+// may be swapped.
+func (s *Skill) SwappableDefaultSkill() *Skill {
+	if s.Entity == nil || s.Container() || !strings.HasPrefix(s.Type, gid.Skill) || s.DefaultedFrom == nil {
+		return nil
+	}
+	base := s.DefaultSkill()
+	if base == nil || base == s || base.Container() || !strings.HasPrefix(base.Type, gid.Skill) ||
+		!base.hasDefaultTo(s) {
+		return nil
+	}
+	return base
+}
+
+// CanSwapDefaults returns true if this skill's default can be swapped with the skill it is currently defaulting from.
+func (s *Skill) CanSwapDefaults() bool {
+	return s.SwappableDefaultSkill() != nil
+}
+
+// SwapDefaults swaps this skill with the skill it is currently defaulting from, so that the other skill now defaults
+// from this one. The points spent on each are exchanged, so that this skill now carries the points that were spent on
+// the other. Returns the other skill, or nil if no swap was possible.
+func (s *Skill) SwapDefaults() *Skill {
+	base := s.SwappableDefaultSkill()
+	if base == nil {
+		return nil
+	}
+	s.Points, base.Points = base.Points, s.Points
+	// Drop our default first, so that it no longer blocks the base skill from defaulting to us.
+	s.DefaultedFrom = nil
+	base.UpdateLevel()
+	s.UpdateLevel()
+	s.Entity.Recalculate()
+	return base
+}
+
 // TechniqueSatisfied returns true if the Technique is satisfied.
 func (s *Skill) TechniqueSatisfied(tooltip *xio.ByteBuffer, prefix string) bool {
 	if strings.HasPrefix(s.Type, gid.Skill) || !s.TechniqueDefault.SkillBased() {
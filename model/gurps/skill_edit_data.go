@@ -22,6 +22,7 @@ var _ EditorData[*Skill] = &SkillEditData{}
 
 // SkillEditData holds the Skill data that can be edited by the UI detail editor.
 type SkillEditData struct {
+	LibrarySourceData
 	Name                         string              `json:"name,omitempty"`
 	PageRef                      string              `json:"reference,omitempty"`
 	LocalNotes                   string              `json:"notes,omitempty"`
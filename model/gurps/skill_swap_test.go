@@ -0,0 +1,135 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package gurps_test
+
+import (
+	"testing"
+
+	"github.com/richardwilkes/gcs/model/fxp"
+	"github.com/richardwilkes/gcs/model/gurps"
+	"github.com/richardwilkes/gcs/model/gurps/datafile"
+	"github.com/richardwilkes/gcs/model/gurps/gid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newDefaultingSkill(entity *gurps.Entity, name string, points int, defaultsTo ...string) *gurps.Skill {
+	s := gurps.NewSkill(entity, nil, false)
+	s.Name = name
+	s.Points = fxp.From(points)
+	s.Defaults = []*gurps.SkillDefault{{DefaultType: "dx", Modifier: -fxp.Five}}
+	for _, other := range defaultsTo {
+		s.Defaults = append(s.Defaults, &gurps.SkillDefault{
+			DefaultType: gid.Skill,
+			Name:        other,
+			Modifier:    -fxp.Two,
+		})
+	}
+	entity.Skills = append(entity.Skills, s)
+	return s
+}
+
+// assertNoDefaultCycles verifies that following the default chain from each skill never returns to a skill already seen.
+func assertNoDefaultCycles(t *testing.T, entity *gurps.Entity) {
+	t.Helper()
+	for _, s := range entity.Skills {
+		seen := make(map[*gurps.Skill]bool)
+		for one := s; one != nil; one = one.DefaultSkill() {
+			require.False(t, seen[one], "default cycle involving %s", one.Name)
+			seen[one] = true
+		}
+	}
+}
+
+func TestSwapDefaults(t *testing.T) {
+	ensureSettingsProvider()
+	entity := gurps.NewEntity(datafile.PC)
+	broadsword := newDefaultingSkill(entity, "Broadsword", 8, "Shortsword")
+	shortsword := newDefaultingSkill(entity, "Shortsword", 0, "Broadsword")
+	technique := gurps.NewTechnique(entity, nil, "Broadsword")
+	technique.Name = "Disarming"
+	technique.Points = fxp.Two
+	entity.Skills = append(entity.Skills, technique)
+	otherTechnique := gurps.NewTechnique(entity, nil, "Shortsword")
+	otherTechnique.Name = "Feint"
+	otherTechnique.Points = fxp.One
+	entity.Skills = append(entity.Skills, otherTechnique)
+	entity.Recalculate()
+
+	require.Equal(t, broadsword, shortsword.DefaultSkill())
+	assert.Equal(t, fxp.From(12), broadsword.LevelData.Level)
+	assert.Equal(t, fxp.From(10), shortsword.LevelData.Level)
+	assert.Equal(t, fxp.From(14), technique.LevelData.Level)
+	assert.Equal(t, fxp.Min, otherTechnique.LevelData.Level)
+	assert.True(t, shortsword.CanSwapDefaults())
+	assert.False(t, broadsword.CanSwapDefaults(), "the skill carrying the points isn't defaulting from anything")
+	assert.False(t, technique.CanSwapDefaults(), "techniques cannot swap defaults")
+	assert.Nil(t, technique.SwapDefaults())
+
+	assert.Equal(t, broadsword, shortsword.SwapDefaults())
+	assert.Equal(t, fxp.From(8), shortsword.Points)
+	assert.Equal(t, fxp.From(0), broadsword.Points)
+	assert.Nil(t, shortsword.DefaultSkill())
+	assert.Equal(t, shortsword, broadsword.DefaultSkill())
+	assert.Equal(t, fxp.From(12), shortsword.LevelData.Level)
+	assert.Equal(t, fxp.From(10), broadsword.LevelData.Level)
+	// Techniques require points in their base skill, so they follow the points
+	assert.Equal(t, fxp.Min, technique.LevelData.Level)
+	assert.False(t, technique.TechniqueSatisfied(nil, ""))
+	assert.Equal(t, fxp.From(13), otherTechnique.LevelData.Level)
+	assertNoDefaultCycles(t, entity)
+
+	// Swapping back restores the original arrangement
+	assert.True(t, broadsword.CanSwapDefaults())
+	assert.Equal(t, shortsword, broadsword.SwapDefaults())
+	assert.Equal(t, fxp.From(8), broadsword.Points)
+	assert.Equal(t, fxp.From(0), shortsword.Points)
+	assert.Equal(t, broadsword, shortsword.DefaultSkill())
+	assert.Equal(t, fxp.From(12), broadsword.LevelData.Level)
+	assert.Equal(t, fxp.From(10), shortsword.LevelData.Level)
+	assertNoDefaultCycles(t, entity)
+}
+
+func TestSwapDefaultsRequiresDefaultBack(t *testing.T) {
+	ensureSettingsProvider()
+	entity := gurps.NewEntity(datafile.PC)
+	newDefaultingSkill(entity, "Broadsword", 8)
+	shortsword := newDefaultingSkill(entity, "Shortsword", 0, "Broadsword")
+	entity.Recalculate()
+	require.NotNil(t, shortsword.DefaultSkill())
+	assert.False(t, shortsword.CanSwapDefaults())
+	assert.Nil(t, shortsword.SwapDefaults())
+	assert.Equal(t, fxp.From(0), shortsword.Points)
+}
+
+func TestSwapDefaultsWithinCycle(t *testing.T) {
+	ensureSettingsProvider()
+	entity := gurps.NewEntity(datafile.PC)
+	a := newDefaultingSkill(entity, "A", 8, "B", "C")
+	b := newDefaultingSkill(entity, "B", 0, "A", "C")
+	c := newDefaultingSkill(entity, "C", 0, "A", "B")
+	entity.Recalculate()
+	assertNoDefaultCycles(t, entity)
+	require.Equal(t, a, b.DefaultSkill())
+	require.Equal(t, a, c.DefaultSkill())
+
+	assert.Equal(t, a, b.SwapDefaults())
+	assert.Equal(t, fxp.From(8), b.Points)
+	assert.Equal(t, fxp.From(0), a.Points)
+	assert.Equal(t, fxp.From(0), c.Points)
+	assert.Nil(t, b.DefaultSkill())
+	assert.Equal(t, b, a.DefaultSkill())
+	assert.Equal(t, b, c.DefaultSkill())
+	assertNoDefaultCycles(t, entity)
+	assert.Equal(t, fxp.From(12), b.LevelData.Level)
+	assert.Equal(t, fxp.From(10), a.LevelData.Level)
+}
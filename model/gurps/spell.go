@@ -23,6 +23,7 @@ import (
 	"github.com/richardwilkes/gcs/model/gurps/gid"
 	"github.com/richardwilkes/gcs/model/gurps/skill"
 	"github.com/richardwilkes/gcs/model/jio"
+	"github.com/richardwilkes/gcs/model/rolls"
 	"github.com/richardwilkes/json"
 	"github.com/richardwilkes/toolbox/errs"
 	"github.com/richardwilkes/toolbox/i18n"
@@ -54,6 +55,10 @@ const (
 	SpellRelativeLevelColumn
 	SpellPointsColumn
 	SpellDescriptionForPageColumn
+	SpellTLColumn
+	SpellVTTNotesColumn
+	SpellLibrarySourceColumn
+	SpellIDColumn
 )
 
 const spellListTypeKey = "spell_list"
@@ -64,6 +69,7 @@ type Spell struct {
 	Entity            *Entity
 	LevelData         skill.Level
 	UnsatisfiedReason string
+	Unknown           UnknownFields
 }
 
 type spellListData struct {
@@ -76,7 +82,7 @@ type spellListData struct {
 func NewSpellsFromFile(fileSystem fs.FS, filePath string) ([]*Spell, error) {
 	var data spellListData
 	if err := jio.LoadFromFS(context.Background(), fileSystem, filePath, &data); err != nil {
-		return nil, errs.NewWithCause(gid.InvalidFileDataMsg, err)
+		return nil, gid.InvalidFileData(err)
 	}
 	if data.Type != spellListTypeKey {
 		return nil, errs.New(gid.UnexpectedFileDataMsg)
@@ -141,6 +147,7 @@ func (s *Spell) Clone(entity *Entity, parent *Spell, preserveID bool) *Spell {
 	}
 	other.IsOpen = s.IsOpen
 	other.SpellEditData.CopyFrom(s)
+	other.Unknown = s.Unknown.Clone()
 	if s.HasChildren() {
 		other.Children = make([]*Spell, 0, len(s.Children))
 		for _, child := range s.Children {
@@ -154,13 +161,13 @@ func (s *Spell) Clone(entity *Entity, parent *Spell, preserveID bool) *Spell {
 func (s *Spell) MarshalJSON() ([]byte, error) {
 	s.ClearUnusedFieldsForType()
 	if s.Container() || s.LevelData.Level <= 0 {
-		return json.Marshal(&s.SpellData)
+		return marshalWithUnknownFields(&s.SpellData, s.Unknown)
 	}
 	type calc struct {
 		Level              fxp.Int `json:"level"`
 		RelativeSkillLevel string  `json:"rsl"`
 	}
-	return json.Marshal(&struct {
+	return marshalWithUnknownFields(&struct {
 		SpellData
 		Calc calc `json:"calc"`
 	}{
@@ -169,7 +176,7 @@ func (s *Spell) MarshalJSON() ([]byte, error) {
 			Level:              s.LevelData.Level,
 			RelativeSkillLevel: s.RelativeLevel(),
 		},
-	})
+	}, s.Unknown)
 }
 
 // UnmarshalJSON implements json.Unmarshaler.
@@ -182,8 +189,13 @@ func (s *Spell) UnmarshalJSON(data []byte) error {
 	if err := json.Unmarshal(data, &localData); err != nil {
 		return err
 	}
+	unknown, err := extractUnknownFields(data, &localData, calcKey)
+	if err != nil {
+		return err
+	}
 	localData.ClearUnusedFieldsForType()
 	s.SpellData = localData.SpellData
+	s.Unknown = unknown
 	s.Tags = convertOldCategoriesToTags(s.Tags, localData.Categories)
 	slices.Sort(s.Tags)
 	if s.Container() {
@@ -258,6 +270,9 @@ func (s *Spell) CellData(column int, data *CellData) {
 				data.Tooltip = IncludesModifiersFrom + ":" + level.Tooltip
 			}
 			data.Alignment = unison.EndAlignment
+			if s.Entity != nil && level.Level > 0 {
+				data.Roll = rolls.NewSuccessRequest(s.Entity.RollerName(), s.String(), fxp.As[int](level.Level.Trunc()))
+			}
 		}
 	case SpellRelativeLevelColumn:
 		if !s.Container() {
@@ -300,6 +315,23 @@ func (s *Spell) CellData(column int, data *CellData) {
 				}
 			}
 		}
+	case SpellTLColumn:
+		if !s.Container() && s.TechLevel != nil {
+			data.Type = Text
+			data.Primary = *s.TechLevel
+			data.Alignment = unison.EndAlignment
+		}
+	case SpellVTTNotesColumn:
+		data.Type = Text
+		data.Primary = s.VTTNotes
+	case SpellLibrarySourceColumn:
+		if s.Source != nil {
+			data.Type = Text
+			data.Primary = s.Source.String()
+		}
+	case SpellIDColumn:
+		data.Type = Text
+		data.Primary = s.ID.String()
 	}
 }
 
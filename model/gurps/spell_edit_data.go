@@ -20,6 +20,7 @@ var _ EditorData[*Spell] = &SpellEditData{}
 
 // SpellEditData holds the Spell data that can be edited by the UI detail editor.
 type SpellEditData struct {
+	LibrarySourceData
 	Name              string              `json:"name,omitempty"`
 	PageRef           string              `json:"reference,omitempty"`
 	LocalNotes        string              `json:"notes,omitempty"`
@@ -44,7 +44,7 @@ type Template struct {
 func NewTemplateFromFile(fileSystem fs.FS, filePath string) (*Template, error) {
 	var template Template
 	if err := jio.LoadFromFS(context.Background(), fileSystem, filePath, &template); err != nil {
-		return nil, errs.NewWithCause(gid.InvalidFileDataMsg, err)
+		return nil, gid.InvalidFileData(err)
 	}
 	if template.Type != templateTypeKey {
 		return nil, errs.New(gid.UnexpectedFileDataMsg)
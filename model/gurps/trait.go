@@ -13,6 +13,7 @@ package gurps
 
 import (
 	"context"
+	"fmt"
 	"io/fs"
 	"strings"
 
@@ -22,6 +23,7 @@ import (
 	"github.com/richardwilkes/gcs/model/gurps/nameables"
 	"github.com/richardwilkes/gcs/model/gurps/trait"
 	"github.com/richardwilkes/gcs/model/jio"
+	"github.com/richardwilkes/gcs/model/rolls"
 	"github.com/richardwilkes/json"
 	"github.com/richardwilkes/toolbox/errs"
 	"github.com/richardwilkes/toolbox/i18n"
@@ -40,6 +42,9 @@ const (
 	TraitPointsColumn
 	TraitTagsColumn
 	TraitReferenceColumn
+	TraitVTTNotesColumn
+	TraitLibrarySourceColumn
+	TraitIDColumn
 )
 
 const (
@@ -52,6 +57,7 @@ type Trait struct {
 	TraitData
 	Entity            *Entity
 	UnsatisfiedReason string
+	Unknown           UnknownFields
 }
 
 type traitListData struct {
@@ -64,7 +70,7 @@ type traitListData struct {
 func NewTraitsFromFile(fileSystem fs.FS, filePath string) ([]*Trait, error) {
 	var data traitListData
 	if err := jio.LoadFromFS(context.Background(), fileSystem, filePath, &data); err != nil {
-		return nil, errs.NewWithCause(gid.InvalidFileDataMsg, err)
+		return nil, gid.InvalidFileData(err)
 	}
 	if data.Type == "advantage_list" {
 		data.Type = traitListTypeKey
@@ -108,6 +114,7 @@ func (a *Trait) Clone(entity *Entity, parent *Trait, preserveID bool) *Trait {
 	}
 	other.IsOpen = a.IsOpen
 	other.TraitEditData.CopyFrom(a)
+	other.Unknown = a.Unknown.Clone()
 	if a.HasChildren() {
 		other.Children = make([]*Trait, 0, len(a.Children))
 		for _, child := range a.Children {
@@ -132,7 +139,7 @@ func (a *Trait) MarshalJSON() ([]byte, error) {
 			Points: a.AdjustedPoints(),
 		},
 	}
-	return json.Marshal(&data)
+	return marshalWithUnknownFields(&data, a.Unknown)
 }
 
 // UnmarshalJSON implements json.Unmarshaler.
@@ -150,6 +157,10 @@ func (a *Trait) UnmarshalJSON(data []byte) error {
 	if err := json.Unmarshal(data, &localData); err != nil {
 		return err
 	}
+	unknown, err := extractUnknownFields(data, &localData, calcKey)
+	if err != nil {
+		return err
+	}
 
 	// Swap out old type keys
 	switch localData.Type {
@@ -161,6 +172,7 @@ func (a *Trait) UnmarshalJSON(data []byte) error {
 
 	localData.ClearUnusedFieldsForType()
 	a.TraitData = localData.TraitData
+	a.Unknown = unknown
 	a.transferOldTypeFlagToTags(i18n.Text("Mental"), localData.Mental)
 	a.transferOldTypeFlagToTags(i18n.Text("Physical"), localData.Physical)
 	a.transferOldTypeFlagToTags(i18n.Text("Social"), localData.Social)
@@ -192,6 +204,11 @@ func (a *Trait) CellData(column int, data *CellData) {
 		data.Secondary = a.SecondaryText()
 		data.Disabled = a.Disabled
 		data.UnsatisfiedReason = a.UnsatisfiedReason
+		if a.Entity != nil && a.CR != trait.None && a.Enabled() {
+			data.Roll = rolls.NewSuccessRequest(a.Entity.RollerName(), fmt.Sprintf(i18n.Text("Self-Control: %s"),
+				a.String()), int(a.CR))
+			data.RollLabel = fmt.Sprintf(i18n.Text("Roll Self-Control (%d)"), int(a.CR))
+		}
 	case TraitPointsColumn:
 		data.Type = Text
 		data.Primary = a.AdjustedPoints().String()
@@ -203,6 +220,17 @@ func (a *Trait) CellData(column int, data *CellData) {
 		data.Type = PageRef
 		data.Primary = a.PageRef
 		data.Secondary = a.Name
+	case TraitVTTNotesColumn:
+		data.Type = Text
+		data.Primary = a.VTTNotes
+	case TraitLibrarySourceColumn:
+		if a.Source != nil {
+			data.Type = Text
+			data.Primary = a.Source.String()
+		}
+	case TraitIDColumn:
+		data.Type = Text
+		data.Primary = a.ID.String()
 	}
 }
 
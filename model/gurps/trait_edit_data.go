@@ -22,6 +22,7 @@ var _ EditorData[*Trait] = &TraitEditData{}
 
 // TraitEditData holds the Trait data that can be edited by the UI detail editor.
 type TraitEditData struct {
+	LibrarySourceData
 	Name           string                `json:"name,omitempty"`
 	PageRef        string                `json:"reference,omitempty"`
 	LocalNotes     string                `json:"notes,omitempty"`
@@ -37,6 +37,8 @@ const (
 	TraitModifierCostColumn
 	TraitModifierTagsColumn
 	TraitModifierReferenceColumn
+	TraitModifierVTTNotesColumn
+	TraitModifierIDColumn
 )
 
 const (
@@ -47,7 +49,8 @@ const (
 // TraitModifier holds a modifier to an Trait.
 type TraitModifier struct {
 	TraitModifierData
-	Entity *Entity
+	Entity  *Entity
+	Unknown UnknownFields
 }
 
 type traitModifierListData struct {
@@ -60,7 +63,7 @@ type traitModifierListData struct {
 func NewTraitModifiersFromFile(fileSystem fs.FS, filePath string) ([]*TraitModifier, error) {
 	var data traitModifierListData
 	if err := jio.LoadFromFS(context.Background(), fileSystem, filePath, &data); err != nil {
-		return nil, errs.NewWithCause(gid.InvalidFileDataMsg, err)
+		return nil, gid.InvalidFileData(err)
 	}
 	if data.Type != traitModifierListTypeKey {
 		return nil, errs.New(gid.UnexpectedFileDataMsg)
@@ -101,6 +104,7 @@ func (a *TraitModifier) Clone(entity *Entity, parent *TraitModifier, preserveID
 	}
 	other.IsOpen = a.IsOpen
 	other.TraitModifierEditData.CopyFrom(a)
+	other.Unknown = a.Unknown.Clone()
 	if a.HasChildren() {
 		other.Children = make([]*TraitModifier, 0, len(a.Children))
 		for _, child := range a.Children {
@@ -113,7 +117,7 @@ func (a *TraitModifier) Clone(entity *Entity, parent *TraitModifier, preserveID
 // MarshalJSON implements json.Marshaler.
 func (a *TraitModifier) MarshalJSON() ([]byte, error) {
 	a.ClearUnusedFieldsForType()
-	return json.Marshal(&a.TraitModifierData)
+	return marshalWithUnknownFields(&a.TraitModifierData, a.Unknown)
 }
 
 // UnmarshalJSON implements json.Unmarshaler.
@@ -126,8 +130,13 @@ func (a *TraitModifier) UnmarshalJSON(data []byte) error {
 	if err := json.Unmarshal(data, &localData); err != nil {
 		return err
 	}
+	unknown, err := extractUnknownFields(data, &localData)
+	if err != nil {
+		return err
+	}
 	localData.ClearUnusedFieldsForType()
 	a.TraitModifierData = localData.TraitModifierData
+	a.Unknown = unknown
 	a.Tags = convertOldCategoriesToTags(a.Tags, localData.Categories)
 	slices.Sort(a.Tags)
 	if a.Container() {
@@ -163,6 +172,12 @@ func (a *TraitModifier) CellData(column int, data *CellData) {
 		data.Type = PageRef
 		data.Primary = a.PageRef
 		data.Secondary = a.Name
+	case TraitModifierVTTNotesColumn:
+		data.Type = Text
+		data.Primary = a.VTTNotes
+	case TraitModifierIDColumn:
+		data.Type = Text
+		data.Primary = a.ID.String()
 	}
 }
 
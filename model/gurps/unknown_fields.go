@@ -0,0 +1,175 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package gurps
+
+import (
+	"bytes"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/richardwilkes/json"
+	"github.com/richardwilkes/toolbox/errs"
+)
+
+// calcKey is the key used for calculated values, which are written out for the benefit of third parties, but never read
+// back in.
+const calcKey = "calc"
+
+var knownJSONKeysCache sync.Map
+
+// UnknownFields holds the JSON fields that were present when the data was loaded, but are not understood by the data
+// model. They are preserved so that they can be written back out unchanged, allowing third-party tools to store their
+// own data on individual items.
+type UnknownFields map[string]json.RawMessage
+
+// Clone returns a copy of the fields.
+func (u UnknownFields) Clone() UnknownFields {
+	if len(u) == 0 {
+		return nil
+	}
+	other := make(UnknownFields, len(u))
+	for k, v := range u {
+		other[k] = append(json.RawMessage(nil), v...)
+	}
+	return other
+}
+
+// extractUnknownFields returns the fields within the JSON object in data whose keys don't correspond to any of the JSON
+// keys of the struct that 'known' points to, nor to any of the additional keys provided.
+func extractUnknownFields(data []byte, known any, additionalKnownKeys ...string) (UnknownFields, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, errs.Wrap(err)
+	}
+	keys := knownJSONKeys(reflect.TypeOf(known))
+	var unknown UnknownFields
+outer:
+	for k, v := range fields {
+		lower := strings.ToLower(k)
+		if keys[lower] {
+			continue
+		}
+		for _, one := range additionalKnownKeys {
+			if lower == one {
+				continue outer
+			}
+		}
+		if unknown == nil {
+			unknown = make(UnknownFields)
+		}
+		unknown[k] = v
+	}
+	return unknown, nil
+}
+
+// marshalWithUnknownFields marshals the data, which must produce a JSON object, then adds the unknown fields to it.
+func marshalWithUnknownFields(data any, unknown UnknownFields) ([]byte, error) {
+	buffer, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return unknown.appendTo(buffer)
+}
+
+// appendTo returns the JSON object in data with the unknown fields added to the end, sorted by key. Fields whose keys are
+// already present in the object are not added.
+func (u UnknownFields) appendTo(data []byte) ([]byte, error) {
+	if len(u) == 0 {
+		return data, nil
+	}
+	var existing map[string]json.RawMessage
+	if err := json.Unmarshal(data, &existing); err != nil {
+		return nil, errs.Wrap(err)
+	}
+	keys := make([]string, 0, len(u))
+	for k := range u {
+		if _, exists := existing[k]; !exists {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == 0 {
+		return data, nil
+	}
+	sort.Strings(keys)
+	data = bytes.TrimSpace(data)
+	var buffer bytes.Buffer
+	buffer.Write(data[:len(data)-1])
+	needComma := len(existing) != 0
+	for _, k := range keys {
+		if needComma {
+			buffer.WriteByte(',')
+		}
+		needComma = true
+		key, err := json.Marshal(k)
+		if err != nil {
+			return nil, errs.Wrap(err)
+		}
+		buffer.Write(key)
+		buffer.WriteByte(':')
+		buffer.Write(u[k])
+	}
+	buffer.WriteByte('}')
+	return buffer.Bytes(), nil
+}
+
+// knownJSONKeys returns the set of lowercased JSON keys, including alternates, that the struct type (or pointer to a
+// struct type) will decode.
+func knownJSONKeys(t reflect.Type) map[string]bool {
+	if cached, ok := knownJSONKeysCache.Load(t); ok {
+		return cached.(map[string]bool) //nolint:errcheck // Only this type is stored
+	}
+	keys := make(map[string]bool)
+	collectKnownJSONKeys(t, keys)
+	knownJSONKeysCache.Store(t, keys)
+	return keys
+}
+
+func collectKnownJSONKeys(t reflect.Type, keys map[string]bool) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			for ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				collectKnownJSONKeys(ft, keys)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		keys[strings.ToLower(name)] = true
+		for _, option := range strings.Split(options, ",") {
+			if strings.HasPrefix(option, "alt=") && len(option) > 4 {
+				keys[strings.ToLower(option[4:])] = true
+			}
+		}
+	}
+}
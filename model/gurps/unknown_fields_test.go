@@ -0,0 +1,90 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package gurps_test
+
+import (
+	"flag"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/richardwilkes/gcs/model/gurps"
+	"github.com/richardwilkes/gcs/model/settings"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var updateGolden = flag.Bool("update", false, "update the golden files")
+
+func ensureSettingsProvider() {
+	if gurps.SettingsProvider == nil {
+		s := settings.Default()
+		s.General.AutoFillProfile = false
+		gurps.SettingsProvider = s
+	}
+}
+
+func TestUnknownFieldsRoundTrip(t *testing.T) {
+	ensureSettingsProvider()
+	const dir = "testdata/unknown_fields"
+	checkRoundTrip(t, dir, "traits.adq", gurps.NewTraitsFromFile, gurps.SaveTraits)
+	checkRoundTrip(t, dir, "modifiers.adm", gurps.NewTraitModifiersFromFile, gurps.SaveTraitModifiers)
+	checkRoundTrip(t, dir, "skills.skl", gurps.NewSkillsFromFile, gurps.SaveSkills)
+	checkRoundTrip(t, dir, "spells.spl", gurps.NewSpellsFromFile, gurps.SaveSpells)
+	checkRoundTrip(t, dir, "equipment.eqp", gurps.NewEquipmentFromFile, gurps.SaveEquipment)
+	checkRoundTrip(t, dir, "modifiers.eqm", gurps.NewEquipmentModifiersFromFile, gurps.SaveEquipmentModifiers)
+	checkRoundTrip(t, dir, "notes.not", gurps.NewNotesFromFile, gurps.SaveNotes)
+}
+
+func TestUnknownFieldsSurviveClone(t *testing.T) {
+	traits, err := gurps.NewTraitsFromFile(os.DirFS("testdata/unknown_fields"), "traits.adq")
+	require.NoError(t, err)
+	require.Len(t, traits, 1)
+	clone := traits[0].Clone(nil, nil, false)
+	assert.JSONEq(t, `{"pinned":true}`, string(clone.Unknown["vtt_flags"]))
+	require.Len(t, clone.Children, 1)
+	child := clone.Children[0]
+	assert.JSONEq(t, `"eye"`, string(child.Unknown["vtt_token"]))
+	require.Len(t, child.Modifiers, 1)
+	assert.JSONEq(t, `"scope.png"`, string(child.Modifiers[0].Unknown["vtt_icon"]))
+	require.Len(t, child.Weapons, 1)
+	assert.JSONEq(t, `42`, string(child.Weapons[0].Unknown["vtt_attack_id"]))
+	_, exists := child.Unknown["calc"]
+	assert.False(t, exists)
+}
+
+func checkRoundTrip[T any](t *testing.T, dir, name string, load func(fs.FS, string) ([]T, error), save func([]T, string) error) {
+	t.Helper()
+	goldenPath := filepath.Join(dir, name)
+	rows, err := load(os.DirFS(dir), name)
+	require.NoError(t, err, name)
+	outPath := filepath.Join(t.TempDir(), name)
+	require.NoError(t, save(rows, outPath), name)
+	out, err := os.ReadFile(outPath)
+	require.NoError(t, err, name)
+	if *updateGolden {
+		// Only the final bytes are copied into testdata, so an interrupted update never leaves temporary files there
+		require.NoError(t, os.WriteFile(goldenPath, out, 0o640), name)
+	}
+	golden, err := os.ReadFile(goldenPath)
+	require.NoError(t, err, name)
+	assert.Equal(t, string(golden), string(out), name)
+
+	// A second round trip must also be byte-stable
+	rows, err = load(os.DirFS(filepath.Dir(outPath)), name)
+	require.NoError(t, err, name)
+	require.NoError(t, save(rows, outPath), name)
+	out, err = os.ReadFile(outPath)
+	require.NoError(t, err, name)
+	assert.Equal(t, string(golden), string(out), name)
+}
@@ -27,6 +27,7 @@ import (
 	"github.com/richardwilkes/gcs/model/gurps/skill"
 	"github.com/richardwilkes/gcs/model/gurps/weapon"
 	"github.com/richardwilkes/gcs/model/id"
+	"github.com/richardwilkes/gcs/model/rolls"
 	"github.com/richardwilkes/json"
 	"github.com/richardwilkes/toolbox/i18n"
 	"github.com/richardwilkes/toolbox/log/jot"
@@ -86,8 +87,9 @@ type WeaponData struct {
 // Weapon holds the stats for a weapon.
 type Weapon struct {
 	WeaponData
-	Owner WeaponOwner
-	id    uuid.UUID
+	Owner   WeaponOwner
+	Unknown UnknownFields
+	id      uuid.UUID
 }
 
 // ExtractWeaponsOfType filters the input list down to only those weapons of the given type.
@@ -131,6 +133,7 @@ func (w *Weapon) Clone(_ *Entity, _ *Weapon, preserveID bool) *Weapon {
 		other.id = uuid.New()
 	}
 	other.Damage = *other.Damage.Clone(&other)
+	other.Unknown = w.Unknown.Clone()
 	if other.Defaults != nil {
 		other.Defaults = make([]*SkillDefault, 0, len(w.Defaults))
 		for _, one := range w.Defaults {
@@ -213,7 +216,21 @@ func (w *Weapon) MarshalJSON() ([]byte, error) {
 	} else {
 		data.Calc.Range = w.ResolvedRange()
 	}
-	return json.Marshal(&data)
+	return marshalWithUnknownFields(&data, w.Unknown)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (w *Weapon) UnmarshalJSON(data []byte) error {
+	w.WeaponData = WeaponData{}
+	if err := json.Unmarshal(data, &w.WeaponData); err != nil {
+		return err
+	}
+	unknown, err := extractUnknownFields(data, &w.WeaponData, calcKey)
+	if err != nil {
+		return err
+	}
+	w.Unknown = unknown
+	return nil
 }
 
 // UUID returns the UUID of this data. For weapons, this is only valid while the data is in memory.
@@ -238,6 +255,13 @@ func (w *Weapon) String() string {
 	return w.Owner.Description()
 }
 
+func (w *Weapon) rollDescription() string {
+	if w.Usage == "" {
+		return w.String()
+	}
+	return w.String() + " (" + w.Usage + ")"
+}
+
 // Notes returns the notes for this weapon.
 func (w *Weapon) Notes() string {
 	var buffer strings.Builder
@@ -662,13 +686,20 @@ func (w *Weapon) CellData(column int, data *CellData) {
 	case WeaponUsageColumn:
 		data.Primary = w.Usage
 	case WeaponSLColumn:
-		data.Primary = w.SkillLevel(&buffer).String()
+		level := w.SkillLevel(&buffer)
+		data.Primary = level.String()
+		if pc := w.PC(); pc != nil && level > 0 {
+			data.Roll = rolls.NewSuccessRequest(pc.RollerName(), w.rollDescription(), fxp.As[int](level.Trunc()))
+		}
 	case WeaponParryColumn:
 		data.Primary = w.ResolvedParry(&buffer)
 	case WeaponBlockColumn:
 		data.Primary = w.ResolvedBlock(&buffer)
 	case WeaponDamageColumn:
 		data.Primary = w.Damage.ResolvedDamage(&buffer)
+		if pc := w.PC(); pc != nil {
+			data.Roll = rolls.NewDamageRequest(pc.RollerName(), w.rollDescription(), data.Primary)
+		}
 	case WeaponReachColumn:
 		data.Primary = w.Reach
 	case WeaponSTColumn:
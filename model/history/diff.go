@@ -0,0 +1,159 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package history
+
+import (
+	"strings"
+
+	"github.com/richardwilkes/gcs/model/jio"
+)
+
+// Possible DiffKind values.
+const (
+	Unchanged DiffKind = iota
+	Removed
+	Added
+)
+
+// maxEditDistance limits the effort spent looking for a minimal diff. Inputs that differ by more than this many lines
+// are reported as a wholesale replacement of the differing region.
+const maxEditDistance = 2000
+
+// DiffKind identifies the kind of a DiffLine.
+type DiffKind uint8
+
+// DiffLine holds a single line of a diff.
+type DiffLine struct {
+	Kind DiffKind
+	Text string
+}
+
+// DiffJSON returns a line-oriented diff between two JSON documents. Both documents are first converted to a canonical
+// form, so that differences in formatting and key order are not reported.
+func DiffJSON(before, after []byte) ([]DiffLine, error) {
+	var err error
+	if before, err = jio.CanonicalJSON(before); err != nil {
+		return nil, err
+	}
+	if after, err = jio.CanonicalJSON(after); err != nil {
+		return nil, err
+	}
+	return Diff(splitLines(string(before)), splitLines(string(after))), nil
+}
+
+// Diff returns a minimal line-oriented diff that transforms a into b.
+func Diff(a, b []string) []DiffLine {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	result := make([]DiffLine, 0, len(a)+len(b)-prefix-suffix)
+	for _, line := range a[:prefix] {
+		result = append(result, DiffLine{Kind: Unchanged, Text: line})
+	}
+	result = append(result, myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		result = append(result, DiffLine{Kind: Unchanged, Text: line})
+	}
+	return result
+}
+
+// myers implements the O(ND) difference algorithm described by Eugene W. Myers.
+func myers(a, b []string) []DiffLine {
+	n := len(a)
+	m := len(b)
+	limit := n + m
+	if limit > maxEditDistance {
+		limit = maxEditDistance
+	}
+	offset := n + m + 1
+	v := make([]int, 2*offset+1)
+	// trace[d] holds the furthest reaching x values for diagonals -d-1 through d+1, as they were at the start of round d
+	trace := make([][]int, 0, 16)
+	for d := 0; d <= limit; d++ {
+		trace = append(trace, append([]int(nil), v[offset-d-1:offset+d+2]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrack(a, b, trace)
+			}
+		}
+	}
+	result := make([]DiffLine, 0, n+m)
+	for _, line := range a {
+		result = append(result, DiffLine{Kind: Removed, Text: line})
+	}
+	for _, line := range b {
+		result = append(result, DiffLine{Kind: Added, Text: line})
+	}
+	return result
+}
+
+func backtrack(a, b []string, trace [][]int) []DiffLine {
+	x := len(a)
+	y := len(b)
+	reversed := make([]DiffLine, 0, x+y)
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		at := func(k int) int { return v[k+d+1] }
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			reversed = append(reversed, DiffLine{Kind: Unchanged, Text: a[x-1]})
+			x--
+			y--
+		}
+		if d > 0 {
+			if x == prevX {
+				reversed = append(reversed, DiffLine{Kind: Added, Text: b[y-1]})
+				y--
+			} else {
+				reversed = append(reversed, DiffLine{Kind: Removed, Text: a[x-1]})
+				x--
+			}
+		}
+	}
+	for i, j := 0, len(reversed)-1; i < j; i, j = i+1, j-1 {
+		reversed[i], reversed[j] = reversed[j], reversed[i]
+	}
+	return reversed
+}
+
+func splitLines(s string) []string {
+	s = strings.TrimSuffix(s, "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
@@ -0,0 +1,95 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package history_test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/richardwilkes/gcs/model/history"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore(t *testing.T) {
+	dir := t.TempDir()
+	store := history.NewStore(filepath.Join(dir, "history"))
+	file := filepath.Join(dir, "Bob.gcs")
+	revs, err := store.Revisions(file)
+	require.NoError(t, err)
+	assert.Empty(t, revs)
+
+	require.NoError(t, store.Record(file, []byte(`{"v":1}`), 3))
+	require.NoError(t, store.Record(file, []byte(`{"v":2}`), 3))
+	require.NoError(t, store.Record(file, []byte(`{"v":1}`), 3))
+	revs, err = store.Revisions(file)
+	require.NoError(t, err)
+	require.Len(t, revs, 2, "identical content should only be stored once")
+	assert.Equal(t, history.KeyFor([]byte(`{"v":1}`)), revs[0].Key)
+	assert.Equal(t, history.KeyFor([]byte(`{"v":2}`)), revs[1].Key)
+
+	require.NoError(t, store.Record(file, []byte(`{"v":3}`), 3))
+	require.NoError(t, store.Record(file, []byte(`{"v":4}`), 3))
+	revs, err = store.Revisions(file)
+	require.NoError(t, err)
+	require.Len(t, revs, 3)
+	for i, expected := range []string{`{"v":4}`, `{"v":3}`, `{"v":1}`} {
+		var data []byte
+		data, err = store.Data(file, revs[i])
+		require.NoError(t, err)
+		assert.Equal(t, expected, string(data))
+	}
+
+	other, err := store.Revisions(filepath.Join(dir, "Other.gcs"))
+	require.NoError(t, err)
+	assert.Empty(t, other)
+
+	moved := filepath.Join(dir, "sub", "Robert.gcs")
+	require.NoError(t, store.Move(file, moved))
+	revs, err = store.Revisions(file)
+	require.NoError(t, err)
+	assert.Empty(t, revs)
+	revs, err = store.Revisions(moved)
+	require.NoError(t, err)
+	require.Len(t, revs, 3)
+	data, err := store.Data(moved, revs[0])
+	require.NoError(t, err)
+	assert.Equal(t, `{"v":4}`, string(data))
+}
+
+func TestDiff(t *testing.T) {
+	a := strings.Split("a b c e f g", " ")
+	b := strings.Split("a c d e g h", " ")
+	var buffer strings.Builder
+	for _, line := range history.Diff(a, b) {
+		switch line.Kind {
+		case history.Removed:
+			buffer.WriteByte('-')
+		case history.Added:
+			buffer.WriteByte('+')
+		default:
+			buffer.WriteByte(' ')
+		}
+		buffer.WriteString(line.Text)
+	}
+	assert.Equal(t, " a-b c+d e-f g+h", buffer.String())
+
+	lines, err := history.DiffJSON([]byte(`{"b":2,"a":1}`), []byte(`{"a":1,"b":3}`))
+	require.NoError(t, err)
+	require.Len(t, lines, 5)
+	assert.Equal(t, history.Unchanged, lines[1].Kind)
+	assert.Equal(t, history.Removed, lines[2].Kind)
+	assert.Equal(t, "\t\"b\": 2", lines[2].Text)
+	assert.Equal(t, history.Added, lines[3].Kind)
+	assert.Equal(t, "\t\"b\": 3", lines[3].Text)
+}
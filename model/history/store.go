@@ -0,0 +1,235 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package history
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/richardwilkes/gcs/model/crc"
+	"github.com/richardwilkes/gcs/model/jio"
+	"github.com/richardwilkes/toolbox/cmdline"
+	"github.com/richardwilkes/toolbox/errs"
+	"github.com/richardwilkes/toolbox/xio/fs/paths"
+	"github.com/richardwilkes/toolbox/xio/fs/safe"
+)
+
+const (
+	storeVersion  = 1
+	indexFileName = "index.json"
+	revisionExt   = ".gz"
+)
+
+var (
+	defaultStoreOnce sync.Once
+	defaultStore     *Store
+)
+
+// Revision holds information about a single revision of a file.
+type Revision struct {
+	// Key identifies the content of the revision. It is the CRC-64 of the content, so identical content always has the
+	// same key.
+	Key       string   `json:"key"`
+	Timestamp jio.Time `json:"timestamp"`
+	Size      int      `json:"size"`
+}
+
+type fileHistory struct {
+	Version   int         `json:"version"`
+	Path      string      `json:"path"`
+	Revisions []*Revision `json:"revisions,omitempty"`
+}
+
+// Store holds the revision history of files. The revisions of each file are kept in their own directory and are
+// addressed by their content, so saving the same content more than once does not consume additional space.
+type Store struct {
+	root string
+	lock sync.Mutex
+}
+
+// DefaultStore returns the store used by the application.
+func DefaultStore() *Store {
+	defaultStoreOnce.Do(func() {
+		defaultStore = NewStore(filepath.Join(paths.AppDataDir(), cmdline.AppCmdName+"_history"))
+	})
+	return defaultStore
+}
+
+// NewStore creates a new store that keeps its data in the root directory.
+func NewStore(root string) *Store {
+	return &Store{root: root}
+}
+
+// KeyFor returns the key that would be used for a revision with the given content.
+func KeyFor(data []byte) string {
+	return fmt.Sprintf("%016x", crc.Bytes(0, data))
+}
+
+// Record adds a revision with the content to the history of the file, making it the most recent one. If a revision with
+// identical content already exists, it is moved to the front rather than being stored again. Only the most recent 'keep'
+// revisions are retained.
+func (s *Store) Record(filePath string, data []byte, keep int) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	dir, h, err := s.load(filePath)
+	if err != nil {
+		return err
+	}
+	key := KeyFor(data)
+	revisions := make([]*Revision, 0, len(h.Revisions)+1)
+	revisions = append(revisions, &Revision{
+		Key:       key,
+		Timestamp: jio.Now(),
+		Size:      len(data),
+	})
+	found := false
+	for _, rev := range h.Revisions {
+		if rev.Key == key {
+			found = true
+		} else {
+			revisions = append(revisions, rev)
+		}
+	}
+	if !found {
+		if err = os.MkdirAll(dir, 0o750); err != nil {
+			return errs.NewWithCause(dir, err)
+		}
+		if err = writeRevision(filepath.Join(dir, key+revisionExt), data); err != nil {
+			return err
+		}
+	}
+	if keep < 1 {
+		keep = 1
+	}
+	for len(revisions) > keep {
+		last := revisions[len(revisions)-1]
+		revisions = revisions[:len(revisions)-1]
+		p := filepath.Join(dir, last.Key+revisionExt)
+		if err = os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return errs.NewWithCause(p, err)
+		}
+	}
+	h.Revisions = revisions
+	return jio.SaveToFile(context.Background(), filepath.Join(dir, indexFileName), h)
+}
+
+// Revisions returns the revisions of the file, most recent first.
+func (s *Store) Revisions(filePath string) ([]*Revision, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	_, h, err := s.load(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return h.Revisions, nil
+}
+
+// Data returns the content of the revision of the file.
+func (s *Store) Data(filePath string, rev *Revision) ([]byte, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	p := filepath.Join(s.dirFor(filePath), rev.Key+revisionExt)
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, errs.NewWithCause(p, err)
+	}
+	defer func() { _ = f.Close() }() //nolint:errcheck // Read-only
+	var gz *gzip.Reader
+	if gz, err = gzip.NewReader(f); err != nil {
+		return nil, errs.NewWithCause(p, err)
+	}
+	var data []byte
+	if data, err = io.ReadAll(gz); err != nil {
+		return nil, errs.NewWithCause(p, err)
+	}
+	if KeyFor(data) != rev.Key {
+		return nil, errs.New("revision is corrupt: " + p)
+	}
+	return data, nil
+}
+
+// Move the history of the file at 'from' so that it becomes the history of the file at 'to', replacing any history the
+// latter had. Nothing happens if the file at 'from' has no history.
+func (s *Store) Move(from, to string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	fromDir, h, err := s.load(from)
+	if err != nil {
+		return err
+	}
+	if len(h.Revisions) == 0 {
+		return nil
+	}
+	toDir := s.dirFor(to)
+	if fromDir == toDir {
+		return nil
+	}
+	if err = os.RemoveAll(toDir); err != nil {
+		return errs.NewWithCause(toDir, err)
+	}
+	if err = os.Rename(fromDir, toDir); err != nil {
+		return errs.NewWithCause(toDir, err)
+	}
+	h.Path = to
+	return jio.SaveToFile(context.Background(), filepath.Join(toDir, indexFileName), h)
+}
+
+func (s *Store) dirFor(filePath string) string {
+	if abs, err := filepath.Abs(filePath); err == nil {
+		filePath = abs
+	}
+	return filepath.Join(s.root, fmt.Sprintf("%016x", crc.String(0, filepath.Clean(filePath))))
+}
+
+func (s *Store) load(filePath string) (dir string, h *fileHistory, err error) {
+	dir = s.dirFor(filePath)
+	h = &fileHistory{}
+	p := filepath.Join(dir, indexFileName)
+	if err = jio.LoadFromFile(context.Background(), p, h); err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", nil, err
+		}
+		h = &fileHistory{}
+	}
+	if h.Version > storeVersion {
+		return "", nil, errs.Newf("%s: history version %d is newer than this version supports", p, h.Version)
+	}
+	h.Version = storeVersion
+	h.Path = filePath
+	return dir, h, nil
+}
+
+func writeRevision(p string, data []byte) error {
+	var buffer bytes.Buffer
+	gz := gzip.NewWriter(&buffer)
+	if _, err := gz.Write(data); err != nil {
+		return errs.Wrap(err)
+	}
+	if err := gz.Close(); err != nil {
+		return errs.Wrap(err)
+	}
+	if err := safe.WriteFileWithMode(p, func(w io.Writer) error {
+		_, err := w.Write(buffer.Bytes())
+		return err
+	}, 0o640); err != nil {
+		return errs.NewWithCause(p, err)
+	}
+	return nil
+}
@@ -19,30 +19,78 @@ import (
 	"io"
 	"io/fs"
 	"os"
+	"path/filepath"
 
+	"github.com/richardwilkes/gcs/model/schema"
 	"github.com/richardwilkes/json"
 	"github.com/richardwilkes/toolbox/errs"
-	"github.com/richardwilkes/toolbox/xio"
+	"github.com/richardwilkes/toolbox/log/jot"
 )
 
-// LoadFromFile loads JSON data from the specified path.
+// LoadFromFile loads JSON data from the specified path. If the path refers to a directory holding data in the split
+// format, it is treated as a single file. If a schema exists for the file's extension, the data is also validated
+// against it. Problems found by the schema are logged as warnings when the data can still be decoded and are returned
+// along with the decoding error when it cannot.
 func LoadFromFile(ctx context.Context, path string, data any) error {
-	f, err := os.Open(path)
+	split := IsSplitPath(path)
+	var buffer []byte
+	var err error
+	if split {
+		buffer, err = readSplit(os.DirFS(path), ".")
+	} else {
+		buffer, err = os.ReadFile(path)
+	}
 	if err != nil {
 		return errs.NewWithCause(path, err)
 	}
-	defer xio.CloseIgnoringErrors(f)
-	return Load(ctx, bufio.NewReader(f), data)
+	return loadBuffer(ctx, path, buffer, split, data)
 }
 
-// LoadFromFS loads JSON data from the specified filesystem path.
+// LoadFromFS loads JSON data from the specified filesystem path. If the path refers to a directory holding data in the
+// split format, it is treated as a single file. If a schema exists for the file's extension, the data is also validated
+// against it, just as with LoadFromFile.
 func LoadFromFS(ctx context.Context, fileSystem fs.FS, path string, data any) error {
-	f, err := fileSystem.Open(path)
+	split := IsSplitFS(fileSystem, path)
+	var buffer []byte
+	var err error
+	if split {
+		buffer, err = readSplit(fileSystem, path)
+	} else {
+		buffer, err = fs.ReadFile(fileSystem, path)
+	}
 	if err != nil {
 		return errs.NewWithCause(path, err)
 	}
-	defer xio.CloseIgnoringErrors(f)
-	return Load(ctx, bufio.NewReader(f), data)
+	return loadBuffer(ctx, path, buffer, split, data)
+}
+
+func loadBuffer(ctx context.Context, path string, buffer []byte, split bool, data any) error {
+	var problems *schema.Error
+	if s := schema.ForExtension(filepath.Ext(path)); s != nil {
+		if problems = s.Validate(buffer); problems != nil && split {
+			problems.ClearPositions()
+		}
+	}
+	if err := Load(ctx, bytes.NewReader(buffer), data); err != nil {
+		if located := schema.Locate(buffer, err); located != nil {
+			if split {
+				located.ClearPositions()
+			}
+			err = errs.Wrap(located)
+		}
+		if problems != nil {
+			// The schema problems may be unrelated to what the decoder couldn't handle, so report both, with the
+			// decoder's complaint first.
+			return errs.Append(err, problems)
+		}
+		return err
+	}
+	if problems != nil {
+		// The decoders accept some older and looser forms of data that the schema does not describe, so the problems
+		// are only worth noting.
+		jot.Warn(errs.NewWithCause(path, problems))
+	}
+	return nil
 }
 
 // Load JSON data.
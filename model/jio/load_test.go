@@ -0,0 +1,53 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package jio_test
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/richardwilkes/gcs/model/jio"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadReportsDecodeFailureWithSchemaProblems(t *testing.T) {
+	// The first row has a problem the schema complains about but the decoder doesn't care about, while the second holds a
+	// value the decoder cannot handle. Both must be reported.
+	fileSystem := fstest.MapFS{
+		"mixed.skl": &fstest.MapFile{Data: []byte(`{
+	"type": "skill_list",
+	"version": 4,
+	"rows": [
+		{
+			"type": "skill",
+			"name": 5
+		},
+		{
+			"type": "skill",
+			"name": "Jumping",
+			"points": 2
+		}
+	]
+}`)},
+	}
+	var data struct {
+		Rows []struct {
+			Points bool `json:"points"`
+		} `json:"rows"`
+	}
+	err := jio.LoadFromFS(context.Background(), fileSystem, "mixed.skl", &data)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `$.rows[1].points (line 12, column 14) in "Jumping"`)
+	assert.Contains(t, err.Error(), "$.rows[0].name (line 7, column 12)")
+}
@@ -24,8 +24,19 @@ import (
 	"github.com/richardwilkes/toolbox/xio/fs/safe"
 )
 
-// SaveToFile writes the data as JSON to the given path. Parent directories will be created automatically, if needed.
+// SaveToFile writes the data as JSON to the given path. Parent directories will be created automatically, if needed. If
+// the path refers to a directory holding data in the split format, the data is written using that format.
 func SaveToFile(ctx context.Context, path string, data any) error {
+	if IsSplitPath(path) {
+		var buffer bytes.Buffer
+		if err := Save(ctx, &buffer, data); err != nil {
+			return errs.NewWithCause(path, err)
+		}
+		if err := writeSplit(path, buffer.Bytes()); err != nil {
+			return errs.NewWithCause(path, err)
+		}
+		return nil
+	}
 	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
 		return errs.Wrap(err)
 	}
@@ -0,0 +1,356 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package jio
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/richardwilkes/json"
+	"github.com/richardwilkes/toolbox/errs"
+	"github.com/richardwilkes/toolbox/xio/fs/safe"
+)
+
+// SplitManifestName is the name of the manifest file within a directory that holds data in the split format.
+const SplitManifestName = "manifest.json"
+
+const (
+	splitFormatVersion = 1
+	splitItemExt       = ".json"
+)
+
+// SplitKeys holds the top-level keys whose values, when they are arrays of objects, have each of their elements stored
+// in a separate file when using the split format.
+var SplitKeys = []string{"rows", "traits", "skills", "spells", "equipment", "other_equipment", "notes"}
+
+// The split format stores a JSON document as a directory containing a manifest plus one file per element of each of the
+// document's top-level arrays named by SplitKeys. Each element file is named by the element's ID and contains canonical
+// JSON with its keys sorted, so that the same data always produces the same bytes and changes to one element never
+// touch the files of another. The manifest holds the remainder of the document along with the order of the elements.
+type splitManifest struct {
+	SplitVersion int                 `json:"split_version"`
+	Data         json.RawMessage     `json:"data"`
+	Lists        map[string][]string `json:"lists,omitempty"`
+}
+
+// IsSplitPath returns true if the path refers to a directory that holds data in the split format.
+func IsSplitPath(p string) bool {
+	fi, err := os.Stat(filepath.Join(p, SplitManifestName))
+	return err == nil && fi.Mode().IsRegular()
+}
+
+// IsSplitFS returns true if the path within the filesystem refers to a directory that holds data in the split format.
+func IsSplitFS(fileSystem fs.FS, p string) bool {
+	fi, err := fs.Stat(fileSystem, path.Join(p, SplitManifestName))
+	return err == nil && fi.Mode().IsRegular()
+}
+
+// ReadFile returns the contents of the file at the path. If the path refers to a directory holding data in the split
+// format, its contents are assembled into a single JSON document.
+func ReadFile(p string) ([]byte, error) {
+	if IsSplitPath(p) {
+		return readSplit(os.DirFS(p), ".")
+	}
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return nil, errs.NewWithCause(p, err)
+	}
+	return data, nil
+}
+
+// WriteFile writes the JSON data to the file at the path. If the path refers to a directory holding data in the split
+// format, the data is written using that format and perm is ignored.
+func WriteFile(p string, data []byte, perm fs.FileMode) error {
+	if IsSplitPath(p) {
+		if err := writeSplit(p, data); err != nil {
+			return errs.NewWithCause(p, err)
+		}
+		return nil
+	}
+	if err := safe.WriteFileWithMode(p, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	}, perm); err != nil {
+		return errs.NewWithCause(p, err)
+	}
+	return nil
+}
+
+// SplitFile converts the JSON file at the path into the split format. The directory that replaces it will have the same
+// name as the original file.
+func SplitFile(p string) error {
+	if IsSplitPath(p) {
+		return nil
+	}
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return errs.NewWithCause(p, err)
+	}
+	tmpDir := p + ".split.tmp"
+	if err = os.RemoveAll(tmpDir); err != nil {
+		return errs.NewWithCause(tmpDir, err)
+	}
+	if err = writeSplit(tmpDir, data); err != nil {
+		_ = os.RemoveAll(tmpDir) //nolint:errcheck // Already returning an error
+		return err
+	}
+	backup := p + ".bak.tmp"
+	if err = os.Rename(p, backup); err != nil {
+		_ = os.RemoveAll(tmpDir) //nolint:errcheck // Already returning an error
+		return errs.NewWithCause(p, err)
+	}
+	if err = os.Rename(tmpDir, p); err != nil {
+		_ = os.Rename(backup, p) //nolint:errcheck // Already returning an error
+		_ = os.RemoveAll(tmpDir) //nolint:errcheck // Already returning an error
+		return errs.NewWithCause(p, err)
+	}
+	if err = os.Remove(backup); err != nil {
+		return errs.NewWithCause(backup, err)
+	}
+	return nil
+}
+
+// JoinFile converts the directory at the path, which must hold data in the split format, back into a single JSON file
+// with the same name.
+func JoinFile(p string) error {
+	if !IsSplitPath(p) {
+		return errs.New("not in the split format: " + p)
+	}
+	data, err := readSplit(os.DirFS(p), ".")
+	if err != nil {
+		return err
+	}
+	var buffer bytes.Buffer
+	if err = json.Indent(&buffer, data, "", "\t"); err != nil {
+		return errs.NewWithCause(p, err)
+	}
+	buffer.WriteByte('\n')
+	tmpFile := p + ".join.tmp"
+	if err = safe.WriteFileWithMode(tmpFile, func(w io.Writer) error {
+		_, writeErr := w.Write(buffer.Bytes())
+		return writeErr
+	}, 0o640); err != nil {
+		return errs.NewWithCause(tmpFile, err)
+	}
+	backup := p + ".bak.tmp"
+	if err = os.Rename(p, backup); err != nil {
+		_ = os.Remove(tmpFile) //nolint:errcheck // Already returning an error
+		return errs.NewWithCause(p, err)
+	}
+	if err = os.Rename(tmpFile, p); err != nil {
+		_ = os.Rename(backup, p) //nolint:errcheck // Already returning an error
+		_ = os.Remove(tmpFile)   //nolint:errcheck // Already returning an error
+		return errs.NewWithCause(p, err)
+	}
+	if err = os.RemoveAll(backup); err != nil {
+		return errs.NewWithCause(backup, err)
+	}
+	return nil
+}
+
+// readSplit assembles the directory at the path within the filesystem, which must hold data in the split format, into
+// a single JSON document.
+func readSplit(fileSystem fs.FS, dirPath string) ([]byte, error) {
+	manifestPath := path.Join(dirPath, SplitManifestName)
+	data, err := fs.ReadFile(fileSystem, manifestPath)
+	if err != nil {
+		return nil, errs.NewWithCause(manifestPath, err)
+	}
+	var manifest splitManifest
+	if err = json.Unmarshal(data, &manifest); err != nil {
+		return nil, errs.NewWithCause(manifestPath, err)
+	}
+	if manifest.SplitVersion > splitFormatVersion {
+		return nil, errs.Newf("%s: split format version %d is newer than this version supports", manifestPath,
+			manifest.SplitVersion)
+	}
+	doc := make(map[string]json.RawMessage)
+	if len(manifest.Data) != 0 {
+		if err = json.Unmarshal(manifest.Data, &doc); err != nil {
+			return nil, errs.NewWithCause(manifestPath, err)
+		}
+	}
+	for key, names := range manifest.Lists {
+		items := make([]json.RawMessage, 0, len(names))
+		for _, name := range names {
+			itemPath := path.Join(dirPath, name+splitItemExt)
+			var item []byte
+			if item, err = fs.ReadFile(fileSystem, itemPath); err != nil {
+				return nil, errs.NewWithCause(itemPath, err)
+			}
+			items = append(items, bytes.TrimSpace(item))
+		}
+		if doc[key], err = json.Marshal(items); err != nil {
+			return nil, errs.Wrap(err)
+		}
+	}
+	if data, err = json.Marshal(doc); err != nil {
+		return nil, errs.Wrap(err)
+	}
+	return data, nil
+}
+
+// writeSplit writes the JSON document into the directory at the path using the split format. Element files whose
+// content has not changed are left untouched and element files that are no longer referenced are removed.
+func writeSplit(dirPath string, data []byte) error {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return errs.NewWithCause("split format requires a JSON object", err)
+	}
+	manifest := splitManifest{
+		SplitVersion: splitFormatVersion,
+		Lists:        make(map[string][]string),
+	}
+	files := make(map[string][]byte)
+	for _, key := range SplitKeys {
+		raw, exists := doc[key]
+		if !exists {
+			continue
+		}
+		var items []json.RawMessage
+		if err := json.Unmarshal(raw, &items); err != nil {
+			continue // Not an array, so leave it in the manifest
+		}
+		names := make([]string, 0, len(items))
+		allObjects := true
+		for i, item := range items {
+			canonical, err := CanonicalJSON(item)
+			if err != nil || !bytes.HasPrefix(canonical, []byte("{")) {
+				allObjects = false
+				break
+			}
+			name := splitItemName(item, key, i, files)
+			files[name] = canonical
+			names = append(names, name)
+		}
+		if !allObjects {
+			// Not an array of objects, so leave it in the manifest
+			for _, name := range names {
+				delete(files, name)
+			}
+			continue
+		}
+		manifest.Lists[key] = names
+		delete(doc, key)
+	}
+	var err error
+	if manifest.Data, err = json.Marshal(doc); err != nil {
+		return errs.Wrap(err)
+	}
+	if err = os.MkdirAll(dirPath, 0o750); err != nil {
+		return errs.Wrap(err)
+	}
+	for name, content := range files {
+		if err = writeIfChanged(filepath.Join(dirPath, name+splitItemExt), content); err != nil {
+			return err
+		}
+	}
+	var manifestData []byte
+	if manifestData, err = json.Marshal(&manifest); err != nil {
+		return errs.Wrap(err)
+	}
+	if manifestData, err = CanonicalJSON(manifestData); err != nil {
+		return err
+	}
+	if err = writeIfChanged(filepath.Join(dirPath, SplitManifestName), manifestData); err != nil {
+		return err
+	}
+	var entries []os.DirEntry
+	if entries, err = os.ReadDir(dirPath); err != nil {
+		return errs.Wrap(err)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.Type().IsRegular() && name != SplitManifestName && strings.HasSuffix(name, splitItemExt) {
+			if _, exists := files[strings.TrimSuffix(name, splitItemExt)]; !exists {
+				if err = os.Remove(filepath.Join(dirPath, name)); err != nil {
+					return errs.Wrap(err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// splitItemName returns the name of the file (without its extension) that an element should be stored in. The
+// element's ID is used if it has one, otherwise a name is derived from its key and position.
+func splitItemName(item json.RawMessage, key string, index int, used map[string][]byte) string {
+	var ided struct {
+		ID string `json:"id"`
+	}
+	base := ""
+	if err := json.Unmarshal(item, &ided); err == nil && isSafeSplitName(ided.ID) {
+		base = strings.ToLower(ided.ID)
+	} else {
+		base = key + "-" + strconv.Itoa(index+1)
+	}
+	name := base
+	for i := 2; ; i++ {
+		if _, exists := used[name]; !exists && name != strings.TrimSuffix(SplitManifestName, splitItemExt) {
+			return name
+		}
+		name = base + "-" + strconv.Itoa(i)
+	}
+}
+
+func isSafeSplitName(name string) bool {
+	if name == "" || len(name) > 64 {
+		return false
+	}
+	for _, ch := range name {
+		if !(ch >= '0' && ch <= '9') && !(ch >= 'a' && ch <= 'f') && !(ch >= 'A' && ch <= 'F') && ch != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// CanonicalJSON returns the JSON data indented with tabs and with the keys of every object sorted, so that equivalent
+// data always produces the same bytes.
+func CanonicalJSON(data []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var v any
+	if err := decoder.Decode(&v); err != nil {
+		return nil, errs.Wrap(err)
+	}
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "\t")
+	if err := encoder.Encode(v); err != nil {
+		return nil, errs.Wrap(err)
+	}
+	return buffer.Bytes(), nil
+}
+
+func writeIfChanged(p string, data []byte) error {
+	if existing, err := os.ReadFile(p); err == nil && bytes.Equal(existing, data) {
+		return nil
+	} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return errs.NewWithCause(p, err)
+	}
+	if err := safe.WriteFileWithMode(p, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	}, 0o640); err != nil {
+		return errs.NewWithCause(p, err)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package jio_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/richardwilkes/gcs/model/jio"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const splitTestJSON = `{
+	"type": "skill_list",
+	"version": 4,
+	"rows": [
+		{
+			"id": "7d1c0a4e-8f1b-4a55-9d2e-000000000002",
+			"type": "skill",
+			"name": "Climbing",
+			"points": 1.5
+		},
+		{
+			"id": "7d1c0a4e-8f1b-4a55-9d2e-000000000001",
+			"type": "skill_container",
+			"name": "Athletics",
+			"children": [
+				{
+					"id": "7d1c0a4e-8f1b-4a55-9d2e-000000000003",
+					"type": "skill",
+					"name": "Acrobatics <Stunts>"
+				}
+			]
+		},
+		{
+			"type": "skill",
+			"name": "No ID"
+		}
+	]
+}
+`
+
+func TestSplitRoundTrip(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "Skills.skl")
+	require.NoError(t, os.WriteFile(p, []byte(splitTestJSON), 0o640))
+	var original map[string]any
+	require.NoError(t, jio.LoadFromFile(context.Background(), p, &original))
+
+	require.NoError(t, jio.SplitFile(p))
+	assert.True(t, jio.IsSplitPath(p))
+	for _, name := range []string{
+		jio.SplitManifestName,
+		"7d1c0a4e-8f1b-4a55-9d2e-000000000001.json",
+		"7d1c0a4e-8f1b-4a55-9d2e-000000000002.json",
+		"rows-3.json",
+	} {
+		assert.FileExists(t, filepath.Join(p, name))
+	}
+	var loaded map[string]any
+	require.NoError(t, jio.LoadFromFile(context.Background(), p, &loaded))
+	assert.Equal(t, original, loaded)
+	loaded = nil
+	require.NoError(t, jio.LoadFromFS(context.Background(), os.DirFS(filepath.Dir(p)), filepath.Base(p), &loaded))
+	assert.Equal(t, original, loaded)
+
+	// Changing, reordering and removing items only touches the files of the items involved
+	unchanged := filepath.Join(p, "7d1c0a4e-8f1b-4a55-9d2e-000000000001.json")
+	before, err := os.ReadFile(unchanged)
+	require.NoError(t, err)
+	past := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+	rows := loaded["rows"].([]any) //nolint:errcheck // Test data
+	rows[0].(map[string]any)["name"] = "Climbing!"
+	loaded["rows"] = []any{rows[1], rows[0]}
+	require.NoError(t, os.Chtimes(unchanged, past, past))
+	require.NoError(t, jio.SaveToFile(context.Background(), p, loaded))
+	assert.True(t, jio.IsSplitPath(p))
+	assert.NoFileExists(t, filepath.Join(p, "rows-3.json"))
+	fi, err := os.Stat(unchanged)
+	require.NoError(t, err)
+	assert.Equal(t, past.Unix(), fi.ModTime().Unix())
+	after, err := os.ReadFile(unchanged)
+	require.NoError(t, err)
+	assert.Equal(t, before, after)
+
+	var resaved map[string]any
+	require.NoError(t, jio.LoadFromFile(context.Background(), p, &resaved))
+	assert.Equal(t, loaded, resaved)
+
+	require.NoError(t, jio.JoinFile(p))
+	assert.False(t, jio.IsSplitPath(p))
+	fi, err = os.Stat(p)
+	require.NoError(t, err)
+	assert.True(t, fi.Mode().IsRegular())
+	var joined map[string]any
+	require.NoError(t, jio.LoadFromFile(context.Background(), p, &joined))
+	assert.Equal(t, loaded, joined)
+}
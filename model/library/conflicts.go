@@ -0,0 +1,412 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package library
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/richardwilkes/gcs/model/id"
+	"github.com/richardwilkes/gcs/model/jio"
+	"github.com/richardwilkes/json"
+	"github.com/richardwilkes/toolbox/errs"
+	"github.com/richardwilkes/toolbox/i18n"
+	"github.com/richardwilkes/toolbox/log/jot"
+	"github.com/richardwilkes/toolbox/txt"
+)
+
+// Possible ConflictKind values.
+const (
+	// DuplicateConflict is used for items with the same name whose definitions are identical.
+	DuplicateConflict ConflictKind = iota
+	// VariantConflict is used for items with the same name whose definitions differ.
+	VariantConflict
+	// IDCollisionConflict is used for items that share the same ID.
+	IDCollisionConflict
+)
+
+// Row-level keys that are ignored at any depth when comparing definitions.
+var nonDefinitionKeys = map[string]bool{
+	"id":   true,
+	"calc": true,
+	"open": true,
+}
+
+// ConflictKind identifies the kind of a Conflict.
+type ConflictKind uint8
+
+// ConflictItem holds a single item involved in a Conflict.
+type ConflictItem struct {
+	LibraryKey string
+	Path       string
+	ID         uuid.UUID
+	Type       string
+	Name       string
+	definition string
+}
+
+// Conflict holds a set of library items that duplicate or collide with one another.
+type Conflict struct {
+	Kind  ConflictKind
+	Type  string
+	Name  string
+	Items []*ConflictItem
+}
+
+// ConflictReport holds the results of an analysis of the libraries.
+type ConflictReport struct {
+	FilesScanned int
+	ItemsScanned int
+	Conflicts    []*Conflict
+}
+
+// String implements fmt.Stringer.
+func (k ConflictKind) String() string {
+	switch k {
+	case DuplicateConflict:
+		return i18n.Text("Duplicate")
+	case VariantConflict:
+		return i18n.Text("Variant")
+	case IDCollisionConflict:
+		return i18n.Text("ID Collision")
+	default:
+		return i18n.Text("Unknown")
+	}
+}
+
+// Description returns a longer description of the kind of conflict.
+func (k ConflictKind) Description() string {
+	switch k {
+	case DuplicateConflict:
+		return i18n.Text("Items with the same name and identical definitions")
+	case VariantConflict:
+		return i18n.Text("Items with the same name but different definitions")
+	case IDCollisionConflict:
+		return i18n.Text("Items that share the same ID")
+	default:
+		return ""
+	}
+}
+
+// RelativePath returns the path of the file the item came from, relative to its library.
+func (item *ConflictItem) RelativePath(libs Libraries) string {
+	if lib, ok := libs[item.LibraryKey]; ok {
+		if rel, err := filepath.Rel(lib.PathOnDisk, item.Path); err == nil {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return item.Path
+}
+
+// FindConflicts scans all of the list files within the libraries, looking for duplicate items, items with the same name
+// but different definitions, and items whose IDs collide.
+func FindConflicts(libs Libraries) *ConflictReport {
+	report := &ConflictReport{}
+	seen := make(map[string]bool)
+	var items []*ConflictItem
+	forEachIndexedFile(libs, func(lib *Library, p string) {
+		if seen[p] {
+			return
+		}
+		seen[p] = true
+		data, err := jio.ReadFile(p)
+		if err != nil {
+			jot.Warn(errs.NewWithCause("unable to read "+p+" for conflict analysis", err))
+			return
+		}
+		report.FilesScanned++
+		items = append(items, conflictItemsFromFileData(lib.Key(), p, data)...)
+	})
+	report.ItemsScanned = len(items)
+	byName := make(map[string][]*ConflictItem)
+	byID := make(map[uuid.UUID][]*ConflictItem)
+	for _, item := range items {
+		if item.Name != "" {
+			key := item.Type + "\n" + strings.ToLower(item.Name)
+			byName[key] = append(byName[key], item)
+		}
+		if item.ID != uuid.Nil {
+			byID[item.ID] = append(byID[item.ID], item)
+		}
+	}
+	for _, group := range byName {
+		if len(group) < 2 {
+			continue
+		}
+		kind := DuplicateConflict
+		for _, item := range group[1:] {
+			if item.definition != group[0].definition {
+				kind = VariantConflict
+				break
+			}
+		}
+		if kind == VariantConflict {
+			// Keep items with identical definitions next to each other
+			sort.SliceStable(group, func(i, j int) bool { return group[i].definition < group[j].definition })
+		}
+		report.Conflicts = append(report.Conflicts, &Conflict{
+			Kind:  kind,
+			Type:  group[0].Type,
+			Name:  group[0].Name,
+			Items: group,
+		})
+	}
+	for _, group := range byID {
+		if len(group) > 1 {
+			report.Conflicts = append(report.Conflicts, &Conflict{
+				Kind:  IDCollisionConflict,
+				Type:  group[0].Type,
+				Name:  group[0].Name,
+				Items: group,
+			})
+		}
+	}
+	sort.Slice(report.Conflicts, func(i, j int) bool {
+		ci := report.Conflicts[i]
+		cj := report.Conflicts[j]
+		if ci.Kind != cj.Kind {
+			return ci.Kind > cj.Kind
+		}
+		if ci.Name != cj.Name {
+			return txt.NaturalLess(ci.Name, cj.Name, true)
+		}
+		if ci.Type != cj.Type {
+			return ci.Type < cj.Type
+		}
+		return ci.Items[0].ID.String() < cj.Items[0].ID.String()
+	})
+	return report
+}
+
+// IDCollisions returns the number of ID collisions in the report.
+func (r *ConflictReport) IDCollisions() int {
+	count := 0
+	for _, c := range r.Conflicts {
+		if c.Kind == IDCollisionConflict {
+			count++
+		}
+	}
+	return count
+}
+
+// Write a textual form of the report.
+func (r *ConflictReport) Write(w io.Writer, libs Libraries) error {
+	if _, err := fmt.Fprintf(w, i18n.Text("Scanned %d items in %d files.\n"), r.ItemsScanned, r.FilesScanned); err != nil {
+		return errs.Wrap(err)
+	}
+	if len(r.Conflicts) == 0 {
+		_, err := fmt.Fprintln(w, i18n.Text("No conflicts found."))
+		return errs.Wrap(err)
+	}
+	for _, c := range r.Conflicts {
+		if _, err := fmt.Fprintf(w, "\n%s: %s [%s]\n", c.Kind, c.Name, c.Type); err != nil {
+			return errs.Wrap(err)
+		}
+		for _, item := range c.Items {
+			libTitle := item.LibraryKey
+			if lib, ok := libs[item.LibraryKey]; ok {
+				libTitle = lib.Title
+			}
+			if _, err := fmt.Fprintf(w, "    %s: %s (%s)\n", libTitle, item.RelativePath(libs), item.ID); err != nil {
+				return errs.Wrap(err)
+			}
+		}
+	}
+	return nil
+}
+
+// RegenerateCollidingIDs assigns new IDs to the items involved in the report's ID collisions, leaving one item in each
+// collision with its original ID. Preference for keeping the original ID is given to items in libraries that receive
+// their content from a release source, since any changes made to those would be lost upon their next update. Only the
+// affected files are modified and only the IDs of their rows are changed, although those files are rewritten in
+// canonical form, which may alter their formatting and key order. Returns the number of IDs that were regenerated.
+func RegenerateCollidingIDs(libs Libraries, report *ConflictReport) (int, error) {
+	// For each file, the IDs that need to be replaced and how many of their occurrences should be left alone
+	plan := make(map[string]map[uuid.UUID]int)
+	for _, c := range report.Conflicts {
+		if c.Kind != IDCollisionConflict {
+			continue
+		}
+		keeper := c.Items[0]
+		for _, item := range c.Items {
+			if lib, ok := libs[item.LibraryKey]; ok && lib.HasReleaseSource() {
+				keeper = item
+				break
+			}
+		}
+		for _, item := range c.Items {
+			ids, ok := plan[item.Path]
+			if !ok {
+				ids = make(map[uuid.UUID]int)
+				plan[item.Path] = ids
+			}
+			if _, exists := ids[item.ID]; !exists {
+				ids[item.ID] = 0
+			}
+			if item == keeper {
+				ids[item.ID]++
+			}
+		}
+	}
+	paths := make([]string, 0, len(plan))
+	for p := range plan {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	total := 0
+	for _, p := range paths {
+		count, err := regenerateIDsInFile(p, plan[p])
+		total += count
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+func regenerateIDsInFile(filePath string, ids map[uuid.UUID]int) (int, error) {
+	fi, err := os.Stat(filePath)
+	if err != nil {
+		return 0, errs.NewWithCause(filePath, err)
+	}
+	var data []byte
+	if data, err = jio.ReadFile(filePath); err != nil {
+		return 0, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var file map[string]any
+	if err = decoder.Decode(&file); err != nil {
+		return 0, errs.NewWithCause(filePath, err)
+	}
+	rows, _ := file["rows"].([]any)
+	seen := make(map[uuid.UUID]int)
+	count := regenerateRowIDs(rows, ids, seen)
+	if count == 0 {
+		return 0, nil
+	}
+	var buffer bytes.Buffer
+	if err = jio.Save(context.Background(), &buffer, file); err != nil {
+		return 0, errs.NewWithCause(filePath, err)
+	}
+	if err = jio.WriteFile(filePath, buffer.Bytes(), fi.Mode().Perm()); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// regenerateRowIDs assigns new IDs to the rows, and their children, whose IDs are in the ids map, leaving the number of
+// occurrences specified by the map untouched. Only the rows themselves are considered, so the IDs of nested data, such
+// as modifiers and weapons, are left alone, just as they are ignored when building a ConflictReport.
+func regenerateRowIDs(rows []any, ids map[uuid.UUID]int, seen map[uuid.UUID]int) int {
+	count := 0
+	for _, one := range rows {
+		row, ok := one.(map[string]any)
+		if !ok {
+			continue
+		}
+		if s, isStr := row["id"].(string); isStr {
+			if parsed, err := uuid.Parse(s); err == nil {
+				if keep, exists := ids[parsed]; exists {
+					seen[parsed]++
+					if seen[parsed] > keep {
+						row["id"] = id.NewUUID().String()
+						count++
+					}
+				}
+			}
+		}
+		if children, isList := row["children"].([]any); isList {
+			count += regenerateRowIDs(children, ids, seen)
+		}
+	}
+	return count
+}
+
+func conflictItemsFromFileData(libKey, filePath string, data []byte) []*ConflictItem {
+	var file struct {
+		Rows []map[string]any `json:"rows"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		jot.Warn(errs.NewWithCause("unable to parse "+filePath+" for conflict analysis", err))
+		return nil
+	}
+	var items []*ConflictItem
+	var collect func(rows []any)
+	collect = func(rows []any) {
+		for _, one := range rows {
+			row, ok := one.(map[string]any)
+			if !ok {
+				continue
+			}
+			item := &ConflictItem{
+				LibraryKey: libKey,
+				Path:       filePath,
+			}
+			item.Type, _ = row["type"].(string)
+			if s, isStr := row["id"].(string); isStr {
+				if parsed, err := uuid.Parse(s); err == nil {
+					item.ID = parsed
+				}
+			}
+			item.Name = rowName(row)
+			if item.Name != "" {
+				if specialization, isStr := row["specialization"].(string); isStr && specialization != "" {
+					item.Name += " (" + specialization + ")"
+				}
+			}
+			if def, err := json.Marshal(stripNonDefinitionKeys(row)); err == nil {
+				item.definition = string(def)
+			}
+			items = append(items, item)
+			if children, isList := row["children"].([]any); isList {
+				collect(children)
+			}
+		}
+	}
+	rows := make([]any, len(file.Rows))
+	for i, row := range file.Rows {
+		rows[i] = row
+	}
+	collect(rows)
+	return items
+}
+
+// stripNonDefinitionKeys returns a copy of the value with the keys that don't contribute to an item's definition
+// removed.
+func stripNonDefinitionKeys(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for k, one := range v {
+			if !nonDefinitionKeys[k] {
+				m[k] = stripNonDefinitionKeys(one)
+			}
+		}
+		return m
+	case []any:
+		list := make([]any, len(v))
+		for i, one := range v {
+			list[i] = stripNonDefinitionKeys(one)
+		}
+		return list
+	default:
+		return v
+	}
+}
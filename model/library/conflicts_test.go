@@ -0,0 +1,197 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package library_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/richardwilkes/gcs/model/library"
+	"github.com/richardwilkes/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	masterConflictSkillsJSON = `{
+	"type": "skill_list",
+	"version": 4,
+	"rows": [
+		{
+			"id": "7d1c0a4e-8f1b-4a55-9d2e-000000000001",
+			"type": "skill",
+			"name": "Acrobatics",
+			"difficulty": "dx/h",
+			"weapons": [
+				{
+					"id": "7d1c0a4e-8f1b-4a55-9d2e-000000000001",
+					"type": "melee_weapon"
+				}
+			],
+			"calc": {"level": 10}
+		},
+		{
+			"id": "7d1c0a4e-8f1b-4a55-9d2e-000000000002",
+			"type": "skill",
+			"name": "Climbing",
+			"difficulty": "dx/a"
+		}
+	]
+}`
+	userConflictSkillsJSON = `{
+	"type": "skill_list",
+	"version": 4,
+	"rows": [
+		{
+			"id": "7d1c0a4e-8f1b-4a55-9d2e-000000000001",
+			"type": "skill",
+			"name": "Acrobatics",
+			"difficulty": "dx/h",
+			"weapons": [
+				{
+					"id": "7d1c0a4e-8f1b-4a55-9d2e-000000000001",
+					"type": "melee_weapon"
+				}
+			],
+			"calc": {"level": 12}
+		},
+		{
+			"id": "7d1c0a4e-8f1b-4a55-9d2e-000000000003",
+			"type": "skill",
+			"name": "climbing",
+			"difficulty": "dx/e"
+		}
+	]
+}`
+)
+
+const (
+	masterConflictEquipmentJSON = `{
+	"type": "equipment_list",
+	"version": 4,
+	"rows": [
+		{
+			"id": "7d1c0a4e-8f1b-4a55-9d2e-000000000011",
+			"type": "equipment",
+			"description": "Backpack, Small",
+			"weight": "3 lb"
+		},
+		{
+			"id": "7d1c0a4e-8f1b-4a55-9d2e-000000000012",
+			"type": "equipment",
+			"description": "Rope, 3/8\"",
+			"weight": "1.5 lb"
+		}
+	]
+}`
+	userConflictEquipmentJSON = `{
+	"type": "equipment_list",
+	"version": 4,
+	"rows": [
+		{
+			"id": "7d1c0a4e-8f1b-4a55-9d2e-000000000013",
+			"type": "equipment",
+			"description": "Backpack, Small",
+			"weight": "3 lb"
+		},
+		{
+			"id": "7d1c0a4e-8f1b-4a55-9d2e-000000000014",
+			"type": "equipment",
+			"description": "Rope, 3/8\"",
+			"weight": "2 lb"
+		}
+	]
+}`
+)
+
+func TestConflicts(t *testing.T) {
+	base := t.TempDir()
+	libs := library.Libraries{}
+	master := libs.Master()
+	master.PathOnDisk = filepath.Join(base, "master")
+	user := libs.User()
+	user.PathOnDisk = filepath.Join(base, "user")
+	masterFile := filepath.Join(master.PathOnDisk, "Skills", "Basic.skl")
+	userFile := filepath.Join(user.PathOnDisk, "Mine.skl")
+	writeFile(t, masterFile, masterConflictSkillsJSON)
+	writeFile(t, userFile, userConflictSkillsJSON)
+
+	report := library.FindConflicts(libs)
+	assert.Equal(t, 2, report.FilesScanned)
+	assert.Equal(t, 4, report.ItemsScanned)
+	require.Len(t, report.Conflicts, 3)
+	assert.Equal(t, library.IDCollisionConflict, report.Conflicts[0].Kind)
+	assert.Equal(t, "Acrobatics", report.Conflicts[0].Name)
+	assert.Equal(t, library.VariantConflict, report.Conflicts[1].Kind)
+	assert.Equal(t, "Climbing", report.Conflicts[1].Name)
+	assert.Equal(t, library.DuplicateConflict, report.Conflicts[2].Kind)
+	assert.Equal(t, "Acrobatics", report.Conflicts[2].Name)
+	assert.Equal(t, 1, report.IDCollisions())
+
+	var buffer strings.Builder
+	require.NoError(t, report.Write(&buffer, libs))
+	assert.Contains(t, buffer.String(), "Master Library: Skills/Basic.skl (7d1c0a4e-8f1b-4a55-9d2e-000000000001)")
+
+	// The copy in the master library keeps its ID, while the one in the user library gets a new one
+	count, err := library.RegenerateCollidingIDs(libs, report)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	data, err := os.ReadFile(masterFile)
+	require.NoError(t, err)
+	assert.Equal(t, masterConflictSkillsJSON, string(data))
+	data, err = os.ReadFile(userFile)
+	require.NoError(t, err)
+	var file struct {
+		Rows []struct {
+			ID      string `json:"id"`
+			Weapons []struct {
+				ID string `json:"id"`
+			} `json:"weapons"`
+			Calc struct {
+				Level int `json:"level"`
+			} `json:"calc"`
+		} `json:"rows"`
+	}
+	require.NoError(t, json.Unmarshal(data, &file))
+	require.Len(t, file.Rows, 2)
+	assert.NotEqual(t, "7d1c0a4e-8f1b-4a55-9d2e-000000000001", file.Rows[0].ID)
+	require.Len(t, file.Rows[0].Weapons, 1)
+	assert.Equal(t, "7d1c0a4e-8f1b-4a55-9d2e-000000000001", file.Rows[0].Weapons[0].ID)
+	assert.Equal(t, 12, file.Rows[0].Calc.Level)
+	assert.Equal(t, "7d1c0a4e-8f1b-4a55-9d2e-000000000003", file.Rows[1].ID)
+
+	report = library.FindConflicts(libs)
+	assert.Zero(t, report.IDCollisions())
+	assert.Len(t, report.Conflicts, 2)
+}
+
+func TestEquipmentConflicts(t *testing.T) {
+	base := t.TempDir()
+	libs := library.Libraries{}
+	master := libs.Master()
+	master.PathOnDisk = filepath.Join(base, "master")
+	user := libs.User()
+	user.PathOnDisk = filepath.Join(base, "user")
+	writeFile(t, filepath.Join(master.PathOnDisk, "Equipment", "Basic.eqp"), masterConflictEquipmentJSON)
+	writeFile(t, filepath.Join(user.PathOnDisk, "Mine.eqp"), userConflictEquipmentJSON)
+
+	report := library.FindConflicts(libs)
+	assert.Equal(t, 4, report.ItemsScanned)
+	require.Len(t, report.Conflicts, 2)
+	assert.Equal(t, library.VariantConflict, report.Conflicts[0].Kind)
+	assert.Equal(t, `Rope, 3/8"`, report.Conflicts[0].Name)
+	assert.Equal(t, library.DuplicateConflict, report.Conflicts[1].Kind)
+	assert.Equal(t, "Backpack, Small", report.Conflicts[1].Name)
+	assert.Zero(t, report.IDCollisions())
+}
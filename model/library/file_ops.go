@@ -0,0 +1,105 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package library
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/richardwilkes/toolbox/errs"
+)
+
+// Exists returns true if something exists at the path.
+func Exists(p string) bool {
+	_, err := os.Lstat(p)
+	return err == nil
+}
+
+// UniquePath returns the path if nothing exists there yet, otherwise a number is appended to the base name of the path
+// until an unused one is found.
+func UniquePath(p string) string {
+	if !Exists(p) {
+		return p
+	}
+	ext := filepath.Ext(p)
+	base := strings.TrimSuffix(p, ext)
+	for i := 2; ; i++ {
+		if candidate := fmt.Sprintf("%s %d%s", base, i, ext); !Exists(candidate) {
+			return candidate
+		}
+	}
+}
+
+// ValidFileName returns true if the name may be used as the name of a file or directory within a library.
+func ValidFileName(name string) bool {
+	return strings.TrimSpace(name) == name && name != "" && name != "." && name != ".." &&
+		!strings.HasPrefix(name, ".") && !strings.ContainsAny(name, `/\:*?"<>|`)
+}
+
+// CopyPath copies the file or directory at src to dst, which must not already exist. Hidden files within directories
+// are not copied.
+func CopyPath(src, dst string) error {
+	if Exists(dst) {
+		return errs.Wrap(&fs.PathError{Op: "copy", Path: dst, Err: fs.ErrExist})
+	}
+	fi, err := os.Stat(src)
+	if err != nil {
+		return errs.Wrap(err)
+	}
+	if fi.IsDir() {
+		if err = os.MkdirAll(dst, 0o750); err != nil {
+			return errs.Wrap(err)
+		}
+		return copyFS(os.DirFS(src), ".", dst)
+	}
+	return copyFile(os.DirFS(filepath.Dir(src)), filepath.Base(src), dst)
+}
+
+// MovePath moves the file or directory at src to dst, which must not already exist. If the two locations are on
+// different volumes, the content is copied and then removed from its original location.
+func MovePath(src, dst string) error {
+	if dstInfo, err := os.Lstat(dst); err == nil {
+		// Permit changing just the case of the name on file systems that are case-insensitive
+		if srcInfo, srcErr := os.Lstat(src); srcErr != nil || !os.SameFile(srcInfo, dstInfo) {
+			return errs.Wrap(&fs.PathError{Op: "move", Path: dst, Err: fs.ErrExist})
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0o750); err != nil {
+		return errs.Wrap(err)
+	}
+	err := os.Rename(src, dst)
+	if err == nil {
+		return nil
+	}
+	var linkErr *os.LinkError
+	if !errors.As(err, &linkErr) || !Exists(src) {
+		return errs.Wrap(err)
+	}
+	if copyErr := CopyPath(src, dst); copyErr != nil {
+		removeAllLogging(dst)
+		return errs.Append(errs.Wrap(err), copyErr)
+	}
+	if err = os.RemoveAll(src); err != nil {
+		return errs.NewWithCause("copied, but unable to remove "+src, err)
+	}
+	return nil
+}
+
+// IsWithin returns true if the path is the same as, or is contained by, the directory.
+func IsWithin(dir, p string) bool {
+	rel, err := filepath.Rel(dir, p)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,58 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package library_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/richardwilkes/gcs/model/library"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileOperations(t *testing.T) {
+	base := t.TempDir()
+	src := filepath.Join(base, "Traits")
+	writeFile(t, filepath.Join(src, "a.adq"), "a")
+	writeFile(t, filepath.Join(src, "sub", "b.adq"), "b")
+
+	assert.Equal(t, filepath.Join(base, "New"), library.UniquePath(filepath.Join(base, "New")))
+	assert.Equal(t, filepath.Join(src, "a 2.adq"), library.UniquePath(filepath.Join(src, "a.adq")))
+	writeFile(t, filepath.Join(src, "a 2.adq"), "a2")
+	assert.Equal(t, filepath.Join(src, "a 3.adq"), library.UniquePath(filepath.Join(src, "a.adq")))
+
+	dup := filepath.Join(base, "Traits copy")
+	require.NoError(t, library.CopyPath(src, dup))
+	data, err := os.ReadFile(filepath.Join(dup, "sub", "b.adq"))
+	require.NoError(t, err)
+	assert.Equal(t, "b", string(data))
+	assert.Error(t, library.CopyPath(src, dup), "should not overwrite")
+
+	moved := filepath.Join(base, "Other", "Moved")
+	require.NoError(t, library.MovePath(dup, moved))
+	assert.NoDirExists(t, dup)
+	assert.FileExists(t, filepath.Join(moved, "a.adq"))
+	assert.Error(t, library.MovePath(src, moved), "should not overwrite")
+	assert.DirExists(t, src)
+
+	assert.True(t, library.IsWithin(src, filepath.Join(src, "sub", "b.adq")))
+	assert.True(t, library.IsWithin(src, src))
+	assert.False(t, library.IsWithin(src, moved))
+	assert.False(t, library.IsWithin(src, src+"2"))
+
+	assert.True(t, library.ValidFileName("My Traits.adq"))
+	for _, name := range []string{"", " a", ".hidden", "..", "a/b", `a\b`, "a:b"} {
+		assert.False(t, library.ValidFileName(name), name)
+	}
+}
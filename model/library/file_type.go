@@ -13,6 +13,7 @@ package library
 
 import (
 	"path"
+	"sort"
 	"strings"
 
 	"github.com/richardwilkes/toolbox/txt"
@@ -37,14 +38,18 @@ const (
 	NotesExt              = ".not"
 	TemplatesExt          = ".gct"
 	SheetExt              = ".gcs"
+	EncounterExt          = ".gce"
+	CampaignExt           = ".gcp"
 )
 
 // FileInfo contains some static information about a given file type.
 type FileInfo struct {
 	Extension             string
+	Name                  string
 	ExtensionsToGroupWith []string
 	SVG                   *unison.SVG
 	Load                  func(filePath string) (unison.Dockable, error)
+	CreateEmpty           func(filePath string) error
 	IsSpecial             bool
 	IsGCSData             bool
 	IsImage               bool
@@ -78,3 +83,15 @@ func AcceptableExtensions() []string {
 	txt.SortStringsNaturalAscending(list)
 	return list
 }
+
+// CreatableFileInfos returns the FileInfos for file types that can be created empty, sorted by name.
+func CreatableFileInfos() []FileInfo {
+	list := make([]FileInfo, 0, len(fileTypeRegistry))
+	for _, v := range fileTypeRegistry {
+		if v.CreateEmpty != nil {
+			list = append(list, v)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return txt.NaturalLess(list[i].Name, list[j].Name, true) })
+	return list
+}
@@ -0,0 +1,390 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package library
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/richardwilkes/gcs/model/crc"
+	"github.com/richardwilkes/gcs/model/jio"
+	"github.com/richardwilkes/json"
+	"github.com/richardwilkes/toolbox/cmdline"
+	"github.com/richardwilkes/toolbox/errs"
+	"github.com/richardwilkes/toolbox/log/jot"
+	"github.com/richardwilkes/toolbox/txt"
+	"github.com/richardwilkes/toolbox/xio/fs/paths"
+)
+
+const indexVersion = 1
+
+// IndexedExtensions holds the file extensions whose contents are indexed for searching.
+var IndexedExtensions = []string{
+	TraitsExt,
+	TraitModifiersExt,
+	SkillsExt,
+	SpellsExt,
+	EquipmentExt,
+	EquipmentModifiersExt,
+	NotesExt,
+}
+
+// Row-level keys whose values are not worth indexing.
+var nonIndexedRowKeys = map[string]bool{
+	"id":       true,
+	"type":     true,
+	"children": true,
+	"calc":     true,
+	"open":     true,
+}
+
+var (
+	globalIndexOnce sync.Once
+	globalIndex     *Index
+)
+
+// IndexEntry holds the searchable information for a single row within a library file.
+type IndexEntry struct {
+	LibraryKey string    `json:"library"`
+	Path       string    `json:"path"`
+	ID         uuid.UUID `json:"id"`
+	Type       string    `json:"type"`
+	Name       string    `json:"name,omitempty"`
+	Tags       []string  `json:"tags,omitempty"`
+	PageRef    string    `json:"reference,omitempty"`
+	Text       string    `json:"text"`
+}
+
+// Extension returns the file extension of the file the entry came from.
+func (e *IndexEntry) Extension() string {
+	return strings.ToLower(filepath.Ext(e.Path))
+}
+
+// IndexQuery holds the criteria for a search of the Index.
+type IndexQuery struct {
+	// Text must contain each of the whitespace-separated terms, ignoring case. If empty, all entries match.
+	Text string
+	// Extensions limits the results to entries from files with these extensions. If empty, all extensions match.
+	Extensions []string
+	// Tag limits the results to entries with this tag, ignoring case. If empty, all entries match.
+	Tag string
+}
+
+type indexedFile struct {
+	CRC     uint64        `json:"crc"`
+	Entries []*IndexEntry `json:"entries,omitempty"`
+}
+
+type indexData struct {
+	Version int                     `json:"version"`
+	Files   map[string]*indexedFile `json:"files"`
+}
+
+// Index provides full-text searching of the contents of libraries. The index is persisted to disk and is refreshed by
+// calling Update(), which only re-parses files whose CRC-64 has changed.
+type Index struct {
+	filePath   string
+	updateLock sync.Mutex
+	lock       sync.RWMutex
+	files      map[string]*indexedFile
+}
+
+// GlobalIndex returns the global Index, loading it from disk the first time it is requested.
+func GlobalIndex() *Index {
+	globalIndexOnce.Do(func() {
+		globalIndex = NewIndex(filepath.Join(paths.AppDataDir(), cmdline.AppCmdName+"_library_index.json"))
+	})
+	return globalIndex
+}
+
+// NewIndex creates a new Index backed by the given file. Any existing data in the file will be loaded. If filePath is
+// empty, the index will not be persisted.
+func NewIndex(filePath string) *Index {
+	idx := &Index{
+		filePath: filePath,
+		files:    make(map[string]*indexedFile),
+	}
+	if filePath != "" {
+		var data indexData
+		if err := jio.LoadFromFile(context.Background(), filePath, &data); err != nil {
+			if !errors.Is(err, fs.ErrNotExist) {
+				jot.Warn(errs.NewWithCause("unable to load library index; it will be rebuilt", err))
+			}
+		} else if data.Version == indexVersion && data.Files != nil {
+			idx.files = data.Files
+		}
+	}
+	return idx
+}
+
+// Update brings the index up to date with the contents of the libraries, returning true if anything changed.
+func (idx *Index) Update(libs Libraries) bool {
+	idx.updateLock.Lock()
+	defer idx.updateLock.Unlock()
+	idx.lock.RLock()
+	existing := idx.files
+	idx.lock.RUnlock()
+	files := make(map[string]*indexedFile, len(existing))
+	changed := false
+	forEachIndexedFile(libs, func(lib *Library, p string) {
+		if _, seen := files[p]; seen {
+			return // Nested libraries may result in the same file being visited more than once
+		}
+		data, err := jio.ReadFile(p)
+		if err != nil {
+			jot.Warn(errs.NewWithCause("unable to read "+p+" for indexing", err))
+			return
+		}
+		sum := crc.Bytes(0, data)
+		if prior, ok := existing[p]; ok && prior.CRC == sum && (len(prior.Entries) == 0 ||
+			prior.Entries[0].LibraryKey == lib.Key()) {
+			files[p] = prior
+			return
+		}
+		changed = true
+		files[p] = &indexedFile{
+			CRC:     sum,
+			Entries: indexFileData(lib.Key(), p, data),
+		}
+	})
+	if len(files) != len(existing) {
+		changed = true
+	}
+	if !changed {
+		return false
+	}
+	idx.lock.Lock()
+	idx.files = files
+	idx.lock.Unlock()
+	if idx.filePath != "" {
+		if err := jio.SaveToFile(context.Background(), idx.filePath, &indexData{
+			Version: indexVersion,
+			Files:   files,
+		}); err != nil {
+			jot.Warn(errs.NewWithCause("unable to save library index", err))
+		}
+	}
+	return true
+}
+
+// Search returns the entries that match the query, sorted by name.
+func (idx *Index) Search(query IndexQuery) []*IndexEntry {
+	terms := strings.Fields(strings.ToLower(query.Text))
+	exts := make(map[string]bool, len(query.Extensions))
+	for _, ext := range query.Extensions {
+		exts[strings.ToLower(ext)] = true
+	}
+	tag := strings.TrimSpace(query.Tag)
+	var results []*IndexEntry
+	idx.lock.RLock()
+	for p, f := range idx.files {
+		if len(exts) != 0 && !exts[strings.ToLower(filepath.Ext(p))] {
+			continue
+		}
+		for _, entry := range f.Entries {
+			if entry.matches(terms, tag) {
+				results = append(results, entry)
+			}
+		}
+	}
+	idx.lock.RUnlock()
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Name != results[j].Name {
+			return txt.NaturalLess(results[i].Name, results[j].Name, true)
+		}
+		return txt.NaturalLess(results[i].Path, results[j].Path, true)
+	})
+	return results
+}
+
+// Tags returns the set of tags found within the index, sorted.
+func (idx *Index) Tags() []string {
+	set := make(map[string]bool)
+	idx.lock.RLock()
+	for _, f := range idx.files {
+		for _, entry := range f.Entries {
+			for _, tag := range entry.Tags {
+				set[tag] = true
+			}
+		}
+	}
+	idx.lock.RUnlock()
+	tags := make([]string, 0, len(set))
+	for tag := range set {
+		tags = append(tags, tag)
+	}
+	txt.SortStringsNaturalAscending(tags)
+	return tags
+}
+
+func (e *IndexEntry) matches(terms []string, tag string) bool {
+	if tag != "" {
+		found := false
+		for _, one := range e.Tags {
+			if strings.EqualFold(one, tag) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	for _, term := range terms {
+		if !strings.Contains(e.Text, term) {
+			return false
+		}
+	}
+	return true
+}
+
+// forEachIndexedFile calls fn for each file within the libraries that has one of the IndexedExtensions. Hidden files and
+// directories are skipped, while directories holding data in the split format are treated as files. As with
+// ForEachFile, nested libraries may result in the same file being visited more than once.
+func forEachIndexedFile(libs Libraries, fn func(lib *Library, p string)) {
+	ForEachFile(libs, IndexedExtensions, fn)
+}
+
+// ForEachFile calls fn for each file within the libraries that has one of the given extensions. Files stored in split
+// form are reported once, using the path of their directory. Hidden files and directories are skipped. Note that nested
+// libraries may result in the same file being visited more than once.
+func ForEachFile(libs Libraries, extensions []string, fn func(lib *Library, p string)) {
+	for _, lib := range libs.List() {
+		root := lib.PathOnDisk
+		if err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if p == root {
+					return err
+				}
+				return nil
+			}
+			if strings.HasPrefix(d.Name(), ".") && p != root {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if hasExtension(p, extensions) {
+				if !d.IsDir() {
+					fn(lib, p)
+				} else if jio.IsSplitPath(p) {
+					fn(lib, p)
+					return filepath.SkipDir
+				}
+			}
+			return nil
+		}); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			jot.Warn(errs.NewWithCause("unable to scan "+root, err))
+		}
+	}
+}
+
+func hasExtension(p string, extensions []string) bool {
+	ext := strings.ToLower(filepath.Ext(p))
+	for _, one := range extensions {
+		if ext == one {
+			return true
+		}
+	}
+	return false
+}
+
+// indexFileData parses the data from a library file, returning the entries for each of its rows. Rather than decoding
+// into the full data model, the raw JSON is examined so that every textual value, including those within features,
+// defaults, prerequisites and weapons, becomes searchable.
+func indexFileData(libKey, filePath string, data []byte) []*IndexEntry {
+	var file struct {
+		Rows []map[string]any `json:"rows"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		jot.Warn(errs.NewWithCause("unable to parse "+filePath+" for indexing", err))
+		return nil
+	}
+	var entries []*IndexEntry
+	var collect func(rows []map[string]any)
+	collect = func(rows []map[string]any) {
+		for _, row := range rows {
+			entry := &IndexEntry{
+				LibraryKey: libKey,
+				Path:       filePath,
+			}
+			entry.Type, _ = row["type"].(string)
+			if s, ok := row["id"].(string); ok {
+				if parsed, err := uuid.Parse(s); err == nil {
+					entry.ID = parsed
+				}
+			}
+			entry.Name = rowName(row)
+			entry.PageRef, _ = row["reference"].(string)
+			if tags, ok := row["tags"].([]any); ok {
+				for _, one := range tags {
+					if tag, isStr := one.(string); isStr && tag != "" {
+						entry.Tags = append(entry.Tags, tag)
+					}
+				}
+			}
+			var buffer strings.Builder
+			for k, v := range row {
+				if !nonIndexedRowKeys[k] {
+					collectText(&buffer, v)
+				}
+			}
+			entry.Text = strings.ToLower(buffer.String())
+			entries = append(entries, entry)
+			if children, ok := row["children"].([]any); ok {
+				childRows := make([]map[string]any, 0, len(children))
+				for _, child := range children {
+					if m, isMap := child.(map[string]any); isMap {
+						childRows = append(childRows, m)
+					}
+				}
+				collect(childRows)
+			}
+		}
+	}
+	collect(file.Rows)
+	return entries
+}
+
+// rowName returns the name of a raw library row. Equipment stores its name as a description, so that is used when no
+// name is present.
+func rowName(row map[string]any) string {
+	if name, ok := row["name"].(string); ok {
+		return name
+	}
+	name, _ := row["description"].(string)
+	return name
+}
+
+func collectText(buffer *strings.Builder, value any) {
+	switch v := value.(type) {
+	case string:
+		if v != "" {
+			buffer.WriteString(v)
+			buffer.WriteByte('\n')
+		}
+	case []any:
+		for _, one := range v {
+			collectText(buffer, one)
+		}
+	case map[string]any:
+		for _, one := range v {
+			collectText(buffer, one)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package library_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/richardwilkes/gcs/model/library"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const indexSkillsJSON = `{
+	"type": "skill_list",
+	"version": 4,
+	"rows": [
+		{
+			"id": "2b0ae1de-4c4b-4e0b-9c1a-0d6b5d8e0f01",
+			"type": "skill_container",
+			"name": "Combat",
+			"children": [
+				{
+					"id": "2b0ae1de-4c4b-4e0b-9c1a-0d6b5d8e0f02",
+					"type": "skill",
+					"name": "Broadsword",
+					"reference": "B208",
+					"tags": ["Melee Combat", "Weapon"],
+					"defaults": [{"type": "skill", "name": "Shortsword", "modifier": -2}]
+				}
+			]
+		},
+		{
+			"id": "2b0ae1de-4c4b-4e0b-9c1a-0d6b5d8e0f03",
+			"type": "skill",
+			"name": "Acrobatics",
+			"tags": ["Athletic"]
+		}
+	]
+}`
+
+const indexEquipmentJSON = `{
+	"type": "equipment_list",
+	"version": 4,
+	"rows": [
+		{
+			"id": "2b0ae1de-4c4b-4e0b-9c1a-0d6b5d8e0f11",
+			"type": "equipment",
+			"description": "Backpack, Small",
+			"reference": "B288",
+			"notes": "Holds 40 lbs."
+		}
+	]
+}`
+
+func TestIndex(t *testing.T) {
+	base := t.TempDir()
+	libs := library.Libraries{}
+	lib := libs.Master()
+	lib.PathOnDisk = filepath.Join(base, "lib")
+	skills := filepath.Join(lib.PathOnDisk, "Skills", "Basic.skl")
+	writeFile(t, skills, indexSkillsJSON)
+	writeFile(t, filepath.Join(lib.PathOnDisk, ".hidden", "Ignored.skl"), indexSkillsJSON)
+	writeFile(t, filepath.Join(lib.PathOnDisk, "readme.txt"), "Broadsword")
+
+	indexFile := filepath.Join(base, "index.json")
+	idx := library.NewIndex(indexFile)
+	require.True(t, idx.Update(libs))
+	assert.False(t, idx.Update(libs))
+
+	results := idx.Search(library.IndexQuery{})
+	require.Len(t, results, 3)
+	assert.Equal(t, "Acrobatics", results[0].Name)
+	assert.Equal(t, "Broadsword", results[1].Name)
+	assert.Equal(t, "Combat", results[2].Name)
+	assert.Equal(t, "B208", results[1].PageRef)
+	assert.Equal(t, "2b0ae1de-4c4b-4e0b-9c1a-0d6b5d8e0f02", results[1].ID.String())
+	assert.Equal(t, library.SkillsExt, results[1].Extension())
+
+	// Text nested within defaults is searchable and every term must match
+	results = idx.Search(library.IndexQuery{Text: "shortSWORD broad"})
+	require.Len(t, results, 1)
+	assert.Equal(t, "Broadsword", results[0].Name)
+	assert.Empty(t, idx.Search(library.IndexQuery{Text: "shortsword acrobatics"}))
+
+	assert.Len(t, idx.Search(library.IndexQuery{Tag: "weapon"}), 1)
+	assert.Empty(t, idx.Search(library.IndexQuery{Extensions: []string{library.SpellsExt}}))
+	assert.Equal(t, []string{"Athletic", "Melee Combat", "Weapon"}, idx.Tags())
+
+	// The index persists and only notices actual changes
+	idx = library.NewIndex(indexFile)
+	assert.Len(t, idx.Search(library.IndexQuery{}), 3)
+	assert.False(t, idx.Update(libs))
+	writeFile(t, skills, `{"type":"skill_list","version":4,"rows":[{"type":"skill","name":"Climbing"}]}`)
+	assert.True(t, idx.Update(libs))
+	results = idx.Search(library.IndexQuery{})
+	require.Len(t, results, 1)
+	assert.Equal(t, "Climbing", results[0].Name)
+}
+
+func TestIndexEquipmentNames(t *testing.T) {
+	base := t.TempDir()
+	libs := library.Libraries{}
+	lib := libs.Master()
+	lib.PathOnDisk = filepath.Join(base, "lib")
+	writeFile(t, filepath.Join(lib.PathOnDisk, "Equipment", "Basic.eqp"), indexEquipmentJSON)
+
+	idx := library.NewIndex(filepath.Join(base, "index.json"))
+	require.True(t, idx.Update(libs))
+	results := idx.Search(library.IndexQuery{Text: "backpack"})
+	require.Len(t, results, 1)
+	assert.Equal(t, "Backpack, Small", results[0].Name)
+	assert.Equal(t, "B288", results[0].PageRef)
+	assert.Equal(t, library.EquipmentExt, results[0].Extension())
+}
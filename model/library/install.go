@@ -0,0 +1,314 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package library
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/richardwilkes/gcs/model/gurps/gid"
+	"github.com/richardwilkes/toolbox/errs"
+	"github.com/richardwilkes/toolbox/log/jot"
+	"github.com/richardwilkes/toolbox/txt"
+	"github.com/richardwilkes/toolbox/xio"
+)
+
+// ChecksumFile is the name of the optional manifest within a library that lists the SHA-256 checksums of its files,
+// one per line, in the same format as produced by the sha256sum tool.
+const ChecksumFile = "checksums.sha256"
+
+// IsCompatibleVersion returns true if a library with the given version can be used with this version of GCS.
+func IsCompatibleVersion(version string) bool {
+	incompatibleFutureLibraryVersion := strconv.Itoa(gid.CurrentDataVersion + 1)
+	minimumLibraryVersion := strconv.Itoa(gid.MinimumLibraryVersion)
+	return incompatibleFutureLibraryVersion != version &&
+		!txt.NaturalLess(version, minimumLibraryVersion, true) &&
+		!txt.NaturalLess(incompatibleFutureLibraryVersion, version, true)
+}
+
+// InstallFromPath installs the library contents found at the given path, which may be either a zip archive or a
+// directory. The contents must contain a release.txt file with a compatible version. See InstallFromFS() for details.
+func (l *Library) InstallFromPath(srcPath string) error {
+	fi, err := os.Stat(srcPath)
+	if err != nil {
+		return errs.NewWithCause("unable to access "+srcPath, err)
+	}
+	if fi.IsDir() {
+		return l.InstallFromFS(os.DirFS(srcPath), "")
+	}
+	var zr *zip.ReadCloser
+	if zr, err = zip.OpenReader(srcPath); err != nil {
+		return errs.NewWithCause("unable to open archive "+srcPath, err)
+	}
+	defer xio.CloseIgnoringErrors(zr)
+	return l.InstallFromFS(zr, "")
+}
+
+// InstallFromFS installs the library contents found within the file system. The library root is taken to be the
+// shallowest directory containing a release.txt file or, failing that, a directory named "Library" at the top level or
+// one level down, as is found in GitHub release archives. If version is not empty, it is written to the release.txt
+// file, replacing any that was present.
+//
+// The contents are first staged into a temporary directory alongside the library and validated. A release.txt file
+// must be present and its version must be compatible. If a checksum manifest is present, every file listed within it
+// must exist and match. Only once validation succeeds is the existing library swapped out for the new one. Should the
+// swap fail, the previous contents are restored.
+func (l *Library) InstallFromFS(fileSystem fs.FS, version string) error {
+	root, err := findLibraryRoot(fileSystem)
+	if err != nil {
+		return err
+	}
+	target := filepath.Clean(l.PathOnDisk)
+	parent := filepath.Dir(target)
+	if err = os.MkdirAll(parent, 0o750); err != nil {
+		return errs.NewWithCause("unable to create "+parent, err)
+	}
+	var staging string
+	if staging, err = os.MkdirTemp(parent, "."+filepath.Base(target)+"-staging-"); err != nil {
+		return errs.NewWithCause("unable to create staging directory", err)
+	}
+	defer removeAllLogging(staging)
+	if err = copyFS(fileSystem, root, staging); err != nil {
+		return err
+	}
+	if version != "" {
+		f := filepath.Join(staging, releaseFile)
+		if err = os.WriteFile(f, []byte(version+"\n"), 0o640); err != nil {
+			return errs.NewWithCause("unable to create "+f, err)
+		}
+	}
+	if version, err = validateStaging(staging); err != nil {
+		return err
+	}
+	if err = swapIn(staging, target); err != nil {
+		return err
+	}
+	l.LastSeen = version
+	return nil
+}
+
+func findLibraryRoot(fileSystem fs.FS) (string, error) {
+	root := ""
+	rootDepth := -1
+	libraryDir := ""
+	if err := fs.WalkDir(fileSystem, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		depth := strings.Count(p, "/")
+		if d.IsDir() {
+			if libraryDir == "" && depth < 2 && strings.EqualFold(d.Name(), "Library") {
+				libraryDir = p
+			}
+			return nil
+		}
+		if d.Name() == releaseFile && (rootDepth == -1 || depth < rootDepth) {
+			root = path.Dir(p)
+			rootDepth = depth
+		}
+		return nil
+	}); err != nil {
+		return "", errs.NewWithCause("unable to scan library contents", err)
+	}
+	switch {
+	case rootDepth != -1:
+		return root, nil
+	case libraryDir != "":
+		return libraryDir, nil
+	default:
+		return ".", nil
+	}
+}
+
+func copyFS(fileSystem fs.FS, root, dst string) error {
+	return fs.WalkDir(fileSystem, root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return errs.NewWithCause("unable to read "+p, err)
+		}
+		rel := p
+		if root != "." {
+			rel = strings.TrimPrefix(strings.TrimPrefix(p, root), "/")
+		}
+		if rel == "." || rel == "" {
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !fs.ValidPath(rel) {
+			return errs.Newf("path outside of root is not permitted: %s", p)
+		}
+		fullPath := filepath.Join(dst, filepath.FromSlash(rel))
+		if d.IsDir() {
+			if err = os.MkdirAll(fullPath, 0o750); err != nil {
+				return errs.NewWithCause("unable to create "+fullPath, err)
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if err = copyFile(fileSystem, p, fullPath); err != nil {
+			return errs.NewWithCause("unable to create "+fullPath, err)
+		}
+		return nil
+	})
+}
+
+func copyFile(fileSystem fs.FS, src, dst string) (err error) {
+	var r fs.File
+	if r, err = fileSystem.Open(src); err != nil {
+		return errs.Wrap(err)
+	}
+	defer xio.CloseIgnoringErrors(r)
+	if err = os.MkdirAll(filepath.Dir(dst), 0o750); err != nil {
+		return errs.Wrap(err)
+	}
+	var file *os.File
+	if file, err = os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o640); err != nil {
+		return errs.Wrap(err)
+	}
+	if _, err = io.Copy(file, r); err != nil {
+		err = errs.Wrap(err)
+	}
+	if closeErr := file.Close(); closeErr != nil && err == nil {
+		err = errs.Wrap(closeErr)
+	}
+	return
+}
+
+// validateStaging checks the staged library for validity, returning its version.
+func validateStaging(dir string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, releaseFile))
+	if err != nil {
+		return "", errs.NewWithCause("library is missing its "+releaseFile+" file", err)
+	}
+	version := strings.TrimSpace(string(bytes.SplitN(data, []byte{'\n'}, 2)[0]))
+	if version == "" {
+		return "", errs.New("library " + releaseFile + " file does not contain a version")
+	}
+	if !IsCompatibleVersion(version) {
+		return "", errs.Newf("library version %s is not compatible with this version of GCS", version)
+	}
+	if err = VerifyChecksums(dir); err != nil {
+		return "", err
+	}
+	return version, nil
+}
+
+// VerifyChecksums verifies the files in the library directory against its checksum manifest. If there is no manifest,
+// nothing is verified.
+func VerifyChecksums(dir string) error {
+	f, err := os.Open(filepath.Join(dir, ChecksumFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return errs.NewWithCause("unable to open "+ChecksumFile, err)
+	}
+	defer xio.CloseIgnoringErrors(f)
+	s := bufio.NewScanner(f)
+	lineNum := 0
+	for s.Scan() {
+		lineNum++
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.SplitN(line, " ", 2)
+		if len(parts) != 2 {
+			return errs.Newf("invalid entry on line %d of %s", lineNum, ChecksumFile)
+		}
+		expected := strings.ToLower(parts[0])
+		name := strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(parts[1]), "*"), "./")
+		if !fs.ValidPath(name) {
+			return errs.Newf("invalid path on line %d of %s: %s", lineNum, ChecksumFile, name)
+		}
+		var actual string
+		if actual, err = fileChecksum(filepath.Join(dir, filepath.FromSlash(name))); err != nil {
+			return errs.NewWithCause("unable to verify "+name, err)
+		}
+		if actual != expected {
+			return errs.Newf("checksum mismatch for %s", name)
+		}
+	}
+	if err = s.Err(); err != nil {
+		return errs.NewWithCause("unable to read "+ChecksumFile, err)
+	}
+	return nil
+}
+
+func fileChecksum(p string) (string, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return "", errs.Wrap(err)
+	}
+	defer xio.CloseIgnoringErrors(f)
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return "", errs.Wrap(err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// swapIn replaces the target directory with the staging directory, restoring the original if something goes wrong.
+func swapIn(staging, target string) error {
+	_, err := os.Stat(target)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return errs.NewWithCause("unable to access "+target, err)
+		}
+		if err = os.Rename(staging, target); err != nil {
+			return errs.NewWithCause("unable to install library", err)
+		}
+		return nil
+	}
+	var tmp string
+	if tmp, err = os.MkdirTemp(filepath.Dir(target), "."+filepath.Base(target)+"-previous-"); err != nil {
+		return errs.NewWithCause("unable to create backup directory", err)
+	}
+	backup := filepath.Join(tmp, filepath.Base(target))
+	if err = os.Rename(target, backup); err != nil {
+		removeAllLogging(tmp)
+		return errs.NewWithCause("unable to move existing library out of the way", err)
+	}
+	if err = os.Rename(staging, target); err != nil {
+		if restoreErr := os.Rename(backup, target); restoreErr != nil {
+			// Leave the backup in place so that the user can recover it manually
+			return errs.Append(errs.NewWithCause("unable to install library", err),
+				errs.NewWithCause("unable to restore previous library; it may be found at "+backup, restoreErr))
+		}
+		removeAllLogging(tmp)
+		return errs.NewWithCause("unable to install library", err)
+	}
+	removeAllLogging(tmp)
+	return nil
+}
+
+func removeAllLogging(p string) {
+	if err := os.RemoveAll(p); err != nil {
+		jot.Warn(errs.NewWithCause("unable to remove "+p, err))
+	}
+}
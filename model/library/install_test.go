@@ -0,0 +1,64 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package library_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/richardwilkes/gcs/model/library"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstallFromPath(t *testing.T) {
+	base := t.TempDir()
+	lib := &library.Library{Title: "Test", PathOnDisk: filepath.Join(base, "lib")}
+	writeFile(t, filepath.Join(lib.PathOnDisk, "old.txt"), "old")
+
+	src := filepath.Join(base, "src")
+	writeFile(t, filepath.Join(src, "release.txt"), "4\n")
+	writeFile(t, filepath.Join(src, "Traits", "a.adq"), "traits")
+	sum := sha256.Sum256([]byte("traits"))
+	writeFile(t, filepath.Join(src, library.ChecksumFile), hex.EncodeToString(sum[:])+"  Traits/a.adq\n")
+	require.NoError(t, lib.InstallFromPath(src))
+	assert.Equal(t, "4", lib.VersionOnDisk())
+	assert.NoFileExists(t, filepath.Join(lib.PathOnDisk, "old.txt"))
+	assert.FileExists(t, filepath.Join(lib.PathOnDisk, "Traits", "a.adq"))
+
+	// A checksum mismatch must leave the installed library untouched
+	writeFile(t, filepath.Join(src, "Traits", "a.adq"), "tampered")
+	assert.Error(t, lib.InstallFromPath(src))
+	data, err := os.ReadFile(filepath.Join(lib.PathOnDisk, "Traits", "a.adq"))
+	require.NoError(t, err)
+	assert.Equal(t, "traits", string(data))
+
+	// An incompatible version must be rejected
+	require.NoError(t, os.Remove(filepath.Join(src, library.ChecksumFile)))
+	writeFile(t, filepath.Join(src, "release.txt"), "1\n")
+	assert.Error(t, lib.InstallFromPath(src))
+	assert.Equal(t, "4", lib.VersionOnDisk())
+
+	// No staging or backup directories should be left behind
+	entries, err := os.ReadDir(base)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
+
+func writeFile(t *testing.T, p, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o750))
+	require.NoError(t, os.WriteFile(p, []byte(content), 0o640))
+}
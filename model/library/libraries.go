@@ -16,9 +16,12 @@ import (
 	"io/fs"
 	"net/http"
 	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/richardwilkes/gcs/model/id"
 	"github.com/richardwilkes/gcs/model/jio"
 	"github.com/richardwilkes/json"
 	"github.com/richardwilkes/toolbox/i18n"
@@ -102,7 +105,23 @@ func (l Libraries) User() *Library {
 	return lib
 }
 
-// List returns an ordered list of Library objects.
+// NewLocalKey returns a key suitable for a new Library that is a plain local folder, not tied to a release source.
+func (l Libraries) NewLocalKey(title string) string {
+	base := id.Sanitize(strings.ReplaceAll(strings.TrimSpace(title), " ", "_"), true)
+	if strings.Trim(base, "_") == "" {
+		base = "library"
+	}
+	key := userGitHubAccountName + "/" + base
+	for i := 2; ; i++ {
+		if _, exists := l[key]; !exists {
+			return key
+		}
+		key = userGitHubAccountName + "/" + base + "_" + strconv.Itoa(i)
+	}
+}
+
+// List returns an ordered list of Library objects. The order of this list also determines precedence when files with
+// the same name exist in more than one library: earlier libraries win.
 func (l Libraries) List() []*Library {
 	libs := make([]*Library, 0, len(l))
 	for _, lib := range l {
@@ -19,11 +19,9 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
-	"strconv"
 	"strings"
 	"sync"
 
-	"github.com/richardwilkes/gcs/model/gurps/gid"
 	"github.com/richardwilkes/toolbox/errs"
 	"github.com/richardwilkes/toolbox/log/jot"
 	"github.com/richardwilkes/toolbox/txt"
@@ -34,11 +32,14 @@ const releaseFile = "release.txt"
 
 // Library holds information about a library of data files.
 type Library struct {
-	Title             string `json:"title,omitempty"`
-	GitHubAccountName string `json:"-"`
-	RepoName          string `json:"-"`
-	PathOnDisk        string `json:"path,omitempty"`
-	LastSeen          string `json:"last_seen,omitempty"`
+	Title             string            `json:"title,omitempty"`
+	GitHubAccountName string            `json:"-"`
+	RepoName          string            `json:"-"`
+	PathOnDisk        string            `json:"path,omitempty"`
+	LastSeen          string            `json:"last_seen,omitempty"`
+	Order             int               `json:"order,omitempty"`
+	SourceType        ReleaseSourceType `json:"source_type,omitempty"`
+	SourceURL         string            `json:"source_url,omitempty"`
 	lock              sync.RWMutex
 	upgrade           *Release
 }
@@ -91,19 +92,51 @@ func (l *Library) IsUser() bool {
 	return l.GitHubAccountName == userGitHubAccountName && l.RepoName == userRepoName
 }
 
+// HasRepoKey returns true if this Library's key identifies an account and repository on a server, rather than being a
+// locally assigned name.
+func (l *Library) HasRepoKey() bool {
+	return l.GitHubAccountName != userGitHubAccountName && l.GitHubAccountName != "" && l.RepoName != ""
+}
+
+// ReleaseSource returns the source of releases for this Library, or nil if it is a plain local folder.
+func (l *Library) ReleaseSource() ReleaseSource {
+	switch l.SourceType.EnsureValid() {
+	case GiteaReleaseSource:
+		if l.HasRepoKey() && l.SourceURL != "" {
+			return &GiteaSource{
+				BaseURL:     l.SourceURL,
+				AccountName: l.GitHubAccountName,
+				RepoName:    l.RepoName,
+			}
+		}
+	case ManifestReleaseSource:
+		if l.SourceURL != "" {
+			return &ManifestSource{URL: l.SourceURL}
+		}
+	default:
+		if l.HasRepoKey() {
+			return &GitHubSource{
+				BaseURL:     l.SourceURL,
+				AccountName: l.GitHubAccountName,
+				RepoName:    l.RepoName,
+			}
+		}
+	}
+	return nil
+}
+
+// HasReleaseSource returns true if this Library is tied to a source of releases rather than being a plain local folder.
+func (l *Library) HasReleaseSource() bool {
+	return l.ReleaseSource() != nil
+}
+
 // CheckForAvailableUpgrade returns releases that can be upgraded to.
 func (l *Library) CheckForAvailableUpgrade(ctx context.Context, client *http.Client) {
 	l.lock.Lock()
 	l.upgrade = nil
 	l.lock.Unlock()
-	incompatibleFutureLibraryVersion := strconv.Itoa(gid.CurrentDataVersion + 1)
-	minimumLibraryVersion := strconv.Itoa(gid.MinimumLibraryVersion)
-	available, err := LoadReleases(ctx, client, l.GitHubAccountName, l.RepoName, l.VersionOnDisk(),
-		func(version, notes string) bool {
-			return incompatibleFutureLibraryVersion == version ||
-				txt.NaturalLess(version, minimumLibraryVersion, true) ||
-				txt.NaturalLess(incompatibleFutureLibraryVersion, version, true)
-		})
+	available, err := LoadReleasesFromSource(ctx, client, l.ReleaseSource(), l.VersionOnDisk(),
+		func(version, notes string) bool { return !IsCompatibleVersion(version) })
 	var upgrade *Release
 	if err != nil {
 		jot.Error(err)
@@ -137,13 +170,24 @@ func (l *Library) AvailableUpdate() *Release {
 	return &r
 }
 
-// Less returns true if this Library should be placed before the other Library.
+// Less returns true if this Library should be placed before the other Library. Libraries with an explicit order are
+// sorted by it. Otherwise, the User Library comes first, followed by the Master Library, followed by the rest in key
+// order.
 func (l *Library) Less(other *Library) bool {
+	if l.Order != other.Order {
+		return l.Order < other.Order
+	}
 	if l.IsUser() {
-		return true
+		return !other.IsUser()
+	}
+	if other.IsUser() {
+		return false
 	}
 	if l.IsMaster() {
-		return !other.IsUser()
+		return !other.IsMaster()
+	}
+	if other.IsMaster() {
+		return false
 	}
 	if txt.NaturalLess(l.GitHubAccountName, other.GitHubAccountName, true) {
 		return true
@@ -166,12 +210,8 @@ func (l *Library) VersionOnDisk() string {
 	return strings.TrimSpace(string(bytes.SplitN(data, []byte{'\n'}, 2)[0]))
 }
 
-// Download the release onto the local disk.
+// Download the release and install it. See InstallFromFS() for details.
 func (l *Library) Download(ctx context.Context, client *http.Client, release Release) error {
-	p := l.Path()
-	if err := os.MkdirAll(p, 0o750); err != nil {
-		return errs.NewWithCause("unable to create "+p, err)
-	}
 	data, err := l.downloadRelease(ctx, client, release)
 	if err != nil {
 		return err
@@ -180,59 +220,7 @@ func (l *Library) Download(ctx context.Context, client *http.Client, release Rel
 	if zr, err = zip.NewReader(bytes.NewReader(data), int64(len(data))); err != nil {
 		return errs.NewWithCause("unable to open archive "+release.ZipFileURL, err)
 	}
-	root := filepath.Clean(p)
-	rootWithTrailingSep := root
-	if !strings.HasSuffix(rootWithTrailingSep, string(filepath.Separator)) {
-		rootWithTrailingSep += string(filepath.Separator)
-	}
-	for _, f := range zr.File {
-		fi := f.FileInfo()
-		mode := fi.Mode()
-		if mode&os.ModeType == 0 { // normal files only
-			parts := strings.SplitN(filepath.ToSlash(f.Name), "/", 3)
-			if len(parts) != 3 {
-				continue
-			}
-			if !strings.EqualFold("Library", parts[1]) {
-				continue
-			}
-			fullPath := filepath.Join(root, parts[2])
-			if !strings.HasPrefix(fullPath, rootWithTrailingSep) {
-				return errs.Newf("path outside of root is not permitted: %s", fullPath)
-			}
-			parent := filepath.Dir(fullPath)
-			if err = os.MkdirAll(parent, 0o750); err != nil {
-				return errs.NewWithCause("unable to create "+parent, err)
-			}
-			if err = extractFile(f, fullPath); err != nil {
-				return errs.NewWithCause("unable to create "+fullPath, err)
-			}
-		}
-	}
-	f := filepath.Join(root, releaseFile)
-	if err = os.WriteFile(f, []byte(release.Version+"\n"), 0o640); err != nil {
-		return errs.NewWithCause("unable to create "+f, err)
-	}
-	return nil
-}
-
-func extractFile(f *zip.File, dst string) (err error) {
-	var r io.ReadCloser
-	if r, err = f.Open(); err != nil {
-		return errs.Wrap(err)
-	}
-	defer xio.CloseIgnoringErrors(r)
-	var file *os.File
-	if file, err = os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, f.FileInfo().Mode().Perm()&0o750); err != nil {
-		return errs.Wrap(err)
-	}
-	if _, err = io.Copy(file, r); err != nil {
-		err = errs.Wrap(err)
-	}
-	if closeErr := file.Close(); closeErr != nil && err == nil {
-		err = errs.Wrap(closeErr)
-	}
-	return
+	return l.InstallFromFS(zr, release.Version)
 }
 
 func (l *Library) downloadRelease(ctx context.Context, client *http.Client, release Release) ([]byte, error) {
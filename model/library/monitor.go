@@ -0,0 +1,191 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package library
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/richardwilkes/toolbox/errs"
+	"github.com/richardwilkes/toolbox/log/jot"
+)
+
+// Monitor watches the directories of a set of libraries for changes, coalescing bursts of file system activity into a
+// single notification.
+type Monitor struct {
+	lock     sync.Mutex
+	watcher  *fsnotify.Watcher
+	delay    time.Duration
+	callback func(changed []*Library)
+	libs     []*Library
+	watched  map[string]bool
+	pending  map[*Library]bool
+	timer    *time.Timer
+}
+
+// NewMonitor creates a new Monitor. Once file system activity within one or more of the watched libraries has been
+// quiet for the given delay, the scan cache will be cleared and the callback will be called from a background goroutine
+// with the libraries that were changed.
+func NewMonitor(delay time.Duration, callback func(changed []*Library)) (*Monitor, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, errs.NewWithCause("unable to create file system watcher", err)
+	}
+	m := &Monitor{
+		watcher:  watcher,
+		delay:    delay,
+		callback: callback,
+		watched:  make(map[string]bool),
+		pending:  make(map[*Library]bool),
+	}
+	go m.run()
+	return m, nil
+}
+
+// Watch replaces the set of libraries being watched.
+func (m *Monitor) Watch(libs Libraries) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	for p := range m.watched {
+		if err := m.watcher.Remove(p); err != nil {
+			jot.Debug(errs.NewWithCause("unable to stop watching "+p, err))
+		}
+	}
+	m.watched = make(map[string]bool)
+	m.pending = make(map[*Library]bool)
+	m.libs = libs.List()
+	for _, lib := range m.libs {
+		m.addTree(lib.Path())
+	}
+}
+
+// Stop watching for changes. The Monitor may not be used after this call.
+func (m *Monitor) Stop() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.timer != nil {
+		m.timer.Stop()
+		m.timer = nil
+	}
+	if err := m.watcher.Close(); err != nil {
+		jot.Warn(errs.NewWithCause("unable to close file system watcher", err))
+	}
+}
+
+func (m *Monitor) run() {
+	for {
+		select {
+		case event, ok := <-m.watcher.Events:
+			if !ok {
+				return
+			}
+			m.handleEvent(event)
+		case err, ok := <-m.watcher.Errors:
+			if !ok {
+				return
+			}
+			jot.Warn(errs.NewWithCause("file system watcher error", err))
+		}
+	}
+}
+
+func (m *Monitor) handleEvent(event fsnotify.Event) {
+	if event.Op == fsnotify.Chmod {
+		return
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	lib := m.libraryFor(event.Name)
+	if lib == nil {
+		return
+	}
+	if event.Op&fsnotify.Create != 0 {
+		m.addTree(event.Name)
+	}
+	if event.Op&(fsnotify.Remove|fsnotify.Rename) != 0 {
+		delete(m.watched, event.Name)
+	}
+	m.pending[lib] = true
+	if m.timer == nil {
+		m.timer = time.AfterFunc(m.delay, m.notify)
+	} else {
+		m.timer.Reset(m.delay)
+	}
+}
+
+func (m *Monitor) notify() {
+	m.lock.Lock()
+	m.timer = nil
+	changed := make([]*Library, 0, len(m.pending))
+	for _, lib := range m.libs {
+		if m.pending[lib] {
+			changed = append(changed, lib)
+		}
+	}
+	m.pending = make(map[*Library]bool)
+	m.lock.Unlock()
+	if len(changed) != 0 {
+		ClearScanCache()
+		m.callback(changed)
+	}
+}
+
+// libraryFor returns the library that contains the given path. The lock must be held when calling this.
+func (m *Monitor) libraryFor(p string) *Library {
+	var found *Library
+	var foundLen int
+	for _, lib := range m.libs {
+		root := filepath.Clean(lib.PathOnDisk)
+		if p == root || strings.HasPrefix(p, root+string(filepath.Separator)) {
+			// Nested libraries are permitted, so prefer the most specific match
+			if found == nil || len(root) > foundLen {
+				found = lib
+				foundLen = len(root)
+			}
+		}
+	}
+	return found
+}
+
+// addTree adds a watch for the given directory and all of its non-hidden sub-directories. The lock must be held when
+// calling this.
+func (m *Monitor) addTree(root string) {
+	if err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if p == root {
+				return err
+			}
+			return nil
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if p != root && strings.HasPrefix(d.Name(), ".") {
+			return filepath.SkipDir
+		}
+		if !m.watched[p] {
+			if err = m.watcher.Add(p); err != nil {
+				jot.Warn(errs.NewWithCause("unable to watch "+p, err))
+				return nil
+			}
+			m.watched[p] = true
+		}
+		return nil
+	}); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		jot.Warn(errs.NewWithCause("unable to watch "+root, err))
+	}
+}
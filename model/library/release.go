@@ -15,15 +15,11 @@ import (
 	"context"
 	"net/http"
 	"sort"
-	"strings"
 
-	"github.com/richardwilkes/json"
-	"github.com/richardwilkes/toolbox/errs"
 	"github.com/richardwilkes/toolbox/txt"
-	"github.com/richardwilkes/toolbox/xio"
 )
 
-// Release holds information about a single release of a GitHub repo.
+// Release holds information about a single release of a library or the application.
 type Release struct {
 	Version     string
 	Notes       string
@@ -41,39 +37,28 @@ func LoadReleases(ctx context.Context, client *http.Client, githubAccountName, r
 	if githubAccountName == "" || githubAccountName == "*" || repoName == "" {
 		return nil, nil
 	}
-	var versions []Release
-	uri := "https://api.github.com/repos/" + githubAccountName + "/" + repoName + "/releases"
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, http.NoBody)
-	if err != nil {
-		return nil, errs.NewWithCause("unable to create GitHub API request "+uri, err)
-	}
-	var rsp *http.Response
-	if rsp, err = client.Do(req); err != nil {
-		return nil, errs.NewWithCause("GitHub API request failed "+uri, err)
-	}
-	defer xio.DiscardAndCloseIgnoringErrors(rsp.Body)
-	if rsp.StatusCode < 200 || rsp.StatusCode > 299 {
-		return nil, errs.New("unexpected response code from GitHub API " + uri + " -> " + rsp.Status)
-	}
-	var releases []struct {
-		TagName    string `json:"tag_name"`
-		Body       string `json:"body"`
-		ZipBallURL string `json:"zipball_url"`
+	return LoadReleasesFromSource(ctx, client, &GitHubSource{
+		AccountName: githubAccountName,
+		RepoName:    repoName,
+	}, currentVersion, filter)
+}
+
+// LoadReleasesFromSource loads the list of releases available from a ReleaseSource that are at least as new as the
+// current version, newest first. Releases for which the filter returns true are omitted. The release matching the
+// current version is only included if there are no newer releases.
+func LoadReleasesFromSource(ctx context.Context, client *http.Client, source ReleaseSource, currentVersion string, filter func(version, notes string) bool) ([]Release, error) {
+	if source == nil {
+		return nil, nil
 	}
-	if err = json.NewDecoder(rsp.Body).Decode(&releases); err != nil {
-		return nil, errs.NewWithCause("unable to decode response from GitHub API "+uri, err)
+	releases, err := source.FetchReleases(ctx, client)
+	if err != nil {
+		return nil, err
 	}
+	var versions []Release
 	for _, one := range releases {
-		if strings.HasPrefix(one.TagName, "v") {
-			if version := strings.TrimSpace(one.TagName[1:]); version != "" &&
-				(currentVersion == version || txt.NaturalLess(currentVersion, version, true)) {
-				if filter == nil || !filter(version, one.Body) {
-					versions = append(versions, Release{
-						Version:    version,
-						Notes:      one.Body,
-						ZipFileURL: one.ZipBallURL,
-					})
-				}
+		if currentVersion == one.Version || txt.NaturalLess(currentVersion, one.Version, true) {
+			if filter == nil || !filter(one.Version, one.Notes) {
+				versions = append(versions, one)
 			}
 		}
 	}
@@ -0,0 +1,163 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package library
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/richardwilkes/json"
+	"github.com/richardwilkes/toolbox/errs"
+	"github.com/richardwilkes/toolbox/xio"
+)
+
+// DefaultGitHubAPIURL is the base URL used for the GitHub API when one isn't otherwise specified.
+const DefaultGitHubAPIURL = "https://api.github.com"
+
+// ReleaseSource provides the list of releases from a server.
+type ReleaseSource interface {
+	// String returns a description of the source, suitable for use in error messages.
+	String() string
+	// FetchReleases returns all releases the source knows about, in no particular order and without filtering.
+	FetchReleases(ctx context.Context, client *http.Client) ([]Release, error)
+}
+
+// GitHubSource retrieves releases using the GitHub API.
+type GitHubSource struct {
+	// BaseURL is the base URL of the API. If empty, DefaultGitHubAPIURL will be used.
+	BaseURL     string
+	AccountName string
+	RepoName    string
+}
+
+func (s *GitHubSource) String() string {
+	return "GitHub " + s.AccountName + "/" + s.RepoName
+}
+
+// FetchReleases implements ReleaseSource.
+func (s *GitHubSource) FetchReleases(ctx context.Context, client *http.Client) ([]Release, error) {
+	base := s.BaseURL
+	if base == "" {
+		base = DefaultGitHubAPIURL
+	}
+	return fetchRepoReleases(ctx, client, strings.TrimSuffix(base, "/")+"/repos/"+s.AccountName+"/"+s.RepoName+
+		"/releases", s.String())
+}
+
+// GiteaSource retrieves releases using the Gitea API, which Forgejo also provides.
+type GiteaSource struct {
+	// BaseURL is the base URL of the server, e.g. https://gitea.example.com
+	BaseURL     string
+	AccountName string
+	RepoName    string
+}
+
+func (s *GiteaSource) String() string {
+	return "Gitea " + s.BaseURL + " " + s.AccountName + "/" + s.RepoName
+}
+
+// FetchReleases implements ReleaseSource.
+func (s *GiteaSource) FetchReleases(ctx context.Context, client *http.Client) ([]Release, error) {
+	return fetchRepoReleases(ctx, client, strings.TrimSuffix(s.BaseURL, "/")+"/api/v1/repos/"+s.AccountName+"/"+
+		s.RepoName+"/releases", s.String())
+}
+
+// ManifestSource retrieves releases from a static JSON file. The file must contain an array of objects, each with a
+// "version", optional "notes" and a "zip_url" field. A zip_url that is relative is resolved against the URL of the
+// manifest.
+type ManifestSource struct {
+	URL string
+}
+
+func (s *ManifestSource) String() string {
+	return "manifest " + s.URL
+}
+
+// FetchReleases implements ReleaseSource.
+func (s *ManifestSource) FetchReleases(ctx context.Context, client *http.Client) ([]Release, error) {
+	base, err := url.Parse(s.URL)
+	if err != nil {
+		return nil, errs.NewWithCause("invalid manifest URL "+s.URL, err)
+	}
+	var entries []struct {
+		Version string `json:"version"`
+		Notes   string `json:"notes"`
+		ZipURL  string `json:"zip_url"`
+	}
+	if err = fetchJSON(ctx, client, s.URL, s.String(), &entries); err != nil {
+		return nil, err
+	}
+	releases := make([]Release, 0, len(entries))
+	for _, one := range entries {
+		version := strings.TrimPrefix(strings.TrimSpace(one.Version), "v")
+		if version == "" || one.ZipURL == "" {
+			continue
+		}
+		var ref *url.URL
+		if ref, err = url.Parse(one.ZipURL); err != nil {
+			return nil, errs.NewWithCause("invalid zip_url in "+s.String(), err)
+		}
+		releases = append(releases, Release{
+			Version:    version,
+			Notes:      one.Notes,
+			ZipFileURL: base.ResolveReference(ref).String(),
+		})
+	}
+	return releases, nil
+}
+
+// fetchRepoReleases retrieves releases from an API that provides the GitHub JSON layout. Only those with tags of the
+// form "v<version>" are returned.
+func fetchRepoReleases(ctx context.Context, client *http.Client, uri, desc string) ([]Release, error) {
+	var entries []struct {
+		TagName    string `json:"tag_name"`
+		Body       string `json:"body"`
+		ZipBallURL string `json:"zipball_url"`
+	}
+	if err := fetchJSON(ctx, client, uri, desc, &entries); err != nil {
+		return nil, err
+	}
+	releases := make([]Release, 0, len(entries))
+	for _, one := range entries {
+		if strings.HasPrefix(one.TagName, "v") {
+			if version := strings.TrimSpace(one.TagName[1:]); version != "" {
+				releases = append(releases, Release{
+					Version:    version,
+					Notes:      one.Body,
+					ZipFileURL: one.ZipBallURL,
+				})
+			}
+		}
+	}
+	return releases, nil
+}
+
+func fetchJSON(ctx context.Context, client *http.Client, uri, desc string, data any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, http.NoBody)
+	if err != nil {
+		return errs.NewWithCause("unable to create request for "+desc+": "+uri, err)
+	}
+	var rsp *http.Response
+	if rsp, err = client.Do(req); err != nil {
+		return errs.NewWithCause("request failed for "+desc+": "+uri, err)
+	}
+	defer xio.DiscardAndCloseIgnoringErrors(rsp.Body)
+	if rsp.StatusCode < 200 || rsp.StatusCode > 299 {
+		return errs.New("unexpected response code from " + desc + ": " + uri + " -> " + rsp.Status)
+	}
+	if err = json.NewDecoder(rsp.Body).Decode(data); err != nil {
+		return errs.NewWithCause("unable to decode response from "+desc+": "+uri, err)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package library_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/richardwilkes/gcs/model/library"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const repoReleasesJSON = `[
+	{"tag_name": "v4.1.0", "body": "Newer", "zipball_url": "https://example.com/4.1.0.zip"},
+	{"tag_name": "v4.0.0", "body": "Current", "zipball_url": "https://example.com/4.0.0.zip"},
+	{"tag_name": "v3.9.0", "body": "Older", "zipball_url": "https://example.com/3.9.0.zip"},
+	{"tag_name": "nightly", "body": "Ignored", "zipball_url": "https://example.com/nightly.zip"}
+]`
+
+func newReleaseServer(t *testing.T, path, body string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != path {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestGitHubSource(t *testing.T) {
+	server := newReleaseServer(t, "/repos/acct/repo/releases", repoReleasesJSON)
+	source := &library.GitHubSource{BaseURL: server.URL, AccountName: "acct", RepoName: "repo"}
+	releases, err := library.LoadReleasesFromSource(context.Background(), server.Client(), source, "4.0.0", nil)
+	require.NoError(t, err)
+	require.Len(t, releases, 1)
+	assert.Equal(t, "4.1.0", releases[0].Version)
+	assert.Equal(t, "Newer", releases[0].Notes)
+	assert.Equal(t, "https://example.com/4.1.0.zip", releases[0].ZipFileURL)
+}
+
+func TestGiteaSource(t *testing.T) {
+	server := newReleaseServer(t, "/api/v1/repos/acct/repo/releases", repoReleasesJSON)
+	source := &library.GiteaSource{BaseURL: server.URL + "/", AccountName: "acct", RepoName: "repo"}
+	releases, err := library.LoadReleasesFromSource(context.Background(), server.Client(), source, "3.9.0",
+		func(version, _ string) bool { return version == "4.1.0" })
+	require.NoError(t, err)
+	require.Len(t, releases, 1)
+	assert.Equal(t, "4.0.0", releases[0].Version)
+}
+
+func TestManifestSource(t *testing.T) {
+	server := newReleaseServer(t, "/libs/manifest.json", `[
+		{"version": "v4.2", "notes": "Relative", "zip_url": "archives/4.2.zip"},
+		{"version": "4.1", "zip_url": "https://mirror.example.com/4.1.zip"},
+		{"version": "", "zip_url": "missing-version.zip"}
+	]`)
+	source := &library.ManifestSource{URL: server.URL + "/libs/manifest.json"}
+	releases, err := library.LoadReleasesFromSource(context.Background(), server.Client(), source, "4.2", nil)
+	require.NoError(t, err)
+	require.Len(t, releases, 1)
+	assert.Equal(t, "4.2", releases[0].Version)
+	assert.Equal(t, server.URL+"/libs/archives/4.2.zip", releases[0].ZipFileURL)
+
+	releases, err = library.LoadReleasesFromSource(context.Background(), server.Client(), source, "4.0", nil)
+	require.NoError(t, err)
+	require.Len(t, releases, 2)
+	assert.Equal(t, "4.2", releases[0].Version)
+	assert.Equal(t, "https://mirror.example.com/4.1.zip", releases[1].ZipFileURL)
+}
+
+func TestReleaseSourceErrors(t *testing.T) {
+	server := newReleaseServer(t, "/elsewhere", "[]")
+	source := &library.ManifestSource{URL: server.URL + "/manifest.json"}
+	_, err := library.LoadReleasesFromSource(context.Background(), server.Client(), source, "4", nil)
+	assert.Error(t, err)
+
+	server = newReleaseServer(t, "/manifest.json", "not json")
+	source = &library.ManifestSource{URL: server.URL + "/manifest.json"}
+	_, err = library.LoadReleasesFromSource(context.Background(), server.Client(), source, "4", nil)
+	assert.Error(t, err)
+}
+
+func TestLibraryReleaseSource(t *testing.T) {
+	libs := library.NewLibraries()
+	assert.IsType(t, &library.GitHubSource{}, libs.Master().ReleaseSource())
+	assert.Nil(t, libs.User().ReleaseSource())
+
+	lib := &library.Library{SourceType: library.GiteaReleaseSource}
+	lib.ConfigureForKey("acct/repo")
+	assert.Nil(t, lib.ReleaseSource())
+	lib.SourceURL = "https://gitea.example.com"
+	assert.Equal(t, &library.GiteaSource{BaseURL: "https://gitea.example.com", AccountName: "acct", RepoName: "repo"},
+		lib.ReleaseSource())
+
+	lib = &library.Library{SourceType: library.ManifestReleaseSource, SourceURL: "https://example.com/m.json"}
+	lib.ConfigureForKey(libs.NewLocalKey("Campaign"))
+	assert.True(t, lib.HasReleaseSource())
+	assert.False(t, lib.HasRepoKey())
+}
@@ -0,0 +1,62 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package library
+
+import (
+	"github.com/richardwilkes/toolbox/i18n"
+)
+
+// Possible ReleaseSourceType values.
+const (
+	GitHubReleaseSource   = ReleaseSourceType("github")
+	GiteaReleaseSource    = ReleaseSourceType("gitea")
+	ManifestReleaseSource = ReleaseSourceType("manifest")
+)
+
+// AllReleaseSourceTypes is the complete set of ReleaseSourceType values.
+var AllReleaseSourceTypes = []ReleaseSourceType{
+	GitHubReleaseSource,
+	GiteaReleaseSource,
+	ManifestReleaseSource,
+}
+
+// ReleaseSourceType holds the type of server that provides the releases for a library.
+type ReleaseSourceType string
+
+// EnsureValid ensures this is of a known value.
+func (r ReleaseSourceType) EnsureValid() ReleaseSourceType {
+	for _, one := range AllReleaseSourceTypes {
+		if one == r {
+			return r
+		}
+	}
+	return AllReleaseSourceTypes[0]
+}
+
+// UsesRepo returns true if this type of source identifies releases by an account and repository name.
+func (r ReleaseSourceType) UsesRepo() bool {
+	return r.EnsureValid() != ManifestReleaseSource
+}
+
+// String implements fmt.Stringer.
+func (r ReleaseSourceType) String() string {
+	switch r {
+	case GitHubReleaseSource:
+		return i18n.Text("GitHub Releases")
+	case GiteaReleaseSource:
+		return i18n.Text("Gitea/Forgejo Releases")
+	case ManifestReleaseSource:
+		return i18n.Text("Release Manifest")
+	default:
+		return GitHubReleaseSource.String()
+	}
+}
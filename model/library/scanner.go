@@ -16,7 +16,9 @@ import (
 	"io/fs"
 	"os"
 	"path"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/richardwilkes/toolbox/errs"
 	"github.com/richardwilkes/toolbox/i18n"
@@ -24,6 +26,11 @@ import (
 	xfs "github.com/richardwilkes/toolbox/xio/fs"
 )
 
+var (
+	scanCacheLock sync.Mutex
+	scanCache     = make(map[string][]*NamedFileSet)
+)
+
 // NamedFileRef holds a reference to a file.
 type NamedFileRef struct {
 	Name       string
@@ -41,8 +48,48 @@ type NamedFileSet struct {
 	List []*NamedFileRef
 }
 
-// ScanForNamedFileSets scans for settings files of a particular type.
+// ScanForNamedFileSets scans for settings files of a particular type. Libraries are scanned in the order returned by
+// Libraries.List(), followed by the built-in files, if any. When omitDuplicateNames is true, a file whose name matches
+// one already found in an earlier library is skipped, so earlier libraries take precedence. Results are cached until
+// ClearScanCache() is called.
 func ScanForNamedFileSets(builtIn fs.FS, builtInDir, extension string, omitDuplicateNames bool, libraries Libraries) []*NamedFileSet {
+	key := scanCacheKey(builtIn != nil, builtInDir, extension, omitDuplicateNames, libraries)
+	scanCacheLock.Lock()
+	list, ok := scanCache[key]
+	scanCacheLock.Unlock()
+	if !ok {
+		list = scanForNamedFileSetsInLibraries(builtIn, builtInDir, extension, omitDuplicateNames, libraries)
+		scanCacheLock.Lock()
+		scanCache[key] = list
+		scanCacheLock.Unlock()
+	}
+	return list
+}
+
+// ClearScanCache discards any results cached by ScanForNamedFileSets().
+func ClearScanCache() {
+	scanCacheLock.Lock()
+	scanCache = make(map[string][]*NamedFileSet)
+	scanCacheLock.Unlock()
+}
+
+func scanCacheKey(hasBuiltIn bool, builtInDir, extension string, omitDuplicateNames bool, libraries Libraries) string {
+	var buffer strings.Builder
+	buffer.WriteString(strconv.FormatBool(hasBuiltIn))
+	buffer.WriteByte(0)
+	buffer.WriteString(builtInDir)
+	buffer.WriteByte(0)
+	buffer.WriteString(strings.ToLower(extension))
+	buffer.WriteByte(0)
+	buffer.WriteString(strconv.FormatBool(omitDuplicateNames))
+	for _, lib := range libraries.List() {
+		buffer.WriteByte(0)
+		buffer.WriteString(lib.PathOnDisk)
+	}
+	return buffer.String()
+}
+
+func scanForNamedFileSetsInLibraries(builtIn fs.FS, builtInDir, extension string, omitDuplicateNames bool, libraries Libraries) []*NamedFileSet {
 	set := make(map[string]bool)
 	list := make([]*NamedFileSet, 0)
 	for _, lib := range libraries.List() {
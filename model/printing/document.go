@@ -0,0 +1,329 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package printing
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/richardwilkes/gcs/model/gurps/settings"
+	"github.com/richardwilkes/toolbox/cmdline"
+	"github.com/richardwilkes/toolbox/errs"
+	"github.com/richardwilkes/toolbox/i18n"
+	"github.com/richardwilkes/toolbox/xio/fs/safe"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	bodySize         = 8
+	secondarySize    = 7
+	headingSize      = 10
+	titleSize        = 9
+	decorationSize   = 7
+	lineSpacing      = 1.2
+	blockSpacing     = 10
+	cellHPadding     = 3
+	cellVPadding     = 1.5
+	indentPerLevel   = 10
+	headerHeight     = 18
+	footerHeight     = 16
+	ruleThickness    = 0.5
+	headingGray      = 0.8
+	headerRowGray    = 0.9
+	stripeGray       = 0.95
+	dimGray          = 0.5
+	minLinesAfterTop = 3
+)
+
+// Options holds the page geometry and the text used to decorate each page. All measurements are in points.
+type Options struct {
+	// Title is shown at the top of each page and is used as the title of the PDF document.
+	Title string
+	// Note is shown at the top of each page, opposite the title.
+	Note         string
+	Width        float32
+	Height       float32
+	TopMargin    float32
+	LeftMargin   float32
+	BottomMargin float32
+	RightMargin  float32
+	// Created is recorded as the creation time of the PDF document.
+	Created time.Time
+}
+
+// OptionsFromPage returns Options using the paper size, orientation and margins from the page settings.
+func OptionsFromPage(pageSettings *settings.Page, title string) Options {
+	width, height := pageSettings.Orientation.Dimensions(pageSettings.Size.Dimensions())
+	return Options{
+		Title:        title,
+		Width:        width.Pixels(),
+		Height:       height.Pixels(),
+		TopMargin:    pageSettings.TopMargin.Pixels(),
+		LeftMargin:   pageSettings.LeftMargin.Pixels(),
+		BottomMargin: pageSettings.BottomMargin.Pixels(),
+		RightMargin:  pageSettings.RightMargin.Pixels(),
+		Created:      time.Now(),
+	}
+}
+
+// Field holds a labeled value.
+type Field struct {
+	Label string
+	Value string
+}
+
+// Document lays out content across as many pages as are needed to hold it.
+type Document struct {
+	opts    Options
+	pages   []*page
+	current *page
+	y       float32
+	// headed is true when the most recent content added was a heading, which should not be separated from the content
+	// that follows it.
+	headed  bool
+	missing map[rune]bool
+}
+
+// NewDocument creates a new, empty document.
+func NewDocument(opts Options) *Document {
+	return &Document{
+		opts:    opts,
+		missing: make(map[rune]bool),
+	}
+}
+
+// PageCount returns the number of pages the content currently occupies.
+func (d *Document) PageCount() int {
+	return len(d.pages)
+}
+
+func (d *Document) contentLeft() float32 {
+	return d.opts.LeftMargin
+}
+
+func (d *Document) contentWidth() float32 {
+	return d.opts.Width - (d.opts.LeftMargin + d.opts.RightMargin)
+}
+
+func (d *Document) contentTop() float32 {
+	return d.opts.TopMargin + headerHeight
+}
+
+func (d *Document) contentBottom() float32 {
+	return d.opts.Height - (d.opts.BottomMargin + footerHeight)
+}
+
+func (d *Document) newPage() {
+	d.current = &page{
+		height:  d.opts.Height,
+		missing: d.missing,
+	}
+	d.pages = append(d.pages, d.current)
+	d.y = d.contentTop()
+}
+
+// ensureSpace starts a new page if the space remaining on the current one is less than the height requested. Returns
+// true if a new page was started.
+func (d *Document) ensureSpace(height float32) bool {
+	if d.current == nil || (d.y+height > d.contentBottom() && d.y > d.contentTop()) {
+		d.newPage()
+		return true
+	}
+	return false
+}
+
+// beginBlock adds the spacing that separates blocks of content.
+func (d *Document) beginBlock() {
+	if d.headed {
+		d.headed = false
+		return
+	}
+	if d.current != nil && d.y > d.contentTop() {
+		d.y += blockSpacing
+	}
+}
+
+func lineHeight(size float32) float32 {
+	return size * lineSpacing
+}
+
+// Heading adds a section heading.
+func (d *Document) Heading(title string) {
+	d.beginBlock()
+	d.ensureSpace(headingHeight() + lineHeight(bodySize)*minLinesAfterTop)
+	d.drawHeading(title)
+	d.headed = true
+}
+
+func headingHeight() float32 {
+	return lineHeight(headingSize) + 2*cellVPadding
+}
+
+func (d *Document) drawHeading(title string) {
+	height := headingHeight()
+	d.current.setGray(headingGray)
+	d.current.fillRect(d.contentLeft(), d.y, d.contentWidth(), height)
+	d.current.setGray(0)
+	d.current.text(d.contentLeft()+cellHPadding, d.y+cellVPadding+headingSize, BoldFont, headingSize,
+		BoldFont.truncate(title, headingSize, d.contentWidth()-2*cellHPadding))
+	d.y += height
+}
+
+// Paragraph adds a block of text, wrapped to fit the page width.
+func (d *Document) Paragraph(text string) {
+	d.beginBlock()
+	d.ensureSpace(lineHeight(bodySize))
+	for _, line := range RegularFont.wrap(text, bodySize, d.contentWidth()) {
+		d.ensureSpace(lineHeight(bodySize))
+		d.current.text(d.contentLeft(), d.y+bodySize, RegularFont, bodySize, line)
+		d.y += lineHeight(bodySize)
+	}
+}
+
+// Fields adds a grid of labeled values, filling each row across the requested number of columns before moving on to
+// the next row. Fields with an empty value are omitted.
+func (d *Document) Fields(columns int, fields []Field) {
+	list := make([]Field, 0, len(fields))
+	for _, f := range fields {
+		if f.Value != "" {
+			list = append(list, f)
+		}
+	}
+	if len(list) == 0 {
+		return
+	}
+	if columns < 1 {
+		columns = 1
+	}
+	d.beginBlock()
+	columnWidth := d.contentWidth() / float32(columns)
+	labelWidths := make([]float32, columns)
+	for i, f := range list {
+		if w := BoldFont.Width(f.Label, bodySize); w > labelWidths[i%columns] {
+			labelWidths[i%columns] = w
+		}
+	}
+	for i := range labelWidths {
+		if labelWidths[i] > columnWidth/2 {
+			labelWidths[i] = columnWidth / 2
+		}
+	}
+	lh := lineHeight(bodySize)
+	for start := 0; start < len(list); start += columns {
+		end := start + columns
+		if end > len(list) {
+			end = len(list)
+		}
+		wrapped := make([][]string, end-start)
+		rowLines := 1
+		for i := start; i < end; i++ {
+			col := i - start
+			wrapped[col] = RegularFont.wrap(list[i].Value, bodySize, columnWidth-labelWidths[col]-2*cellHPadding)
+			if len(wrapped[col]) > rowLines {
+				rowLines = len(wrapped[col])
+			}
+		}
+		d.ensureSpace(lh * float32(rowLines))
+		for i := start; i < end; i++ {
+			col := i - start
+			x := d.contentLeft() + columnWidth*float32(col)
+			d.current.text(x, d.y+bodySize, BoldFont, bodySize, BoldFont.truncate(list[i].Label, bodySize,
+				labelWidths[col]))
+			for j, line := range wrapped[col] {
+				d.current.text(x+labelWidths[col]+cellHPadding, d.y+bodySize+lh*float32(j), RegularFont, bodySize, line)
+			}
+		}
+		d.y += lh * float32(rowLines)
+	}
+}
+
+// MissingCharacters returns the characters within the document that the fonts used for printing cannot represent, in
+// ascending order. Each was replaced with a question mark. Since the page decorations are only added once the document
+// is written, this should be called after WriteTo(), Bytes() or Save().
+func (d *Document) MissingCharacters() []rune {
+	list := make([]rune, 0, len(d.missing))
+	for r := range d.missing {
+		list = append(list, r)
+	}
+	slices.Sort(list)
+	return list
+}
+
+// WriteTo writes the document as a PDF.
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	if d.current == nil {
+		d.newPage()
+	}
+	d.decorate()
+	var buffer bytes.Buffer
+	if err := writePDF(&buffer, d.pages, d.opts.Width, d.opts.Height, d.opts.Title, d.opts.Created); err != nil {
+		return 0, err
+	}
+	n, err := w.Write(buffer.Bytes())
+	return int64(n), errs.Wrap(err)
+}
+
+// Bytes returns the document as a PDF.
+func (d *Document) Bytes() ([]byte, error) {
+	var buffer bytes.Buffer
+	if _, err := d.WriteTo(&buffer); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// Save writes the document as a PDF to the file.
+func (d *Document) Save(filePath string) error {
+	data, err := d.Bytes()
+	if err != nil {
+		return err
+	}
+	return safe.WriteFileWithMode(filePath, func(w io.Writer) error {
+		_, wErr := w.Write(data)
+		return errs.Wrap(wErr)
+	}, 0o640)
+}
+
+// decorate adds the header and footer to each page. Since the footer contains the total page count, this can only be
+// done once all content has been laid out. Calling this more than once has no additional effect.
+func (d *Document) decorate() {
+	for i, p := range d.pages {
+		if p.decorated {
+			continue
+		}
+		p.decorated = true
+		left := d.contentLeft()
+		right := left + d.contentWidth()
+		width := d.contentWidth()
+
+		y := d.opts.TopMargin
+		noteWidth := RegularFont.Width(d.opts.Note, decorationSize)
+		p.text(left, y+titleSize, BoldFont, titleSize, BoldFont.truncate(d.opts.Title, titleSize,
+			width-noteWidth-2*cellHPadding))
+		p.text(right-noteWidth, y+titleSize, RegularFont, decorationSize, d.opts.Note)
+		p.line(left, y+headerHeight-4, right, y+headerHeight-4, ruleThickness)
+
+		y = d.opts.Height - (d.opts.BottomMargin + footerHeight)
+		p.line(left, y+4, right, y+4, ruleThickness)
+		y += 4 + decorationSize + 4
+		outer := fmt.Sprintf(i18n.Text("%s is copyrighted ©%s by %s"), cmdline.AppName,
+			cmdline.ResolveCopyrightYears(), cmdline.CopyrightHolder)
+		pageNumber := fmt.Sprintf(i18n.Text("Page %d of %d"), i+1, len(d.pages))
+		if i&1 == 1 {
+			outer, pageNumber = pageNumber, outer
+		}
+		p.text(left, y, RegularFont, decorationSize, outer)
+		p.text(right-RegularFont.Width(pageNumber, decorationSize), y, RegularFont, decorationSize, pageNumber)
+	}
+}
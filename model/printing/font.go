@@ -0,0 +1,206 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package printing
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Font identifies one of the standard PDF fonts used for printing. These fonts are built into every PDF reader, so
+// nothing needs to be embedded in the output.
+type Font uint8
+
+// Possible Font values.
+const (
+	RegularFont Font = iota
+	BoldFont
+)
+
+// Widths of the printable ASCII characters (space through tilde), in 1/1000ths of the font size, taken from the Adobe
+// font metrics for the standard fonts.
+var (
+	regularWidths = [95]uint16{
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+		1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+		333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+		556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+	}
+	boldWidths = [95]uint16{
+		278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+		975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+		333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+		611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+	}
+)
+
+// winAnsi maps the characters in the 0x80-0x9F range of the WinAnsiEncoding to their byte values. Characters in the
+// 0xA0-0xFF range share their values with Latin-1, so need no mapping.
+var winAnsi = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87, 'ˆ': 0x88, '‰': 0x89, 'Š': 0x8A,
+	'‹': 0x8B, 'Œ': 0x8C, 'Ž': 0x8E, '‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97,
+	'˜': 0x98, '™': 0x99, 'š': 0x9A, '›': 0x9B, 'œ': 0x9C, 'ž': 0x9E, 'Ÿ': 0x9F,
+}
+
+// encode converts the text into the WinAnsiEncoding used by the fonts. Characters that cannot be represented are
+// replaced with a question mark and, if missing is not nil, recorded in it.
+func encode(text string, missing map[rune]bool) []byte {
+	buffer := make([]byte, 0, len(text))
+	for _, r := range text {
+		switch {
+		case r == '\t':
+			buffer = append(buffer, ' ')
+		case r >= ' ' && r <= '~':
+			buffer = append(buffer, byte(r))
+		case r >= 0xA0 && r <= 0xFF:
+			buffer = append(buffer, byte(r))
+		default:
+			if b, ok := winAnsi[r]; ok {
+				buffer = append(buffer, b)
+			} else if r >= ' ' {
+				buffer = append(buffer, '?')
+				if missing != nil {
+					missing[r] = true
+				}
+			}
+		}
+	}
+	return buffer
+}
+
+func (f Font) charWidth(ch byte) uint16 {
+	if ch >= ' ' && ch <= '~' {
+		if f == BoldFont {
+			return boldWidths[ch-' ']
+		}
+		return regularWidths[ch-' ']
+	}
+	switch ch {
+	case 0x85, 0x97, 0x99:
+		return 1000
+	case 0x91, 0x92:
+		if f == BoldFont {
+			return 278
+		}
+		return 222
+	case 0x93, 0x94:
+		if f == BoldFont {
+			return 500
+		}
+		return 333
+	case 0x95:
+		return 350
+	case 0xA0:
+		return 278
+	case 0xB0:
+		return 400
+	case 0xB1, 0xD7:
+		return 584
+	case 0xBC, 0xBD, 0xBE:
+		return 834
+	}
+	if ch >= 0xC0 && ch <= 0xDE {
+		return 722
+	}
+	return 556
+}
+
+// Width returns the width of the text when drawn in this font at the given size.
+func (f Font) Width(text string, size float32) float32 {
+	var total int
+	for _, ch := range encode(text, nil) {
+		total += int(f.charWidth(ch))
+	}
+	return float32(total) * size / 1000
+}
+
+// resourceName returns the name used to refer to the font within a page's content.
+func (f Font) resourceName() string {
+	if f == BoldFont {
+		return "F2"
+	}
+	return "F1"
+}
+
+// baseFont returns the PostScript name of the font.
+func (f Font) baseFont() string {
+	if f == BoldFont {
+		return "Helvetica-Bold"
+	}
+	return "Helvetica"
+}
+
+// wrap breaks the text into lines that fit within the width when drawn in this font at the given size. Embedded line
+// feeds always start a new line.
+func (f Font) wrap(text string, size, width float32) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(strings.ReplaceAll(text, "\r", ""), "\n") {
+		var line string
+		for _, word := range strings.Fields(paragraph) {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+			if f.Width(candidate, size) <= width {
+				line = candidate
+				continue
+			}
+			if line != "" {
+				lines = append(lines, line)
+			}
+			for f.Width(word, size) > width {
+				i := f.fittingPrefix(word, size, width)
+				lines = append(lines, word[:i])
+				word = word[i:]
+			}
+			line = word
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// fittingPrefix returns the byte length of the longest prefix of the text that fits within the width, which will
+// always include at least one character.
+func (f Font) fittingPrefix(text string, size, width float32) int {
+	end := 0
+	for i := range text {
+		if i != 0 && f.Width(text[:i], size) > width {
+			break
+		}
+		end = i
+	}
+	if end == 0 {
+		_, end = utf8.DecodeRuneInString(text)
+	}
+	return end
+}
+
+// truncate shortens the text with an ellipsis, if needed, so that it fits within the width.
+func (f Font) truncate(text string, size, width float32) string {
+	if f.Width(text, size) <= width {
+		return text
+	}
+	const ellipsis = "…"
+	for i := len(text) - 1; i > 0; i-- {
+		if utf8.RuneStart(text[i]) {
+			if candidate := text[:i] + ellipsis; f.Width(candidate, size) <= width {
+				return candidate
+			}
+		}
+	}
+	return ellipsis
+}
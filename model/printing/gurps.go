@@ -0,0 +1,462 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package printing
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/richardwilkes/gcs/model/gurps"
+	"github.com/richardwilkes/gcs/model/gurps/attribute"
+	"github.com/richardwilkes/gcs/model/gurps/weapon"
+	"github.com/richardwilkes/toolbox/i18n"
+	"github.com/richardwilkes/unison"
+)
+
+// nodeColumn pairs a column of a node's cell data with the column's presentation.
+type nodeColumn struct {
+	Column
+	id int
+}
+
+// Sheet lays out a character sheet, using the page settings of the sheet. The blocks of lists are printed in the
+// order specified by the sheet's block layout.
+func Sheet(entity *gurps.Entity) *Document {
+	title := entity.Profile.Name
+	if gurps.SheetSettingsFor(entity).UseTitleInFooter && entity.Profile.Title != "" {
+		title = entity.Profile.Title
+	}
+	if title == "" {
+		title = i18n.Text("Unnamed Character")
+	}
+	opts := OptionsFromPage(gurps.SheetSettingsFor(entity).Page, title)
+	opts.Note = fmt.Sprintf(i18n.Text("Modified %s"), entity.ModifiedOn)
+	d := NewDocument(opts)
+	d.Heading(i18n.Text("Identity & Description"))
+	d.Fields(4, profileFields(entity))
+	d.Heading(fmt.Sprintf(i18n.Text("%s Points"), entity.TotalPoints.String()))
+	d.Fields(5, pointsFields(entity))
+	d.Table(attributesTable(entity))
+	d.Fields(4, physicalFields(entity))
+	for _, row := range gurps.SheetSettingsFor(entity).BlockLayout.ByRow() {
+		for _, key := range row {
+			if t := sheetBlock(entity, key); t != nil && len(t.Rows) != 0 {
+				d.Table(t)
+			}
+		}
+	}
+	return d
+}
+
+// Template lays out a template, using the default page settings.
+func Template(tmpl *gurps.Template, title string) *Document {
+	d := NewDocument(OptionsFromPage(gurps.SheetSettingsFor(nil).Page, title))
+	for _, t := range []*Table{
+		nodeTable(i18n.Text("Traits"), traitPageColumns(), tmpl.Traits),
+		nodeTable(i18n.Text("Skills"), skillPageColumns(false), tmpl.Skills),
+		nodeTable(i18n.Text("Spells"), spellPageColumns(false), tmpl.Spells),
+		nodeTable(i18n.Text("Equipment"), equipmentPageColumns(false, i18n.Text("Equipment")), tmpl.Equipment),
+		nodeTable(i18n.Text("Notes"), noteColumns(), tmpl.Notes),
+	} {
+		if len(t.Rows) != 0 {
+			d.Table(t)
+		}
+	}
+	if d.PageCount() == 0 {
+		d.Paragraph(i18n.Text("This template is empty."))
+	}
+	return d
+}
+
+// List lays out the contents of a list, using the default page settings.
+func List[T gurps.NodeConstraint[T]](title string, rows []T) *Document {
+	d := NewDocument(OptionsFromPage(gurps.SheetSettingsFor(nil).Page, title))
+	d.Table(ListTable(rows))
+	return d
+}
+
+// ListTable returns a table holding the contents of a list, using the same columns the list shows when opened on its
+// own.
+func ListTable[T gurps.NodeConstraint[T]](rows []T) *Table {
+	var columns []nodeColumn
+	switch any(rows).(type) {
+	case []*gurps.Trait:
+		columns = traitListColumns()
+	case []*gurps.TraitModifier:
+		columns = traitModifierColumns()
+	case []*gurps.Skill:
+		columns = skillListColumns()
+	case []*gurps.Spell:
+		columns = spellListColumns()
+	case []*gurps.Equipment:
+		columns = equipmentListColumns()
+	case []*gurps.EquipmentModifier:
+		columns = equipmentModifierColumns()
+	case []*gurps.Note:
+		columns = noteColumns()
+	}
+	return nodeTable("", columns, rows)
+}
+
+// nodeTable creates a table from the nodes and all of their descendants. The first flexible column is used to show
+// the hierarchy.
+func nodeTable[T gurps.NodeConstraint[T]](title string, columns []nodeColumn, roots []T) *Table {
+	t := &Table{
+		Title:           title,
+		Columns:         make([]Column, len(columns)),
+		HierarchyColumn: -1,
+	}
+	for i, col := range columns {
+		t.Columns[i] = col.Column
+		if col.Flexible && t.HierarchyColumn == -1 {
+			t.HierarchyColumn = i
+		}
+	}
+	var add func(list []T, depth int)
+	add = func(list []T, depth int) {
+		for _, one := range list {
+			row := Row{
+				Cells:     make([]Cell, len(columns)),
+				Depth:     depth,
+				Container: one.Container(),
+				Dim:       !one.Enabled(),
+			}
+			for i, col := range columns {
+				var data gurps.CellData
+				one.CellData(col.id, &data)
+				row.Cells[i] = cellFromData(&data)
+			}
+			t.Rows = append(t.Rows, row)
+			if one.Container() {
+				add(one.NodeChildren(), depth+1)
+			}
+		}
+	}
+	add(roots, 0)
+	return t
+}
+
+func cellFromData(data *gurps.CellData) Cell {
+	cell := Cell{Align: data.Alignment}
+	switch data.Type {
+	case gurps.Text:
+		cell.Primary = data.Primary
+		cell.Secondary = data.Secondary
+		if data.UnsatisfiedReason != "" {
+			cell.Secondary = strings.TrimSpace(cell.Secondary + "\n" + data.UnsatisfiedReason)
+		}
+	case gurps.Toggle:
+		if data.Checked {
+			cell.Primary = "•"
+		}
+	case gurps.PageRef:
+		cell.Primary = data.Primary
+	}
+	return cell
+}
+
+func profileFields(entity *gurps.Entity) []Field {
+	p := entity.Profile
+	fields := []Field{
+		{Label: i18n.Text("Name"), Value: p.Name},
+		{Label: i18n.Text("Title"), Value: p.Title},
+		{Label: i18n.Text("Organization"), Value: p.Organization},
+		{Label: i18n.Text("Player"), Value: p.PlayerName},
+		{Label: i18n.Text("Gender"), Value: p.Gender},
+		{Label: i18n.Text("Age"), Value: p.Age},
+		{Label: i18n.Text("Birthday"), Value: p.Birthday},
+		{Label: i18n.Text("Religion"), Value: p.Religion},
+	}
+	if p.Height != 0 {
+		fields = append(fields, Field{Label: i18n.Text("Height"), Value: p.Height.String()})
+	}
+	if p.Weight != 0 {
+		fields = append(fields, Field{Label: i18n.Text("Weight"), Value: p.Weight.String()})
+	}
+	return append(fields,
+		Field{Label: i18n.Text("Size"), Value: strconv.Itoa(p.AdjustedSizeModifier())},
+		Field{Label: i18n.Text("TL"), Value: p.TechLevel},
+		Field{Label: i18n.Text("Hair"), Value: p.Hair},
+		Field{Label: i18n.Text("Eyes"), Value: p.Eyes},
+		Field{Label: i18n.Text("Skin"), Value: p.Skin},
+		Field{Label: i18n.Text("Hand"), Value: p.Handedness},
+	)
+}
+
+func pointsFields(entity *gurps.Entity) []Field {
+	ad, disad, race, quirk := entity.TraitPoints()
+	return []Field{
+		{Label: i18n.Text("Unspent"), Value: entity.UnspentPoints().String()},
+		{Label: i18n.Text("Race"), Value: race.String()},
+		{Label: i18n.Text("Attributes"), Value: entity.AttributePoints().String()},
+		{Label: i18n.Text("Advantages"), Value: ad.String()},
+		{Label: i18n.Text("Disadvantages"), Value: disad.String()},
+		{Label: i18n.Text("Quirks"), Value: quirk.String()},
+		{Label: i18n.Text("Skills"), Value: entity.SkillPoints().String()},
+		{Label: i18n.Text("Spells"), Value: entity.SpellPoints().String()},
+	}
+}
+
+func physicalFields(entity *gurps.Entity) []Field {
+	units := gurps.SheetSettingsFor(entity).DefaultWeightUnits
+	enc := entity.EncumbranceLevel(false)
+	return []Field{
+		{Label: i18n.Text("Basic Thrust"), Value: entity.Thrust().String()},
+		{Label: i18n.Text("Basic Swing"), Value: entity.Swing().String()},
+		{Label: i18n.Text("Basic Lift"), Value: units.Format(entity.BasicLift())},
+		{Label: i18n.Text("Encumbrance"), Value: enc.String()},
+		{Label: i18n.Text("Move"), Value: strconv.Itoa(entity.Move(enc))},
+		{Label: i18n.Text("Dodge"), Value: strconv.Itoa(entity.Dodge(enc))},
+	}
+}
+
+func attributesTable(entity *gurps.Entity) *Table {
+	t := &Table{
+		Title: i18n.Text("Attributes"),
+		Columns: []Column{
+			{Title: i18n.Text("Attribute"), Flexible: true},
+			{Title: i18n.Text("Value")},
+			{Title: i18n.Text("Pts")},
+		},
+	}
+	var primary, secondary, pools []Row
+	for _, def := range gurps.SheetSettingsFor(entity).Attributes.List() {
+		attr, ok := entity.Attributes.Set[def.ID()]
+		if !ok {
+			continue
+		}
+		value := attr.Maximum().String()
+		if def.Type == attribute.Pool {
+			value = fmt.Sprintf(i18n.Text("%s of %s"), attr.Current().String(), value)
+		}
+		row := Row{Cells: []Cell{
+			{Primary: def.CombinedName()},
+			{Primary: value, Align: unison.EndAlignment},
+			{Primary: "[" + attr.PointCost().String() + "]", Align: unison.EndAlignment},
+		}}
+		switch {
+		case def.Type == attribute.Pool:
+			pools = append(pools, row)
+		case def.Primary():
+			primary = append(primary, row)
+		default:
+			secondary = append(secondary, row)
+		}
+	}
+	t.Rows = append(append(primary, secondary...), pools...)
+	return t
+}
+
+func sheetBlock(entity *gurps.Entity, key string) *Table {
+	switch key {
+	case gurps.BlockLayoutReactionsKey:
+		return nodeTable(i18n.Text("Reactions"), conditionalModifierColumns(i18n.Text("Reaction")),
+			entity.Reactions())
+	case gurps.BlockLayoutConditionalModifiersKey:
+		return nodeTable(i18n.Text("Conditional Modifiers"), conditionalModifierColumns(i18n.Text("Condition")),
+			entity.ConditionalModifiers())
+	case gurps.BlockLayoutMeleeKey:
+		return nodeTable(i18n.Text("Melee Weapons"), weaponPageColumns(weapon.Melee),
+			entity.EquippedWeapons(weapon.Melee))
+	case gurps.BlockLayoutRangedKey:
+		return nodeTable(i18n.Text("Ranged Weapons"), weaponPageColumns(weapon.Ranged),
+			entity.EquippedWeapons(weapon.Ranged))
+	case gurps.BlockLayoutTraitsKey:
+		return nodeTable(i18n.Text("Traits"), traitPageColumns(), entity.Traits)
+	case gurps.BlockLayoutSkillsKey:
+		return nodeTable(i18n.Text("Skills"), skillPageColumns(true), entity.Skills)
+	case gurps.BlockLayoutSpellsKey:
+		return nodeTable(i18n.Text("Spells"), spellPageColumns(true), entity.Spells)
+	case gurps.BlockLayoutEquipmentKey:
+		title := fmt.Sprintf(i18n.Text("Carried Equipment (%s; $%s)"),
+			entity.SheetSettings.DefaultWeightUnits.Format(entity.WeightCarried(false)), entity.WealthCarried().String())
+		return nodeTable(title, equipmentPageColumns(true, i18n.Text("Carried Equipment")), entity.CarriedEquipment)
+	case gurps.BlockLayoutOtherEquipmentKey:
+		title := fmt.Sprintf(i18n.Text("Other Equipment ($%s)"), entity.WealthNotCarried().String())
+		return nodeTable(title, equipmentPageColumns(false, i18n.Text("Other Equipment")), entity.OtherEquipment)
+	case gurps.BlockLayoutNotesKey:
+		return nodeTable(i18n.Text("Notes"), noteColumns(), entity.Notes)
+	default:
+		return nil
+	}
+}
+
+func descriptionColumn(title string, id int) nodeColumn {
+	return nodeColumn{Column: Column{Title: title, Flexible: true}, id: id}
+}
+
+func plainColumn(title string, id int) nodeColumn {
+	return nodeColumn{Column: Column{Title: title}, id: id}
+}
+
+func pageRefColumn() nodeColumn {
+	return plainColumn(i18n.Text("Ref"), gurps.PageRefCellAlias)
+}
+
+func traitListColumns() []nodeColumn {
+	return []nodeColumn{
+		descriptionColumn(i18n.Text("Trait"), gurps.TraitDescriptionColumn),
+		plainColumn(i18n.Text("Pts"), gurps.TraitPointsColumn),
+		plainColumn(i18n.Text("Tags"), gurps.TraitTagsColumn),
+		pageRefColumn(),
+	}
+}
+
+func traitPageColumns() []nodeColumn {
+	return []nodeColumn{
+		descriptionColumn(i18n.Text("Trait"), gurps.TraitDescriptionColumn),
+		plainColumn(i18n.Text("Pts"), gurps.TraitPointsColumn),
+		pageRefColumn(),
+	}
+}
+
+func traitModifierColumns() []nodeColumn {
+	return []nodeColumn{
+		descriptionColumn(i18n.Text("Trait Modifier"), gurps.TraitModifierDescriptionColumn),
+		plainColumn(i18n.Text("Cost Modifier"), gurps.TraitModifierCostColumn),
+		plainColumn(i18n.Text("Tags"), gurps.TraitModifierTagsColumn),
+		pageRefColumn(),
+	}
+}
+
+func skillListColumns() []nodeColumn {
+	return []nodeColumn{
+		descriptionColumn(i18n.Text("Skill / Technique"), gurps.SkillDescriptionColumn),
+		plainColumn(i18n.Text("Diff"), gurps.SkillDifficultyColumn),
+		plainColumn(i18n.Text("Tags"), gurps.SkillTagsColumn),
+		pageRefColumn(),
+	}
+}
+
+func skillPageColumns(forEntity bool) []nodeColumn {
+	columns := []nodeColumn{descriptionColumn(i18n.Text("Skill / Technique"), gurps.SkillDescriptionColumn)}
+	if forEntity {
+		columns = append(columns,
+			plainColumn(i18n.Text("SL"), gurps.SkillLevelColumn),
+			plainColumn(i18n.Text("RSL"), gurps.SkillRelativeLevelColumn))
+	}
+	return append(columns, plainColumn(i18n.Text("Pts"), gurps.SkillPointsColumn), pageRefColumn())
+}
+
+func spellListColumns() []nodeColumn {
+	return []nodeColumn{
+		descriptionColumn(i18n.Text("Spell"), gurps.SpellDescriptionColumn),
+		plainColumn(i18n.Text("College"), gurps.SpellCollegeColumn),
+		plainColumn(i18n.Text("Resist"), gurps.SpellResistColumn),
+		plainColumn(i18n.Text("Class"), gurps.SpellClassColumn),
+		plainColumn(i18n.Text("Cost"), gurps.SpellCastCostColumn),
+		plainColumn(i18n.Text("Maintain"), gurps.SpellMaintainCostColumn),
+		plainColumn(i18n.Text("Time"), gurps.SpellCastTimeColumn),
+		plainColumn(i18n.Text("Duration"), gurps.SpellDurationColumn),
+		plainColumn(i18n.Text("Diff"), gurps.SpellDifficultyColumn),
+		plainColumn(i18n.Text("Tags"), gurps.SpellTagsColumn),
+		pageRefColumn(),
+	}
+}
+
+func spellPageColumns(forEntity bool) []nodeColumn {
+	columns := []nodeColumn{
+		descriptionColumn(i18n.Text("Spell"), gurps.SpellDescriptionForPageColumn),
+		plainColumn(i18n.Text("College"), gurps.SpellCollegeColumn),
+	}
+	if forEntity {
+		columns = append(columns,
+			plainColumn(i18n.Text("SL"), gurps.SpellLevelColumn),
+			plainColumn(i18n.Text("RSL"), gurps.SpellRelativeLevelColumn))
+	} else {
+		columns = append(columns, plainColumn(i18n.Text("Diff"), gurps.SpellDifficultyColumn))
+	}
+	return append(columns, plainColumn(i18n.Text("Pts"), gurps.SpellPointsColumn), pageRefColumn())
+}
+
+func equipmentListColumns() []nodeColumn {
+	return []nodeColumn{
+		descriptionColumn(i18n.Text("Equipment"), gurps.EquipmentDescriptionColumn),
+		plainColumn(i18n.Text("Uses"), gurps.EquipmentMaxUsesColumn),
+		plainColumn(i18n.Text("TL"), gurps.EquipmentTLColumn),
+		plainColumn(i18n.Text("LC"), gurps.EquipmentLCColumn),
+		plainColumn(i18n.Text("Cost"), gurps.EquipmentCostColumn),
+		plainColumn(i18n.Text("Weight"), gurps.EquipmentWeightColumn),
+		plainColumn(i18n.Text("Tags"), gurps.EquipmentTagsColumn),
+		pageRefColumn(),
+	}
+}
+
+func equipmentPageColumns(carried bool, title string) []nodeColumn {
+	var columns []nodeColumn
+	if carried {
+		columns = append(columns, plainColumn(i18n.Text("Eqp"), gurps.EquipmentEquippedColumn))
+	}
+	return append(columns,
+		plainColumn("#", gurps.EquipmentQuantityColumn),
+		descriptionColumn(title, gurps.EquipmentDescriptionColumn),
+		plainColumn(i18n.Text("Uses"), gurps.EquipmentUsesColumn),
+		plainColumn(i18n.Text("TL"), gurps.EquipmentTLColumn),
+		plainColumn(i18n.Text("LC"), gurps.EquipmentLCColumn),
+		plainColumn(i18n.Text("Cost"), gurps.EquipmentCostColumn),
+		plainColumn(i18n.Text("Weight"), gurps.EquipmentWeightColumn),
+		plainColumn(i18n.Text("Sum Cost"), gurps.EquipmentExtendedCostColumn),
+		plainColumn(i18n.Text("Sum Weight"), gurps.EquipmentExtendedWeightColumn),
+		pageRefColumn(),
+	)
+}
+
+func equipmentModifierColumns() []nodeColumn {
+	return []nodeColumn{
+		descriptionColumn(i18n.Text("Equipment Modifier"), gurps.EquipmentModifierDescriptionColumn),
+		plainColumn(i18n.Text("TL"), gurps.EquipmentModifierTechLevelColumn),
+		plainColumn(i18n.Text("Cost Adjustment"), gurps.EquipmentModifierCostColumn),
+		plainColumn(i18n.Text("Weight Adjustment"), gurps.EquipmentModifierWeightColumn),
+		plainColumn(i18n.Text("Tags"), gurps.EquipmentModifierTagsColumn),
+		pageRefColumn(),
+	}
+}
+
+func noteColumns() []nodeColumn {
+	return []nodeColumn{
+		descriptionColumn(i18n.Text("Note"), gurps.NoteTextColumn),
+		pageRefColumn(),
+	}
+}
+
+func conditionalModifierColumns(title string) []nodeColumn {
+	return []nodeColumn{
+		plainColumn("±", gurps.ConditionalModifierValueColumn),
+		descriptionColumn(title, gurps.ConditionalModifierDescriptionColumn),
+	}
+}
+
+func weaponPageColumns(weaponType weapon.Type) []nodeColumn {
+	columns := []nodeColumn{
+		descriptionColumn(weaponType.String(), gurps.WeaponDescriptionColumn),
+		plainColumn(i18n.Text("Usage"), gurps.WeaponUsageColumn),
+		plainColumn(i18n.Text("SL"), gurps.WeaponSLColumn),
+	}
+	if weaponType == weapon.Melee {
+		columns = append(columns,
+			plainColumn(i18n.Text("Parry"), gurps.WeaponParryColumn),
+			plainColumn(i18n.Text("Block"), gurps.WeaponBlockColumn),
+			plainColumn(i18n.Text("Damage"), gurps.WeaponDamageColumn),
+			plainColumn(i18n.Text("Reach"), gurps.WeaponReachColumn))
+	} else {
+		columns = append(columns,
+			plainColumn(i18n.Text("Acc"), gurps.WeaponAccColumn),
+			plainColumn(i18n.Text("Damage"), gurps.WeaponDamageColumn),
+			plainColumn(i18n.Text("Range"), gurps.WeaponRangeColumn),
+			plainColumn(i18n.Text("RoF"), gurps.WeaponRoFColumn),
+			plainColumn(i18n.Text("Shots"), gurps.WeaponShotsColumn),
+			plainColumn(i18n.Text("Bulk"), gurps.WeaponBulkColumn),
+			plainColumn(i18n.Text("Recoil"), gurps.WeaponRecoilColumn))
+	}
+	return append(columns, plainColumn(i18n.Text("ST"), gurps.WeaponSTColumn))
+}
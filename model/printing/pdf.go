@@ -0,0 +1,153 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package printing
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+	"unicode/utf16"
+
+	"github.com/richardwilkes/toolbox/errs"
+)
+
+// page holds the drawing operations for a single page. Coordinates are in points, with the origin at the top-left
+// corner of the page, and are converted to the bottom-left origin PDF uses as the operations are recorded.
+type page struct {
+	height    float32
+	content   bytes.Buffer
+	missing   map[rune]bool
+	decorated bool
+}
+
+func (p *page) setGray(gray float32) {
+	fmt.Fprintf(&p.content, "%s g %s G\n", num(gray), num(gray))
+}
+
+func (p *page) fillRect(x, y, width, height float32) {
+	fmt.Fprintf(&p.content, "%s %s %s %s re f\n", num(x), num(p.height-y-height), num(width), num(height))
+}
+
+func (p *page) line(x1, y1, x2, y2, thickness float32) {
+	fmt.Fprintf(&p.content, "%s w %s %s m %s %s l S\n", num(thickness), num(x1), num(p.height-y1), num(x2),
+		num(p.height-y2))
+}
+
+// text draws the text with its baseline at y.
+func (p *page) text(x, y float32, font Font, size float32, text string) {
+	if text == "" {
+		return
+	}
+	fmt.Fprintf(&p.content, "BT /%s %s Tf %s %s Td ", font.resourceName(), num(size), num(x), num(p.height-y))
+	writeLiteral(&p.content, encode(text, p.missing))
+	p.content.WriteString(" Tj ET\n")
+}
+
+// writePDF writes the pages out as a PDF document.
+func writePDF(w io.Writer, pages []*page, width, height float32, title string, created time.Time) error {
+	var buffer bytes.Buffer
+	var offsets []int
+	startObject := func() int {
+		offsets = append(offsets, buffer.Len())
+		id := len(offsets)
+		fmt.Fprintf(&buffer, "%d 0 obj\n", id)
+		return id
+	}
+	buffer.WriteString("%PDF-1.4\n%\xE2\xE3\xCF\xD3\n")
+
+	const (
+		catalogID = 1
+		pagesID   = 2
+		fontsID   = 3
+		infoID    = 6
+	)
+	startObject()
+	fmt.Fprintf(&buffer, "<< /Type /Catalog /Pages %d 0 R >>\nendobj\n", pagesID)
+	startObject()
+	fmt.Fprintf(&buffer, "<< /Type /Pages /Count %d /Kids [", len(pages))
+	for i := range pages {
+		fmt.Fprintf(&buffer, " %d 0 R", infoID+1+i*2)
+	}
+	fmt.Fprintf(&buffer, " ] /MediaBox [0 0 %s %s] >>\nendobj\n", num(width), num(height))
+	startObject()
+	fmt.Fprintf(&buffer, "<< /Font << /%s %d 0 R /%s %d 0 R >> >>\nendobj\n", RegularFont.resourceName(),
+		fontsID+1, BoldFont.resourceName(), fontsID+2)
+	for _, f := range []Font{RegularFont, BoldFont} {
+		startObject()
+		fmt.Fprintf(&buffer,
+			"<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>\nendobj\n", f.baseFont())
+	}
+	startObject()
+	buffer.WriteString("<< /Title ")
+	writeTextString(&buffer, title)
+	fmt.Fprintf(&buffer, " /Producer (GCS) /CreationDate (D:%s) >>\nendobj\n", created.UTC().Format("20060102150405Z"))
+
+	for _, p := range pages {
+		pageID := startObject()
+		fmt.Fprintf(&buffer, "<< /Type /Page /Parent %d 0 R /Resources %d 0 R /Contents %d 0 R >>\nendobj\n",
+			pagesID, fontsID, pageID+1)
+		var compressed bytes.Buffer
+		zw := zlib.NewWriter(&compressed)
+		if _, err := zw.Write(p.content.Bytes()); err != nil {
+			return errs.Wrap(err)
+		}
+		if err := zw.Close(); err != nil {
+			return errs.Wrap(err)
+		}
+		startObject()
+		fmt.Fprintf(&buffer, "<< /Length %d /Filter /FlateDecode >>\nstream\n", compressed.Len())
+		buffer.Write(compressed.Bytes())
+		buffer.WriteString("\nendstream\nendobj\n")
+	}
+
+	xref := buffer.Len()
+	fmt.Fprintf(&buffer, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buffer, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buffer, "trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+		len(offsets)+1, catalogID, infoID, xref)
+	_, err := w.Write(buffer.Bytes())
+	return errs.Wrap(err)
+}
+
+// writeLiteral writes the data as a PDF literal string, escaping as needed.
+func writeLiteral(buffer *bytes.Buffer, data []byte) {
+	buffer.WriteByte('(')
+	for _, ch := range data {
+		switch ch {
+		case '(', ')', '\\':
+			buffer.WriteByte('\\')
+			buffer.WriteByte(ch)
+		default:
+			buffer.WriteByte(ch)
+		}
+	}
+	buffer.WriteByte(')')
+}
+
+// writeTextString writes the text as a PDF text string, which uses UTF-16 so that any character may be represented.
+func writeTextString(buffer *bytes.Buffer, text string) {
+	buffer.WriteString("<FEFF")
+	for _, ch := range utf16.Encode([]rune(text)) {
+		fmt.Fprintf(buffer, "%04X", ch)
+	}
+	buffer.WriteByte('>')
+}
+
+// num formats the value for use within a PDF.
+func num(value float32) string {
+	return strconv.FormatFloat(float64(value), 'f', -1, 32)
+}
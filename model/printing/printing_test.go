@@ -0,0 +1,161 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package printing_test
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/richardwilkes/gcs/model/gurps"
+	"github.com/richardwilkes/gcs/model/gurps/datafile"
+	"github.com/richardwilkes/gcs/model/printing"
+	"github.com/richardwilkes/gcs/model/settings"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	if gurps.SettingsProvider == nil {
+		s := settings.Default()
+		s.General.AutoFillProfile = false
+		gurps.SettingsProvider = s
+	}
+}
+
+var streamRegex = regexp.MustCompile(`(?s)/Length (\d+) /Filter /FlateDecode >>\nstream\n`)
+
+// pageContents returns the decompressed content of each page within the PDF.
+func pageContents(t *testing.T, data []byte) []string {
+	t.Helper()
+	var pages []string
+	for _, loc := range streamRegex.FindAllSubmatchIndex(data, -1) {
+		length, err := strconv.Atoi(string(data[loc[2]:loc[3]]))
+		require.NoError(t, err)
+		var r io.ReadCloser
+		r, err = zlib.NewReader(bytes.NewReader(data[loc[1] : loc[1]+length]))
+		require.NoError(t, err)
+		var content []byte
+		content, err = io.ReadAll(r)
+		require.NoError(t, err)
+		pages = append(pages, string(content))
+	}
+	return pages
+}
+
+func TestListPaginatesWithRepeatedHeaders(t *testing.T) {
+	skills := make([]*gurps.Skill, 0, 200)
+	for i := 0; i < 200; i++ {
+		skill := gurps.NewSkill(nil, nil, false)
+		skill.Name = fmt.Sprintf("Skill Number %d", i+1)
+		skills = append(skills, skill)
+	}
+	d := printing.List("Skills (test)", skills)
+	data, err := d.Bytes()
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(data, []byte("%PDF-1.4\n")))
+	assert.True(t, bytes.HasSuffix(data, []byte("%%EOF\n")))
+	require.Greater(t, d.PageCount(), 1)
+	assert.Contains(t, string(data), fmt.Sprintf("/Count %d ", d.PageCount()))
+
+	pages := pageContents(t, data)
+	require.Len(t, pages, d.PageCount())
+	for i, content := range pages {
+		assert.Contains(t, content, "(Skill / Technique)", "page %d", i+1)
+		assert.Contains(t, content, "(Skills \\(test\\))", "page %d", i+1)
+		assert.Contains(t, content, fmt.Sprintf("(Page %d of %d)", i+1, len(pages)), "page %d", i+1)
+	}
+	assert.Contains(t, pages[0], "(Skill Number 1)")
+	assert.Contains(t, pages[len(pages)-1], "(Skill Number 200)")
+}
+
+func TestSheet(t *testing.T) {
+	entity := gurps.NewEntity(datafile.PC)
+	entity.Profile.Name = "Léa O'Brien"
+	container := gurps.NewTrait(entity, nil, true)
+	container.Name = "Senses"
+	child := gurps.NewTrait(entity, container, false)
+	child.Name = "Acute Vision"
+	container.Children = append(container.Children, child)
+	entity.Traits = append(entity.Traits, container)
+	entity.Recalculate()
+
+	d := printing.Sheet(entity)
+	data, err := d.Bytes()
+	require.NoError(t, err)
+	pages := pageContents(t, data)
+	require.NotEmpty(t, pages)
+	assert.Contains(t, pages[0], "(L\xe9a O'Brien)")
+	all := ""
+	for _, content := range pages {
+		all += content
+	}
+	assert.Contains(t, all, "(Senses)")
+	assert.Contains(t, all, "(Acute Vision)")
+	assert.Contains(t, all, "(Attributes)")
+}
+
+func TestMissingCharacters(t *testing.T) {
+	entity := gurps.NewEntity(datafile.PC)
+	entity.Profile.Name = "Иван Łukasz"
+	entity.Recalculate()
+
+	d := printing.Sheet(entity)
+	data, err := d.Bytes()
+	require.NoError(t, err)
+	pages := pageContents(t, data)
+	require.NotEmpty(t, pages)
+	assert.Contains(t, pages[0], "(???? ?ukasz)")
+	assert.Equal(t, []rune("ŁИавн"), d.MissingCharacters())
+}
+
+func TestTableWidthsFitPage(t *testing.T) {
+	d := printing.NewDocument(printing.Options{
+		Title:        "Wide",
+		Width:        200,
+		Height:       300,
+		TopMargin:    10,
+		LeftMargin:   10,
+		BottomMargin: 10,
+		RightMargin:  10,
+		Created:      time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC),
+	})
+	d.Table(&printing.Table{
+		Columns: []printing.Column{
+			{Title: "Description", Flexible: true},
+			{Title: "A very long column title"},
+			{Title: "Another long column title"},
+		},
+		Rows: []printing.Row{{Cells: []printing.Cell{
+			{Primary: "A description that is long enough that it will need to wrap across several lines"},
+			{Primary: "Value"},
+			{Primary: "Value"},
+		}}},
+	})
+	data, err := d.Bytes()
+	require.NoError(t, err)
+	assert.Equal(t, 1, d.PageCount())
+	assert.Contains(t, string(data), "/CreationDate (D:20220601000000Z)")
+	for _, content := range pageContents(t, data) {
+		for _, match := range regexp.MustCompile(`(?m)([\d.]+) [\d.]+ Td `).FindAllStringSubmatch(content, -1) {
+			x, parseErr := strconv.ParseFloat(match[1], 64)
+			require.NoError(t, parseErr)
+			assert.GreaterOrEqual(t, x, 10.0)
+			assert.Less(t, x, 190.0)
+		}
+	}
+}
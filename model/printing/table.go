@@ -0,0 +1,302 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package printing
+
+import (
+	"github.com/richardwilkes/toolbox/i18n"
+	"github.com/richardwilkes/unison"
+)
+
+const (
+	// maxFixedColumnFraction limits the portion of the page width a column that isn't flexible may occupy.
+	maxFixedColumnFraction = 0.25
+	// minFlexibleColumnFraction is the minimum portion of the page width reserved for the flexible columns.
+	minFlexibleColumnFraction = 0.3
+)
+
+// Column describes a column within a Table. The titles of flexible columns are start-aligned, while the others are
+// centered.
+type Column struct {
+	Title string
+	// Flexible columns receive the width not needed by the other columns. If no columns are flexible, the hierarchy
+	// column is treated as flexible.
+	Flexible bool
+}
+
+// Cell holds the content of a single cell. The secondary text, if any, is drawn below the primary text in a smaller
+// font.
+type Cell struct {
+	Primary   string
+	Secondary string
+	Align     unison.Alignment
+}
+
+// Row holds the content of a single row within a Table.
+type Row struct {
+	Cells []Cell
+	// Depth is the number of levels the row is nested within containers.
+	Depth     int
+	Container bool
+	Dim       bool
+}
+
+// Table holds tabular content.
+type Table struct {
+	Title   string
+	Columns []Column
+	Rows    []Row
+	// HierarchyColumn is the index of the column that is indented to reflect the depth of each row.
+	HierarchyColumn int
+}
+
+type cellLayout struct {
+	primary   []string
+	secondary []string
+}
+
+type rowLayout struct {
+	cells  []cellLayout
+	height float32
+}
+
+// Table adds a table. The column headers are repeated at the top of each page the table spans.
+func (d *Document) Table(t *Table) {
+	if len(t.Columns) == 0 {
+		return
+	}
+	widths := d.columnWidths(t)
+	rows := make([]rowLayout, len(t.Rows))
+	for i := range t.Rows {
+		rows[i] = t.layoutRow(i, widths)
+	}
+	headerRow := t.layoutHeader(widths)
+	d.beginBlock()
+	needed := headerRow.height
+	if t.Title != "" {
+		needed += headingHeight()
+	}
+	if len(rows) != 0 {
+		needed += rows[0].height
+	}
+	d.ensureSpace(needed)
+	d.drawTableTop(t, t.Title, widths, headerRow)
+	for i := range rows {
+		if d.y+rows[i].height > d.contentBottom() {
+			d.newPage()
+			title := t.Title
+			if title != "" {
+				title += " " + i18n.Text("(continued)")
+			}
+			d.drawTableTop(t, title, widths, headerRow)
+		}
+		if i&1 == 1 {
+			d.current.setGray(stripeGray)
+			d.current.fillRect(d.contentLeft(), d.y, d.contentWidth(), rows[i].height)
+			d.current.setGray(0)
+		}
+		d.drawRow(t, &t.Rows[i], &rows[i], widths)
+	}
+	d.current.line(d.contentLeft(), d.y, d.contentLeft()+d.contentWidth(), d.y, ruleThickness)
+}
+
+func (d *Document) drawTableTop(t *Table, title string, widths []float32, headerRow rowLayout) {
+	if title != "" {
+		d.drawHeading(title)
+	}
+	d.current.setGray(headerRowGray)
+	d.current.fillRect(d.contentLeft(), d.y, d.contentWidth(), headerRow.height)
+	d.current.setGray(0)
+	x := d.contentLeft()
+	for i := range t.Columns {
+		align := unison.MiddleAlignment
+		if t.isFlexible(i) {
+			align = unison.StartAlignment
+		}
+		d.drawCellLines(x, d.y+cellVPadding, widths[i], align, BoldFont, bodySize, headerRow.cells[i].primary)
+		x += widths[i]
+	}
+	d.y += headerRow.height
+}
+
+func (d *Document) drawRow(t *Table, row *Row, layout *rowLayout, widths []float32) {
+	font := RegularFont
+	if row.Container {
+		font = BoldFont
+	}
+	if row.Dim {
+		d.current.setGray(dimGray)
+	}
+	x := d.contentLeft()
+	for i := range t.Columns {
+		if i >= len(row.Cells) {
+			break
+		}
+		align := row.Cells[i].Align
+		indent := t.indent(row, i)
+		y := d.y + cellVPadding
+		d.drawCellLines(x+indent, y, widths[i]-indent, align, font, bodySize, layout.cells[i].primary)
+		y += lineHeight(bodySize) * float32(len(layout.cells[i].primary))
+		d.drawCellLines(x+indent, y, widths[i]-indent, align, RegularFont, secondarySize, layout.cells[i].secondary)
+		x += widths[i]
+	}
+	if row.Dim {
+		d.current.setGray(0)
+	}
+	d.y += layout.height
+}
+
+// drawCellLines draws the lines of text within a cell, starting with the top of the first line at y.
+func (d *Document) drawCellLines(x, y, width float32, align unison.Alignment, font Font, size float32, lines []string) {
+	for _, line := range lines {
+		lineX := x + cellHPadding
+		switch align {
+		case unison.MiddleAlignment:
+			lineX = x + (width-font.Width(line, size))/2
+		case unison.EndAlignment:
+			lineX = x + width - cellHPadding - font.Width(line, size)
+		default:
+		}
+		d.current.text(lineX, y+size, font, size, line)
+		y += lineHeight(size)
+	}
+}
+
+func (t *Table) indent(row *Row, column int) float32 {
+	if column == t.HierarchyColumn {
+		return indentPerLevel * float32(row.Depth)
+	}
+	return 0
+}
+
+func (t *Table) isFlexible(column int) bool {
+	for _, col := range t.Columns {
+		if col.Flexible {
+			return t.Columns[column].Flexible
+		}
+	}
+	return column == t.HierarchyColumn
+}
+
+// columnWidths determines the width of each column. Columns that aren't flexible are given the width needed to show
+// their content without wrapping, within limits, while the flexible columns share what remains.
+func (d *Document) columnWidths(t *Table) []float32 {
+	available := d.contentWidth()
+	widths := make([]float32, len(t.Columns))
+	var fixed, minFlexible float32
+	flexibleCount := 0
+	for i, col := range t.Columns {
+		titleWidth := BoldFont.Width(col.Title, bodySize) + 2*cellHPadding
+		if t.isFlexible(i) {
+			flexibleCount++
+			minFlexible += titleWidth
+			continue
+		}
+		width := titleWidth
+		for j := range t.Rows {
+			row := &t.Rows[j]
+			if i >= len(row.Cells) {
+				continue
+			}
+			font := RegularFont
+			if row.Container {
+				font = BoldFont
+			}
+			if w := font.Width(row.Cells[i].Primary, bodySize) + 2*cellHPadding; w > width {
+				width = w
+			}
+			if w := RegularFont.Width(row.Cells[i].Secondary, secondarySize) + 2*cellHPadding; w > width {
+				width = w
+			}
+		}
+		if width > available*maxFixedColumnFraction {
+			width = available * maxFixedColumnFraction
+		}
+		widths[i] = width
+		fixed += width
+	}
+	if flexibleCount == 0 {
+		// Give any excess to the last column
+		if fixed < available {
+			widths[len(widths)-1] += available - fixed
+		}
+		return widths
+	}
+	if reserve := available * minFlexibleColumnFraction; minFlexible < reserve {
+		minFlexible = reserve
+	}
+	if fixed+minFlexible > available && fixed > 0 {
+		scale := (available - minFlexible) / fixed
+		if scale < 0 {
+			scale = 0
+		}
+		fixed = 0
+		for i := range widths {
+			if !t.isFlexible(i) {
+				widths[i] *= scale
+				fixed += widths[i]
+			}
+		}
+	}
+	share := (available - fixed) / float32(flexibleCount)
+	for i := range widths {
+		if t.isFlexible(i) {
+			widths[i] = share
+		}
+	}
+	return widths
+}
+
+func (t *Table) layoutHeader(widths []float32) rowLayout {
+	layout := rowLayout{cells: make([]cellLayout, len(t.Columns))}
+	lines := 1
+	for i, col := range t.Columns {
+		layout.cells[i].primary = BoldFont.wrap(col.Title, bodySize, widths[i]-2*cellHPadding)
+		if len(layout.cells[i].primary) > lines {
+			lines = len(layout.cells[i].primary)
+		}
+	}
+	layout.height = lineHeight(bodySize)*float32(lines) + 2*cellVPadding
+	return layout
+}
+
+func (t *Table) layoutRow(index int, widths []float32) rowLayout {
+	row := &t.Rows[index]
+	font := RegularFont
+	if row.Container {
+		font = BoldFont
+	}
+	layout := rowLayout{cells: make([]cellLayout, len(t.Columns))}
+	var tallest float32
+	for i := range t.Columns {
+		if i >= len(row.Cells) {
+			continue
+		}
+		width := widths[i] - t.indent(row, i) - 2*cellHPadding
+		var height float32
+		if row.Cells[i].Primary != "" {
+			layout.cells[i].primary = font.wrap(row.Cells[i].Primary, bodySize, width)
+			height += lineHeight(bodySize) * float32(len(layout.cells[i].primary))
+		}
+		if row.Cells[i].Secondary != "" {
+			layout.cells[i].secondary = RegularFont.wrap(row.Cells[i].Secondary, secondarySize, width)
+			height += lineHeight(secondarySize) * float32(len(layout.cells[i].secondary))
+		}
+		if height > tallest {
+			tallest = height
+		}
+	}
+	if tallest < lineHeight(bodySize) {
+		tallest = lineHeight(bodySize)
+	}
+	layout.height = tallest + 2*cellVPadding
+	return layout
+}
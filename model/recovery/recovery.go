@@ -0,0 +1,202 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package recovery
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/richardwilkes/gcs/model/id"
+	"github.com/richardwilkes/gcs/model/jio"
+	"github.com/richardwilkes/json"
+	"github.com/richardwilkes/toolbox/cmdline"
+	"github.com/richardwilkes/toolbox/errs"
+	"github.com/richardwilkes/toolbox/log/jot"
+	"github.com/richardwilkes/toolbox/xio/fs/paths"
+	"github.com/richardwilkes/toolbox/xio/fs/safe"
+)
+
+const (
+	snapshotVersion = 1
+	snapshotExt     = ".snapshot"
+	heartbeatName   = "heartbeat"
+)
+
+// Snapshot holds a copy of the unsaved content of a document.
+type Snapshot struct {
+	Version   int             `json:"version"`
+	Path      string          `json:"path"`
+	Title     string          `json:"title"`
+	Untitled  bool            `json:"untitled,omitempty"`
+	Timestamp jio.Time        `json:"timestamp"`
+	Data      json.RawMessage `json:"data"`
+	file      string
+}
+
+// Session holds the snapshots taken during a single run of the application. A session periodically updates its
+// heartbeat so that other runs of the application can determine whether it is still active.
+type Session struct {
+	dir string
+}
+
+// DefaultRoot returns the default directory that sessions are stored within.
+func DefaultRoot() string {
+	return filepath.Join(paths.AppDataDir(), cmdline.AppCmdName+"_recovery")
+}
+
+// NewSession creates a new session within the root directory.
+func NewSession(root string) (*Session, error) {
+	s := &Session{dir: filepath.Join(root, id.NewUUID().String())}
+	if err := os.MkdirAll(s.dir, 0o750); err != nil {
+		return nil, errs.NewWithCause(s.dir, err)
+	}
+	if err := s.Touch(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Touch updates the session's heartbeat.
+func (s *Session) Touch() error {
+	p := filepath.Join(s.dir, heartbeatName)
+	now := time.Now()
+	if err := os.Chtimes(p, now, now); err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			return errs.NewWithCause(p, err)
+		}
+		if err = os.WriteFile(p, nil, 0o640); err != nil {
+			return errs.NewWithCause(p, err)
+		}
+	}
+	return nil
+}
+
+// Write the snapshot into the session, replacing any prior snapshot with the same key.
+func (s *Session) Write(key string, snapshot *Snapshot) error {
+	snapshot.Version = snapshotVersion
+	data, err := jio.SerializeAndCompress(snapshot)
+	if err != nil {
+		return err
+	}
+	p := filepath.Join(s.dir, key+snapshotExt)
+	if err = safe.WriteFileWithMode(p, func(w io.Writer) error {
+		_, writeErr := w.Write(data)
+		return writeErr
+	}, 0o600); err != nil {
+		return errs.NewWithCause(p, err)
+	}
+	return nil
+}
+
+// Remove the snapshot with the key from the session, if present.
+func (s *Session) Remove(key string) {
+	p := filepath.Join(s.dir, key+snapshotExt)
+	if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		jot.Warn(errs.NewWithCause(p, err))
+	}
+}
+
+// End the session, removing all of its snapshots.
+func (s *Session) End() {
+	if err := os.RemoveAll(s.dir); err != nil {
+		jot.Warn(errs.NewWithCause(s.dir, err))
+	}
+}
+
+// Orphaned returns the snapshots from sessions within the root directory, other than the current session, whose
+// heartbeat hasn't been updated within the staleAfter duration. These are sessions that did not end normally. Sessions
+// with no snapshots are removed. The snapshots are sorted from newest to oldest.
+func Orphaned(root string, staleAfter time.Duration, current *Session) []*Snapshot {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			jot.Warn(errs.NewWithCause(root, err))
+		}
+		return nil
+	}
+	var list []*Snapshot
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		dir := filepath.Join(root, entry.Name())
+		if current != nil && dir == current.dir {
+			continue
+		}
+		if fi, statErr := os.Stat(filepath.Join(dir, heartbeatName)); statErr == nil &&
+			time.Since(fi.ModTime()) < staleAfter {
+			continue // Still in use by another run of the application
+		}
+		snapshots := readSessionSnapshots(dir)
+		if len(snapshots) == 0 {
+			if err = os.RemoveAll(dir); err != nil {
+				jot.Warn(errs.NewWithCause(dir, err))
+			}
+			continue
+		}
+		list = append(list, snapshots...)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Timestamp.After(list[j].Timestamp) })
+	return list
+}
+
+func readSessionSnapshots(dir string) []*Snapshot {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		jot.Warn(errs.NewWithCause(dir, err))
+		return nil
+	}
+	var list []*Snapshot
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || !strings.HasSuffix(entry.Name(), snapshotExt) {
+			continue
+		}
+		p := filepath.Join(dir, entry.Name())
+		var data []byte
+		if data, err = os.ReadFile(p); err != nil {
+			jot.Warn(errs.NewWithCause(p, err))
+			continue
+		}
+		var snapshot Snapshot
+		if err = jio.DecompressAndDeserialize(data, &snapshot); err != nil || snapshot.Version > snapshotVersion {
+			jot.Warn(errs.NewWithCause("unable to read recovery snapshot "+p, err))
+			continue
+		}
+		snapshot.file = p
+		list = append(list, &snapshot)
+	}
+	return list
+}
+
+// Discard the snapshot, removing it from disk along with its session, if it was the last snapshot remaining within it.
+func (s *Snapshot) Discard() {
+	if s.file == "" {
+		return
+	}
+	if err := os.Remove(s.file); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		jot.Warn(errs.NewWithCause(s.file, err))
+		return
+	}
+	dir := filepath.Dir(s.file)
+	s.file = ""
+	if len(readSessionSnapshots(dir)) == 0 {
+		if err := os.RemoveAll(dir); err != nil {
+			jot.Warn(errs.NewWithCause(dir, err))
+		}
+	}
+}
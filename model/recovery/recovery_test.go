@@ -0,0 +1,73 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package recovery_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/richardwilkes/gcs/model/jio"
+	"github.com/richardwilkes/gcs/model/recovery"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecovery(t *testing.T) {
+	root := t.TempDir()
+	crashed, err := recovery.NewSession(root)
+	require.NoError(t, err)
+	require.NoError(t, crashed.Write("a", &recovery.Snapshot{
+		Path:      "/tmp/Bob.gcs",
+		Title:     "Bob",
+		Timestamp: jio.Time(time.Now().Add(-time.Minute)),
+		Data:      []byte(`{"type":"character"}`),
+	}))
+	require.NoError(t, crashed.Write("b", &recovery.Snapshot{
+		Path:      "untitled.skl",
+		Title:     "untitled",
+		Untitled:  true,
+		Timestamp: jio.Now(),
+		Data:      []byte(`{"type":"skill_list"}`),
+	}))
+	require.NoError(t, crashed.Write("c", &recovery.Snapshot{Title: "Removed", Data: []byte(`{}`)}))
+	crashed.Remove("c")
+
+	current, err := recovery.NewSession(root)
+	require.NoError(t, err)
+	require.NoError(t, current.Write("d", &recovery.Snapshot{Title: "Current", Data: []byte(`{}`)}))
+
+	// Sessions with a recent heartbeat are not considered orphaned
+	assert.Empty(t, recovery.Orphaned(root, time.Hour, current))
+
+	list := recovery.Orphaned(root, 0, current)
+	require.Len(t, list, 2)
+	assert.Equal(t, "untitled", list[0].Title)
+	assert.True(t, list[0].Untitled)
+	assert.Equal(t, `{"type":"skill_list"}`, string(list[0].Data))
+	assert.Equal(t, "Bob", list[1].Title)
+	assert.Equal(t, "/tmp/Bob.gcs", list[1].Path)
+
+	list[0].Discard()
+	require.Len(t, recovery.Orphaned(root, 0, current), 1)
+	list[1].Discard()
+	assert.Empty(t, recovery.Orphaned(root, 0, current))
+
+	// Only the current session remains
+	entries, err := os.ReadDir(root)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+	current.End()
+	entries, err = os.ReadDir(root)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
@@ -0,0 +1,90 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package rolls
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/richardwilkes/toolbox/errs"
+	"github.com/richardwilkes/toolbox/i18n"
+)
+
+// MaxHistory is the maximum number of results retained by a History.
+const MaxHistory = 1000
+
+var defaultHistory = &History{}
+
+// History holds the results of the rolls made during the current session.
+type History struct {
+	lock    sync.RWMutex
+	results []*Result
+}
+
+// DefaultHistory returns the history used for rolls made from the user interface.
+func DefaultHistory() *History {
+	return defaultHistory
+}
+
+// Add a result to the history, discarding the oldest results if more than MaxHistory are present.
+func (h *History) Add(result *Result) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.results = append(h.results, result)
+	if len(h.results) > MaxHistory {
+		h.results = append([]*Result(nil), h.results[len(h.results)-MaxHistory:]...)
+	}
+}
+
+// Results returns the results in the history, oldest first.
+func (h *History) Results() []*Result {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	return append([]*Result(nil), h.results...)
+}
+
+// Clear removes all results from the history.
+func (h *History) Clear() {
+	h.lock.Lock()
+	h.results = nil
+	h.lock.Unlock()
+}
+
+// Export the history as a plain text session log.
+func (h *History) Export(w io.Writer) error {
+	results := h.Results()
+	bw := bufio.NewWriter(w)
+	if _, err := fmt.Fprintf(bw, i18n.Text("Session log exported %s")+"\n\n", time.Now().Format(time.RFC1123)); err != nil {
+		return errs.Wrap(err)
+	}
+	var lastDay string
+	for _, one := range results {
+		if day := one.When.Format("2006-01-02"); day != lastDay {
+			if lastDay != "" {
+				if _, err := bw.WriteString("\n"); err != nil {
+					return errs.Wrap(err)
+				}
+			}
+			lastDay = day
+			if _, err := fmt.Fprintf(bw, "%s\n", day); err != nil {
+				return errs.Wrap(err)
+			}
+		}
+		if _, err := fmt.Fprintln(bw, one.String()); err != nil {
+			return errs.Wrap(err)
+		}
+	}
+	return errs.Wrap(bw.Flush())
+}
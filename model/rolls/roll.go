@@ -0,0 +1,256 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package rolls
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/richardwilkes/rpgtools/dice"
+	"github.com/richardwilkes/toolbox/i18n"
+	"github.com/richardwilkes/toolbox/xmath/rand"
+)
+
+// Possible Kind values.
+const (
+	NoRoll Kind = iota
+	SuccessRoll
+	DamageRoll
+)
+
+// Possible Outcome values.
+const (
+	NoOutcome Outcome = iota
+	CriticalSuccess
+	Succeeded
+	Failed
+	CriticalFailure
+)
+
+// Kind identifies the type of roll being made.
+type Kind uint8
+
+// Outcome holds the outcome of a success roll.
+type Outcome uint8
+
+// Request holds the information needed to make a roll.
+type Request struct {
+	Kind Kind
+	// Who is the name of the character making the roll, if any.
+	Who string
+	// What describes what is being rolled against, e.g. a skill name.
+	What string
+	// Target is the level being rolled against. Only used for success rolls.
+	Target int
+	// Damage is the damage to roll, e.g. "2d+1 cut". Only used for damage rolls.
+	Damage string
+}
+
+// Result holds the result of a roll.
+type Result struct {
+	Request
+	When     time.Time
+	Modifier int
+	Dice     []int
+	Total    int
+	Outcome  Outcome
+}
+
+// NewSuccessRequest creates a new request for a success roll.
+func NewSuccessRequest(who, what string, target int) Request {
+	return Request{
+		Kind:   SuccessRoll,
+		Who:    who,
+		What:   what,
+		Target: target,
+	}
+}
+
+// NewDamageRequest creates a new request for a damage roll. If the damage contains no dice specification, the returned
+// request cannot be rolled.
+func NewDamageRequest(who, what, damage string) Request {
+	if start, _ := dice.ExtractDicePosition(damage); start == -1 {
+		return Request{}
+	}
+	return Request{
+		Kind:   DamageRoll,
+		Who:    who,
+		What:   what,
+		Damage: damage,
+	}
+}
+
+// CanRoll returns true if this request describes a roll that can be made.
+func (r *Request) CanRoll() bool {
+	return r.Kind != NoRoll
+}
+
+// Roll the request using the provided randomizer, applying the modifier to the result.
+func (r *Request) Roll(rnd rand.Randomizer, modifier int) *Result {
+	result := &Result{
+		Request:  *r,
+		When:     time.Now(),
+		Modifier: modifier,
+	}
+	switch r.Kind {
+	case SuccessRoll:
+		result.Dice = rollDice(rnd, 3, 6)
+		result.Total = sum(result.Dice)
+		result.Outcome = OutcomeFor(result.Total, result.EffectiveTarget())
+	case DamageRoll:
+		start, end := dice.ExtractDicePosition(r.Damage)
+		if start != -1 {
+			d := dice.New(r.Damage[start:end])
+			d.Normalize()
+			result.Dice = rollDice(rnd, d.Count, d.Sides)
+			result.Total = (sum(result.Dice)+d.Modifier)*d.Multiplier + modifier
+			if result.Total < 0 {
+				result.Total = 0
+			}
+		}
+	}
+	return result
+}
+
+// OutcomeFor returns the outcome of a success roll of the given total made against the effective level, per the rules
+// for critical success and failure from B347-B348.
+func OutcomeFor(total, effective int) Outcome {
+	switch {
+	case total <= 4, total == 5 && effective >= 15, total == 6 && effective >= 16:
+		return CriticalSuccess
+	case total >= 18, total == 17 && effective <= 15, total-effective >= 10:
+		return CriticalFailure
+	case total == 17, total > effective:
+		return Failed
+	default:
+		return Succeeded
+	}
+}
+
+// EffectiveTarget returns the level being rolled against, after the modifier has been applied.
+func (r *Result) EffectiveTarget() int {
+	return r.Target + r.Modifier
+}
+
+// Margin returns the margin of success (positive) or failure (negative) of a success roll.
+func (r *Result) Margin() int {
+	return r.EffectiveTarget() - r.Total
+}
+
+// TargetText returns a description of the level being rolled against, if any.
+func (r *Result) TargetText() string {
+	switch r.Kind {
+	case SuccessRoll:
+		if r.Modifier == 0 {
+			return strconv.Itoa(r.Target)
+		}
+		return fmt.Sprintf("%d%+d = %d", r.Target, r.Modifier, r.EffectiveTarget())
+	case DamageRoll:
+		if r.Modifier == 0 {
+			return r.Damage
+		}
+		return fmt.Sprintf("%s %+d", r.Damage, r.Modifier)
+	default:
+		return ""
+	}
+}
+
+// DiceText returns the individual dice that were rolled.
+func (r *Result) DiceText() string {
+	list := make([]string, len(r.Dice))
+	for i, one := range r.Dice {
+		list[i] = strconv.Itoa(one)
+	}
+	return strings.Join(list, ", ")
+}
+
+// OutcomeText returns a description of the outcome.
+func (r *Result) OutcomeText() string {
+	switch r.Kind {
+	case SuccessRoll:
+		margin := r.Margin()
+		if margin < 0 {
+			margin = -margin
+		}
+		return fmt.Sprintf(i18n.Text("%s by %d"), r.Outcome, margin)
+	case DamageRoll:
+		if _, end := dice.ExtractDicePosition(r.Damage); end != -1 {
+			if damageType := strings.TrimSpace(r.Damage[end:]); damageType != "" {
+				return fmt.Sprintf("%d %s", r.Total, damageType)
+			}
+		}
+		return strconv.Itoa(r.Total)
+	default:
+		return ""
+	}
+}
+
+// String implements fmt.Stringer.
+func (r *Result) String() string {
+	var buffer strings.Builder
+	buffer.WriteString(r.When.Format("15:04:05"))
+	buffer.WriteByte(' ')
+	if r.Who != "" {
+		buffer.WriteString(r.Who)
+		buffer.WriteString(": ")
+	}
+	buffer.WriteString(r.What)
+	buffer.WriteString(" (")
+	buffer.WriteString(r.TargetText())
+	buffer.WriteString(") ")
+	buffer.WriteString(fmt.Sprintf(i18n.Text("rolled %d [%s]"), r.Total, r.DiceText()))
+	if r.Kind == SuccessRoll {
+		buffer.WriteString(", ")
+		buffer.WriteString(r.OutcomeText())
+	} else if text := r.OutcomeText(); text != strconv.Itoa(r.Total) {
+		buffer.WriteString(", ")
+		buffer.WriteString(text)
+	}
+	return buffer.String()
+}
+
+// String implements fmt.Stringer.
+func (o Outcome) String() string {
+	switch o {
+	case CriticalSuccess:
+		return i18n.Text("Critical success")
+	case Succeeded:
+		return i18n.Text("Success")
+	case Failed:
+		return i18n.Text("Failure")
+	case CriticalFailure:
+		return i18n.Text("Critical failure")
+	default:
+		return ""
+	}
+}
+
+func rollDice(rnd rand.Randomizer, count, sides int) []int {
+	if sides < 1 {
+		return nil
+	}
+	list := make([]int, count)
+	for i := range list {
+		list[i] = 1 + rnd.Intn(sides)
+	}
+	return list
+}
+
+func sum(list []int) int {
+	total := 0
+	for _, one := range list {
+		total += one
+	}
+	return total
+}
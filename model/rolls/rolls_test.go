@@ -0,0 +1,86 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package rolls_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/richardwilkes/gcs/model/rolls"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fixedRandomizer returns a predetermined sequence of die faces.
+type fixedRandomizer []int
+
+func (f *fixedRandomizer) Intn(_ int) int {
+	v := (*f)[0]
+	*f = (*f)[1:]
+	return v - 1
+}
+
+func TestOutcomeFor(t *testing.T) {
+	for i, one := range []struct {
+		total     int
+		effective int
+		expected  rolls.Outcome
+	}{
+		{3, 1, rolls.CriticalSuccess},
+		{4, 3, rolls.CriticalSuccess},
+		{5, 14, rolls.Succeeded},
+		{5, 15, rolls.CriticalSuccess},
+		{6, 15, rolls.Succeeded},
+		{6, 16, rolls.CriticalSuccess},
+		{10, 10, rolls.Succeeded},
+		{11, 10, rolls.Failed},
+		{14, 4, rolls.CriticalFailure},
+		{16, 20, rolls.Succeeded},
+		{17, 15, rolls.CriticalFailure},
+		{17, 16, rolls.Failed},
+		{17, 20, rolls.Failed},
+		{18, 25, rolls.CriticalFailure},
+	} {
+		assert.Equal(t, one.expected, rolls.OutcomeFor(one.total, one.effective), "%d: %d vs %d", i, one.total,
+			one.effective)
+	}
+}
+
+func TestRoll(t *testing.T) {
+	rnd := fixedRandomizer{2, 3, 4}
+	req := rolls.NewSuccessRequest("Bob", "Broadsword", 12)
+	result := req.Roll(&rnd, -2)
+	assert.Equal(t, 9, result.Total)
+	assert.Equal(t, 10, result.EffectiveTarget())
+	assert.Equal(t, rolls.Succeeded, result.Outcome)
+	assert.Equal(t, "12-2 = 10", result.TargetText())
+	assert.Equal(t, "Success by 1", result.OutcomeText())
+
+	req = rolls.NewDamageRequest("Bob", "Broadsword", "special")
+	assert.False(t, req.CanRoll())
+	rnd = fixedRandomizer{6, 1}
+	req = rolls.NewDamageRequest("Bob", "Broadsword", "2d+1 cut")
+	result = req.Roll(&rnd, 1)
+	assert.Equal(t, 9, result.Total)
+	assert.Equal(t, "9 cut", result.OutcomeText())
+
+	var h rolls.History
+	for i := 0; i < rolls.MaxHistory+5; i++ {
+		h.Add(result)
+	}
+	assert.Len(t, h.Results(), rolls.MaxHistory)
+	var buffer strings.Builder
+	require.NoError(t, h.Export(&buffer))
+	assert.Contains(t, buffer.String(), "Bob: Broadsword (2d+1 cut +1) rolled 9 [6, 1], 9 cut")
+	h.Clear()
+	assert.Empty(t, h.Results())
+}
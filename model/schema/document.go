@@ -0,0 +1,450 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package schema
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/richardwilkes/toolbox/i18n"
+)
+
+// maxDepth limits how deeply values may be nested, to guard against malicious input exhausting the stack.
+const maxDepth = 1000
+
+type valueKind uint8
+
+const (
+	nullKind valueKind = iota
+	booleanKind
+	numberKind
+	stringKind
+	arrayKind
+	objectKind
+)
+
+// node holds a parsed JSON value along with the location in the source where it was found.
+type node struct {
+	parent *node
+	key    string // The key within the parent, if the parent is an object
+	index  int    // The index within the parent, if the parent is an array
+	kind   valueKind
+	start  int
+	end    int
+	text   string // The decoded string, the literal number, or the literal boolean
+	keys   []string
+	values []*node // Either the object values, in the same order as keys, or the array elements
+}
+
+type parser struct {
+	data  []byte
+	pos   int
+	depth int
+}
+
+// parseError describes a syntax error within a document.
+type parseError struct {
+	offset  int
+	message string
+}
+
+func (e *parseError) Error() string {
+	return e.message
+}
+
+// parse the data into a tree of nodes.
+func parse(data []byte) (*node, error) {
+	p := &parser{data: data}
+	p.skipWhitespace()
+	root, err := p.parseValue(nil)
+	if err != nil {
+		return nil, err
+	}
+	p.skipWhitespace()
+	if p.pos < len(p.data) {
+		return nil, p.fail(i18n.Text("unexpected data after the end of the document"))
+	}
+	return root, nil
+}
+
+func (p *parser) fail(msg string) error {
+	return &parseError{offset: p.pos, message: msg}
+}
+
+func (p *parser) skipWhitespace() {
+	for p.pos < len(p.data) {
+		switch p.data[p.pos] {
+		case ' ', '\t', '\n', '\r':
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (p *parser) parseValue(parent *node) (*node, error) {
+	if p.pos >= len(p.data) {
+		return nil, p.fail(i18n.Text("unexpected end of the document"))
+	}
+	n := &node{parent: parent, start: p.pos}
+	var err error
+	switch ch := p.data[p.pos]; {
+	case ch == '{':
+		err = p.parseObject(n)
+	case ch == '[':
+		err = p.parseArray(n)
+	case ch == '"':
+		n.kind = stringKind
+		n.text, err = p.parseString()
+	case ch == '-' || (ch >= '0' && ch <= '9'):
+		n.kind = numberKind
+		n.text, err = p.parseNumber()
+	case ch == 't':
+		n.kind = booleanKind
+		n.text = "true"
+		err = p.parseLiteral(n.text)
+	case ch == 'f':
+		n.kind = booleanKind
+		n.text = "false"
+		err = p.parseLiteral(n.text)
+	case ch == 'n':
+		n.kind = nullKind
+		err = p.parseLiteral("null")
+	default:
+		err = p.fail(fmt.Sprintf(i18n.Text("unexpected character %q"), rune(ch)))
+	}
+	if err != nil {
+		return nil, err
+	}
+	n.end = p.pos
+	return n, nil
+}
+
+func (p *parser) enter() error {
+	p.depth++
+	if p.depth > maxDepth {
+		return p.fail(i18n.Text("values are nested too deeply"))
+	}
+	return nil
+}
+
+func (p *parser) parseObject(n *node) error {
+	n.kind = objectKind
+	if err := p.enter(); err != nil {
+		return err
+	}
+	p.pos++
+	p.skipWhitespace()
+	if p.pos < len(p.data) && p.data[p.pos] == '}' {
+		p.pos++
+		p.depth--
+		return nil
+	}
+	for {
+		if p.pos >= len(p.data) || p.data[p.pos] != '"' {
+			return p.fail(i18n.Text("expected a property name"))
+		}
+		key, err := p.parseString()
+		if err != nil {
+			return err
+		}
+		p.skipWhitespace()
+		if p.pos >= len(p.data) || p.data[p.pos] != ':' {
+			return p.fail(i18n.Text("expected ':' after a property name"))
+		}
+		p.pos++
+		p.skipWhitespace()
+		var child *node
+		if child, err = p.parseValue(n); err != nil {
+			return err
+		}
+		child.key = key
+		n.keys = append(n.keys, key)
+		n.values = append(n.values, child)
+		p.skipWhitespace()
+		if p.pos >= len(p.data) {
+			return p.fail(i18n.Text("unexpected end of the document"))
+		}
+		switch p.data[p.pos] {
+		case ',':
+			p.pos++
+			p.skipWhitespace()
+		case '}':
+			p.pos++
+			p.depth--
+			return nil
+		default:
+			return p.fail(i18n.Text("expected ',' or '}' after a property value"))
+		}
+	}
+}
+
+func (p *parser) parseArray(n *node) error {
+	n.kind = arrayKind
+	if err := p.enter(); err != nil {
+		return err
+	}
+	p.pos++
+	p.skipWhitespace()
+	if p.pos < len(p.data) && p.data[p.pos] == ']' {
+		p.pos++
+		p.depth--
+		return nil
+	}
+	for {
+		child, err := p.parseValue(n)
+		if err != nil {
+			return err
+		}
+		child.index = len(n.values)
+		n.values = append(n.values, child)
+		p.skipWhitespace()
+		if p.pos >= len(p.data) {
+			return p.fail(i18n.Text("unexpected end of the document"))
+		}
+		switch p.data[p.pos] {
+		case ',':
+			p.pos++
+			p.skipWhitespace()
+		case ']':
+			p.pos++
+			p.depth--
+			return nil
+		default:
+			return p.fail(i18n.Text("expected ',' or ']' after an array element"))
+		}
+	}
+}
+
+func (p *parser) parseLiteral(literal string) error {
+	if !bytes.HasPrefix(p.data[p.pos:], []byte(literal)) {
+		return p.fail(i18n.Text("invalid literal"))
+	}
+	p.pos += len(literal)
+	return nil
+}
+
+func (p *parser) parseNumber() (string, error) {
+	start := p.pos
+	if p.data[p.pos] == '-' {
+		p.pos++
+	}
+	switch {
+	case p.pos < len(p.data) && p.data[p.pos] == '0':
+		p.pos++
+	case p.pos < len(p.data) && p.data[p.pos] >= '1' && p.data[p.pos] <= '9':
+		p.skipDigits()
+	default:
+		return "", p.fail(i18n.Text("invalid number"))
+	}
+	if p.pos < len(p.data) && p.data[p.pos] == '.' {
+		p.pos++
+		if !p.skipDigits() {
+			return "", p.fail(i18n.Text("invalid number"))
+		}
+	}
+	if p.pos < len(p.data) && (p.data[p.pos] == 'e' || p.data[p.pos] == 'E') {
+		p.pos++
+		if p.pos < len(p.data) && (p.data[p.pos] == '+' || p.data[p.pos] == '-') {
+			p.pos++
+		}
+		if !p.skipDigits() {
+			return "", p.fail(i18n.Text("invalid number"))
+		}
+	}
+	return string(p.data[start:p.pos]), nil
+}
+
+func (p *parser) skipDigits() bool {
+	start := p.pos
+	for p.pos < len(p.data) && p.data[p.pos] >= '0' && p.data[p.pos] <= '9' {
+		p.pos++
+	}
+	return p.pos > start
+}
+
+func (p *parser) parseString() (string, error) {
+	p.pos++
+	var buffer strings.Builder
+	for {
+		if p.pos >= len(p.data) {
+			return "", p.fail(i18n.Text("unterminated string"))
+		}
+		ch := p.data[p.pos]
+		switch {
+		case ch == '"':
+			p.pos++
+			return buffer.String(), nil
+		case ch == '\\':
+			r, err := p.parseEscape()
+			if err != nil {
+				return "", err
+			}
+			buffer.WriteRune(r)
+		case ch < ' ':
+			return "", p.fail(i18n.Text("invalid control character in string"))
+		case ch < utf8.RuneSelf:
+			buffer.WriteByte(ch)
+			p.pos++
+		default:
+			r, size := utf8.DecodeRune(p.data[p.pos:])
+			buffer.WriteRune(r)
+			p.pos += size
+		}
+	}
+}
+
+func (p *parser) parseEscape() (rune, error) {
+	p.pos++
+	if p.pos >= len(p.data) {
+		return 0, p.fail(i18n.Text("unterminated string"))
+	}
+	ch := p.data[p.pos]
+	p.pos++
+	switch ch {
+	case '"', '\\', '/':
+		return rune(ch), nil
+	case 'b':
+		return '\b', nil
+	case 'f':
+		return '\f', nil
+	case 'n':
+		return '\n', nil
+	case 'r':
+		return '\r', nil
+	case 't':
+		return '\t', nil
+	case 'u':
+		r, err := p.parseHex()
+		if err != nil {
+			return 0, err
+		}
+		if utf16.IsSurrogate(r) {
+			if p.pos+1 < len(p.data) && p.data[p.pos] == '\\' && p.data[p.pos+1] == 'u' {
+				save := p.pos
+				p.pos += 2
+				var r2 rune
+				if r2, err = p.parseHex(); err != nil {
+					return 0, err
+				}
+				if combined := utf16.DecodeRune(r, r2); combined != utf8.RuneError {
+					return combined, nil
+				}
+				p.pos = save
+			}
+			return utf8.RuneError, nil
+		}
+		return r, nil
+	default:
+		p.pos--
+		return 0, p.fail(i18n.Text("invalid escape sequence in string"))
+	}
+}
+
+func (p *parser) parseHex() (rune, error) {
+	if p.pos+4 > len(p.data) {
+		return 0, p.fail(i18n.Text("invalid escape sequence in string"))
+	}
+	v, err := strconv.ParseUint(string(p.data[p.pos:p.pos+4]), 16, 16)
+	if err != nil {
+		return 0, p.fail(i18n.Text("invalid escape sequence in string"))
+	}
+	p.pos += 4
+	return rune(v), nil
+}
+
+// child returns the value for the key within an object node, or nil.
+func (n *node) child(key string) *node {
+	for i, k := range n.keys {
+		if k == key {
+			return n.values[i]
+		}
+	}
+	return nil
+}
+
+// path returns the location of the node within the document, using JSONPath notation.
+func (n *node) path() string {
+	var parts []string
+	for one := n; one.parent != nil; one = one.parent {
+		if one.parent.kind == arrayKind {
+			parts = append(parts, "["+strconv.Itoa(one.index)+"]")
+		} else if isIdentifier(one.key) {
+			parts = append(parts, "."+one.key)
+		} else {
+			parts = append(parts, "["+strconv.Quote(one.key)+"]")
+		}
+	}
+	var buffer strings.Builder
+	buffer.WriteByte('$')
+	for i := len(parts) - 1; i >= 0; i-- {
+		buffer.WriteString(parts[i])
+	}
+	return buffer.String()
+}
+
+// itemName returns the name of the closest enclosing item, if any.
+func (n *node) itemName() string {
+	for one := n; one != nil; one = one.parent {
+		if one.kind != objectKind {
+			continue
+		}
+		for _, key := range []string{"name", "description"} {
+			if v := one.child(key); v != nil && v.kind == stringKind && strings.TrimSpace(v.text) != "" {
+				return v.text
+			}
+		}
+	}
+	return ""
+}
+
+// locate returns the deepest node that contains the offset.
+func (n *node) locate(offset int) *node {
+	for _, child := range n.values {
+		if offset > child.start && offset <= child.end {
+			return child.locate(offset)
+		}
+	}
+	return n
+}
+
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		if !(r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (i > 0 && r >= '0' && r <= '9')) {
+			return false
+		}
+	}
+	return true
+}
+
+// lineAndColumn returns the 1-based line and column for the byte offset within the data.
+func lineAndColumn(data []byte, offset int) (line, column int) {
+	if offset > len(data) {
+		offset = len(data)
+	}
+	line = 1
+	lineStart := 0
+	for i := 0; i < offset; i++ {
+		if data[i] == '\n' {
+			line++
+			lineStart = i + 1
+		}
+	}
+	return line, utf8.RuneCount(data[lineStart:offset]) + 1
+}
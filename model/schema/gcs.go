@@ -0,0 +1,582 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package schema
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/richardwilkes/json"
+	"github.com/richardwilkes/toolbox/errs"
+)
+
+// Ext is the extension used for published schema files.
+const Ext = ".schema.json"
+
+type fileSchema struct {
+	name   string
+	schema *Schema
+}
+
+var (
+	fileSchemasOnce sync.Once
+	fileSchemas     map[string]*fileSchema
+)
+
+// ForExtension returns the schema for data files with the given extension, or nil if there isn't one.
+func ForExtension(ext string) *Schema {
+	if fs, ok := gcsSchemas()[strings.ToLower(ext)]; ok {
+		return fs.schema
+	}
+	return nil
+}
+
+// Extensions returns the file extensions that have schemas.
+func Extensions() []string {
+	m := gcsSchemas()
+	list := make([]string, 0, len(m))
+	for ext := range m {
+		list = append(list, ext)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// FileName returns the name used for the published schema for data files with the given extension.
+func FileName(ext string) string {
+	if fs, ok := gcsSchemas()[strings.ToLower(ext)]; ok {
+		return fs.name + Ext
+	}
+	return ""
+}
+
+// WriteAll writes the schemas for all of the data file types into the directory, creating it if necessary.
+func WriteAll(dir string) error {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return errs.Wrap(err)
+	}
+	for _, ext := range Extensions() {
+		data, err := json.MarshalIndent(ForExtension(ext), "", "  ")
+		if err != nil {
+			return errs.Wrap(err)
+		}
+		p := filepath.Join(dir, FileName(ext))
+		if err = os.WriteFile(p, append(data, '\n'), 0o640); err != nil {
+			return errs.NewWithCause(p, err)
+		}
+	}
+	return nil
+}
+
+func gcsSchemas() map[string]*fileSchema {
+	fileSchemasOnce.Do(func() {
+		defs := commonDefs()
+		listFile := func(title, itemDef string) *Schema {
+			return document(title, map[string]*Schema{
+				"type":    str("Identifies the kind of data held by the file."),
+				"version": integer("The version of the data format."),
+				"rows":    arrayOf("The items.", ref(itemDef)),
+			})
+		}
+		sheet := document("GCS character sheet", map[string]*Schema{
+			"type":            str("Identifies the kind of data held by the file."),
+			"version":         integer("The version of the data format."),
+			"id":              ref("id"),
+			"total_points":    ref("fixed"),
+			"profile":         ref("profile"),
+			"settings":        obj("The sheet settings."),
+			"attributes":      arrayOf("The attribute values.", ref("attribute")),
+			"traits":          arrayOf("The traits.", ref("trait")),
+			"advantages":      arrayOf("Deprecated. The older name for traits.", ref("trait")),
+			"skills":          arrayOf("The skills.", ref("skill")),
+			"spells":          arrayOf("The spells.", ref("spell")),
+			"equipment":       arrayOf("The carried equipment.", ref("equipment")),
+			"other_equipment": arrayOf("The equipment that is not being carried.", ref("equipment")),
+			"notes":           arrayOf("The notes.", ref("note")),
+			"created_date":    str("When the sheet was created."),
+			"modified_date":   str("When the sheet was last modified."),
+			"third_party":     obj("Data maintained by other applications."),
+			"proposals": arrayOf("Named plans for advancing the character that have not been applied yet.",
+				object("", map[string]*Schema{
+					"id":      ref("id"),
+					"name":    str("The name of the proposal."),
+					"created": str("When the proposal was created."),
+					"entity":  obj("The character sheet as it would be with the proposal applied."),
+				})),
+			"calc": obj("Calculated values. Ignored when loading."),
+		})
+		template := document("GCS character template", map[string]*Schema{
+			"type":       str("Identifies the kind of data held by the file."),
+			"version":    integer("The version of the data format."),
+			"id":         ref("id"),
+			"traits":     arrayOf("The traits.", ref("trait")),
+			"advantages": arrayOf("Deprecated. The older name for traits.", ref("trait")),
+			"skills":     arrayOf("The skills.", ref("skill")),
+			"spells":     arrayOf("The spells.", ref("spell")),
+			"equipment":  arrayOf("The equipment.", ref("equipment")),
+			"notes":      arrayOf("The notes.", ref("note")),
+		})
+		encounter := document("GCS encounter", map[string]*Schema{
+			"type":    str("Identifies the kind of data held by the file."),
+			"version": integer("The version of the data format."),
+			"id":      ref("id"),
+			"round":   integer("The current round."),
+			"turn":    integer("The index of the combatant whose turn it is."),
+			"combatants": arrayOf("The combatants, in initiative order.", object("", map[string]*Schema{
+				"id":     ref("id"),
+				"name":   str("The name of the combatant."),
+				"source": str("The path of the character sheet the combatant was added from."),
+				"notes":  str("Notes."),
+				"entity": obj("A snapshot of the combatant's character sheet."),
+			})),
+		})
+		campaign := document("GCS campaign", map[string]*Schema{
+			"type":           str("Identifies the kind of data held by the file."),
+			"version":        integer("The version of the data format."),
+			"id":             ref("id"),
+			"members":        arrayOf("The paths of the member character sheets, relative to the campaign file.", str("")),
+			"sheet_settings": obj("The sheet settings shared by the members."),
+			"page_refs":      obj("The page reference mappings shared by the members, keyed by reference prefix."),
+			"calendar_ref":   str("The name of the calendar used by the campaign."),
+		})
+		attributes := document("GCS attribute definitions", map[string]*Schema{
+			"type":               str("Identifies the kind of data held by the file."),
+			"version":            integer("The version of the data format."),
+			"rows":               arrayOf("The attribute definitions.", ref("attribute_def")),
+			"attribute_settings": arrayOf("Deprecated. The older name for rows.", ref("attribute_def")),
+			"attributes":         arrayOf("Deprecated. The older name for rows.", ref("attribute_def")),
+		})
+		bodyType := document("GCS body type", map[string]*Schema{
+			"type":          str("Identifies the kind of data held by the file."),
+			"version":       integer("The version of the data format."),
+			"name":          str("The name of the body type."),
+			"roll":          ref("dice"),
+			"locations":     arrayOf("The hit locations.", ref("hit_location")),
+			"hit_locations": arrayOf("Deprecated. The older name for locations.", ref("hit_location")),
+		})
+		ancestry := document("GCS ancestry", map[string]*Schema{
+			"name":           str("The name of the ancestry."),
+			"common_options": ref("ancestry_options"),
+			"gender_options": arrayOf("The options for each gender, weighted by how likely they are to be chosen.",
+				object("", map[string]*Schema{
+					"weight": integer("The relative likelihood of this choice."),
+					"value":  ref("ancestry_options"),
+				})),
+		})
+		names := document("GCS name generator", map[string]*Schema{
+			"type":          str("The kind of name generation to perform, e.g. simple or markov_chain."),
+			"training_data": arrayOf("The names used to train the generator.", str("")),
+		})
+		cal := document("GCS calendar", map[string]*Schema{
+			"day_zero_weekday": integer("The index of the week day for the first day of the first year."),
+			"weekdays":         arrayOf("The names of the days of the week.", str("")),
+			"months": arrayOf("The months of the year.", object("", map[string]*Schema{
+				"name": str("The name of the month."),
+				"days": integer("The number of days in the month."),
+			})),
+			"seasons": arrayOf("The seasons of the year.", object("", map[string]*Schema{
+				"name":        str("The name of the season."),
+				"start_month": integer("The month the season starts in."),
+				"start_day":   integer("The day the season starts on."),
+				"end_month":   integer("The month the season ends in."),
+				"end_day":     integer("The day the season ends on."),
+			})),
+			"era":          str("The suffix for years in the current era."),
+			"previous_era": str("The suffix for years before the current era."),
+			"leapyear": object("Describes how leap years are determined.", map[string]*Schema{
+				"month":  integer("The month that receives the extra day."),
+				"every":  integer("Leap years occur every this many years."),
+				"except": integer("Except for years divisible by this."),
+				"unless": integer("Unless the year is also divisible by this."),
+			}),
+		})
+		fileSchemas = map[string]*fileSchema{
+			".gcs":      {name: "sheet", schema: sheet},
+			".gct":      {name: "template", schema: template},
+			".gce":      {name: "encounter", schema: encounter},
+			".gcp":      {name: "campaign", schema: campaign},
+			".adq":      {name: "traits", schema: listFile("GCS traits library", "trait")},
+			".adm":      {name: "trait_modifiers", schema: listFile("GCS trait modifiers library", "trait_modifier")},
+			".eqp":      {name: "equipment", schema: listFile("GCS equipment library", "equipment")},
+			".eqm":      {name: "equipment_modifiers", schema: listFile("GCS equipment modifiers library", "equipment_modifier")},
+			".skl":      {name: "skills", schema: listFile("GCS skills library", "skill")},
+			".spl":      {name: "spells", schema: listFile("GCS spells library", "spell")},
+			".not":      {name: "notes", schema: listFile("GCS notes library", "note")},
+			".attr":     {name: "attributes", schema: attributes},
+			".body":     {name: "body_type", schema: bodyType},
+			".ancestry": {name: "ancestry", schema: ancestry},
+			".names":    {name: "names", schema: names},
+			".calendar": {name: "calendar", schema: cal},
+		}
+		for _, fs := range fileSchemas {
+			fs.schema.Draft = Draft
+			fs.schema.Defs = reachableDefs(fs.schema, defs)
+			fs.schema.compile()
+		}
+	})
+	return fileSchemas
+}
+
+func commonDefs() map[string]*Schema {
+	itemBase := func(kind string, props map[string]*Schema) *Schema {
+		all := map[string]*Schema{
+			"id":        ref("id"),
+			"type":      str("The kind of " + kind + ", with a _container suffix for containers."),
+			"reference": str("Page references."),
+			"notes":     str("Notes."),
+			"vtt_notes": str("Notes for use by virtual tabletops."),
+			"tags":      ref("tags"),
+			"open":      boolean("Whether a container is expanded."),
+			"calc":      obj("Calculated values. Ignored when loading."),
+		}
+		for k, v := range props {
+			all[k] = v
+		}
+		return object("", all)
+	}
+	return map[string]*Schema{
+		"id": {
+			Description: "A UUID, such as 0b2c7a16-e5f0-4c2b-b9c6-3bd2e8ad2c12",
+			Type:        Types{StringType},
+			Pattern:     `^(urn:uuid:)?\{?[0-9a-fA-F]{8}-?[0-9a-fA-F]{4}-?[0-9a-fA-F]{4}-?[0-9a-fA-F]{4}-?[0-9a-fA-F]{12}\}?$`,
+		},
+		"fixed": {
+			Description: "A decimal number, written either as a number or as a string, such as 1.5 or \"-2\"",
+			Type:        Types{NumberType, StringType},
+			Pattern:     `^[+-]?([0-9]+(\.[0-9]*)?|\.[0-9]*)?([eE][+-]?[0-9]+)?$`,
+		},
+		"weight":  str("A weight, with optional units, such as \"1.5 lb\"."),
+		"length":  str("A length, with optional units, such as \"5' 10\\\"\"."),
+		"dice":    str("A dice specification, such as \"3d6+2\"."),
+		"tags":    arrayOf("Tags used for categorizing and searching.", str("")),
+		"formula": str("A formula."),
+		"criteria": object("A comparison used to match values.", map[string]*Schema{
+			"compare":   str("The kind of comparison, e.g. is, contains, at_least."),
+			"qualifier": {Description: "The value to compare against.", Type: Types{StringType, NumberType}},
+		}),
+		"prereq": object("A prerequisite, or a list of them.", map[string]*Schema{
+			"type":           str("The kind of prerequisite, e.g. prereq_list, trait_prereq, skill_prereq."),
+			"all":            boolean("For lists, whether all of the prerequisites must be met, rather than just one."),
+			"when_tl":        ref("criteria"),
+			"prereqs":        arrayOf("The prerequisites within a list.", ref("prereq")),
+			"has":            boolean("Whether the prerequisite must be present, rather than absent."),
+			"name":           ref("criteria"),
+			"level":          ref("criteria"),
+			"notes":          ref("criteria"),
+			"specialization": ref("criteria"),
+			"qualifier":      ref("criteria"),
+			"quantity":       ref("criteria"),
+			"which":          str("The attribute to check."),
+			"combined_with":  str("A second attribute whose value is added to the first."),
+			"sub_type":       str("What aspect of spells to check, e.g. name, tag, college."),
+		}),
+		"feature": object("A feature that modifies the character, such as a bonus.", map[string]*Schema{
+			"type":           str("The kind of feature, e.g. attribute_bonus, skill_bonus, dr_bonus."),
+			"amount":         ref("fixed"),
+			"per_level":      boolean("Whether the amount is multiplied by the number of levels."),
+			"attribute":      str("The attribute affected."),
+			"limitation":     str("Limits the attribute bonus to certain uses, e.g. striking_only."),
+			"situation":      str("The situation in which the modifier applies."),
+			"reduction":      str("The amount of weight reduction, as a percentage or a weight."),
+			"percentage":     ref("fixed"),
+			"percent":        boolean("Whether the amount is a percentage."),
+			"location":       str("The hit location the DR applies to."),
+			"specialization": {Description: "The specialization to match, or the kind of damage the DR applies to.", Type: Types{StringType, ObjectType}},
+			"selection_type": str("How the affected items are selected."),
+			"match":          str("How the affected spells are matched, e.g. all_colleges, spell_name."),
+			"name":           ref("criteria"),
+			"level":          ref("criteria"),
+			"tags":           ref("criteria"),
+			"category":       ref("criteria"),
+		}),
+		"skill_default": object("A skill or attribute a skill defaults to.", map[string]*Schema{
+			"type":           str("The attribute, skill, parry, or block to default to."),
+			"name":           str("The name of the skill to default to."),
+			"specialization": str("The specialization of the skill to default to."),
+			"modifier":       ref("fixed"),
+			"level":          ref("fixed"),
+			"adjusted_level": ref("fixed"),
+			"points":         ref("fixed"),
+		}),
+		"weapon": object("A weapon usage.", map[string]*Schema{
+			"id":   ref("id"),
+			"type": str("The kind of weapon, either melee_weapon or ranged_weapon."),
+			"damage": object("The damage done by the weapon.", map[string]*Schema{
+				"type":                        str("The type of damage, e.g. cut, imp, cr."),
+				"st":                          str("The strength-based damage, e.g. sw or thr."),
+				"base":                        ref("dice"),
+				"armor_divisor":               ref("fixed"),
+				"fragmentation":               ref("dice"),
+				"fragmentation_armor_divisor": ref("fixed"),
+				"fragmentation_type":          str("The type of fragmentation damage."),
+				"modifier_per_die":            ref("fixed"),
+			}),
+			"strength":     str("The minimum strength."),
+			"usage":        str("The usage, e.g. Swung or Thrown."),
+			"usage_notes":  str("Notes about the usage."),
+			"reach":        str("The reach."),
+			"parry":        str("The parry."),
+			"block":        str("The block."),
+			"accuracy":     str("The accuracy."),
+			"range":        str("The range."),
+			"rate_of_fire": str("The rate of fire."),
+			"shots":        str("The number of shots."),
+			"bulk":         str("The bulk."),
+			"recoil":       str("The recoil."),
+			"defaults":     arrayOf("The skills used with the weapon.", ref("skill_default")),
+			"calc":         obj("Calculated values. Ignored when loading."),
+		}),
+		"trait_modifier": itemBase("trait modifier", map[string]*Schema{
+			"name":      str("The name of the modifier."),
+			"cost":      ref("fixed"),
+			"levels":    ref("fixed"),
+			"affects":   str("What the modifier affects, e.g. total, base_only, levels_only."),
+			"cost_type": str("How the cost is applied, e.g. percentage, points, multiplier."),
+			"disabled":  boolean("Whether the modifier is disabled."),
+			"features":  arrayOf("The features provided.", ref("feature")),
+			"children":  arrayOf("The modifiers within a container.", ref("trait_modifier")),
+		}),
+		"trait": itemBase("trait", map[string]*Schema{
+			"name":             str("The name of the trait."),
+			"ancestry":         str("The ancestry, for containers of that type."),
+			"userdesc":         str("A description provided by the user."),
+			"modifiers":        arrayOf("The modifiers.", ref("trait_modifier")),
+			"base_points":      ref("fixed"),
+			"levels":           ref("fixed"),
+			"points_per_level": ref("fixed"),
+			"prereqs":          ref("prereq"),
+			"weapons":          arrayOf("The weapons.", ref("weapon")),
+			"features":         arrayOf("The features provided.", ref("feature")),
+			"cr":               integer("The self-control roll, if any."),
+			"cr_adj":           str("The adjustment applied for the self-control roll."),
+			"container_type":   str("The kind of container, e.g. group, meta_trait, alternative_abilities."),
+			"disabled":         boolean("Whether the trait is disabled."),
+			"round_down":       boolean("Whether the cost is rounded down."),
+			"children":         arrayOf("The traits within a container.", ref("trait")),
+			"categories":       arrayOf("Deprecated. Converted into tags.", str("")),
+			"mental":           boolean("Deprecated. Converted into a tag."),
+			"physical":         boolean("Deprecated. Converted into a tag."),
+			"social":           boolean("Deprecated. Converted into a tag."),
+			"exotic":           boolean("Deprecated. Converted into a tag."),
+			"supernatural":     boolean("Deprecated. Converted into a tag."),
+		}),
+		"skill": itemBase("skill, or technique", map[string]*Schema{
+			"name":                           str("The name of the skill."),
+			"specialization":                 str("The specialization."),
+			"tech_level":                     str("The tech level."),
+			"difficulty":                     str("The base attribute and difficulty, e.g. dx/a."),
+			"points":                         ref("fixed"),
+			"encumbrance_penalty_multiplier": ref("fixed"),
+			"defaulted_from":                 ref("skill_default"),
+			"defaults":                       arrayOf("The defaults.", ref("skill_default")),
+			"default":                        ref("skill_default"),
+			"limit":                          ref("fixed"),
+			"prereqs":                        ref("prereq"),
+			"weapons":                        arrayOf("The weapons.", ref("weapon")),
+			"features":                       arrayOf("The features provided.", ref("feature")),
+			"children":                       arrayOf("The skills within a container.", ref("skill")),
+			"categories":                     arrayOf("Deprecated. Converted into tags.", str("")),
+		}),
+		"spell": itemBase("spell", map[string]*Schema{
+			"name":       str("The name of the spell."),
+			"tech_level": str("The tech level."),
+			"difficulty": str("The base attribute and difficulty, e.g. iq/h."),
+			"college": {
+				Description: "The colleges the spell belongs to.",
+				Type:        Types{ArrayType, StringType},
+				Items:       str(""),
+			},
+			"power_source":     str("The power source."),
+			"spell_class":      str("The class of spell."),
+			"resist":           str("What resists the spell."),
+			"casting_cost":     str("The casting cost."),
+			"maintenance_cost": str("The maintenance cost."),
+			"casting_time":     str("The casting time."),
+			"duration":         str("The duration."),
+			"base_skill":       str("The base skill, for ritual magic spells."),
+			"prereq_count":     integer("The number of prerequisites, for ritual magic spells."),
+			"points":           ref("fixed"),
+			"prereqs":          ref("prereq"),
+			"weapons":          arrayOf("The weapons.", ref("weapon")),
+			"children":         arrayOf("The spells within a container.", ref("spell")),
+			"categories":       arrayOf("Deprecated. Converted into tags.", str("")),
+		}),
+		"equipment_modifier": itemBase("equipment modifier", map[string]*Schema{
+			"name":        str("The name of the modifier."),
+			"cost_type":   str("How the cost is applied."),
+			"weight_type": str("How the weight is applied."),
+			"disabled":    boolean("Whether the modifier is disabled."),
+			"tech_level":  str("The tech level."),
+			"cost":        str("The cost adjustment."),
+			"weight":      str("The weight adjustment."),
+			"features":    arrayOf("The features provided.", ref("feature")),
+			"children":    arrayOf("The modifiers within a container.", ref("equipment_modifier")),
+			"categories":  arrayOf("Deprecated. Converted into tags.", str("")),
+		}),
+		"equipment": itemBase("equipment", map[string]*Schema{
+			"description":              str("The name of the equipment."),
+			"tech_level":               str("The tech level."),
+			"legality_class":           str("The legality class."),
+			"modifiers":                arrayOf("The modifiers.", ref("equipment_modifier")),
+			"quantity":                 ref("fixed"),
+			"value":                    ref("fixed"),
+			"weight":                   ref("weight"),
+			"max_uses":                 integer("The maximum number of uses."),
+			"uses":                     integer("The number of uses remaining."),
+			"prereqs":                  ref("prereq"),
+			"weapons":                  arrayOf("The weapons.", ref("weapon")),
+			"features":                 arrayOf("The features provided.", ref("feature")),
+			"equipped":                 boolean("Whether the equipment is equipped."),
+			"ignore_weight_for_skills": boolean("Whether the weight is ignored for skill encumbrance."),
+			"children":                 arrayOf("The equipment within a container.", ref("equipment")),
+			"categories":               arrayOf("Deprecated. Converted into tags.", str("")),
+		}),
+		"note": itemBase("note", map[string]*Schema{
+			"text":     str("The text of the note."),
+			"children": arrayOf("The notes within a container.", ref("note")),
+		}),
+		"attribute": object("The value of an attribute.", map[string]*Schema{
+			"attr_id": str("The ID of the attribute definition."),
+			"adj":     ref("fixed"),
+			"damage":  ref("fixed"),
+			"calc":    obj("Calculated values. Ignored when loading."),
+		}),
+		"attribute_def": object("The definition of an attribute.", map[string]*Schema{
+			"id":                      str("The ID of the attribute."),
+			"type":                    str("The kind of attribute, e.g. integer, decimal, pool."),
+			"name":                    str("The short name of the attribute."),
+			"full_name":               str("The full name of the attribute."),
+			"attribute_base":          ref("formula"),
+			"cost_per_point":          ref("fixed"),
+			"cost_adj_percent_per_sm": ref("fixed"),
+			"thresholds": arrayOf("The thresholds, for pools.", object("", map[string]*Schema{
+				"state":       str("The name of the state."),
+				"explanation": str("An explanation of the state."),
+				"multiplier":  ref("fixed"),
+				"divisor":     ref("fixed"),
+				"addition":    ref("fixed"),
+				"ops":         arrayOf("The operations affected, e.g. halve_move.", str("")),
+			})),
+		}),
+		"body_type": object("A table of hit locations.", map[string]*Schema{
+			"name":      str("The name of the table."),
+			"roll":      ref("dice"),
+			"locations": arrayOf("The hit locations.", ref("hit_location")),
+		}),
+		"hit_location": object("A hit location.", map[string]*Schema{
+			"id":          str("The ID of the hit location."),
+			"choice_name": str("The name shown when choosing the location."),
+			"table_name":  str("The name shown in the table."),
+			"slots":       integer("The number of slots on the roll table."),
+			"hit_penalty": integer("The penalty to hit the location."),
+			"dr_bonus":    integer("The DR provided by the location itself."),
+			"description": str("The description."),
+			"sub_table":   ref("body_type"),
+			"calc":        obj("Calculated values. Ignored when loading."),
+		}),
+		"profile": object("The character's profile.", map[string]*Schema{
+			"player_name":  str("The name of the player."),
+			"name":         str("The name of the character."),
+			"title":        str("The title."),
+			"organization": str("The organization."),
+			"religion":     str("The religion."),
+			"age":          str("The age."),
+			"birthday":     str("The birthday."),
+			"eyes":         str("The eye color."),
+			"hair":         str("The hair color and style."),
+			"skin":         str("The skin color."),
+			"handedness":   str("The handedness."),
+			"gender":       str("The gender."),
+			"tech_level":   str("The tech level."),
+			"portrait":     str("The portrait image, encoded with base64."),
+			"height":       ref("length"),
+			"weight":       ref("weight"),
+			"SM":           integer("The size modifier."),
+		}),
+		"ancestry_options": object("The options used to randomize a character's description.", map[string]*Schema{
+			"name":               str("The name of the option set."),
+			"height_formula":     ref("formula"),
+			"weight_formula":     ref("formula"),
+			"age_formula":        ref("formula"),
+			"hair_options":       arrayOf("The hair options.", ref("weighted_string")),
+			"eye_options":        arrayOf("The eye options.", ref("weighted_string")),
+			"skin_options":       arrayOf("The skin options.", ref("weighted_string")),
+			"handedness_options": arrayOf("The handedness options.", ref("weighted_string")),
+			"name_generators":    arrayOf("The names of the name generators to use.", str("")),
+		}),
+		"weighted_string": object("A choice, weighted by how likely it is to be chosen.", map[string]*Schema{
+			"weight": integer("The relative likelihood of this choice."),
+			"value":  str("The choice."),
+		}),
+	}
+}
+
+// reachableDefs returns the subset of the definitions that are referenced, directly or indirectly, by the schema.
+func reachableDefs(s *Schema, defs map[string]*Schema) map[string]*Schema {
+	result := make(map[string]*Schema)
+	var walk func(one *Schema)
+	walk = func(one *Schema) {
+		if one == nil {
+			return
+		}
+		if one.Ref != "" {
+			name := strings.TrimPrefix(one.Ref, defsPrefix)
+			if _, exists := result[name]; !exists {
+				if def, ok := defs[name]; ok {
+					result[name] = def
+					walk(def)
+				}
+			}
+		}
+		for _, sub := range one.Properties {
+			walk(sub)
+		}
+		walk(one.Items)
+	}
+	walk(s)
+	return result
+}
+
+func ref(name string) *Schema {
+	return &Schema{Ref: defsPrefix + name}
+}
+
+func document(title string, properties map[string]*Schema) *Schema {
+	return &Schema{Title: title, Type: Types{ObjectType}, Properties: properties}
+}
+
+func object(description string, properties map[string]*Schema) *Schema {
+	return &Schema{Description: description, Type: Types{ObjectType}, Properties: properties}
+}
+
+func obj(description string) *Schema {
+	return &Schema{Description: description, Type: Types{ObjectType}}
+}
+
+func arrayOf(description string, items *Schema) *Schema {
+	return &Schema{Description: description, Type: Types{ArrayType}, Items: items}
+}
+
+func str(description string) *Schema {
+	return &Schema{Description: description, Type: Types{StringType}}
+}
+
+func integer(description string) *Schema {
+	return &Schema{Description: description, Type: Types{IntegerType}}
+}
+
+func boolean(description string) *Schema {
+	return &Schema{Description: description, Type: Types{BooleanType}}
+}
@@ -0,0 +1,371 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/richardwilkes/json"
+	"github.com/richardwilkes/toolbox/i18n"
+	"github.com/richardwilkes/toolbox/txt"
+)
+
+// Draft identifies the version of the JSON Schema specification the schemas conform to.
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// maxProblems limits the number of problems reported for a single document.
+const maxProblems = 10
+
+const defsPrefix = "#/$defs/"
+
+// Possible values for the JSON Schema "type" keyword.
+const (
+	ObjectType  = "object"
+	ArrayType   = "array"
+	StringType  = "string"
+	NumberType  = "number"
+	IntegerType = "integer"
+	BooleanType = "boolean"
+	NullType    = "null"
+)
+
+// Schema holds the subset of JSON Schema used to describe the data files. Properties that are not listed are always
+// permitted, since the data files may contain fields added by newer versions.
+type Schema struct {
+	Draft       string             `json:"$schema,omitempty"`
+	Ref         string             `json:"$ref,omitempty"`
+	Title       string             `json:"title,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Type        Types              `json:"type,omitempty"`
+	Pattern     string             `json:"pattern,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	Items       *Schema            `json:"items,omitempty"`
+	Defs        map[string]*Schema `json:"$defs,omitempty"`
+	pattern     *regexp.Regexp
+}
+
+// Types holds the permitted types of a value.
+type Types []string
+
+// MarshalJSON implements json.Marshaler.
+func (t Types) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (t *Types) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = Types{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*t = list
+	return nil
+}
+
+// Problem describes a single way in which a document does not conform to its schema.
+type Problem struct {
+	// Path is the location of the offending value within the document, in JSONPath notation.
+	Path string
+	// Line and Column identify the position of the offending value within the file. Both are 1-based and will be zero
+	// if the position is not known.
+	Line   int
+	Column int
+	// Item is the name of the closest enclosing item, if any.
+	Item    string
+	Message string
+}
+
+func (p *Problem) String() string {
+	var buffer strings.Builder
+	buffer.WriteString(p.Path)
+	if p.Line > 0 {
+		fmt.Fprintf(&buffer, i18n.Text(" (line %d, column %d)"), p.Line, p.Column)
+	}
+	if p.Item != "" {
+		fmt.Fprintf(&buffer, i18n.Text(" in %q"), p.Item)
+	}
+	buffer.WriteString(": ")
+	buffer.WriteString(p.Message)
+	return buffer.String()
+}
+
+// Error is returned when a document cannot be understood.
+type Error struct {
+	Problems []*Problem
+	// Omitted holds the number of additional problems that were found, but not recorded.
+	Omitted int
+}
+
+func (e *Error) Error() string {
+	var buffer strings.Builder
+	for i, p := range e.Problems {
+		if i != 0 {
+			buffer.WriteByte('\n')
+		}
+		buffer.WriteString(p.String())
+	}
+	if e.Omitted > 0 {
+		fmt.Fprintf(&buffer, i18n.Text("\n…and %d more"), e.Omitted)
+	}
+	return buffer.String()
+}
+
+// ClearPositions removes the line and column information from the problems. Used when the document was assembled from
+// multiple files, making the positions meaningless.
+func (e *Error) ClearPositions() {
+	for _, p := range e.Problems {
+		p.Line = 0
+		p.Column = 0
+	}
+}
+
+func (e *Error) add(data []byte, n *node, offset int, msg string) {
+	if len(e.Problems) >= maxProblems {
+		e.Omitted++
+		return
+	}
+	p := &Problem{Message: msg}
+	p.Line, p.Column = lineAndColumn(data, offset)
+	if n != nil {
+		p.Path = n.path()
+		p.Item = n.itemName()
+	} else {
+		p.Path = "$"
+	}
+	e.Problems = append(e.Problems, p)
+}
+
+// compile prepares the schema and all of its sub-schemas for use.
+func (s *Schema) compile() {
+	if s == nil {
+		return
+	}
+	if s.Pattern != "" && s.pattern == nil {
+		s.pattern = regexp.MustCompile(s.Pattern)
+	}
+	for _, one := range s.Properties {
+		one.compile()
+	}
+	s.Items.compile()
+	for _, one := range s.Defs {
+		one.compile()
+	}
+}
+
+// Validate the data against the schema. Returns nil if the data conforms, or an *Error describing the problems if not.
+func (s *Schema) Validate(data []byte) *Error {
+	var e Error
+	root, err := parse(data)
+	if err != nil {
+		var pe *parseError
+		if errors.As(err, &pe) {
+			e.add(data, nil, pe.offset, pe.message)
+		}
+		return &e
+	}
+	s.validate(s, root, data, &e)
+	if len(e.Problems) == 0 {
+		return nil
+	}
+	return &e
+}
+
+func (s *Schema) validate(root *Schema, n *node, data []byte, e *Error) {
+	if s.Ref != "" {
+		if ref, ok := root.Defs[strings.TrimPrefix(s.Ref, defsPrefix)]; ok {
+			ref.validate(root, n, data, e)
+		}
+		return
+	}
+	if len(s.Type) != 0 && !s.permits(n) {
+		e.add(data, n, n.start, fmt.Sprintf(i18n.Text("expected %s, but found %s"), describeTypes(s.Type),
+			describeNode(n)))
+		return
+	}
+	switch n.kind {
+	case objectKind:
+		for i, key := range n.keys {
+			if sub, ok := s.Properties[key]; ok {
+				sub.validate(root, n.values[i], data, e)
+			}
+		}
+	case arrayKind:
+		if s.Items != nil {
+			for _, child := range n.values {
+				s.Items.validate(root, child, data, e)
+			}
+		}
+	case stringKind:
+		if s.pattern != nil && !s.pattern.MatchString(n.text) {
+			msg := fmt.Sprintf(i18n.Text("%q is not valid"), n.text)
+			if s.Description != "" {
+				msg += "; " + i18n.Text("expected ") + txt.FirstToLower(strings.TrimSuffix(s.Description, "."))
+			}
+			e.add(data, n, n.start, msg)
+		}
+	default:
+	}
+}
+
+func (s *Schema) permits(n *node) bool {
+	for _, t := range s.Type {
+		switch t {
+		case ObjectType:
+			if n.kind == objectKind {
+				return true
+			}
+		case ArrayType:
+			if n.kind == arrayKind {
+				return true
+			}
+		case StringType:
+			if n.kind == stringKind {
+				return true
+			}
+		case NumberType:
+			if n.kind == numberKind {
+				return true
+			}
+		case IntegerType:
+			if n.kind == numberKind && !strings.ContainsAny(n.text, ".eE") {
+				return true
+			}
+		case BooleanType:
+			if n.kind == booleanKind {
+				return true
+			}
+		case NullType:
+			if n.kind == nullKind {
+				return true
+			}
+		}
+	}
+	// A null is accepted by the loader wherever an object, array or string is permitted, so treat it as absent.
+	return n.kind == nullKind
+}
+
+func describeTypes(types Types) string {
+	list := make([]string, len(types))
+	for i, t := range types {
+		list[i] = describeType(t)
+	}
+	switch len(list) {
+	case 1:
+		return list[0]
+	case 2:
+		return list[0] + i18n.Text(" or ") + list[1]
+	default:
+		return strings.Join(list[:len(list)-1], ", ") + i18n.Text(", or ") + list[len(list)-1]
+	}
+}
+
+func describeType(t string) string {
+	switch t {
+	case ObjectType:
+		return i18n.Text("an object")
+	case ArrayType:
+		return i18n.Text("an array")
+	case StringType:
+		return i18n.Text("a string")
+	case NumberType:
+		return i18n.Text("a number")
+	case IntegerType:
+		return i18n.Text("a whole number")
+	case BooleanType:
+		return i18n.Text("true or false")
+	case NullType:
+		return i18n.Text("null")
+	default:
+		return t
+	}
+}
+
+func describeNode(n *node) string {
+	switch n.kind {
+	case objectKind:
+		return i18n.Text("an object")
+	case arrayKind:
+		return i18n.Text("an array")
+	case stringKind:
+		return i18n.Text("the string ") + strconv.Quote(n.text)
+	case numberKind:
+		return i18n.Text("the number ") + n.text
+	case booleanKind:
+		return n.text
+	default:
+		return i18n.Text("null")
+	}
+}
+
+// Locate attempts to determine where within the data the decoding error occurred. Returns nil if the error does not
+// carry position information.
+func Locate(data []byte, err error) *Error {
+	var e Error
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.As(err, &typeErr):
+		var n *node
+		if root, parseErr := parse(data); parseErr == nil {
+			n = root.locate(int(typeErr.Offset))
+		}
+		offset := int(typeErr.Offset)
+		if n != nil {
+			offset = n.start
+		}
+		e.add(data, n, offset, fmt.Sprintf(i18n.Text("expected %s, but found %s"), describeGoType(typeErr.Type),
+			typeErr.Value))
+	case errors.As(err, &syntaxErr):
+		e.add(data, nil, int(syntaxErr.Offset), syntaxErr.Error())
+	default:
+		return nil
+	}
+	return &e
+}
+
+func describeGoType(t reflect.Type) string {
+	if t == nil {
+		return i18n.Text("a different value")
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8,
+		reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return describeType(IntegerType)
+	case reflect.Float32, reflect.Float64:
+		return describeType(NumberType)
+	case reflect.String:
+		return describeType(StringType)
+	case reflect.Bool:
+		return describeType(BooleanType)
+	case reflect.Slice, reflect.Array:
+		return describeType(ArrayType)
+	case reflect.Struct, reflect.Map:
+		return describeType(ObjectType)
+	case reflect.Pointer:
+		return describeGoType(t.Elem())
+	default:
+		return i18n.Text("a different value")
+	}
+}
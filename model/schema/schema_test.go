@@ -0,0 +1,121 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package schema_test
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/richardwilkes/gcs/model/schema"
+	"github.com/richardwilkes/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmbeddedDataConforms(t *testing.T) {
+	count := 0
+	for _, dir := range []string{"../gurps/data", "../gurps/testdata", "../gurps/ancestry/data", "../gurps/settings/data"} {
+		require.NoError(t, filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			s := schema.ForExtension(filepath.Ext(p))
+			if s == nil {
+				return nil
+			}
+			data, err := os.ReadFile(p)
+			require.NoError(t, err)
+			if problems := s.Validate(data); problems != nil {
+				t.Errorf("%s: %s", p, problems)
+			}
+			count++
+			return nil
+		}))
+	}
+	assert.NotZero(t, count)
+}
+
+func TestProblemsAreLocated(t *testing.T) {
+	data := []byte(`{
+	"type": "trait_list",
+	"version": 4,
+	"rows": [
+		{
+			"type": "trait_container",
+			"name": "Senses",
+			"children": [
+				{ "type": "trait", "name": "Acute Vision", "base_points": "two" },
+				{ "type": "trait", "name": "Night Vision", "tags": "Physical" }
+			]
+		}
+	]
+}`)
+	problems := schema.ForExtension(".adq").Validate(data)
+	require.NotNil(t, problems)
+	require.Len(t, problems.Problems, 2)
+
+	p := problems.Problems[0]
+	assert.Equal(t, "$.rows[0].children[0].base_points", p.Path)
+	assert.Equal(t, 9, p.Line)
+	assert.Equal(t, 63, p.Column)
+	assert.Equal(t, "Acute Vision", p.Item)
+
+	p = problems.Problems[1]
+	assert.Equal(t, "$.rows[0].children[1].tags", p.Path)
+	assert.Equal(t, 10, p.Line)
+	assert.Equal(t, "Night Vision", p.Item)
+	assert.Contains(t, p.Message, "an array")
+
+	problems = schema.ForExtension(".adq").Validate([]byte("{\n\t\"rows\": [\n\t\t{ \"name\": \"x\" \n\t]\n}"))
+	require.NotNil(t, problems)
+	require.Len(t, problems.Problems, 1)
+	assert.Equal(t, 4, problems.Problems[0].Line)
+	assert.Equal(t, 2, problems.Problems[0].Column)
+
+	assert.Nil(t, schema.ForExtension(".adq").Validate([]byte(`{"rows":[{"name":"é😀","levels":"-1.5"}]}`)))
+	assert.Nil(t, schema.ForExtension(".adq").Validate([]byte(`{"rows":[{"name":"x","levels":"-"}]}`)))
+}
+
+func TestLocate(t *testing.T) {
+	data := []byte("{\n  \"rows\": [\n    { \"name\": \"Knife\", \"uses\": 1.5 }\n  ]\n}")
+	var target struct {
+		Rows []struct {
+			Name string `json:"name"`
+			Uses int    `json:"uses"`
+		} `json:"rows"`
+	}
+	err := json.Unmarshal(data, &target)
+	require.Error(t, err)
+	located := schema.Locate(data, err)
+	require.NotNil(t, located)
+	require.Len(t, located.Problems, 1)
+	p := located.Problems[0]
+	assert.Equal(t, "$.rows[0].uses", p.Path)
+	assert.Equal(t, 3, p.Line)
+	assert.Equal(t, 32, p.Column)
+	assert.Equal(t, "Knife", p.Item)
+}
+
+func TestSchemasArePublishable(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, schema.WriteAll(dir))
+	for _, ext := range schema.Extensions() {
+		data, err := os.ReadFile(filepath.Join(dir, schema.FileName(ext)))
+		require.NoError(t, err)
+		var s schema.Schema
+		require.NoError(t, json.Unmarshal(data, &s))
+		assert.Equal(t, schema.Draft, s.Draft)
+		assert.NotEmpty(t, s.Title, ext)
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"runtime"
 	"strings"
 
+	"github.com/richardwilkes/gcs/model/filter"
 	"github.com/richardwilkes/gcs/model/fxp"
 	"github.com/richardwilkes/gcs/model/gurps"
 	"github.com/richardwilkes/gcs/model/gurps/settings"
@@ -63,6 +64,7 @@ type Settings struct {
 	Fonts              theme.Fonts                `json:"fonts"`
 	QuickExports       *gurps.QuickExports        `json:"quick_exports,omitempty"`
 	Sheet              *gurps.SheetSettings       `json:"sheet_settings,omitempty"`
+	Filters            filter.Filters             `json:"filters,omitempty"`
 }
 
 // Default returns new default settings.
@@ -109,6 +111,9 @@ func (s *Settings) EnsureValidity() {
 	}
 	if len(s.LibrarySet) == 0 {
 		s.LibrarySet = library.NewLibraries()
+	} else {
+		s.LibrarySet.Master()
+		s.LibrarySet.User()
 	}
 	if s.LastDirs == nil {
 		s.LastDirs = make(map[string]string)
@@ -0,0 +1,155 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package trash
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/richardwilkes/gcs/model/id"
+	"github.com/richardwilkes/gcs/model/jio"
+	"github.com/richardwilkes/gcs/model/library"
+	"github.com/richardwilkes/toolbox/cmdline"
+	"github.com/richardwilkes/toolbox/errs"
+	"github.com/richardwilkes/toolbox/log/jot"
+	"github.com/richardwilkes/toolbox/xio/fs/paths"
+)
+
+const (
+	entryVersion  = 1
+	entryFileName = "entry.json"
+)
+
+var (
+	defaultTrashOnce sync.Once
+	defaultTrash     *Trash
+)
+
+// Entry holds information about a file or directory that has been placed in the trash.
+type Entry struct {
+	Version      int      `json:"version"`
+	OriginalPath string   `json:"path"`
+	Timestamp    jio.Time `json:"timestamp"`
+	dir          string
+}
+
+// Trash holds files and directories that have been deleted, so that they may be restored later. Each item is kept in
+// its own directory along with a record of where it came from.
+type Trash struct {
+	root string
+}
+
+// Default returns the trash used by the application.
+func Default() *Trash {
+	defaultTrashOnce.Do(func() {
+		defaultTrash = New(filepath.Join(paths.AppDataDir(), cmdline.AppCmdName+"_trash"))
+	})
+	return defaultTrash
+}
+
+// New creates a new trash that keeps its data in the root directory.
+func New(root string) *Trash {
+	return &Trash{root: root}
+}
+
+// Discard moves the file or directory at the path into the trash.
+func (t *Trash) Discard(p string) (*Entry, error) {
+	var err error
+	if p, err = filepath.Abs(p); err != nil {
+		return nil, errs.Wrap(err)
+	}
+	if !library.Exists(p) {
+		return nil, errs.Wrap(&fs.PathError{Op: "discard", Path: p, Err: fs.ErrNotExist})
+	}
+	e := &Entry{
+		Version:      entryVersion,
+		OriginalPath: p,
+		Timestamp:    jio.Now(),
+		dir:          filepath.Join(t.root, id.NewUUID().String()),
+	}
+	if err = os.MkdirAll(e.dir, 0o750); err != nil {
+		return nil, errs.NewWithCause(e.dir, err)
+	}
+	if err = jio.SaveToFile(context.Background(), filepath.Join(e.dir, entryFileName), e); err != nil {
+		e.remove()
+		return nil, err
+	}
+	if err = library.MovePath(p, e.contentPath()); err != nil {
+		e.remove()
+		return nil, err
+	}
+	return e, nil
+}
+
+// Entries returns the entries currently in the trash, sorted from newest to oldest.
+func (t *Trash) Entries() []*Entry {
+	dirs, err := os.ReadDir(t.root)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			jot.Warn(errs.NewWithCause(t.root, err))
+		}
+		return nil
+	}
+	list := make([]*Entry, 0, len(dirs))
+	for _, one := range dirs {
+		if !one.IsDir() {
+			continue
+		}
+		e := &Entry{dir: filepath.Join(t.root, one.Name())}
+		if err = jio.LoadFromFile(context.Background(), filepath.Join(e.dir, entryFileName), e); err != nil ||
+			e.Version > entryVersion {
+			jot.Warn(errs.NewWithCause("unable to read trash entry "+e.dir, err))
+			continue
+		}
+		list = append(list, e)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Timestamp.After(list[j].Timestamp) })
+	return list
+}
+
+// Purge permanently removes the entries that were placed in the trash longer ago than the maximum age.
+func (t *Trash) Purge(maxAge time.Duration) {
+	for _, e := range t.Entries() {
+		if time.Since(time.Time(e.Timestamp)) > maxAge {
+			e.remove()
+		}
+	}
+}
+
+// Restore the entry to its original location. This will fail if something else now occupies that location.
+func (e *Entry) Restore() error {
+	if e.dir == "" {
+		return errs.New("entry is no longer in the trash")
+	}
+	if err := library.MovePath(e.contentPath(), e.OriginalPath); err != nil {
+		return err
+	}
+	e.remove()
+	return nil
+}
+
+func (e *Entry) contentPath() string {
+	return filepath.Join(e.dir, filepath.Base(e.OriginalPath))
+}
+
+func (e *Entry) remove() {
+	if err := os.RemoveAll(e.dir); err != nil {
+		jot.Warn(errs.NewWithCause(e.dir, err))
+	}
+	e.dir = ""
+}
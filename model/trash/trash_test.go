@@ -0,0 +1,65 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package trash_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/richardwilkes/gcs/model/trash"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrash(t *testing.T) {
+	dir := t.TempDir()
+	tr := trash.New(filepath.Join(dir, "trash"))
+	file := filepath.Join(dir, "lib", "Bob.gcs")
+	require.NoError(t, os.MkdirAll(filepath.Dir(file), 0o750))
+	require.NoError(t, os.WriteFile(file, []byte("bob"), 0o640))
+
+	entry, err := tr.Discard(file)
+	require.NoError(t, err)
+	assert.NoFileExists(t, file)
+	assert.Equal(t, file, entry.OriginalPath)
+	entries := tr.Entries()
+	require.Len(t, entries, 1)
+	assert.Equal(t, file, entries[0].OriginalPath)
+
+	_, err = tr.Discard(file)
+	assert.Error(t, err, "nothing left to discard")
+
+	require.NoError(t, entry.Restore())
+	data, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, "bob", string(data))
+	assert.Empty(t, tr.Entries())
+	assert.Error(t, entry.Restore(), "already restored")
+
+	// Restoring must not clobber a replacement
+	entry, err = tr.Discard(filepath.Dir(file))
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Dir(file), 0o750))
+	assert.Error(t, entry.Restore())
+	require.NoError(t, os.Remove(filepath.Dir(file)))
+	require.NoError(t, entry.Restore())
+	assert.FileExists(t, file)
+
+	_, err = tr.Discard(file)
+	require.NoError(t, err)
+	tr.Purge(time.Hour)
+	assert.Len(t, tr.Entries(), 1)
+	tr.Purge(0)
+	assert.Empty(t, tr.Entries())
+}
@@ -76,8 +76,13 @@ func New(filePath string, pageLoadedCallback func()) (*PDF, error) {
 	if err != nil {
 		return nil, errs.Wrap(err)
 	}
-	var doc *pdf.Document
-	if doc, err = pdf.New(data, 0); err != nil {
+	return NewFromData(data, pageLoadedCallback)
+}
+
+// NewFromData creates a new PDF page renderer for PDF data held in memory.
+func NewFromData(data []byte, pageLoadedCallback func()) (*PDF, error) {
+	doc, err := pdf.New(data, 0)
+	if err != nil {
 		return nil, errs.Wrap(err)
 	}
 	display := unison.PrimaryDisplay()
@@ -21,6 +21,8 @@ var (
 	BackSVG                    = mustSVG(256, 512, "M137.4 406.6 9.4 279.5C3.125 272.4 0 264.2 0 255.1s3.125-16.38 9.375-22.63l128-127.1c9.156-9.156 22.91-11.9 34.88-6.943S192 115.1 192 128v255.1c0 12.94-7.781 24.62-19.75 29.58s-25.75 3.12-34.85-6.08z")
 	BookmarkSVG                = mustSVG(384, 512, "M384 48v464L192 400 0 512V48C0 21.5 21.5 0 48 0h288c26.5 0 48 21.5 48 48z")
 	CheckmarkSVG               = mustSVG(172, 172, "m149.285 31.294-11.86-8.063c-3.283-2.222-7.779-1.37-9.975 1.887l-58.143 85.741-26.72-26.72c-2.791-2.79-7.34-2.79-10.13 0L22.3 94.295c-2.79 2.79-2.79 7.339 0 10.156l41.088 41.087c2.3 2.3 5.917 4.058 9.173 4.058 3.257 0 6.538-2.042 8.657-5.117l69.979-103.236c2.222-3.256 1.37-7.727-1.913-9.95z")
+	CaretDownSVG               = mustSVG(320, 512, "M310.6 246.6l-127.1 128C176.4 380.9 168.2 384 160 384s-16.38-3.125-22.63-9.375l-127.1-128C.2244 237.5-2.516 223.7 2.438 211.8S19.07 192 32 192h255.1c12.94 0 24.62 7.781 29.58 19.75S319.8 237.5 310.6 246.6z")
+	CaretUpSVG                 = mustSVG(320, 512, "M9.39 265.4l127.1-128c12.5-12.5 32.75-12.5 45.25 0l127.1 128c9.156 9.156 11.9 22.91 6.943 34.88S299.9 320 287.1 320H32.01c-12.94 0-24.62-7.781-29.58-19.75S.2333 274.5 9.39 265.4z")
 	CircledAddSVG              = mustSVG(512, 512, "M0 256C0 114.6 114.6 0 256 0s256 114.6 256 256-114.6 256-256 256S0 397.4 0 256zm256 112c13.3 0 24-10.7 24-24v-64h64c13.3 0 24-10.7 24-24s-10.7-24-24-24h-64v-64c0-13.3-10.7-24-24-24s-24 10.7-24 24v64h-64c-13.3 0-24 10.7-24 24s10.7 24 24 24h64v64c0 13.3 10.7 24 24 24z")
 	CircledCheckSVG            = mustSVG(26, 26, "M13 .188C5.926.188.187 5.926.187 13c0 7.074 5.739 12.813 12.813 12.813 7.074 0 12.813-5.739 12.813-12.813C25.813 5.926 20.073.187 13 .187Zm6.734 8.847-6.87 10.133c-.204.3-.528.504-.848.504-.32 0-.672-.176-.899-.399l-4.031-4.035a.707.707 0 0 1 0-.996l.996-.996a.703.703 0 0 1 .992 0l2.625 2.621 5.703-8.414a.712.712 0 0 1 .98-.187l1.169.793a.706.706 0 0 1 .183.976Z")
 	CircledVerticalEllipsisSVG = mustSVG(512, 512, "M256 0C114.6 0 0 114.6 0 256s114.6 256 256 256 256-114.6 256-256S397.4 0 256 0zm0 40c30.93 0 56 25.07 56 56 0 30.9-25.07 56-56 56s-56-25.1-56-56c0-30.93 25.07-56 56-56zm0 160c30.93 0 56 25.1 56 56s-25.07 56-56 56-56-25.1-56-56 25.07-56 56-56zm0 160c30.93 0 56 25.1 56 56s-25.07 56-56 56-56-25.1-56-56 25.07-56 56-56z")
@@ -27,6 +27,8 @@ var (
 	Duplicate *unison.Action
 	// OpenEditor opens an editor for the selected item(s).
 	OpenEditor *unison.Action
+	// BulkEdit opens an editor that applies changes to all of the selected items at once.
+	BulkEdit *unison.Action
 	// CopyToSheet copies the selected items to the foremost character sheet.
 	CopyToSheet *unison.Action
 	// CopyToTemplate copies the selected items to the foremost template.
@@ -116,6 +118,13 @@ func registerEditMenuActions() {
 		EnabledCallback: unison.RouteActionToFocusEnabledFunc,
 		ExecuteCallback: unison.RouteActionToFocusExecuteFunc,
 	}
+	BulkEdit = &unison.Action{
+		ID:              constants.BulkEditItemID,
+		Title:           i18n.Text("Bulk Edit…"),
+		KeyBinding:      unison.KeyBinding{KeyCode: unison.KeyI, Modifiers: unison.ShiftModifier | unison.OSMenuCmdModifier()},
+		EnabledCallback: unison.RouteActionToFocusEnabledFunc,
+		ExecuteCallback: unison.RouteActionToFocusExecuteFunc,
+	}
 	CopyToSheet = &unison.Action{
 		ID:              constants.CopyToSheetItemID,
 		Title:           i18n.Text("Copy to Character Sheet"),
@@ -204,8 +213,8 @@ func registerEditMenuActions() {
 		ID:              constants.SwapDefaultsItemID,
 		Title:           i18n.Text("Swap Defaults"),
 		KeyBinding:      unison.KeyBinding{KeyCode: unison.KeyX, Modifiers: unison.ShiftModifier | unison.OSMenuCmdModifier()},
-		EnabledCallback: notEnabled,
-		ExecuteCallback: unimplemented,
+		EnabledCallback: unison.RouteActionToFocusEnabledFunc,
+		ExecuteCallback: unison.RouteActionToFocusExecuteFunc,
 	}
 	ConvertToContainer = &unison.Action{
 		ID:              constants.ConvertToContainerItemID,
@@ -223,6 +232,7 @@ func registerEditMenuActions() {
 	settings.RegisterKeyBinding("delete", unison.DeleteAction)
 	settings.RegisterKeyBinding("select.all", unison.SelectAllAction)
 	settings.RegisterKeyBinding("open.editor", OpenEditor)
+	settings.RegisterKeyBinding("bulk.edit", BulkEdit)
 	settings.RegisterKeyBinding("copy.to_sheet", CopyToSheet)
 	settings.RegisterKeyBinding("copy.to_template", CopyToTemplate)
 	settings.RegisterKeyBinding("apply.template", ApplyTemplate)
@@ -251,6 +261,7 @@ func setupEditMenu(bar unison.Menu) {
 
 	i = insertSeparator(m, m.Item(unison.SelectAllItemID).Index()+1)
 	i = insertItem(m, i, OpenEditor.NewMenuItem(f))
+	i = insertItem(m, i, BulkEdit.NewMenuItem(f))
 
 	i = insertSeparator(m, i)
 	i = insertItem(m, i, CopyToSheet.NewMenuItem(f))
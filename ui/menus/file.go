@@ -19,9 +19,11 @@ import (
 	"strings"
 
 	"github.com/richardwilkes/gcs/constants"
+	"github.com/richardwilkes/gcs/model/campaign"
 	"github.com/richardwilkes/gcs/model/gurps"
 	"github.com/richardwilkes/gcs/model/gurps/datafile"
 	"github.com/richardwilkes/gcs/model/gurps/export"
+	"github.com/richardwilkes/gcs/model/jio"
 	"github.com/richardwilkes/gcs/model/library"
 	"github.com/richardwilkes/gcs/model/settings"
 	"github.com/richardwilkes/gcs/ui/workspace"
@@ -42,6 +44,10 @@ var (
 	NewCharacterSheet *unison.Action
 	// NewCharacterTemplate creates a new character template.
 	NewCharacterTemplate *unison.Action
+	// NewEncounter creates a new encounter for tracking combat.
+	NewEncounter *unison.Action
+	// NewCampaign creates a new campaign for grouping character sheets.
+	NewCampaign *unison.Action
 	// NewTraitsLibrary creates a new traits library.
 	NewTraitsLibrary *unison.Action
 	// NewTraitModifiersLibrary creates a new trait modifiers library.
@@ -64,6 +70,12 @@ var (
 	Save *unison.Action
 	// SaveAs saves to a new file.
 	SaveAs *unison.Action
+	// ConvertToSplitFiles converts the current file to the split storage format.
+	ConvertToSplitFiles *unison.Action
+	// ConvertToSingleFile converts the current file from the split storage format back to a single file.
+	ConvertToSingleFile *unison.Action
+	// RevisionHistory shows the prior revisions of the current file.
+	RevisionHistory *unison.Action
 	// Print the content.
 	Print *unison.Action
 )
@@ -85,6 +97,20 @@ func registerFileMenuActions() {
 			workspace.DisplayNewDockable(nil, sheet.NewTemplate("untitled"+library.TemplatesExt, gurps.NewTemplate()))
 		},
 	}
+	NewEncounter = &unison.Action{
+		ID:    constants.NewEncounterItemID,
+		Title: i18n.Text("New Encounter"),
+		ExecuteCallback: func(_ *unison.Action, _ any) {
+			workspace.DisplayNewDockable(nil, sheet.NewEncounter("untitled"+library.EncounterExt, gurps.NewEncounter()))
+		},
+	}
+	NewCampaign = &unison.Action{
+		ID:    constants.NewCampaignItemID,
+		Title: i18n.Text("New Campaign"),
+		ExecuteCallback: func(_ *unison.Action, _ any) {
+			workspace.DisplayNewDockable(nil, sheet.NewCampaign("untitled"+library.CampaignExt, campaign.NewCampaign()))
+		},
+	}
 	NewTraitsLibrary = &unison.Action{
 		ID:    constants.NewTraitsLibraryItemID,
 		Title: i18n.Text("New Traits Library"),
@@ -198,6 +224,45 @@ func registerFileMenuActions() {
 		EnabledCallback: unison.RouteActionToFocusEnabledFunc,
 		ExecuteCallback: unison.RouteActionToFocusExecuteFunc,
 	}
+	ConvertToSplitFiles = &unison.Action{
+		ID:    constants.ConvertToSplitFilesItemID,
+		Title: i18n.Text("Convert to Split Files"),
+		EnabledCallback: func(_ *unison.Action, _ any) bool {
+			p, ok := convertibleFilePath()
+			return ok && !jio.IsSplitPath(p)
+		},
+		ExecuteCallback: func(_ *unison.Action, _ any) {
+			if p, ok := convertibleFilePath(); ok {
+				convertFileStorage(p, jio.SplitFile)
+			}
+		},
+	}
+	ConvertToSingleFile = &unison.Action{
+		ID:    constants.ConvertToSingleFileItemID,
+		Title: i18n.Text("Convert to Single File"),
+		EnabledCallback: func(_ *unison.Action, _ any) bool {
+			p, ok := convertibleFilePath()
+			return ok && jio.IsSplitPath(p)
+		},
+		ExecuteCallback: func(_ *unison.Action, _ any) {
+			if p, ok := convertibleFilePath(); ok {
+				convertFileStorage(p, jio.JoinFile)
+			}
+		},
+	}
+	RevisionHistory = &unison.Action{
+		ID:    constants.RevisionHistoryItemID,
+		Title: i18n.Text("Revision History…"),
+		EnabledCallback: func(_ *unison.Action, _ any) bool {
+			_, ok := revisionHistoryFilePath()
+			return ok
+		},
+		ExecuteCallback: func(_ *unison.Action, _ any) {
+			if p, ok := revisionHistoryFilePath(); ok {
+				workspace.ShowRevisionHistory(p)
+			}
+		},
+	}
 	Print = &unison.Action{
 		ID:              constants.PrintItemID,
 		Title:           i18n.Text("Print…"),
@@ -219,6 +284,9 @@ func registerFileMenuActions() {
 	settings.RegisterKeyBinding("close", CloseTab)
 	settings.RegisterKeyBinding("save", Save)
 	settings.RegisterKeyBinding("save_as", SaveAs)
+	settings.RegisterKeyBinding("convert_to_split_files", ConvertToSplitFiles)
+	settings.RegisterKeyBinding("convert_to_single_file", ConvertToSingleFile)
+	settings.RegisterKeyBinding("revision_history", RevisionHistory)
 	settings.RegisterKeyBinding("print", Print)
 }
 
@@ -227,6 +295,8 @@ func setupFileMenu(bar unison.Menu) {
 	m := bar.Menu(unison.FileMenuID)
 	i := insertItem(m, 0, NewCharacterSheet.NewMenuItem(f))
 	i = insertItem(m, i, NewCharacterTemplate.NewMenuItem(f))
+	i = insertItem(m, i, NewEncounter.NewMenuItem(f))
+	i = insertItem(m, i, NewCampaign.NewMenuItem(f))
 
 	i = insertSeparator(m, i)
 	i = insertItem(m, i, NewTraitsLibrary.NewMenuItem(f))
@@ -248,12 +318,53 @@ func setupFileMenu(bar unison.Menu) {
 	i = insertSeparator(m, i)
 	i = insertItem(m, i, Save.NewMenuItem(f))
 	i = insertItem(m, i, SaveAs.NewMenuItem(f))
+	i = insertItem(m, i, ConvertToSplitFiles.NewMenuItem(f))
+	i = insertItem(m, i, ConvertToSingleFile.NewMenuItem(f))
+	i = insertItem(m, i, RevisionHistory.NewMenuItem(f))
 	i = insertMenu(m, i, f.NewMenu(constants.ExportToMenuID, i18n.Text("Export To…"), exportToUpdater))
 
 	i = insertSeparator(m, i)
 	insertItem(m, i, Print.NewMenuItem(f))
 }
 
+// convertibleFilePath returns the path of the file backing the active dockable, if it holds GCS data that has been saved
+// to disk and has no unsaved changes.
+func convertibleFilePath() (string, bool) {
+	fbd, ok := workspace.ActiveDockable().(workspace.FileBackedDockable)
+	if !ok || fbd.Modified() {
+		return "", false
+	}
+	p := fbd.BackingFilePath()
+	if !library.FileInfoFor(p).IsGCSData {
+		return "", false
+	}
+	if _, err := os.Stat(p); err != nil {
+		return "", false
+	}
+	return p, true
+}
+
+// revisionHistoryFilePath returns the path of the file backing the active dockable, if it has a revision history.
+func revisionHistoryFilePath() (string, bool) {
+	fbd, ok := workspace.ActiveDockable().(workspace.FileBackedDockable)
+	if !ok {
+		return "", false
+	}
+	if r, isRecoverable := fbd.(workspace.Recoverable); isRecoverable && r.NeedsSaveAs() {
+		return "", false
+	}
+	p := fbd.BackingFilePath()
+	return p, workspace.CanShowRevisionHistory(p)
+}
+
+func convertFileStorage(p string, converter func(string) error) {
+	if err := converter(p); err != nil {
+		unison.ErrorDialogWithError(i18n.Text("Unable to convert ")+filepath.Base(p), err)
+		return
+	}
+	workspace.RefreshLibraries()
+}
+
 func recentFilesUpdater(menu unison.Menu) {
 	menu.RemoveAll()
 	list := settings.Global().ListRecentFiles()
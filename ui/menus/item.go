@@ -14,6 +14,7 @@ package menus
 import (
 	"github.com/richardwilkes/gcs/constants"
 	"github.com/richardwilkes/gcs/model/settings"
+	"github.com/richardwilkes/gcs/ui/workspace"
 	"github.com/richardwilkes/toolbox/i18n"
 	"github.com/richardwilkes/unison"
 )
@@ -65,6 +66,8 @@ var (
 	OpenOnePageReference *unison.Action
 	// OpenEachPageReference opens each page reference associated with the selected items.
 	OpenEachPageReference *unison.Action
+	// RollHistory shows the Roll History.
+	RollHistory *unison.Action
 )
 
 func registerItemMenuActions() {
@@ -250,6 +253,12 @@ func registerItemMenuActions() {
 		EnabledCallback: unison.RouteActionToFocusEnabledFunc,
 		ExecuteCallback: unison.RouteActionToFocusExecuteFunc,
 	}
+	RollHistory = &unison.Action{
+		ID:              constants.RollHistoryItemID,
+		Title:           i18n.Text("Roll History"),
+		KeyBinding:      unison.KeyBinding{KeyCode: unison.KeyL, Modifiers: unison.ShiftModifier | unison.OSMenuCmdModifier()},
+		ExecuteCallback: func(_ *unison.Action, _ any) { workspace.ShowRollHistory() },
+	}
 
 	settings.RegisterKeyBinding("new.adq", NewTrait)
 	settings.RegisterKeyBinding("new.adq.container", NewTraitContainer)
@@ -274,6 +283,7 @@ func registerItemMenuActions() {
 	settings.RegisterKeyBinding("new.ranged", NewRangedWeapon)
 	settings.RegisterKeyBinding("pageref.open.first", OpenOnePageReference)
 	settings.RegisterKeyBinding("pageref.open.all", OpenEachPageReference)
+	settings.RegisterKeyBinding("roll.history", RollHistory)
 }
 
 func createItemMenu(f unison.MenuFactory) unison.Menu {
@@ -314,5 +324,8 @@ func createItemMenu(f unison.MenuFactory) unison.Menu {
 	m.InsertSeparator(-1, false)
 	m.InsertItem(-1, OpenOnePageReference.NewMenuItem(f))
 	m.InsertItem(-1, OpenEachPageReference.NewMenuItem(f))
+
+	m.InsertSeparator(-1, false)
+	m.InsertItem(-1, RollHistory.NewMenuItem(f))
 	return m
 }
@@ -17,23 +17,61 @@ import (
 	"github.com/richardwilkes/gcs/constants"
 	"github.com/richardwilkes/gcs/model/library"
 	"github.com/richardwilkes/gcs/model/settings"
+	"github.com/richardwilkes/gcs/ui/updates"
+	"github.com/richardwilkes/gcs/ui/workspace"
+	uisettings "github.com/richardwilkes/gcs/ui/workspace/settings"
 	"github.com/richardwilkes/toolbox/desktop"
 	"github.com/richardwilkes/toolbox/i18n"
 	"github.com/richardwilkes/unison"
 )
 
-// ChangeLibraryLocations brings up the dialog that allows the user to edit the library locations.
-var ChangeLibraryLocations *unison.Action
+var (
+	// ChangeLibraryLocations brings up the dialog that allows the user to edit the library locations.
+	ChangeLibraryLocations *unison.Action
+	// RefreshLibraries rescans the library directories for changes.
+	RefreshLibraries *unison.Action
+	// SearchLibraries brings up the Library Search view.
+	SearchLibraries *unison.Action
+	// CheckForLibraryUpdates compares the library items in the focused sheet against their current library definitions.
+	CheckForLibraryUpdates *unison.Action
+	// FindLibraryConflicts brings up the Library Conflicts view.
+	FindLibraryConflicts *unison.Action
+)
 
 func registerLibraryMenuActions() {
 	ChangeLibraryLocations = &unison.Action{
 		ID:              constants.ChangeLibraryLocationsItemID,
-		Title:           i18n.Text("Change Library Locations"),
-		EnabledCallback: notEnabled,
-		ExecuteCallback: unimplemented,
+		Title:           i18n.Text("Change Library Locations…"),
+		ExecuteCallback: func(_ *unison.Action, _ any) { uisettings.ShowLibraryLocations() },
+	}
+	RefreshLibraries = &unison.Action{
+		ID:              constants.RefreshLibrariesItemID,
+		Title:           i18n.Text("Refresh Libraries"),
+		ExecuteCallback: func(_ *unison.Action, _ any) { workspace.RefreshLibraries() },
+	}
+	SearchLibraries = &unison.Action{
+		ID:              constants.SearchLibrariesItemID,
+		Title:           i18n.Text("Search Libraries…"),
+		KeyBinding:      unison.KeyBinding{KeyCode: unison.KeyF, Modifiers: unison.ShiftModifier | unison.OSMenuCmdModifier()},
+		ExecuteCallback: func(_ *unison.Action, _ any) { workspace.ShowLibrarySearch() },
+	}
+	CheckForLibraryUpdates = &unison.Action{
+		ID:              constants.CheckForLibraryUpdatesItemID,
+		Title:           i18n.Text("Check for Library Updates…"),
+		EnabledCallback: unison.RouteActionToFocusEnabledFunc,
+		ExecuteCallback: unison.RouteActionToFocusExecuteFunc,
+	}
+	FindLibraryConflicts = &unison.Action{
+		ID:              constants.FindLibraryConflictsItemID,
+		Title:           i18n.Text("Find Library Conflicts…"),
+		ExecuteCallback: func(_ *unison.Action, _ any) { workspace.ShowLibraryConflicts() },
 	}
 
 	settings.RegisterKeyBinding("change_library_locations", ChangeLibraryLocations)
+	settings.RegisterKeyBinding("refresh_libraries", RefreshLibraries)
+	settings.RegisterKeyBinding("search_libraries", SearchLibraries)
+	settings.RegisterKeyBinding("check_for_library_updates", CheckForLibraryUpdates)
+	settings.RegisterKeyBinding("find_library_conflicts", FindLibraryConflicts)
 }
 
 func updateLibraryMenu(m unison.Menu) {
@@ -42,12 +80,19 @@ func updateLibraryMenu(m unison.Menu) {
 	}
 	f := m.Factory()
 	for i, lib := range settings.Global().LibrarySet.List() {
+		if lib.HasReleaseSource() {
+			m.InsertItem(-1, newUpdateLibraryAction(constants.LibraryBaseItemID+i*3, lib).NewMenuItem(f))
+		}
 		if !lib.IsUser() {
-			m.InsertItem(-1, newUpdateLibraryAction(constants.LibraryBaseItemID+i*2, lib).NewMenuItem(f))
+			m.InsertItem(-1, newInstallLibraryAction(constants.LibraryBaseItemID+i*3+1, lib).NewMenuItem(f))
 		}
-		m.InsertItem(-1, newShowLibraryFolderAction(constants.LibraryBaseItemID+i*2+1, lib).NewMenuItem(f))
+		m.InsertItem(-1, newShowLibraryFolderAction(constants.LibraryBaseItemID+i*3+2, lib).NewMenuItem(f))
 		m.InsertSeparator(-1, false)
 	}
+	m.InsertItem(-1, SearchLibraries.NewMenuItem(f))
+	m.InsertItem(-1, CheckForLibraryUpdates.NewMenuItem(f))
+	m.InsertItem(-1, FindLibraryConflicts.NewMenuItem(f))
+	m.InsertItem(-1, RefreshLibraries.NewMenuItem(f))
 	m.InsertItem(-1, ChangeLibraryLocations.NewMenuItem(f))
 }
 
@@ -64,6 +109,9 @@ func newUpdateLibraryAction(id int, lib *library.Library) *unison.Action {
 	case avail.Version == "":
 		action.Title = fmt.Sprintf(i18n.Text("No releases available for %s"), lib.Title)
 		action.EnabledCallback = notEnabled
+	case updates.IsLibraryInstallInProgress(lib):
+		action.Title = fmt.Sprintf(i18n.Text("Updating %s to v%s"), lib.Title, avail.Version)
+		action.EnabledCallback = notEnabled
 	default:
 		currentVersion := lib.VersionOnDisk()
 		if currentVersion != avail.Version {
@@ -71,12 +119,20 @@ func newUpdateLibraryAction(id int, lib *library.Library) *unison.Action {
 		} else {
 			action.Title = fmt.Sprintf(i18n.Text("%s is up to date (re-download v%s)"), lib.Title, currentVersion)
 		}
-		action.EnabledCallback = notEnabled
-		action.ExecuteCallback = unimplemented
+		action.ExecuteCallback = func(_ *unison.Action, _ any) { updates.UpdateLibrary(lib) }
 	}
 	return action
 }
 
+func newInstallLibraryAction(id int, lib *library.Library) *unison.Action {
+	return &unison.Action{
+		ID:              id,
+		Title:           fmt.Sprintf(i18n.Text("Install %s from Archive or Folder…"), lib.Title),
+		EnabledCallback: func(_ *unison.Action, _ any) bool { return !updates.IsLibraryInstallInProgress(lib) },
+		ExecuteCallback: func(_ *unison.Action, _ any) { updates.InstallLibraryFromLocalSource(lib) },
+	}
+}
+
 func newShowLibraryFolderAction(id int, lib *library.Library) *unison.Action {
 	return &unison.Action{
 		ID:    id,
@@ -50,9 +50,9 @@ func registerSettingsMenuActions() {
 		ID:              constants.PerSheetSettingsItemID,
 		Title:           i18n.Text("Sheet Settings…"),
 		KeyBinding:      unison.KeyBinding{KeyCode: unison.KeyComma, Modifiers: unison.ShiftModifier | unison.OSMenuCmdModifier()},
-		EnabledCallback: func(_ *unison.Action, _ any) bool { return sheet.ActiveSheet() != nil },
+		EnabledCallback: func(_ *unison.Action, _ any) bool { return sheetForSettings() != nil },
 		ExecuteCallback: func(_ *unison.Action, _ any) {
-			if s := sheet.ActiveSheet(); s != nil {
+			if s := sheetForSettings(); s != nil {
 				uisettings.ShowSheetSettings(s)
 			}
 		},
@@ -143,3 +143,12 @@ func createSettingsMenu(f unison.MenuFactory) unison.Menu {
 	m.InsertItem(-1, MenuKeySettings.NewMenuItem(f))
 	return m
 }
+
+// sheetForSettings returns the active sheet if its settings may be edited. Settings are not available while a sheet is
+// being planned, since the plan has its own copy of them.
+func sheetForSettings() *sheet.Sheet {
+	if s := sheet.ActiveSheet(); s != nil && !s.Planning() {
+		return s
+	}
+	return nil
+}
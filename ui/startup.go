@@ -32,9 +32,14 @@ func Start(files []string) {
 			jot.FatalIfErr(err)
 			menus.Setup(wnd)
 			workspace.NewWorkspace(wnd)
+			workspace.MonitorLibraries()
+			workspace.UpdateLibraryIndex()
 			wnd.SetFrameRect(unison.PrimaryDisplay().Usable)
 			wnd.ToFront()
 			workspace.OpenFiles(files)
+			workspace.StartAutoSave()
+			workspace.OfferRecovery(wnd)
+			workspace.PurgeTrash()
 		}),
 		unison.OpenFilesCallback(workspace.OpenFiles),
 		unison.AllowQuitCallback(func() bool {
@@ -46,5 +51,6 @@ func Start(files []string) {
 			}
 			return true
 		}),
+		unison.QuittingCallback(workspace.EndAutoSave),
 	) // Never returns
 }
@@ -0,0 +1,132 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package updates
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/richardwilkes/gcs/model/library"
+	"github.com/richardwilkes/gcs/model/settings"
+	"github.com/richardwilkes/gcs/res"
+	"github.com/richardwilkes/gcs/ui/workspace"
+	"github.com/richardwilkes/toolbox/i18n"
+	"github.com/richardwilkes/toolbox/log/jot"
+	"github.com/richardwilkes/unison"
+)
+
+var (
+	libraryInstallLock sync.Mutex
+	libraryInstalling  = make(map[*library.Library]bool)
+)
+
+// IsLibraryInstallInProgress returns true if the library is currently being installed or updated.
+func IsLibraryInstallInProgress(lib *library.Library) bool {
+	libraryInstallLock.Lock()
+	defer libraryInstallLock.Unlock()
+	return libraryInstalling[lib]
+}
+
+func startLibraryInstall(lib *library.Library) bool {
+	libraryInstallLock.Lock()
+	defer libraryInstallLock.Unlock()
+	if libraryInstalling[lib] {
+		return false
+	}
+	libraryInstalling[lib] = true
+	return true
+}
+
+func finishLibraryInstall(lib *library.Library, err error) {
+	libraryInstallLock.Lock()
+	delete(libraryInstalling, lib)
+	libraryInstallLock.Unlock()
+	if err != nil {
+		unison.ErrorDialogWithError(fmt.Sprintf(i18n.Text("Unable to install %s"), lib.Title), err)
+		return
+	}
+	if err = settings.Global().Save(); err != nil {
+		jot.Error(err)
+	}
+	// The library's directory has been replaced, so the watches on the old one must be re-established
+	workspace.MonitorLibraries()
+	workspace.RefreshLibraries()
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute*5)
+		defer cancel()
+		lib.CheckForAvailableUpgrade(ctx, &http.Client{})
+	}()
+}
+
+// UpdateLibrary presents the release notes for the available update to the library and, if the user agrees, downloads
+// and installs it in the background.
+func UpdateLibrary(lib *library.Library) {
+	rel := lib.AvailableUpdate()
+	if rel == nil || !rel.HasUpdate() {
+		return
+	}
+	var buffer strings.Builder
+	fmt.Fprintf(&buffer, "# %s v%s\n", lib.Title, rel.Version)
+	buffer.WriteString(rel.Notes)
+	scroll := unison.NewScrollPanel()
+	scroll.SetContent(convertMarkdownToPanel(buffer.String(), 900), unison.UnmodifiedBehavior,
+		unison.UnmodifiedBehavior)
+	dialog, err := unison.NewDialog(
+		&unison.DrawableSVG{
+			SVG:  res.DownloadSVG,
+			Size: unison.NewSize(48, 48),
+		},
+		unison.DefaultLabelTheme.OnBackgroundInk, scroll,
+		[]*unison.DialogButtonInfo{
+			unison.NewCancelButtonInfo(),
+			unison.NewOKButtonInfoWithTitle(i18n.Text("Update")),
+		})
+	if err != nil {
+		jot.Error(err)
+		return
+	}
+	if dialog.RunModal() != unison.ModalResponseOK || !startLibraryInstall(lib) {
+		return
+	}
+	release := *rel
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute*5)
+		defer cancel()
+		installErr := lib.Download(ctx, &http.Client{}, release)
+		unison.InvokeTask(func() { finishLibraryInstall(lib, installErr) })
+	}()
+}
+
+// InstallLibraryFromLocalSource asks the user to choose a zip archive or directory containing library content and then
+// installs it in place of the library's current content.
+func InstallLibraryFromLocalSource(lib *library.Library) {
+	dialog := unison.NewOpenDialog()
+	dialog.SetResolvesAliases(true)
+	dialog.SetAllowsMultipleSelection(false)
+	dialog.SetCanChooseDirectories(true)
+	dialog.SetCanChooseFiles(true)
+	dialog.SetAllowedExtensions("zip")
+	if !dialog.RunModal() {
+		return
+	}
+	p := dialog.Path()
+	if unison.QuestionDialog(fmt.Sprintf(i18n.Text("Replace the contents of %s?"), lib.Title),
+		fmt.Sprintf(i18n.Text("The current contents of\n%s\nwill be replaced by those found in\n%s"), lib.PathOnDisk,
+			p)) != unison.ModalResponseOK || !startLibraryInstall(lib) {
+		return
+	}
+	finishLibraryInstall(lib, lib.InstallFromPath(p))
+}
@@ -0,0 +1,202 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package ntable
+
+import (
+	"runtime"
+
+	"github.com/richardwilkes/gcs/model/gurps"
+	"github.com/richardwilkes/toolbox"
+	"github.com/richardwilkes/toolbox/i18n"
+	"github.com/richardwilkes/toolbox/xmath"
+	"github.com/richardwilkes/unison"
+)
+
+// ColumnInfo describes a column that may be shown by a ColumnCustomizer.
+type ColumnInfo struct {
+	ID    int
+	Title string
+}
+
+// ColumnCustomizer defines the methods a TableProvider must implement to permit the user to choose which of its columns
+// are shown, the order they are shown in, and their widths.
+type ColumnCustomizer interface {
+	// AvailableColumns returns the columns that may be shown.
+	AvailableColumns() []ColumnInfo
+	// ColumnLayout returns a copy of the current column layout.
+	ColumnLayout() *gurps.ColumnLayout
+	// SetColumnLayout sets the column layout and records it within the appropriate settings. Pass nil to revert to the
+	// default columns.
+	SetColumnLayout(layout *gurps.ColumnLayout)
+}
+
+// InstallColumnCustomization installs a context menu on the header that permits the user to show, hide and reorder the
+// columns, provided the TableProvider is a ColumnCustomizer. If the user is permitted to resize the columns, any changes
+// they make to the column widths are recorded as well. 'changed' will be called, if not nil, after the columns have
+// been altered.
+func InstallColumnCustomization[T gurps.NodeConstraint[T]](header *unison.TableHeader[*Node[T]], table *unison.Table[*Node[T]], provider TableProvider[T], changed func()) {
+	customizer, ok := provider.(ColumnCustomizer)
+	if !ok {
+		return
+	}
+	var widths []float32
+	mouseDownCallback := header.MouseDownCallback
+	header.MouseDownCallback = func(where unison.Point, button, clickCount int, mod unison.Modifiers) bool {
+		if button == unison.ButtonRight || (button == unison.ButtonLeft && mod.ControlDown() && runtime.GOOS == toolbox.MacOS) {
+			widths = nil
+			showColumnMenu(header, table, provider, customizer, where, changed)
+			return true
+		}
+		widths = currentColumnWidths(table)
+		return mouseDownCallback(where, button, clickCount, mod)
+	}
+	mouseUpCallback := header.MouseUpCallback
+	header.MouseUpCallback = func(where unison.Point, button int, mod unison.Modifiers) bool {
+		stop := mouseUpCallback(where, button, mod)
+		if !table.PreventUserColumnResize && len(widths) == len(table.ColumnSizes) {
+			layout := customizer.ColumnLayout()
+			if len(layout.Columns) == len(widths) {
+				altered := false
+				for i, width := range widths {
+					if current := table.ColumnSizes[i].Current; current != width {
+						layout.Columns[i].Width = current
+						altered = true
+					}
+				}
+				if altered {
+					customizer.SetColumnLayout(layout)
+				}
+			}
+		}
+		widths = nil
+		return stop
+	}
+}
+
+// ApplyColumnWidths sets the width of any columns that have a width recorded within the provider's column layout.
+func ApplyColumnWidths[T gurps.NodeConstraint[T]](table *unison.Table[*Node[T]], provider TableProvider[T]) {
+	customizer, ok := provider.(ColumnCustomizer)
+	if !ok {
+		return
+	}
+	layout := customizer.ColumnLayout()
+	if len(layout.Columns) != len(table.ColumnSizes) {
+		return
+	}
+	altered := false
+	for i, one := range layout.Columns {
+		if one.Width > 0 {
+			table.ColumnSizes[i].Current = xmath.Max(one.Width, table.ColumnSizes[i].Minimum)
+			altered = true
+		}
+	}
+	if altered {
+		table.SyncToModel()
+	}
+}
+
+// ClearColumnWidths removes any widths recorded within the provider's column layout, so that the widths of the columns
+// are once again determined by their content.
+func ClearColumnWidths[T gurps.NodeConstraint[T]](provider TableProvider[T]) {
+	customizer, ok := provider.(ColumnCustomizer)
+	if !ok {
+		return
+	}
+	layout := customizer.ColumnLayout()
+	altered := false
+	for _, one := range layout.Columns {
+		if one.Width != 0 {
+			one.Width = 0
+			altered = true
+		}
+	}
+	if altered {
+		customizer.SetColumnLayout(layout)
+	}
+}
+
+func currentColumnWidths[T gurps.NodeConstraint[T]](table *unison.Table[*Node[T]]) []float32 {
+	widths := make([]float32, len(table.ColumnSizes))
+	for i, one := range table.ColumnSizes {
+		widths[i] = one.Current
+	}
+	return widths
+}
+
+func showColumnMenu[T gurps.NodeConstraint[T]](header *unison.TableHeader[*Node[T]], table *unison.Table[*Node[T]], provider TableProvider[T], customizer ColumnCustomizer, where unison.Point, changed func()) {
+	layout := customizer.ColumnLayout()
+	column := table.OverColumn(where.X)
+	update := func(layout *gurps.ColumnLayout) {
+		customizer.SetColumnLayout(layout)
+		applyColumns(header, table, provider)
+		if changed != nil {
+			changed()
+		}
+	}
+	f := unison.DefaultMenuFactory()
+	nextID := unison.PopupMenuTemporaryBaseID | unison.ContextMenuIDFlag
+	addItem := func(m unison.Menu, title string, enabled bool, handler func()) unison.MenuItem {
+		nextID++
+		item := f.NewItem(nextID, title, unison.KeyBinding{}, func(_ unison.MenuItem) bool { return enabled },
+			func(_ unison.MenuItem) { handler() })
+		m.InsertItem(-1, item)
+		return item
+	}
+	m := f.NewMenu(nextID, "", nil)
+	defer m.Dispose()
+	hierarchyID := -1
+	if i := provider.HierarchyColumnIndex(); i >= 0 && i < len(layout.Columns) {
+		hierarchyID = layout.Columns[i].ID
+	}
+	for _, one := range customizer.AvailableColumns() {
+		info := one
+		shown := layout.Index(info.ID) != -1
+		item := addItem(m, info.Title, !shown || (info.ID != hierarchyID && len(layout.Columns) > 1), func() {
+			layout.Toggle(info.ID)
+			update(layout)
+		})
+		if shown {
+			item.SetCheckState(unison.OnCheckState)
+		}
+	}
+	m.InsertSeparator(-1, false)
+	addItem(m, i18n.Text("Move Column Left"), column > 0 && column < len(layout.Columns), func() {
+		layout.Move(column, -1)
+		update(layout)
+	})
+	addItem(m, i18n.Text("Move Column Right"), column >= 0 && column < len(layout.Columns)-1, func() {
+		layout.Move(column, 1)
+		update(layout)
+	})
+	m.InsertSeparator(-1, false)
+	addItem(m, i18n.Text("Reset to Default Columns"), true, func() { update(nil) })
+	m.Popup(header.RectToRoot(unison.Rect{Point: where, Size: unison.Size{Width: 1, Height: 1}}), -1)
+}
+
+func applyColumns[T gurps.NodeConstraint[T]](header *unison.TableHeader[*Node[T]], table *unison.Table[*Node[T]], provider TableProvider[T]) {
+	headers := provider.Headers()
+	header.ColumnHeaders = headers
+	table.ColumnSizes = columnSizesForHeaders(table, headers)
+	table.HierarchyColumnIndex = provider.HierarchyColumnIndex()
+	provider.SyncHeader(headers)
+	table.SyncToModel()
+	if table.PreventUserColumnResize {
+		table.SizeColumnsToFitWithExcessIn(provider.ExcessWidthColumnIndex())
+	} else {
+		table.SizeColumnsToFit(true)
+		ApplyColumnWidths(table, provider)
+	}
+	header.NeedsLayout = true
+	header.MarkForRedraw()
+	table.NeedsLayout = true
+	table.MarkForRedraw()
+}
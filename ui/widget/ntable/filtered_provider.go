@@ -0,0 +1,98 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package ntable
+
+import (
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/richardwilkes/gcs/model/gurps"
+)
+
+var _ TableProvider[*gurps.Trait] = &FilteredProvider[*gurps.Trait]{}
+
+// FilteredProvider wraps a TableProvider, presenting only the rows that satisfy a match function as a flat list. Rows
+// within containers are considered as well, while the containers themselves are only presented if they also match.
+// Everything other than the presentation of the rows is handled by the wrapped provider.
+type FilteredProvider[T gurps.NodeConstraint[T]] struct {
+	TableProvider[T]
+	match func(T) bool
+	order map[uuid.UUID]int
+	nodes NodeCache[T]
+}
+
+// NewFilteredProvider creates a new FilteredProvider.
+func NewFilteredProvider[T gurps.NodeConstraint[T]](provider TableProvider[T], match func(T) bool) *FilteredProvider[T] {
+	return &FilteredProvider[T]{
+		TableProvider: provider,
+		match:         match,
+	}
+}
+
+// Unfiltered returns the wrapped provider.
+func (p *FilteredProvider[T]) Unfiltered() TableProvider[T] {
+	return p.TableProvider
+}
+
+// RootRowCount implements unison.TableModel.
+func (p *FilteredProvider[T]) RootRowCount() int {
+	return len(p.RootRows())
+}
+
+// RootRows implements unison.TableModel.
+func (p *FilteredProvider[T]) RootRows() []*Node[T] {
+	roots := p.TableProvider.RootRows()
+	if len(roots) == 0 {
+		p.nodes.Reset()
+		return nil
+	}
+	var matches []T
+	var collect func(list []T)
+	collect = func(list []T) {
+		for _, one := range list {
+			if p.match(one) {
+				matches = append(matches, one)
+			}
+			if one.Container() {
+				collect(one.NodeChildren())
+			}
+		}
+	}
+	collect(p.TableProvider.RootData())
+	if p.order != nil {
+		sort.SliceStable(matches, func(i, j int) bool { return p.position(matches[i]) < p.position(matches[j]) })
+	}
+	// All of the root nodes share the same table, column map and target, so borrow them from the first one
+	first := roots[0]
+	return p.nodes.rootRows(first.table, first.colMap, matches, first.forPage, true)
+}
+
+func (p *FilteredProvider[T]) position(data T) int {
+	if i, exists := p.order[data.UUID()]; exists {
+		return i
+	}
+	return len(p.order)
+}
+
+// SetRootRows implements unison.TableModel. A reordering of the presented rows, such as results from sorting via the
+// table header, only affects their presentation and leaves the underlying data alone. Anything else is passed along to
+// the wrapped provider.
+func (p *FilteredProvider[T]) SetRootRows(rows []*Node[T]) {
+	if len(rows) == 0 || !rows[0].flat {
+		p.TableProvider.SetRootRows(rows)
+		return
+	}
+	p.order = make(map[uuid.UUID]int, len(rows))
+	for i, row := range rows {
+		p.order[row.UUID()] = i
+	}
+}
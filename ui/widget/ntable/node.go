@@ -16,10 +16,12 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/richardwilkes/gcs/model/gurps"
+	"github.com/richardwilkes/gcs/model/settings"
 	"github.com/richardwilkes/gcs/model/theme"
 	"github.com/richardwilkes/gcs/res"
 	"github.com/richardwilkes/gcs/ui/widget"
-	"github.com/richardwilkes/gcs/ui/workspace/settings"
+	"github.com/richardwilkes/gcs/ui/workspace"
+	wsettings "github.com/richardwilkes/gcs/ui/workspace/settings"
 	"github.com/richardwilkes/toolbox/i18n"
 	"github.com/richardwilkes/toolbox/log/jot"
 	"github.com/richardwilkes/unison"
@@ -30,6 +32,11 @@ const excludeMarker = "exclude"
 
 var _ unison.TableRowData[*Node[*gurps.Trait]] = &Node[*gurps.Trait]{}
 
+// backingFileProvider is implemented by the dockables that hold tables whose content is backed by a file.
+type backingFileProvider interface {
+	BackingFilePath() string
+}
+
 // Node represents a row in a table.
 type Node[T gurps.NodeConstraint[T]] struct {
 	table     *unison.Table[*Node[T]]
@@ -38,7 +45,10 @@ type Node[T gurps.NodeConstraint[T]] struct {
 	children  []*Node[T]
 	cellCache []*CellCache
 	colMap    map[int]int
+	crc       uint64
 	forPage   bool
+	stale     bool
+	flat      bool
 }
 
 // NewNode creates a new node for a table.
@@ -50,6 +60,7 @@ func NewNode[T gurps.NodeConstraint[T]](table *unison.Table[*Node[T]], parent *N
 		cellCache: make([]*CellCache, len(colMap)),
 		colMap:    colMap,
 		forPage:   forPage,
+		stale:     true,
 	}
 }
 
@@ -63,7 +74,23 @@ func (n *Node[T]) CloneForTarget(target unison.Paneler, newParent *Node[T]) *Nod
 	if provider == nil {
 		jot.Fatal(1, "unable to locate entity provider")
 	}
-	return NewNode[T](table, newParent, n.colMap, n.data.Clone(provider.Entity(), newParent.Data(), false), n.forPage)
+	clone := n.data.Clone(provider.Entity(), newParent.Data(), false)
+	if n.data.OwningEntity() == nil {
+		// Data without an owning entity came from a list file, so record which library file it came from when it is
+		// being copied into a sheet or template
+		if from := unison.AncestorOrSelf[backingFileProvider](n.table); from != nil && isEntityOwner(target) {
+			gurps.RecordLibrarySources(settings.Global().LibrarySet, from.BackingFilePath(), n.data, clone)
+		}
+	}
+	return NewNode[T](table, newParent, n.colMap, clone, n.forPage)
+}
+
+func isEntityOwner(target unison.Paneler) bool {
+	if owner := unison.AncestorOrSelf[widget.DockableKind](target); owner != nil {
+		kind := owner.DockableKind()
+		return kind == widget.SheetDockableKind || kind == widget.TemplateDockableKind
+	}
+	return false
 }
 
 // UUID implements unison.TableRowData.
@@ -81,26 +108,46 @@ func (n *Node[T]) SetParent(parent *Node[T]) {
 	n.data.SetParent(parent.Data())
 }
 
-// CanHaveChildren implements unison.TableRowData.
+// CanHaveChildren implements unison.TableRowData. Nodes within a flat list never have children, even if their data is a
+// container.
 func (n *Node[T]) CanHaveChildren() bool {
-	return n.data.Container()
+	return !n.flat && n.data.Container()
 }
 
 // Children implements unison.TableRowData.
 func (n *Node[T]) Children() []*Node[T] {
-	if n.data.Container() && n.children == nil {
-		children := n.data.NodeChildren()
-		n.children = make([]*Node[T], len(children))
-		for i, one := range children {
-			n.children[i] = NewNode[T](n.table, n, n.colMap, one, n.forPage)
+	if n.CanHaveChildren() {
+		if children := n.data.NodeChildren(); !n.childrenMatch(children) {
+			previous := make(map[uuid.UUID]*Node[T], len(n.children))
+			for _, child := range n.children {
+				previous[child.UUID()] = child
+			}
+			claimed := make(map[uuid.UUID]*Node[T], len(children))
+			n.children = make([]*Node[T], len(children))
+			for i, one := range children {
+				n.children[i] = reuseOrCreateNode(previous, claimed, n.table, n, n.colMap, one, n.forPage, false)
+			}
 		}
 	}
 	return n.children
 }
 
+// childrenMatch returns true if the child nodes are still in sync with the children of the underlying data.
+func (n *Node[T]) childrenMatch(children []T) bool {
+	if n.children == nil || len(n.children) != len(children) {
+		return false
+	}
+	for i, child := range n.children {
+		if any(child.data) != any(children[i]) {
+			return false
+		}
+	}
+	return true
+}
+
 // SetChildren implements unison.TableRowData.
 func (n *Node[T]) SetChildren(children []*Node[T]) {
-	if n.data.Container() {
+	if n.CanHaveChildren() {
 		n.data.SetChildren(ExtractNodeDataFromList(children))
 		n.children = nil
 	}
@@ -116,17 +163,21 @@ func (n *Node[T]) CellDataForSort(index int) string {
 	return ""
 }
 
-// ColumnCell implements unison.TableRowData.
+// ColumnCell implements unison.TableRowData. The cells are cached and only rebuilt when their width changes or when the
+// content of the row has changed.
 func (n *Node[T]) ColumnCell(row, col int, foreground, _ unison.Ink, _, _, _ bool) unison.Paneler {
+	if n.stale {
+		n.refresh()
+	}
+	width := n.table.CellWidth(row, col)
+	if cache := n.cellCache[col]; cache != nil && cache.Panel != nil && cache.Width == width {
+		applyForegroundInkRecursively(cache.Panel.AsPanel(), foreground)
+		return cache.Panel
+	}
 	var cellData gurps.CellData
 	if column, exists := n.colMap[col]; exists {
 		n.data.CellData(column, &cellData)
 	}
-	width := n.table.CellWidth(row, col)
-	if n.cellCache[col].Matches(width, &cellData) {
-		applyForegroundInkRecursively(n.cellCache[col].Panel.AsPanel(), foreground)
-		return n.cellCache[col].Panel
-	}
 	cell := n.CellFromCellData(&cellData, width, foreground)
 	n.cellCache[col] = &CellCache{
 		Panel: cell,
@@ -136,6 +187,38 @@ func (n *Node[T]) ColumnCell(row, col int, foreground, _ unison.Ink, _, _, _ boo
 	return cell
 }
 
+// markStale marks the row and its descendants as needing to verify their cached cells before they are next used.
+func (n *Node[T]) markStale() {
+	n.stale = true
+	for _, child := range n.children {
+		child.markStale()
+	}
+}
+
+// refresh computes a CRC-64 of the row's cell content and discards the cached cells if it differs from the one last
+// computed.
+func (n *Node[T]) refresh() {
+	n.stale = false
+	var value uint64
+	for col := range n.cellCache {
+		var cellData gurps.CellData
+		if column, exists := n.colMap[col]; exists {
+			n.data.CellData(column, &cellData)
+		}
+		value = cellData.CRC64(value)
+	}
+	if value != n.crc {
+		n.crc = value
+		n.invalidateCells()
+	}
+}
+
+func (n *Node[T]) invalidateCells() {
+	for i := range n.cellCache {
+		n.cellCache[i] = nil
+	}
+}
+
 func applyForegroundInkRecursively(panel *unison.Panel, foreground unison.Ink) {
 	if label, ok := panel.Self.(*unison.Label); ok {
 		if _, exists := label.ClientData()[excludeMarker]; !exists {
@@ -149,12 +232,12 @@ func applyForegroundInkRecursively(panel *unison.Panel, foreground unison.Ink) {
 
 // IsOpen implements unison.TableRowData.
 func (n *Node[T]) IsOpen() bool {
-	return n.data.Container() && n.data.Open()
+	return n.CanHaveChildren() && n.data.Open()
 }
 
 // SetOpen implements unison.TableRowData.
 func (n *Node[T]) SetOpen(open bool) {
-	if n.data.Container() && open != n.data.Open() {
+	if n.CanHaveChildren() && open != n.data.Open() {
 		n.data.SetOpen(open)
 		n.table.SyncToModel()
 	}
@@ -197,10 +280,10 @@ func (n *Node[T]) CellFromCellData(c *gurps.CellData, width float32, foreground
 
 func (n *Node[T]) createLabelCell(c *gurps.CellData, width float32, foreground unison.Ink) unison.Paneler {
 	p := unison.NewPanel()
-	p.SetLayout(&unison.FlexLayout{
+	p.SetLayout(&sizeCachingLayout{Layout: &unison.FlexLayout{
 		Columns: 1,
 		HAlign:  c.Alignment,
-	})
+	}})
 	n.addLabelCell(c, p, width, c.Primary, n.primaryFieldFont(), foreground, true)
 	if c.Secondary != "" {
 		n.addLabelCell(c, p, width, c.Secondary, n.secondaryFieldFont(), foreground, false)
@@ -231,12 +314,115 @@ func (n *Node[T]) createLabelCell(c *gurps.CellData, width float32, foreground u
 		p.AddChild(label)
 		tooltip = c.UnsatisfiedReason
 	}
+	if c.Roll.CanRoll() {
+		if c.RollLabel != "" {
+			n.addRollLabel(c, p, foreground)
+		} else {
+			n.makeRollable(c, p)
+			if tooltip != "" {
+				tooltip += "\n\n"
+			}
+			tooltip += i18n.Text("Click to roll")
+		}
+	}
 	if tooltip != "" {
 		p.Tooltip = unison.NewTooltipWithText(tooltip)
 	}
 	return p
 }
 
+// makeRollable makes the entire cell act as a link that makes the cell's roll when clicked.
+func (n *Node[T]) makeRollable(c *gurps.CellData, p *unison.Panel) {
+	const isLinkKey = "is_link"
+	req := c.Roll
+	p.DrawCallback = func(gc *unison.Canvas, rect unison.Rect) {
+		if _, exists := p.ClientData()[isLinkKey]; exists {
+			gc.DrawRect(rect, theme.LinkColor.Paint(gc, rect, unison.Fill))
+			applyForegroundInkRecursively(p, theme.OnLinkColor)
+		}
+	}
+	p.MouseEnterCallback = func(where unison.Point, mod unison.Modifiers) bool {
+		p.ClientData()[isLinkKey] = true
+		p.MarkForRedraw()
+		return true
+	}
+	p.MouseExitCallback = func() bool {
+		delete(p.ClientData(), isLinkKey)
+		p.MarkForRedraw()
+		return true
+	}
+	p.MouseDownCallback = func(where unison.Point, button, clickCount int, mod unison.Modifiers) bool {
+		if button != unison.ButtonLeft || clickCount != 1 || mod&(unison.ShiftModifier|unison.CommandModifier|
+			unison.ControlModifier) != 0 {
+			return false
+		}
+		workspace.Roll(p.Window(), req)
+		return true
+	}
+}
+
+// addRollLabel adds a label to the cell that makes the cell's roll when clicked, leaving the remainder of the cell to
+// behave normally.
+func (n *Node[T]) addRollLabel(c *gurps.CellData, p *unison.Panel, foreground unison.Ink) {
+	const isLinkKey = "is_link"
+	req := c.Roll
+	label := unison.NewLabel()
+	label.Font = n.secondaryFieldFont()
+	baseline := label.Font.Baseline()
+	label.Drawable = &unison.DrawableSVG{
+		SVG:  res.RandomizeSVG,
+		Size: unison.NewSize(baseline, baseline),
+	}
+	label.Text = c.RollLabel
+	label.HAlign = c.Alignment
+	label.OnBackgroundInk = foreground
+	label.Tooltip = unison.NewTooltipWithText(i18n.Text("Click to roll"))
+	label.SetLayoutData(&unison.FlexLayoutData{HAlign: c.Alignment})
+	label.DrawCallback = func(gc *unison.Canvas, rect unison.Rect) {
+		if _, exists := label.ClientData()[isLinkKey]; exists {
+			gc.DrawRect(rect, theme.LinkColor.Paint(gc, rect, unison.Fill))
+			save := label.OnBackgroundInk
+			label.OnBackgroundInk = theme.OnLinkColor
+			label.DefaultDraw(gc, rect)
+			label.OnBackgroundInk = save
+		} else {
+			label.DefaultDraw(gc, rect)
+		}
+	}
+	p.AddChild(label)
+	over := func(where unison.Point) bool {
+		return label.FrameRect().ContainsPoint(where)
+	}
+	track := func(where unison.Point) bool {
+		_, was := label.ClientData()[isLinkKey]
+		if is := over(where); is != was {
+			if is {
+				label.ClientData()[isLinkKey] = true
+			} else {
+				delete(label.ClientData(), isLinkKey)
+			}
+			p.MarkForRedraw()
+		}
+		return false
+	}
+	p.MouseEnterCallback = func(where unison.Point, mod unison.Modifiers) bool { return track(where) }
+	p.MouseMoveCallback = func(where unison.Point, mod unison.Modifiers) bool { return track(where) }
+	p.MouseExitCallback = func() bool {
+		if _, exists := label.ClientData()[isLinkKey]; exists {
+			delete(label.ClientData(), isLinkKey)
+			p.MarkForRedraw()
+		}
+		return false
+	}
+	p.MouseDownCallback = func(where unison.Point, button, clickCount int, mod unison.Modifiers) bool {
+		if button != unison.ButtonLeft || clickCount != 1 || !over(where) {
+			return false
+		}
+		workspace.Roll(p.Window(), req)
+		return true
+	}
+}
+
 func (n *Node[T]) addLabelCell(c *gurps.CellData, parent *unison.Panel, width float32, text string, f unison.Font, foreground unison.Ink, primary bool) {
 	decoration := &unison.TextDecoration{
 		Font:          f,
@@ -289,6 +475,8 @@ func (n *Node[T]) createToggleCell(c *gurps.CellData, foreground unison.Ink) uni
 			check.Drawable = nil
 		}
 		check.MarkForLayoutAndRedraw()
+		// Other cells in the row may depend on the state of the check, so rebuild them on their next use
+		n.invalidateCells()
 		widget.MarkModified(check)
 		return true
 	}
@@ -453,9 +641,9 @@ func (n *Node[T]) createPageRefCell(c *gurps.CellData, foreground unison.Ink) un
 			return true
 		}
 		label.MouseDownCallback = func(where unison.Point, button, clickCount int, mod unison.Modifiers) bool {
-			list := settings.ExtractPageReferences(c.Primary)
+			list := wsettings.ExtractPageReferences(c.Primary)
 			if len(list) != 0 {
-				settings.OpenPageReference(label.Window(), list[0], c.Secondary, nil)
+				wsettings.OpenPageReference(label, list[0], c.Secondary, nil)
 			}
 			return true
 		}
@@ -511,7 +699,7 @@ func InsertItem[T gurps.NodeConstraint[T]](owner widget.Rebuildable, table *unis
 				target.SetChildren(append(target.NodeChildren(), item))
 			} else {
 				// Target isn't a container. If it has a parent, insert after the target within that parent.
-				if parent := row.Parent().Data(); parent != zero {
+				if parent := target.Parent(); parent != zero {
 					item.SetParent(parent)
 					children := parent.NodeChildren()
 					parent.SetChildren(slices.Insert(children, slices.Index(children, target)+1, item))
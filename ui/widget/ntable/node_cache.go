@@ -0,0 +1,79 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package ntable
+
+import (
+	"reflect"
+
+	"github.com/google/uuid"
+	"github.com/richardwilkes/gcs/model/gurps"
+	"github.com/richardwilkes/unison"
+)
+
+// NodeCache retains the nodes created for the root rows of a table, so that each time the table is synced to its
+// model, rows whose content hasn't changed can continue to use the cells they have already built. Rows are matched up
+// by UUID and a CRC-64 of each row's cell content is used to determine which of them need to be rebuilt. The check is
+// deferred until a row's cells are next requested, so rows that are never measured or drawn cost very little.
+type NodeCache[T gurps.NodeConstraint[T]] struct {
+	nodes map[uuid.UUID]*Node[T]
+}
+
+// RootRows returns the nodes for the data, reusing those returned by the previous call wherever possible.
+func (c *NodeCache[T]) RootRows(table *unison.Table[*Node[T]], colMap map[int]int, data []T, forPage bool) []*Node[T] {
+	return c.rootRows(table, colMap, data, forPage, false)
+}
+
+func (c *NodeCache[T]) rootRows(table *unison.Table[*Node[T]], colMap map[int]int, data []T, forPage, flat bool) []*Node[T] {
+	claimed := make(map[uuid.UUID]*Node[T], len(data))
+	rows := make([]*Node[T], len(data))
+	for i, one := range data {
+		rows[i] = reuseOrCreateNode(c.nodes, claimed, table, nil, colMap, one, forPage, flat)
+	}
+	c.nodes = claimed
+	for _, row := range rows {
+		row.markStale()
+	}
+	return rows
+}
+
+// Reset discards all retained nodes.
+func (c *NodeCache[T]) Reset() {
+	c.nodes = nil
+}
+
+// reuseOrCreateNode returns the node from 'previous' with the same UUID as the data, updated to reflect the other
+// parameters, or a new node if there is no suitable one. Each node may only be claimed once, since data read from disk
+// is not guaranteed to have unique UUIDs.
+func reuseOrCreateNode[T gurps.NodeConstraint[T]](previous, claimed map[uuid.UUID]*Node[T], table *unison.Table[*Node[T]], parent *Node[T], colMap map[int]int, data T, forPage, flat bool) *Node[T] {
+	id := data.UUID()
+	_, alreadyClaimed := claimed[id]
+	if node, exists := previous[id]; exists && !alreadyClaimed && node.table == table && node.forPage == forPage &&
+		node.flat == flat && sameColMap(node.colMap, colMap) {
+		node.parent = parent
+		if any(node.data) != any(data) {
+			node.data = data
+			node.children = nil
+		}
+		claimed[id] = node
+		return node
+	}
+	node := NewNode[T](table, parent, colMap, data, forPage)
+	node.flat = flat
+	if !alreadyClaimed {
+		claimed[id] = node
+	}
+	return node
+}
+
+func sameColMap(a, b map[int]int) bool {
+	return reflect.ValueOf(a).UnsafePointer() == reflect.ValueOf(b).UnsafePointer()
+}
@@ -0,0 +1,162 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package ntable_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/richardwilkes/gcs/model/fxp"
+	"github.com/richardwilkes/gcs/model/gurps"
+	"github.com/richardwilkes/gcs/model/settings"
+	"github.com/richardwilkes/gcs/ui/widget/ntable"
+	"github.com/richardwilkes/unison"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var equipmentColMap = map[int]int{
+	0: gurps.EquipmentDescriptionColumn,
+	1: gurps.EquipmentQuantityColumn,
+	2: gurps.EquipmentTLColumn,
+	3: gurps.EquipmentLCColumn,
+	4: gurps.EquipmentCostColumn,
+	5: gurps.EquipmentWeightColumn,
+	6: gurps.EquipmentReferenceColumn,
+}
+
+func init() {
+	if gurps.SettingsProvider == nil {
+		s := settings.Default()
+		s.General.AutoFillProfile = false
+		gurps.SettingsProvider = s
+	}
+}
+
+type equipmentModel struct {
+	table    *unison.Table[*ntable.Node[*gurps.Equipment]]
+	data     []*gurps.Equipment
+	nodes    ntable.NodeCache[*gurps.Equipment]
+	uncached bool
+}
+
+func (m *equipmentModel) RootRowCount() int {
+	return len(m.data)
+}
+
+func (m *equipmentModel) RootRows() []*ntable.Node[*gurps.Equipment] {
+	if m.uncached {
+		rows := make([]*ntable.Node[*gurps.Equipment], len(m.data))
+		for i, one := range m.data {
+			rows[i] = ntable.NewNode[*gurps.Equipment](m.table, nil, equipmentColMap, one, true)
+		}
+		return rows
+	}
+	return m.nodes.RootRows(m.table, equipmentColMap, m.data, true)
+}
+
+func (m *equipmentModel) SetRootRows(rows []*ntable.Node[*gurps.Equipment]) {
+	m.data = ntable.ExtractNodeDataFromList(rows)
+}
+
+func newEquipmentTable(count int, uncached bool) *equipmentModel {
+	m := &equipmentModel{uncached: uncached}
+	for i := 0; i < count; i++ {
+		eqp := gurps.NewEquipment(nil, nil, i%10 == 0)
+		eqp.Name = fmt.Sprintf("Piece of equipment number %d, which has a reasonably long description", i+1)
+		eqp.Quantity = fxp.From(i%5 + 1)
+		eqp.PageRef = "B123"
+		if eqp.Container() {
+			eqp.IsOpen = true
+			for j := 0; j < 3; j++ {
+				child := gurps.NewEquipment(nil, eqp, false)
+				child.Name = fmt.Sprintf("Contained item %d", j+1)
+				eqp.Children = append(eqp.Children, child)
+			}
+		}
+		m.data = append(m.data, eqp)
+	}
+	m.table = unison.NewTable[*ntable.Node[*gurps.Equipment]](m)
+	m.table.HierarchyColumnIndex = 0
+	m.table.ColumnSizes = make([]unison.ColumnSize, len(equipmentColMap))
+	for i := range m.table.ColumnSizes {
+		m.table.ColumnSizes[i].Current = 80
+	}
+	m.table.ColumnSizes[0].Current = 200
+	m.table.SyncToModel()
+	return m
+}
+
+func cell(node *ntable.Node[*gurps.Equipment], row, col int) unison.Paneler {
+	return node.ColumnCell(row, col, unison.OnContentColor, unison.ContentColor, false, false, false)
+}
+
+func TestNodeCacheRebuildsOnlyChangedRows(t *testing.T) {
+	m := newEquipmentTable(20, false)
+	rows := m.table.RootRows()
+	require.Len(t, rows, 20)
+	unchanged := cell(rows[1], 4, 0)
+	changed := cell(rows[2], 5, 0)
+	container := rows[0]
+	require.Len(t, container.Children(), 3)
+	child := container.Children()[1]
+	childCell := cell(child, 2, 0)
+
+	m.data[2].Name = "Renamed"
+	m.table.SyncToModel()
+	rows2 := m.table.RootRows()
+	assert.Same(t, rows[1], rows2[1], "nodes should be reused")
+	assert.Same(t, unchanged, cell(rows2[1], 4, 0), "unchanged rows should keep their cells")
+	assert.NotSame(t, changed, cell(rows2[2], 5, 0), "changed rows should rebuild their cells")
+	assert.Same(t, child, rows2[0].Children()[1])
+	assert.Same(t, childCell, cell(child, 2, 0))
+
+	// Removing a row and adding a new child must be reflected
+	m.data = append(m.data[:3], m.data[4:]...)
+	added := gurps.NewEquipment(nil, m.data[0], false)
+	m.data[0].Children = append(m.data[0].Children, added)
+	m.table.SyncToModel()
+	rows3 := m.table.RootRows()
+	require.Len(t, rows3, 19)
+	require.Len(t, rows3[0].Children(), 4)
+	assert.Same(t, added, rows3[0].Children()[3].Data())
+	assert.Same(t, child, rows3[0].Children()[1])
+}
+
+func TestNodeCacheDuplicateIDs(t *testing.T) {
+	m := newEquipmentTable(3, false)
+	dup := m.data[1].Clone(nil, nil, true)
+	m.data = append(m.data, dup)
+	m.table.SyncToModel()
+	rows := m.table.RootRows()
+	require.Len(t, rows, 4)
+	assert.NotSame(t, rows[1], rows[3])
+	assert.Same(t, dup, rows[3].Data())
+}
+
+func benchmarkSync(b *testing.B, count int, uncached bool) {
+	m := newEquipmentTable(count, uncached)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		// Simulate an edit to a single row, as happens while typing into an editor
+		m.data[i%count].Quantity++
+		m.table.SyncToModel()
+	}
+}
+
+func BenchmarkSyncToModel(b *testing.B) {
+	for _, count := range []int{50, 500} {
+		b.Run(fmt.Sprintf("uncached-%d", count), func(b *testing.B) { benchmarkSync(b, count, true) })
+		b.Run(fmt.Sprintf("cached-%d", count), func(b *testing.B) { benchmarkSync(b, count, false) })
+	}
+}
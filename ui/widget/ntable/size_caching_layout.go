@@ -0,0 +1,36 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package ntable
+
+import "github.com/richardwilkes/unison"
+
+// sizeCachingLayout wraps a layout and remembers the sizes it last computed. The content of a cached cell never
+// changes, so this allows the table to recompute its row heights without having to measure the text of every row
+// again, making the cost of rows that are scrolled out of view negligible.
+type sizeCachingLayout struct {
+	unison.Layout
+	hint  unison.Size
+	min   unison.Size
+	pref  unison.Size
+	max   unison.Size
+	valid bool
+}
+
+// LayoutSizes implements unison.Layout.
+func (l *sizeCachingLayout) LayoutSizes(target *unison.Panel, hint unison.Size) (min, pref, max unison.Size) {
+	if !l.valid || l.hint != hint {
+		l.min, l.pref, l.max = l.Layout.LayoutSizes(target, hint)
+		l.hint = hint
+		l.valid = true
+	}
+	return l.min, l.pref, l.max
+}
@@ -81,15 +81,7 @@ func NewNodeTable[T gurps.NodeConstraint[T]](provider TableProvider[T], font uni
 	table.SetLayoutData(layoutData)
 
 	headers := provider.Headers()
-	table.ColumnSizes = make([]unison.ColumnSize, len(headers))
-	for i := range table.ColumnSizes {
-		_, pref, _ := headers[i].AsPanel().Sizes(unison.Size{})
-		pref.Width += table.Padding.Left + table.Padding.Right
-		table.ColumnSizes[i].AutoMinimum = pref.Width
-		table.ColumnSizes[i].AutoMaximum = 800
-		table.ColumnSizes[i].Minimum = pref.Width
-		table.ColumnSizes[i].Maximum = 10000
-	}
+	table.ColumnSizes = columnSizesForHeaders(table, headers)
 	header = unison.NewTableHeader(table, headers...)
 	header.Less = flexibleLess
 	if font != nil {
@@ -128,6 +120,19 @@ func NewNodeTable[T gurps.NodeConstraint[T]](provider TableProvider[T], font uni
 	return header, table
 }
 
+func columnSizesForHeaders[T gurps.NodeConstraint[T]](table *unison.Table[*Node[T]], headers []unison.TableColumnHeader[*Node[T]]) []unison.ColumnSize {
+	sizes := make([]unison.ColumnSize, len(headers))
+	for i := range sizes {
+		_, pref, _ := headers[i].AsPanel().Sizes(unison.Size{})
+		pref.Width += table.Padding.Left + table.Padding.Right
+		sizes[i].AutoMinimum = pref.Width
+		sizes[i].AutoMaximum = 800
+		sizes[i].Minimum = pref.Width
+		sizes[i].Maximum = 10000
+	}
+	return sizes
+}
+
 func flexibleLess(s1, s2 string) bool {
 	if n1, err := fxp.FromString(s1); err == nil {
 		var n2 fxp.Int
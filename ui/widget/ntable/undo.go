@@ -83,3 +83,69 @@ func applyTableData[T gurps.NodeConstraint[T]](table *unison.Table[*Node[T]], da
 	widget.MarkModified(table)
 	table.SetSelectionMap(selMap)
 }
+
+type tableDataSnapshot[T gurps.NodeConstraint[T]] struct {
+	Table    *unison.Table[*Node[T]]
+	Provider TableProvider[T]
+	Data     []byte
+	SelMap   map[uuid.UUID]bool
+}
+
+func newTableDataSnapshot[T gurps.NodeConstraint[T]](table *unison.Table[*Node[T]], provider TableProvider[T]) (*tableDataSnapshot[T], error) {
+	data, err := provider.Serialize()
+	if err != nil {
+		return nil, err
+	}
+	return &tableDataSnapshot[T]{
+		Table:    table,
+		Provider: provider,
+		Data:     data,
+		SelMap:   table.CopySelectionMap(),
+	}, nil
+}
+
+func (s *tableDataSnapshot[T]) apply() {
+	if err := s.Provider.Deserialize(s.Data); err != nil {
+		jot.Error(err)
+		return
+	}
+	finishTableDataEdit(s.Table, s.Provider)
+	s.Table.SetSelectionMap(s.SelMap)
+}
+
+func finishTableDataEdit[T gurps.NodeConstraint[T]](table *unison.Table[*Node[T]], provider TableProvider[T]) {
+	if entity := provider.Entity(); entity != nil {
+		entity.Recalculate()
+	}
+	table.SyncToModel()
+	widget.MarkModified(table)
+}
+
+// PerformTableDataEdit calls 'edit', which should alter the data held by the provider and return true if it did so. If
+// it did, an undo edit with the given name that restores the data to its prior state is then registered.
+func PerformTableDataEdit[T gurps.NodeConstraint[T]](table *unison.Table[*Node[T]], provider TableProvider[T], name string, edit func() bool) {
+	before, err := newTableDataSnapshot(table, provider)
+	if err != nil {
+		jot.Error(err)
+		return
+	}
+	if !edit() {
+		return
+	}
+	if mgr := unison.UndoManagerFor(table); mgr != nil {
+		var after *tableDataSnapshot[T]
+		if after, err = newTableDataSnapshot(table, provider); err != nil {
+			jot.Error(err)
+		} else {
+			mgr.Add(&unison.UndoEdit[*tableDataSnapshot[T]]{
+				ID:         unison.NextUndoID(),
+				EditName:   name,
+				UndoFunc:   func(edit *unison.UndoEdit[*tableDataSnapshot[T]]) { edit.BeforeData.apply() },
+				RedoFunc:   func(edit *unison.UndoEdit[*tableDataSnapshot[T]]) { edit.AfterData.apply() },
+				BeforeData: before,
+				AfterData:  after,
+			})
+		}
+	}
+	finishTableDataEdit(table, provider)
+}
@@ -0,0 +1,204 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package editors
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/richardwilkes/gcs/model/fxp"
+	"github.com/richardwilkes/gcs/model/gurps"
+	"github.com/richardwilkes/gcs/ui/widget"
+	"github.com/richardwilkes/gcs/ui/widget/ntable"
+	"github.com/richardwilkes/toolbox/i18n"
+	"github.com/richardwilkes/toolbox/log/jot"
+	"github.com/richardwilkes/unison"
+)
+
+type bulkTagMode int
+
+const (
+	leaveTagsBulkTagMode bulkTagMode = iota
+	addTagsBulkTagMode
+	replaceTagsBulkTagMode
+)
+
+func (m bulkTagMode) String() string {
+	switch m {
+	case addTagsBulkTagMode:
+		return i18n.Text("Add to existing tags")
+	case replaceTagsBulkTagMode:
+		return i18n.Text("Replace existing tags")
+	default:
+		return i18n.Text("Leave tags unchanged")
+	}
+}
+
+// BulkEdit presents a dialog that permits changes to be made to all of the selected rows at once. The changes are
+// applied as a single undoable edit.
+func BulkEdit[T gurps.NodeConstraint[T]](table *unison.Table[*ntable.Node[T]], provider ntable.TableProvider[T]) {
+	var zero T
+	rows := make([]T, 0, len(table.SelectedRows(false)))
+	for _, row := range table.SelectedRows(false) {
+		if data := row.Data(); data != zero {
+			rows = append(rows, data)
+		}
+	}
+	if len(rows) == 0 {
+		return
+	}
+	capabilities := gurps.BulkEditCapabilitiesFor(zero)
+
+	var dialog *unison.Dialog
+	panel := unison.NewPanel()
+	panel.SetLayout(&unison.FlexLayout{
+		Columns:  2,
+		HSpacing: unison.StdHSpacing,
+		VSpacing: unison.StdVSpacing,
+	})
+	invalid := make(map[*unison.Field]bool)
+	validate := func() {
+		if dialog != nil {
+			dialog.Button(unison.ModalResponseOK).SetEnabled(len(invalid) == 0)
+		}
+	}
+	addField := func(title, tooltip string, parse func(text string) bool) *unison.Field {
+		panel.AddChild(widget.NewFieldLeadingLabel(title))
+		field := unison.NewField()
+		field.SetMinimumTextWidthUsing("A reasonably long value")
+		field.SetLayoutData(&unison.FlexLayoutData{
+			HAlign: unison.FillAlignment,
+			VAlign: unison.MiddleAlignment,
+			HGrab:  true,
+		})
+		if tooltip != "" {
+			field.Tooltip = unison.NewTooltipWithText(tooltip)
+		}
+		if parse != nil {
+			field.ValidateCallback = func() bool {
+				if parse(field.Text()) {
+					delete(invalid, field)
+				} else {
+					invalid[field] = true
+				}
+				validate()
+				return !invalid[field]
+			}
+		}
+		panel.AddChild(field)
+		return field
+	}
+	isNumber := func(allowEmpty bool) func(text string) bool {
+		return func(text string) bool {
+			if text = strings.TrimSpace(text); text == "" {
+				return allowEmpty
+			}
+			_, err := fxp.FromString(text)
+			return err == nil
+		}
+	}
+
+	var tagModePopup *unison.PopupMenu[bulkTagMode]
+	var tagsField *unison.Field
+	if capabilities&gurps.BulkEditTags != 0 {
+		panel.AddChild(widget.NewFieldLeadingLabel(i18n.Text("Tags")))
+		tagModePopup = unison.NewPopupMenu[bulkTagMode]()
+		for _, mode := range []bulkTagMode{leaveTagsBulkTagMode, addTagsBulkTagMode, replaceTagsBulkTagMode} {
+			tagModePopup.AddItem(mode)
+		}
+		tagModePopup.SelectIndex(0)
+		panel.AddChild(tagModePopup)
+		tagsField = addField("", i18n.Text("Separate multiple tags with commas"), nil)
+	}
+	findField := addField(i18n.Text("Find"), i18n.Text("Text to search for within names, notes and page references"), nil)
+	replaceField := addField(i18n.Text("Replace With"), "", nil)
+	var tlField, costField, weightField, addModNameField, addModCostField, removeModField *unison.Field
+	if capabilities&gurps.BulkEditTechLevel != 0 {
+		tlField = addField(i18n.Text("Shift Tech Level By"), i18n.Text("Rows without a tech level are left alone"),
+			isNumber(true))
+	}
+	if capabilities&gurps.BulkEditCost != 0 {
+		var tooltip string
+		if _, ok := any(zero).(*gurps.Trait); ok {
+			tooltip = i18n.Text("The base points and points per level are multiplied by this factor")
+		} else {
+			tooltip = i18n.Text("The value is multiplied by this factor")
+		}
+		costField = addField(i18n.Text("Scale Cost By"), tooltip, isNumber(true))
+	}
+	if capabilities&gurps.BulkEditWeight != 0 {
+		weightField = addField(i18n.Text("Scale Weight By"), i18n.Text("The weight is multiplied by this factor"),
+			isNumber(true))
+	}
+	if capabilities&gurps.BulkEditModifiers != 0 {
+		addModNameField = addField(i18n.Text("Add Modifier Named"), "", nil)
+		addModCostField = addField(i18n.Text("Added Modifier Cost"), "", nil)
+		removeModField = addField(i18n.Text("Remove Modifiers Named"), "", nil)
+	}
+
+	var err error
+	if dialog, err = unison.NewDialog(nil, nil, panel, []*unison.DialogButtonInfo{
+		unison.NewCancelButtonInfo(),
+		unison.NewOKButtonInfo(),
+	}); err != nil {
+		jot.Error(err)
+		return
+	}
+	singular, plural := provider.ItemNames()
+	if len(rows) == 1 {
+		dialog.Window().SetTitle(fmt.Sprintf(i18n.Text("Bulk Edit 1 %s"), singular))
+	} else {
+		dialog.Window().SetTitle(fmt.Sprintf(i18n.Text("Bulk Edit %d %s"), len(rows), plural))
+	}
+	validate()
+	if dialog.RunModal() != unison.ModalResponseOK {
+		return
+	}
+
+	number := func(field *unison.Field) fxp.Int {
+		if field == nil {
+			return 0
+		}
+		value, _ := fxp.FromString(strings.TrimSpace(field.Text())) //nolint:errcheck // Already validated
+		return value
+	}
+	edit := &gurps.BulkEdit{
+		Find:           findField.Text(),
+		Replace:        replaceField.Text(),
+		TechLevelDelta: number(tlField),
+		CostFactor:     number(costField),
+		WeightFactor:   number(weightField),
+	}
+	if tagModePopup != nil {
+		if mode, _ := tagModePopup.Selected(); mode != leaveTagsBulkTagMode {
+			edit.Tags = gurps.ExtractTags(tagsField.Text())
+			edit.ReplaceTags = mode == replaceTagsBulkTagMode
+		}
+	}
+	if addModNameField != nil {
+		edit.AddModifierName = addModNameField.Text()
+		edit.AddModifierCost = addModCostField.Text()
+		edit.RemoveModifierName = removeModField.Text()
+	}
+	if edit.Empty() {
+		return
+	}
+	ntable.PerformTableDataEdit(table, provider, i18n.Text("Bulk Edit"), func() bool {
+		altered := false
+		for _, row := range rows {
+			if edit.Apply(row) {
+				altered = true
+			}
+		}
+		return altered
+	})
+}
@@ -0,0 +1,126 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package editors
+
+import (
+	"github.com/richardwilkes/gcs/model/gurps"
+	"github.com/richardwilkes/gcs/ui/widget/ntable"
+)
+
+// columnCustomizer provides the ntable.ColumnCustomizer implementation shared by the table providers. Column layouts
+// are recorded within the sheet settings of the entity the list belongs to, or within the global sheet settings if it
+// doesn't belong to one. An empty layout key disables customization, leaving the default columns in place.
+type columnCustomizer struct {
+	layoutKey      string
+	entityProvider gurps.EntityProvider
+	defaults       map[int]int
+	available      []ntable.ColumnInfo
+	colMap         map[int]int
+}
+
+func newColumnCustomizer(layoutKey string, entityProvider gurps.EntityProvider, defaults map[int]int, available ...ntable.ColumnInfo) columnCustomizer {
+	c := columnCustomizer{
+		layoutKey:      layoutKey,
+		entityProvider: entityProvider,
+		defaults:       defaults,
+		available:      available,
+		colMap:         defaults,
+	}
+	if layoutKey != "" {
+		c.colMap = c.colMapFor(c.storedLayout())
+	}
+	return c
+}
+
+func (c *columnCustomizer) AvailableColumns() []ntable.ColumnInfo {
+	if c.layoutKey == "" {
+		return nil
+	}
+	return c.available
+}
+
+func (c *columnCustomizer) ColumnLayout() *gurps.ColumnLayout {
+	if layout := c.storedLayout(); layout != nil && sameColumns(layout.ColMap(), c.colMap) {
+		return layout
+	}
+	return gurps.NewColumnLayoutFromColMap(c.colMap)
+}
+
+func (c *columnCustomizer) SetColumnLayout(layout *gurps.ColumnLayout) {
+	if c.layoutKey == "" {
+		return
+	}
+	if layout != nil {
+		layout = layout.Clone()
+		layout.EnsureValidity(c.availableIDs())
+		if sameColumns(layout.ColMap(), c.defaults) && !hasColumnWidths(layout) {
+			layout = nil
+		}
+	}
+	gurps.SheetSettingsFor(c.entityProvider.Entity()).SetColumnLayout(c.layoutKey, layout)
+	// Only replace the column map when the columns have actually changed, since a new column map causes the rows to be
+	// rebuilt.
+	if colMap := c.colMapFor(layout); !sameColumns(colMap, c.colMap) {
+		c.colMap = colMap
+	}
+}
+
+func (c *columnCustomizer) storedLayout() *gurps.ColumnLayout {
+	layout := gurps.SheetSettingsFor(c.entityProvider.Entity()).ColumnLayout(c.layoutKey)
+	if layout != nil {
+		layout.EnsureValidity(c.availableIDs())
+		if len(layout.Columns) == 0 {
+			return nil
+		}
+	}
+	return layout
+}
+
+func (c *columnCustomizer) colMapFor(layout *gurps.ColumnLayout) map[int]int {
+	if layout == nil || len(layout.Columns) == 0 {
+		return c.defaults
+	}
+	return layout.ColMap()
+}
+
+func (c *columnCustomizer) availableIDs() []int {
+	ids := make([]int, len(c.available))
+	for i, one := range c.available {
+		ids[i] = one.ID
+	}
+	return ids
+}
+
+func sameColumns(m1, m2 map[int]int) bool {
+	if len(m1) != len(m2) {
+		return false
+	}
+	for k, v := range m1 {
+		if other, ok := m2[k]; !ok || other != v {
+			return false
+		}
+	}
+	return true
+}
+
+func hasColumnWidths(layout *gurps.ColumnLayout) bool {
+	for _, one := range layout.Columns {
+		if one.Width > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func columnInfo(id int, title string) ntable.ColumnInfo {
+	return ntable.ColumnInfo{ID: id, Title: title}
+}
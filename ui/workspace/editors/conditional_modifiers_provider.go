@@ -33,6 +33,7 @@ var (
 type condModProvider struct {
 	table    *unison.Table[*ntable.Node[*gurps.ConditionalModifier]]
 	provider gurps.ConditionalModifierListProvider
+	nodes    ntable.NodeCache[*gurps.ConditionalModifier]
 }
 
 // NewConditionalModifiersProvider creates a new table provider for conditional modifiers.
@@ -51,12 +52,7 @@ func (p *condModProvider) RootRowCount() int {
 }
 
 func (p *condModProvider) RootRows() []*ntable.Node[*gurps.ConditionalModifier] {
-	data := p.provider.ConditionalModifiers()
-	rows := make([]*ntable.Node[*gurps.ConditionalModifier], 0, len(data))
-	for _, one := range data {
-		rows = append(rows, ntable.NewNode[*gurps.ConditionalModifier](p.table, nil, conditionalModifierColMap, one, true))
-	}
-	return rows
+	return p.nodes.RootRows(p.table, conditionalModifierColMap, p.provider.ConditionalModifiers(), true)
 }
 
 func (p *condModProvider) SetRootRows(_ []*ntable.Node[*gurps.ConditionalModifier]) {
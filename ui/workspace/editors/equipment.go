@@ -24,6 +24,7 @@ import (
 	"github.com/richardwilkes/gcs/ui/widget/ntable"
 	"github.com/richardwilkes/toolbox/i18n"
 	"github.com/richardwilkes/unison"
+	"golang.org/x/exp/slices"
 )
 
 // EditEquipment displays the editor for equipment.
@@ -86,13 +87,35 @@ func EditEquipment(owner widget.Rebuildable, equipment *gurps.Equipment, carried
 			addTagsLabelAndField(content, &e.editorData.Tags)
 			addPageRefLabelAndField(content, &e.editorData.PageRef)
 			adjustFieldBlank(usesField, e.editorData.MaxUses <= 0)
-			content.AddChild(newPrereqPanel(e.target.Entity, &e.editorData.Prereq))
+			content.AddChild(newPrereqPanel(e.target.Entity, e.target, &e.editorData.Prereq))
 			content.AddChild(newFeaturesPanel(e.target.Entity, e.target, &e.editorData.Features))
 			modifiersPanel := newEquipmentModifiersPanel(e.target.Entity, &e.editorData.Modifiers)
 			content.AddChild(modifiersPanel)
 			for _, wt := range weapon.AllType {
 				content.AddChild(newWeaponsPanel(e, e.target, wt, &e.editorData.Weapons))
 			}
+			addModifiersFromLibraryButton(modifiersPanel.AsPanel(), func() {
+				(&modifierPicker[*gurps.EquipmentModifier]{
+					entity:  e.target.Entity,
+					name:    e.editorData.Name,
+					tags:    e.editorData.Tags,
+					choices: gurps.LibraryEquipmentModifiers(gurps.SettingsProvider.Libraries()),
+					preview: func(modifiers []*gurps.EquipmentModifier) string {
+						defUnits := gurps.SheetSettingsFor(e.target.Entity).DefaultWeightUnits
+						after := append(slices.Clone(e.editorData.Modifiers), modifiers...)
+						return modifierPreview(i18n.Text("Value"),
+							gurps.ValueAdjustedForModifiers(e.editorData.Value, e.editorData.Modifiers).Comma(),
+							gurps.ValueAdjustedForModifiers(e.editorData.Value, after).Comma()) + "    " +
+							modifierPreview(i18n.Text("Weight"),
+								defUnits.Format(gurps.WeightAdjustedForModifiers(e.editorData.Weight,
+									e.editorData.Modifiers, defUnits)),
+								defUnits.Format(gurps.WeightAdjustedForModifiers(e.editorData.Weight, after, defUnits)))
+					},
+					table:   modifiersPanel.table,
+					list:    modifiersPanel.EquipmentModifierList,
+					setList: modifiersPanel.SetEquipmentModifierList,
+				}).run()
+			})
 			e.InstallCmdHandlers(constants.NewEquipmentModifierItemID, unison.AlwaysEnabled,
 				func(_ any) { modifiersPanel.provider.CreateItem(e, modifiersPanel.table, ntable.NoItemVariant) })
 			e.InstallCmdHandlers(constants.NewEquipmentContainerModifierItemID, unison.AlwaysEnabled,
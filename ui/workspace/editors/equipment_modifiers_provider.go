@@ -42,12 +42,14 @@ var (
 		6: gurps.EquipmentModifierReferenceColumn,
 	}
 	_ ntable.TableProvider[*gurps.EquipmentModifier] = &eqpModProvider{}
+	_ ntable.ColumnCustomizer                        = &eqpModProvider{}
 )
 
 type eqpModProvider struct {
+	columnCustomizer
 	table    *unison.Table[*ntable.Node[*gurps.EquipmentModifier]]
-	colMap   map[int]int
 	provider gurps.EquipmentModifierListProvider
+	nodes    ntable.NodeCache[*gurps.EquipmentModifier]
 }
 
 // NewEquipmentModifiersProvider creates a new table provider for equipment modifiers.
@@ -56,9 +58,18 @@ func NewEquipmentModifiersProvider(provider gurps.EquipmentModifierListProvider,
 		provider: provider,
 	}
 	if forEditor {
-		p.colMap = equipmentModifierInEditorColMap
+		p.columnCustomizer = newColumnCustomizer("", provider, equipmentModifierInEditorColMap)
 	} else {
-		p.colMap = equipmentModifierColMap
+		p.columnCustomizer = newColumnCustomizer("equipment_modifiers_list", provider, equipmentModifierColMap,
+			columnInfo(gurps.EquipmentModifierDescriptionColumn, i18n.Text("Name")),
+			columnInfo(gurps.EquipmentModifierTechLevelColumn, i18n.Text("Tech Level")),
+			columnInfo(gurps.EquipmentModifierCostColumn, i18n.Text("Cost Adjustment")),
+			columnInfo(gurps.EquipmentModifierWeightColumn, i18n.Text("Weight Adjustment")),
+			columnInfo(gurps.EquipmentModifierTagsColumn, i18n.Text("Tags")),
+			columnInfo(gurps.EquipmentModifierReferenceColumn, i18n.Text("Page Reference")),
+			columnInfo(gurps.EquipmentModifierVTTNotesColumn, i18n.Text("VTT Notes")),
+			columnInfo(gurps.EquipmentModifierIDColumn, i18n.Text("ID")),
+		)
 	}
 	return p
 }
@@ -72,12 +83,7 @@ func (p *eqpModProvider) RootRowCount() int {
 }
 
 func (p *eqpModProvider) RootRows() []*ntable.Node[*gurps.EquipmentModifier] {
-	data := p.provider.EquipmentModifierList()
-	rows := make([]*ntable.Node[*gurps.EquipmentModifier], 0, len(data))
-	for _, one := range data {
-		rows = append(rows, ntable.NewNode[*gurps.EquipmentModifier](p.table, nil, p.colMap, one, false))
-	}
-	return rows
+	return p.nodes.RootRows(p.table, p.colMap, p.provider.EquipmentModifierList(), false)
 }
 
 func (p *eqpModProvider) SetRootRows(rows []*ntable.Node[*gurps.EquipmentModifier]) {
@@ -130,6 +136,10 @@ func (p *eqpModProvider) Headers() []unison.TableColumnHeader[*ntable.Node[*gurp
 			headers = append(headers, NewHeader[*gurps.EquipmentModifier](i18n.Text("Tags"), "", false))
 		case gurps.EquipmentModifierReferenceColumn:
 			headers = append(headers, NewPageRefHeader[*gurps.EquipmentModifier](false))
+		case gurps.EquipmentModifierVTTNotesColumn:
+			headers = append(headers, NewHeader[*gurps.EquipmentModifier](i18n.Text("VTT Notes"), "", false))
+		case gurps.EquipmentModifierIDColumn:
+			headers = append(headers, NewHeader[*gurps.EquipmentModifier](i18n.Text("ID"), "", false))
 		default:
 			jot.Fatalf(1, "invalid equipment modifier column: %d", p.colMap[i])
 		}
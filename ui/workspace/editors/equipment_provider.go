@@ -62,14 +62,16 @@ var (
 		9: gurps.EquipmentReferenceColumn,
 	}
 	_ ntable.TableProvider[*gurps.Equipment] = &equipmentProvider{}
+	_ ntable.ColumnCustomizer                = &equipmentProvider{}
 )
 
 type equipmentProvider struct {
+	columnCustomizer
 	table    *unison.Table[*ntable.Node[*gurps.Equipment]]
-	colMap   map[int]int
 	provider gurps.EquipmentListProvider
 	forPage  bool
 	carried  bool
+	nodes    ntable.NodeCache[*gurps.Equipment]
 }
 
 // NewEquipmentProvider creates a new table provider for equipment. 'carried' is only relevant if 'forPage' is true.
@@ -81,12 +83,60 @@ func NewEquipmentProvider(provider gurps.EquipmentListProvider, forPage, carried
 	}
 	if forPage {
 		if carried {
-			p.colMap = carriedEquipmentPageColMap
+			p.columnCustomizer = newColumnCustomizer("carried_equipment_page", provider, carriedEquipmentPageColMap,
+				columnInfo(gurps.EquipmentEquippedColumn, i18n.Text("Equipped")),
+				columnInfo(gurps.EquipmentQuantityColumn, i18n.Text("Quantity")),
+				columnInfo(gurps.EquipmentDescriptionColumn, i18n.Text("Name")),
+				columnInfo(gurps.EquipmentUsesColumn, i18n.Text("Uses")),
+				columnInfo(gurps.EquipmentMaxUsesColumn, i18n.Text("Maximum Uses")),
+				columnInfo(gurps.EquipmentTLColumn, i18n.Text("Tech Level")),
+				columnInfo(gurps.EquipmentLCColumn, i18n.Text("Legality Class")),
+				columnInfo(gurps.EquipmentCostColumn, i18n.Text("Value")),
+				columnInfo(gurps.EquipmentWeightColumn, i18n.Text("Weight")),
+				columnInfo(gurps.EquipmentExtendedCostColumn, i18n.Text("Extended Value")),
+				columnInfo(gurps.EquipmentExtendedWeightColumn, i18n.Text("Extended Weight")),
+				columnInfo(gurps.EquipmentTagsColumn, i18n.Text("Tags")),
+				columnInfo(gurps.EquipmentReferenceColumn, i18n.Text("Page Reference")),
+				columnInfo(gurps.EquipmentVTTNotesColumn, i18n.Text("VTT Notes")),
+				columnInfo(gurps.EquipmentLibrarySourceColumn, i18n.Text("Library Source")),
+				columnInfo(gurps.EquipmentIDColumn, i18n.Text("ID")),
+			)
 		} else {
-			p.colMap = otherEquipmentPageColMap
+			p.columnCustomizer = newColumnCustomizer("other_equipment_page", provider, otherEquipmentPageColMap,
+				columnInfo(gurps.EquipmentQuantityColumn, i18n.Text("Quantity")),
+				columnInfo(gurps.EquipmentDescriptionColumn, i18n.Text("Name")),
+				columnInfo(gurps.EquipmentUsesColumn, i18n.Text("Uses")),
+				columnInfo(gurps.EquipmentMaxUsesColumn, i18n.Text("Maximum Uses")),
+				columnInfo(gurps.EquipmentTLColumn, i18n.Text("Tech Level")),
+				columnInfo(gurps.EquipmentLCColumn, i18n.Text("Legality Class")),
+				columnInfo(gurps.EquipmentCostColumn, i18n.Text("Value")),
+				columnInfo(gurps.EquipmentWeightColumn, i18n.Text("Weight")),
+				columnInfo(gurps.EquipmentExtendedCostColumn, i18n.Text("Extended Value")),
+				columnInfo(gurps.EquipmentExtendedWeightColumn, i18n.Text("Extended Weight")),
+				columnInfo(gurps.EquipmentTagsColumn, i18n.Text("Tags")),
+				columnInfo(gurps.EquipmentReferenceColumn, i18n.Text("Page Reference")),
+				columnInfo(gurps.EquipmentVTTNotesColumn, i18n.Text("VTT Notes")),
+				columnInfo(gurps.EquipmentLibrarySourceColumn, i18n.Text("Library Source")),
+				columnInfo(gurps.EquipmentIDColumn, i18n.Text("ID")),
+			)
 		}
 	} else {
-		p.colMap = equipmentListColMap
+		p.columnCustomizer = newColumnCustomizer("equipment_list", provider, equipmentListColMap,
+			columnInfo(gurps.EquipmentQuantityColumn, i18n.Text("Quantity")),
+			columnInfo(gurps.EquipmentDescriptionColumn, i18n.Text("Name")),
+			columnInfo(gurps.EquipmentMaxUsesColumn, i18n.Text("Maximum Uses")),
+			columnInfo(gurps.EquipmentTLColumn, i18n.Text("Tech Level")),
+			columnInfo(gurps.EquipmentLCColumn, i18n.Text("Legality Class")),
+			columnInfo(gurps.EquipmentCostColumn, i18n.Text("Value")),
+			columnInfo(gurps.EquipmentWeightColumn, i18n.Text("Weight")),
+			columnInfo(gurps.EquipmentExtendedCostColumn, i18n.Text("Extended Value")),
+			columnInfo(gurps.EquipmentExtendedWeightColumn, i18n.Text("Extended Weight")),
+			columnInfo(gurps.EquipmentTagsColumn, i18n.Text("Tags")),
+			columnInfo(gurps.EquipmentReferenceColumn, i18n.Text("Page Reference")),
+			columnInfo(gurps.EquipmentVTTNotesColumn, i18n.Text("VTT Notes")),
+			columnInfo(gurps.EquipmentLibrarySourceColumn, i18n.Text("Library Source")),
+			columnInfo(gurps.EquipmentIDColumn, i18n.Text("ID")),
+		)
 	}
 	return p
 }
@@ -100,12 +150,7 @@ func (p *equipmentProvider) RootRowCount() int {
 }
 
 func (p *equipmentProvider) RootRows() []*ntable.Node[*gurps.Equipment] {
-	data := p.equipmentList()
-	rows := make([]*ntable.Node[*gurps.Equipment], 0, len(data))
-	for _, one := range data {
-		rows = append(rows, ntable.NewNode[*gurps.Equipment](p.table, nil, p.colMap, one, p.forPage))
-	}
-	return rows
+	return p.nodes.RootRows(p.table, p.colMap, p.equipmentList(), p.forPage)
 }
 
 func (p *equipmentProvider) SetRootRows(rows []*ntable.Node[*gurps.Equipment]) {
@@ -179,6 +224,12 @@ func (p *equipmentProvider) Headers() []unison.TableColumnHeader[*ntable.Node[*g
 			headers = append(headers, NewHeader[*gurps.Equipment](i18n.Text("Tags"), "", p.forPage))
 		case gurps.EquipmentReferenceColumn:
 			headers = append(headers, NewPageRefHeader[*gurps.Equipment](p.forPage))
+		case gurps.EquipmentVTTNotesColumn:
+			headers = append(headers, NewHeader[*gurps.Equipment](i18n.Text("VTT Notes"), "", p.forPage))
+		case gurps.EquipmentLibrarySourceColumn:
+			headers = append(headers, NewHeader[*gurps.Equipment](i18n.Text("Source"), i18n.Text("The library file this was copied from"), p.forPage))
+		case gurps.EquipmentIDColumn:
+			headers = append(headers, NewHeader[*gurps.Equipment](i18n.Text("ID"), "", p.forPage))
 		default:
 			jot.Fatalf(1, "invalid equipment column: %d", p.colMap[i])
 		}
@@ -188,8 +239,8 @@ func (p *equipmentProvider) Headers() []unison.TableColumnHeader[*ntable.Node[*g
 
 func (p *equipmentProvider) SyncHeader(headers []unison.TableColumnHeader[*ntable.Node[*gurps.Equipment]]) {
 	if p.forPage {
-		for i := 0; i < len(carriedEquipmentPageColMap); i++ {
-			if carriedEquipmentPageColMap[i] == gurps.EquipmentDescriptionColumn {
+		for i := 0; i < len(p.colMap) && i < len(headers); i++ {
+			if p.colMap[i] == gurps.EquipmentDescriptionColumn {
 				if header, ok2 := headers[i].(*PageTableColumnHeader[*gurps.Equipment]); ok2 {
 					header.Label.Text = p.descriptionText()
 				}
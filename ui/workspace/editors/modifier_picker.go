@@ -0,0 +1,357 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package editors
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/richardwilkes/gcs/model/gurps"
+	"github.com/richardwilkes/gcs/model/gurps/nameables"
+	"github.com/richardwilkes/gcs/ui/widget"
+	"github.com/richardwilkes/gcs/ui/widget/ntable"
+	"github.com/richardwilkes/toolbox/i18n"
+	"github.com/richardwilkes/toolbox/log/jot"
+	"github.com/richardwilkes/toolbox/txt"
+	"github.com/richardwilkes/unison"
+)
+
+const (
+	pickerNameColumn = iota
+	pickerTagsColumn
+	pickerLibraryColumn
+	pickerColumnCount
+)
+
+// modifierPicker describes how library modifiers are offered for, and added to, the item being edited.
+type modifierPicker[T gurps.NodeConstraint[T]] struct {
+	entity  *gurps.Entity
+	name    string
+	tags    []string
+	choices []*gurps.LibraryModifier[T]
+	// preview returns a description of the effect that adding the modifiers would have on the item, or an empty string
+	// if there is nothing worth showing.
+	preview func(modifiers []T) string
+	table   *unison.Table[*ntable.Node[T]]
+	list    func() []T
+	setList func([]T)
+}
+
+type modifierPickerRow[T gurps.NodeConstraint[T]] struct {
+	id     uuid.UUID
+	choice *gurps.LibraryModifier[T]
+}
+
+func addModifiersFromLibraryButton(parent *unison.Panel, pick func()) {
+	button := unison.NewButton()
+	button.Text = i18n.Text("Add From Library…")
+	button.Tooltip = unison.NewTooltipWithText(i18n.Text("Add modifiers found in the libraries"))
+	button.ClickCallback = pick
+	bar := unison.NewPanel()
+	bar.SetBorder(unison.NewEmptyBorder(unison.NewUniformInsets(unison.StdHSpacing / 2)))
+	bar.SetLayout(&unison.FlexLayout{Columns: 1})
+	bar.SetLayoutData(&unison.FlexLayoutData{
+		HAlign: unison.FillAlignment,
+		HGrab:  true,
+	})
+	bar.AddChild(button)
+	parent.AddChildAtIndex(bar, 0)
+}
+
+// run presents the available modifiers and adds clones of those chosen to the item.
+func (p *modifierPicker[T]) run() {
+	if len(p.choices) == 0 {
+		unison.WarningDialogWithMessage(i18n.Text("No modifiers were found in the libraries."), "")
+		return
+	}
+	hasRelevant := false
+	for _, choice := range p.choices {
+		if choice.RelevantTo(p.name, p.tags) {
+			hasRelevant = true
+			break
+		}
+	}
+
+	table := unison.NewTable[*modifierPickerRow[T]](&unison.SimpleTableModel[*modifierPickerRow[T]]{})
+	table.ColumnSizes = make([]unison.ColumnSize, pickerColumnCount)
+	header := unison.NewTableHeader[*modifierPickerRow[T]](table,
+		unison.NewTableColumnHeader[*modifierPickerRow[T]](i18n.Text("Modifier"), ""),
+		unison.NewTableColumnHeader[*modifierPickerRow[T]](i18n.Text("Tags"), ""),
+		unison.NewTableColumnHeader[*modifierPickerRow[T]](i18n.Text("Library"), ""),
+	)
+	scroller := unison.NewScrollPanel()
+	scroller.SetColumnHeader(header)
+	scroller.SetContent(table, unison.FillBehavior, unison.FillBehavior)
+	scroller.SetLayoutData(&unison.FlexLayoutData{
+		MinSize: unison.Size{Width: 600, Height: 300},
+		HSpan:   2,
+		HAlign:  unison.FillAlignment,
+		VAlign:  unison.FillAlignment,
+		HGrab:   true,
+		VGrab:   true,
+	})
+
+	searchField := unison.NewField()
+	searchField.Watermark = i18n.Text("Search")
+	searchField.Tooltip = unison.NewTooltipWithText(i18n.Text("Each word must appear somewhere within the modifier"))
+	searchField.SetLayoutData(&unison.FlexLayoutData{
+		HAlign: unison.FillAlignment,
+		VAlign: unison.MiddleAlignment,
+		HGrab:  true,
+	})
+
+	relevantCheckBox := unison.NewCheckBox()
+	relevantCheckBox.Text = i18n.Text("Only those relevant to this item")
+	relevantCheckBox.Tooltip = unison.NewTooltipWithText(
+		i18n.Text("Only show modifiers with a tag that matches the name or one of the tags of this item"))
+	if hasRelevant {
+		relevantCheckBox.State = unison.OnCheckState
+	} else {
+		relevantCheckBox.SetEnabled(false)
+	}
+
+	previewLabel := unison.NewLabel()
+	previewLabel.SetLayoutData(&unison.FlexLayoutData{
+		HSpan:  2,
+		HAlign: unison.FillAlignment,
+		HGrab:  true,
+	})
+
+	filter := func() {
+		text := searchField.Text()
+		onlyRelevant := relevantCheckBox.State == unison.OnCheckState
+		rows := make([]*modifierPickerRow[T], 0, len(p.choices))
+		for _, choice := range p.choices {
+			if (!onlyRelevant || choice.RelevantTo(p.name, p.tags)) && choice.Matches(text) {
+				rows = append(rows, &modifierPickerRow[T]{
+					id:     uuid.New(),
+					choice: choice,
+				})
+			}
+		}
+		table.SetRootRows(rows)
+		table.SizeColumnsToFit(true)
+	}
+	updatePreview := func() {
+		previewLabel.Text = ""
+		if p.preview != nil && table.HasSelection() {
+			rows := table.SelectedRows(false)
+			selected := make([]T, 0, len(rows))
+			for _, row := range rows {
+				selected = append(selected, row.choice.Modifier)
+			}
+			previewLabel.Text = p.preview(selected)
+		}
+		previewLabel.MarkForLayoutAndRedraw()
+	}
+	filter()
+
+	panel := unison.NewPanel()
+	panel.SetLayout(&unison.FlexLayout{
+		Columns:  2,
+		HSpacing: unison.StdHSpacing,
+		VSpacing: unison.StdVSpacing,
+	})
+	panel.AddChild(searchField)
+	panel.AddChild(relevantCheckBox)
+	panel.AddChild(scroller)
+	panel.AddChild(previewLabel)
+	dialog, err := unison.NewDialog(nil, nil, panel, []*unison.DialogButtonInfo{
+		unison.NewCancelButtonInfo(),
+		unison.NewOKButtonInfoWithTitle(i18n.Text("Add")),
+	})
+	if err != nil {
+		jot.Error(err)
+		return
+	}
+	dialog.Window().SetTitle(i18n.Text("Add Modifiers From Library"))
+	addButton := dialog.Button(unison.ModalResponseOK)
+	addButton.SetEnabled(false)
+	// The table has no notification of selection changes, so check after each event that might alter it
+	selectionChanged := func() {
+		updatePreview()
+		addButton.SetEnabled(table.HasSelection())
+	}
+	table.MouseUpCallback = func(where unison.Point, button int, mod unison.Modifiers) bool {
+		stop := table.DefaultMouseUp(where, button, mod)
+		selectionChanged()
+		return stop
+	}
+	table.KeyDownCallback = func(keyCode unison.KeyCode, mod unison.Modifiers, repeat bool) bool {
+		stop := table.DefaultKeyDown(keyCode, mod, repeat)
+		selectionChanged()
+		return stop
+	}
+	searchField.ModifiedCallback = func() {
+		filter()
+		selectionChanged()
+	}
+	relevantCheckBox.ClickCallback = searchField.ModifiedCallback
+	table.DoubleClickCallback = func() {
+		if table.HasSelection() {
+			dialog.StopModal(unison.ModalResponseOK)
+		}
+	}
+	searchField.RequestFocus()
+	if dialog.RunModal() != unison.ModalResponseOK {
+		return
+	}
+	var zero T
+	rows := table.SelectedRows(false)
+	modifiers := make([]T, 0, len(rows))
+	for _, row := range rows {
+		modifiers = append(modifiers, row.choice.Modifier.Clone(p.entity, zero, false))
+	}
+	if !nameModifiers(modifiers) {
+		return
+	}
+	p.setList(append(p.list(), modifiers...))
+	widget.MarkModified(p.table)
+}
+
+// nameModifiers prompts for values for any nameable keys found within the modifiers, replacing the keys with the values
+// given. Returns false if the user cancels.
+func nameModifiers[T gurps.NodeConstraint[T]](modifiers []T) bool {
+	keys := make(map[string]string)
+	for _, one := range modifiers {
+		if n, ok := any(one).(nameables.Nameables); ok {
+			n.FillWithNameableKeys(keys)
+		}
+	}
+	if len(keys) == 0 {
+		return true
+	}
+	list := make([]string, 0, len(keys))
+	for k := range keys {
+		list = append(list, k)
+	}
+	sort.Slice(list, func(i, j int) bool { return txt.NaturalLess(list[i], list[j], true) })
+	panel := unison.NewPanel()
+	panel.SetLayout(&unison.FlexLayout{
+		Columns:  2,
+		HSpacing: unison.StdHSpacing,
+		VSpacing: unison.StdVSpacing,
+	})
+	label := unison.NewLabel()
+	label.Text = i18n.Text("Provide values for the placeholders within the modifiers:")
+	label.SetLayoutData(&unison.FlexLayoutData{HSpan: 2})
+	panel.AddChild(label)
+	var first *widget.StringField
+	for _, key := range list {
+		k := key
+		panel.AddChild(widget.NewFieldLeadingLabel(k))
+		field := widget.NewStringField(k, func() string { return keys[k] }, func(s string) { keys[k] = s })
+		field.SetMinimumTextWidthUsing("A reasonably long placeholder value")
+		panel.AddChild(field)
+		if first == nil {
+			first = field
+		}
+	}
+	dialog, err := unison.NewDialog(nil, nil, panel, []*unison.DialogButtonInfo{
+		unison.NewCancelButtonInfo(),
+		unison.NewOKButtonInfo(),
+	})
+	if err != nil {
+		jot.Error(err)
+		return false
+	}
+	dialog.Window().SetTitle(i18n.Text("Name Modifiers"))
+	first.SelectAll()
+	first.RequestFocus()
+	if dialog.RunModal() != unison.ModalResponseOK {
+		return false
+	}
+	for _, one := range modifiers {
+		if n, ok := any(one).(nameables.Nameables); ok {
+			n.ApplyNameableKeys(keys)
+		}
+	}
+	return true
+}
+
+// CloneForTarget implements unison.TableRowData.
+func (r *modifierPickerRow[T]) CloneForTarget(_ unison.Paneler, _ *modifierPickerRow[T]) *modifierPickerRow[T] {
+	return r
+}
+
+// UUID implements unison.TableRowData.
+func (r *modifierPickerRow[T]) UUID() uuid.UUID {
+	return r.id
+}
+
+// Parent implements unison.TableRowData.
+func (r *modifierPickerRow[T]) Parent() *modifierPickerRow[T] {
+	return nil
+}
+
+// SetParent implements unison.TableRowData.
+func (r *modifierPickerRow[T]) SetParent(_ *modifierPickerRow[T]) {
+}
+
+// CanHaveChildren implements unison.TableRowData.
+func (r *modifierPickerRow[T]) CanHaveChildren() bool {
+	return false
+}
+
+// Children implements unison.TableRowData.
+func (r *modifierPickerRow[T]) Children() []*modifierPickerRow[T] {
+	return nil
+}
+
+// SetChildren implements unison.TableRowData.
+func (r *modifierPickerRow[T]) SetChildren(_ []*modifierPickerRow[T]) {
+}
+
+// CellDataForSort implements unison.TableRowData.
+func (r *modifierPickerRow[T]) CellDataForSort(col int) string {
+	switch col {
+	case pickerNameColumn:
+		return r.choice.Name
+	case pickerTagsColumn:
+		return strings.Join(r.choice.Tags, ", ")
+	case pickerLibraryColumn:
+		return r.choice.Library.Title
+	default:
+		return ""
+	}
+}
+
+// ColumnCell implements unison.TableRowData.
+func (r *modifierPickerRow[T]) ColumnCell(_, col int, foreground, _ unison.Ink, _, _, _ bool) unison.Paneler {
+	label := unison.NewLabel()
+	label.LabelTheme.OnBackgroundInk = foreground
+	label.Text = r.CellDataForSort(col)
+	switch col {
+	case pickerNameColumn:
+		if r.choice.Notes != "" {
+			label.Tooltip = unison.NewTooltipWithText(r.choice.Notes)
+		}
+	case pickerLibraryColumn:
+		label.Tooltip = unison.NewTooltipWithText(r.choice.FilePath)
+	}
+	return label
+}
+
+// IsOpen implements unison.TableRowData.
+func (r *modifierPickerRow[T]) IsOpen() bool {
+	return false
+}
+
+// SetOpen implements unison.TableRowData.
+func (r *modifierPickerRow[T]) SetOpen(_ bool) {
+}
+
+func modifierPreview(label, before, after string) string {
+	return fmt.Sprintf(i18n.Text("%s: %s → %s"), label, before, after)
+}
@@ -29,20 +29,33 @@ var (
 		1: gurps.NoteReferenceColumn,
 	}
 	_ ntable.TableProvider[*gurps.Note] = &notesProvider{}
+	_ ntable.ColumnCustomizer           = &notesProvider{}
 )
 
 type notesProvider struct {
+	columnCustomizer
 	table    *unison.Table[*ntable.Node[*gurps.Note]]
 	provider gurps.NoteListProvider
 	forPage  bool
+	nodes    ntable.NodeCache[*gurps.Note]
 }
 
 // NewNotesProvider creates a new table provider for notes.
 func NewNotesProvider(provider gurps.NoteListProvider, forPage bool) ntable.TableProvider[*gurps.Note] {
-	return &notesProvider{
+	p := &notesProvider{
 		provider: provider,
 		forPage:  forPage,
 	}
+	layoutKey := "notes_list"
+	if forPage {
+		layoutKey = "notes_page"
+	}
+	p.columnCustomizer = newColumnCustomizer(layoutKey, provider, noteColMap,
+		columnInfo(gurps.NoteTextColumn, i18n.Text("Text")),
+		columnInfo(gurps.NoteReferenceColumn, i18n.Text("Page Reference")),
+		columnInfo(gurps.NoteIDColumn, i18n.Text("ID")),
+	)
+	return p
 }
 
 func (p *notesProvider) SetTable(table *unison.Table[*ntable.Node[*gurps.Note]]) {
@@ -54,12 +67,7 @@ func (p *notesProvider) RootRowCount() int {
 }
 
 func (p *notesProvider) RootRows() []*ntable.Node[*gurps.Note] {
-	data := p.provider.NoteList()
-	rows := make([]*ntable.Node[*gurps.Note], 0, len(data))
-	for _, one := range data {
-		rows = append(rows, ntable.NewNode[*gurps.Note](p.table, nil, noteColMap, one, p.forPage))
-	}
-	return rows
+	return p.nodes.RootRows(p.table, p.colMap, p.provider.NoteList(), p.forPage)
 }
 
 func (p *notesProvider) SetRootRows(rows []*ntable.Node[*gurps.Note]) {
@@ -96,14 +104,16 @@ func (p *notesProvider) ItemNames() (singular, plural string) {
 
 func (p *notesProvider) Headers() []unison.TableColumnHeader[*ntable.Node[*gurps.Note]] {
 	var headers []unison.TableColumnHeader[*ntable.Node[*gurps.Note]]
-	for i := 0; i < len(noteColMap); i++ {
-		switch noteColMap[i] {
+	for i := 0; i < len(p.colMap); i++ {
+		switch p.colMap[i] {
 		case gurps.NoteTextColumn:
 			headers = append(headers, NewHeader[*gurps.Note](i18n.Text("Note"), "", p.forPage))
 		case gurps.NoteReferenceColumn:
 			headers = append(headers, NewPageRefHeader[*gurps.Note](p.forPage))
+		case gurps.NoteIDColumn:
+			headers = append(headers, NewHeader[*gurps.Note](i18n.Text("ID"), "", p.forPage))
 		default:
-			jot.Fatalf(1, "invalid note column: %d", noteColMap[i])
+			jot.Fatalf(1, "invalid note column: %d", p.colMap[i])
 		}
 	}
 	return headers
@@ -113,7 +123,7 @@ func (p *notesProvider) SyncHeader(_ []unison.TableColumnHeader[*ntable.Node[*gu
 }
 
 func (p *notesProvider) HierarchyColumnIndex() int {
-	for k, v := range noteColMap {
+	for k, v := range p.colMap {
 		if v == gurps.NoteTextColumn {
 			return k
 		}
@@ -37,7 +37,7 @@ func OpenPageRef[T gurps.NodeConstraint[T]](table *unison.Table[*ntable.Node[T]]
 		var data gurps.CellData
 		row.Data().CellData(gurps.PageRefCellAlias, &data)
 		for _, one := range settings.ExtractPageReferences(data.Primary) {
-			if settings.OpenPageReference(table.Window(), one, data.Secondary, promptCtx) {
+			if settings.OpenPageReference(table, one, data.Secondary, promptCtx) {
 				return
 			}
 		}
@@ -51,7 +51,7 @@ func OpenEachPageRef[T gurps.NodeConstraint[T]](table *unison.Table[*ntable.Node
 		var data gurps.CellData
 		row.Data().CellData(gurps.PageRefCellAlias, &data)
 		for _, one := range settings.ExtractPageReferences(data.Primary) {
-			if settings.OpenPageReference(table.Window(), one, data.Secondary, promptCtx) {
+			if settings.OpenPageReference(table, one, data.Secondary, promptCtx) {
 				return
 			}
 		}
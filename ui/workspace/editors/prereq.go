@@ -20,29 +20,57 @@ import (
 	"github.com/richardwilkes/gcs/model/gurps/spell"
 	"github.com/richardwilkes/gcs/res"
 	"github.com/richardwilkes/gcs/ui/widget"
+	"github.com/richardwilkes/gcs/ui/workspace"
 	"github.com/richardwilkes/toolbox/errs"
 	"github.com/richardwilkes/toolbox/i18n"
 	"github.com/richardwilkes/toolbox/log/jot"
+	"github.com/richardwilkes/toolbox/xio"
 	"github.com/richardwilkes/unison"
 	"golang.org/x/exp/slices"
 )
 
-const noAndOr = ""
+const (
+	noAndOr            = ""
+	prereqStatusPrefix = "\n● "
+)
 
-var lastPrereqTypeUsed = prereq.Trait
+var (
+	lastPrereqTypeUsed = prereq.Trait
+	// lastPrereqTargetPath holds the file path of the sheet last chosen to evaluate prerequisites against, rather than
+	// the sheet itself, so that a sheet that has since been closed is not kept alive.
+	lastPrereqTargetPath string
+)
 
 type prereqPanel struct {
 	unison.Panel
-	entity   *gurps.Entity
-	root     **gurps.PrereqList
-	andOrMap map[gurps.Prereq]*unison.Label
+	entity    *gurps.Entity
+	owner     any
+	root      **gurps.PrereqList
+	target    gurps.EntityProvider
+	andOrMap  map[gurps.Prereq]*unison.Label
+	statusMap map[gurps.Prereq]*unison.Label
+}
+
+// prereqTarget is a choice of sheet to evaluate the prerequisites of an item that is not part of a sheet against.
+type prereqTarget struct {
+	title    string
+	path     string
+	provider gurps.EntityProvider
+}
+
+func (t *prereqTarget) String() string {
+	return t.title
 }
 
-func newPrereqPanel(entity *gurps.Entity, root **gurps.PrereqList) *prereqPanel {
+// newPrereqPanel creates a panel for editing the prerequisites of owner. If entity is not nil, each prerequisite shows
+// whether the entity currently satisfies it. Otherwise, an open sheet may be chosen to evaluate them against.
+func newPrereqPanel(entity *gurps.Entity, owner any, root **gurps.PrereqList) *prereqPanel {
 	p := &prereqPanel{
-		entity:   entity,
-		root:     root,
-		andOrMap: make(map[gurps.Prereq]*unison.Label),
+		entity:    entity,
+		owner:     owner,
+		root:      root,
+		andOrMap:  make(map[gurps.Prereq]*unison.Label),
+		statusMap: make(map[gurps.Prereq]*unison.Label),
 	}
 	p.Self = p
 	p.SetLayout(&unison.FlexLayout{Columns: 1})
@@ -59,10 +87,127 @@ func newPrereqPanel(entity *gurps.Entity, root **gurps.PrereqList) *prereqPanel
 	p.DrawCallback = func(gc *unison.Canvas, rect unison.Rect) {
 		gc.DrawRect(rect, unison.ContentColor.Paint(gc, rect, unison.Fill))
 	}
+	if entity != nil {
+		p.target = entity
+	} else {
+		p.AddChild(p.createTargetPanel())
+	}
 	p.AddChild(p.createPrereqListPanel(0, *root))
+	p.Sync()
 	return p
 }
 
+func (p *prereqPanel) createTargetPanel() *unison.Panel {
+	panel := unison.NewPanel()
+	panel.SetLayout(&unison.FlexLayout{
+		Columns:  2,
+		HSpacing: unison.StdHSpacing,
+	})
+	title := i18n.Text("Evaluate against")
+	panel.AddChild(widget.NewFieldLeadingLabel(title))
+	popup := unison.NewPopupMenu[*prereqTarget]()
+	popup.Tooltip = unison.NewTooltipWithText(i18n.Text("The sheet to check the prerequisites against"))
+	popup.AddItem(&prereqTarget{title: i18n.Text("Nothing")})
+	popup.SelectIndex(0)
+	for _, one := range prereqTargets() {
+		popup.AddItem(one)
+		if one.path != "" && one.path == lastPrereqTargetPath {
+			popup.Select(one)
+			p.target = one.provider
+		}
+	}
+	popup.SelectionCallback = func(_ int, item *prereqTarget) {
+		p.target = item.provider
+		lastPrereqTargetPath = item.path
+		p.Sync()
+	}
+	panel.AddChild(popup)
+	return panel
+}
+
+// prereqTargets returns the open sheets.
+func prereqTargets() []*prereqTarget {
+	var list []*prereqTarget
+	for _, wnd := range unison.Windows() {
+		if ws := workspace.FromWindow(wnd); ws != nil {
+			ws.DocumentDock.RootDockLayout().ForEachDockContainer(func(dc *unison.DockContainer) bool {
+				for _, one := range dc.Dockables() {
+					if kind, ok := one.(widget.DockableKind); ok && kind.DockableKind() == widget.SheetDockableKind {
+						if provider, ok2 := one.(gurps.EntityProvider); ok2 {
+							target := &prereqTarget{
+								title:    one.Title(),
+								provider: provider,
+							}
+							if fbd, ok3 := one.(workspace.FileBackedDockable); ok3 {
+								target.path = fbd.BackingFilePath()
+							}
+							list = append(list, target)
+						}
+					}
+				}
+				return false
+			})
+		}
+	}
+	return list
+}
+
+// Sync implements widget.Syncer. Updates the indicator of whether each prerequisite is currently satisfied.
+func (p *prereqPanel) Sync() {
+	var entity *gurps.Entity
+	if p.target != nil {
+		entity = p.target.Entity()
+	}
+	seen := make(map[gurps.Prereq]bool, len(p.statusMap))
+	var update func(pr gurps.Prereq)
+	update = func(pr gurps.Prereq) {
+		seen[pr] = true
+		if label, ok := p.statusMap[pr]; ok {
+			p.updateStatus(label, entity, pr)
+		}
+		if list, ok := pr.(*gurps.PrereqList); ok {
+			for _, child := range list.Prereqs {
+				update(child)
+			}
+		}
+	}
+	if *p.root != nil {
+		update(*p.root)
+	}
+	for pr := range p.statusMap {
+		if !seen[pr] {
+			delete(p.statusMap, pr)
+		}
+	}
+}
+
+func (p *prereqPanel) updateStatus(label *unison.Label, entity *gurps.Entity, pr gurps.Prereq) {
+	if entity == nil {
+		label.Drawable = nil
+		label.Tooltip = nil
+		label.MarkForRedraw()
+		return
+	}
+	baseline := label.Font.Baseline()
+	var tooltip xio.ByteBuffer
+	if pr.Satisfied(entity, p.owner, &tooltip, prereqStatusPrefix) {
+		label.Drawable = &unison.DrawableSVG{
+			SVG:  res.CheckmarkSVG,
+			Size: unison.NewSize(baseline, baseline),
+		}
+		label.OnBackgroundInk = unison.OnContentColor
+		label.Tooltip = unison.NewTooltipWithText(i18n.Text("Currently satisfied"))
+	} else {
+		label.Drawable = &unison.DrawableSVG{
+			SVG:  unison.TriangleExclamationSVG(),
+			Size: unison.NewSize(baseline, baseline),
+		}
+		label.OnBackgroundInk = unison.ErrorColor
+		label.Tooltip = unison.NewTooltipWithText(i18n.Text("Not currently satisfied:") + tooltip.String())
+	}
+	label.MarkForRedraw()
+}
+
 func (p *prereqPanel) createPrereqListPanel(depth int, list *gurps.PrereqList) *unison.Panel {
 	panel := unison.NewPanel()
 	p.createButtonsPanel(panel, depth, list)
@@ -132,6 +277,13 @@ func (p *prereqPanel) createButtonsPanel(parent *unison.Panel, depth int, data g
 	buttons := unison.NewPanel()
 	buttons.SetBorder(unison.NewEmptyBorder(unison.Insets{Left: float32(depth * 20)}))
 	parent.AddChild(buttons)
+	status := unison.NewLabel()
+	status.SetLayoutData(&unison.FlexLayoutData{
+		MinSize: unison.NewSize(status.Font.Baseline(), 0),
+		VAlign:  unison.MiddleAlignment,
+	})
+	p.statusMap[data] = status
+	buttons.AddChild(status)
 	if prereqList, ok := data.(*gurps.PrereqList); ok {
 		addPrereqButton := unison.NewSVGButton(res.CircledAddSVG)
 		addPrereqButton.ClickCallback = func() {
@@ -162,6 +314,7 @@ func (p *prereqPanel) createButtonsPanel(parent *unison.Panel, depth int, data g
 		deleteButton := unison.NewSVGButton(res.TrashSVG)
 		deleteButton.ClickCallback = func() {
 			delete(p.andOrMap, data)
+			delete(p.statusMap, data)
 			if i := slices.IndexFunc(parentList.Prereqs, func(elem gurps.Prereq) bool { return elem == data }); i != -1 {
 				parentList.Prereqs = slices.Delete(parentList.Prereqs, i, i+1)
 			}
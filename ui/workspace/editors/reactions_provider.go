@@ -33,6 +33,7 @@ var (
 type reactionModProvider struct {
 	table    *unison.Table[*ntable.Node[*gurps.ConditionalModifier]]
 	provider gurps.ReactionModifierListProvider
+	nodes    ntable.NodeCache[*gurps.ConditionalModifier]
 }
 
 // NewReactionModifiersProvider creates a new table provider for reaction modifiers.
@@ -51,12 +52,7 @@ func (p *reactionModProvider) RootRowCount() int {
 }
 
 func (p *reactionModProvider) RootRows() []*ntable.Node[*gurps.ConditionalModifier] {
-	data := p.provider.Reactions()
-	rows := make([]*ntable.Node[*gurps.ConditionalModifier], 0, len(data))
-	for _, one := range data {
-		rows = append(rows, ntable.NewNode[*gurps.ConditionalModifier](p.table, nil, conditionalModifierColMap, one, true))
-	}
-	return rows
+	return p.nodes.RootRows(p.table, conditionalModifierColMap, p.provider.Reactions(), true)
 }
 
 func (p *reactionModProvider) SetRootRows(_ []*ntable.Node[*gurps.ConditionalModifier]) {
@@ -43,13 +43,15 @@ var (
 		2: gurps.SkillReferenceColumn,
 	}
 	_ ntable.TableProvider[*gurps.Skill] = &skillsProvider{}
+	_ ntable.ColumnCustomizer            = &skillsProvider{}
 )
 
 type skillsProvider struct {
+	columnCustomizer
 	table    *unison.Table[*ntable.Node[*gurps.Skill]]
-	colMap   map[int]int
 	provider gurps.SkillListProvider
 	forPage  bool
+	nodes    ntable.NodeCache[*gurps.Skill]
 }
 
 // NewSkillsProvider creates a new table provider for skills.
@@ -60,12 +62,44 @@ func NewSkillsProvider(provider gurps.SkillListProvider, forPage bool) ntable.Ta
 	}
 	if forPage {
 		if _, ok := provider.(*gurps.Entity); ok {
-			p.colMap = entitySkillPageColMap
+			p.columnCustomizer = newColumnCustomizer("skills_page", provider, entitySkillPageColMap,
+				columnInfo(gurps.SkillDescriptionColumn, i18n.Text("Name")),
+				columnInfo(gurps.SkillDifficultyColumn, i18n.Text("Difficulty")),
+				columnInfo(gurps.SkillLevelColumn, i18n.Text("Skill Level")),
+				columnInfo(gurps.SkillRelativeLevelColumn, i18n.Text("Relative Skill Level")),
+				columnInfo(gurps.SkillPointsColumn, i18n.Text("Points")),
+				columnInfo(gurps.SkillTLColumn, i18n.Text("Tech Level")),
+				columnInfo(gurps.SkillTagsColumn, i18n.Text("Tags")),
+				columnInfo(gurps.SkillReferenceColumn, i18n.Text("Page Reference")),
+				columnInfo(gurps.SkillVTTNotesColumn, i18n.Text("VTT Notes")),
+				columnInfo(gurps.SkillLibrarySourceColumn, i18n.Text("Library Source")),
+				columnInfo(gurps.SkillIDColumn, i18n.Text("ID")),
+			)
 		} else {
-			p.colMap = skillPageColMap
+			p.columnCustomizer = newColumnCustomizer("skills_template_page", provider, skillPageColMap,
+				columnInfo(gurps.SkillDescriptionColumn, i18n.Text("Name")),
+				columnInfo(gurps.SkillDifficultyColumn, i18n.Text("Difficulty")),
+				columnInfo(gurps.SkillPointsColumn, i18n.Text("Points")),
+				columnInfo(gurps.SkillTLColumn, i18n.Text("Tech Level")),
+				columnInfo(gurps.SkillTagsColumn, i18n.Text("Tags")),
+				columnInfo(gurps.SkillReferenceColumn, i18n.Text("Page Reference")),
+				columnInfo(gurps.SkillVTTNotesColumn, i18n.Text("VTT Notes")),
+				columnInfo(gurps.SkillLibrarySourceColumn, i18n.Text("Library Source")),
+				columnInfo(gurps.SkillIDColumn, i18n.Text("ID")),
+			)
 		}
 	} else {
-		p.colMap = skillListColMap
+		p.columnCustomizer = newColumnCustomizer("skills_list", provider, skillListColMap,
+			columnInfo(gurps.SkillDescriptionColumn, i18n.Text("Name")),
+			columnInfo(gurps.SkillDifficultyColumn, i18n.Text("Difficulty")),
+			columnInfo(gurps.SkillPointsColumn, i18n.Text("Points")),
+			columnInfo(gurps.SkillTLColumn, i18n.Text("Tech Level")),
+			columnInfo(gurps.SkillTagsColumn, i18n.Text("Tags")),
+			columnInfo(gurps.SkillReferenceColumn, i18n.Text("Page Reference")),
+			columnInfo(gurps.SkillVTTNotesColumn, i18n.Text("VTT Notes")),
+			columnInfo(gurps.SkillLibrarySourceColumn, i18n.Text("Library Source")),
+			columnInfo(gurps.SkillIDColumn, i18n.Text("ID")),
+		)
 	}
 	return p
 }
@@ -79,12 +113,7 @@ func (p *skillsProvider) RootRowCount() int {
 }
 
 func (p *skillsProvider) RootRows() []*ntable.Node[*gurps.Skill] {
-	data := p.provider.SkillList()
-	rows := make([]*ntable.Node[*gurps.Skill], 0, len(data))
-	for _, one := range data {
-		rows = append(rows, ntable.NewNode[*gurps.Skill](p.table, nil, p.colMap, one, p.forPage))
-	}
-	return rows
+	return p.nodes.RootRows(p.table, p.colMap, p.provider.SkillList(), p.forPage)
 }
 
 func (p *skillsProvider) SetRootRows(rows []*ntable.Node[*gurps.Skill]) {
@@ -137,6 +166,14 @@ func (p *skillsProvider) Headers() []unison.TableColumnHeader[*ntable.Node[*gurp
 			headers = append(headers, NewHeader[*gurps.Skill](i18n.Text("RSL"), i18n.Text("Relative Skill Level"), p.forPage))
 		case gurps.SkillPointsColumn:
 			headers = append(headers, NewHeader[*gurps.Skill](i18n.Text("Pts"), i18n.Text("Points"), p.forPage))
+		case gurps.SkillTLColumn:
+			headers = append(headers, NewHeader[*gurps.Skill](i18n.Text("TL"), i18n.Text("Tech Level"), p.forPage))
+		case gurps.SkillVTTNotesColumn:
+			headers = append(headers, NewHeader[*gurps.Skill](i18n.Text("VTT Notes"), "", p.forPage))
+		case gurps.SkillLibrarySourceColumn:
+			headers = append(headers, NewHeader[*gurps.Skill](i18n.Text("Source"), i18n.Text("The library file this was copied from"), p.forPage))
+		case gurps.SkillIDColumn:
+			headers = append(headers, NewHeader[*gurps.Skill](i18n.Text("ID"), "", p.forPage))
 		default:
 			jot.Fatalf(1, "invalid skill column: %d", p.colMap[i])
 		}
@@ -53,13 +53,15 @@ var (
 		4: gurps.SpellReferenceColumn,
 	}
 	_ ntable.TableProvider[*gurps.Spell] = &spellsProvider{}
+	_ ntable.ColumnCustomizer            = &spellsProvider{}
 )
 
 type spellsProvider struct {
+	columnCustomizer
 	table    *unison.Table[*ntable.Node[*gurps.Spell]]
-	colMap   map[int]int
 	provider gurps.SpellListProvider
 	forPage  bool
+	nodes    ntable.NodeCache[*gurps.Spell]
 }
 
 // NewSpellsProvider creates a new table provider for spells.
@@ -70,12 +72,65 @@ func NewSpellsProvider(provider gurps.SpellListProvider, forPage bool) ntable.Ta
 	}
 	if forPage {
 		if _, ok := provider.(*gurps.Entity); ok {
-			p.colMap = entitySpellPageColMap
+			p.columnCustomizer = newColumnCustomizer("spells_page", provider, entitySpellPageColMap,
+				columnInfo(gurps.SpellDescriptionForPageColumn, i18n.Text("Name")),
+				columnInfo(gurps.SpellCollegeColumn, i18n.Text("College")),
+				columnInfo(gurps.SpellResistColumn, i18n.Text("Resistance")),
+				columnInfo(gurps.SpellClassColumn, i18n.Text("Class")),
+				columnInfo(gurps.SpellCastCostColumn, i18n.Text("Casting Cost")),
+				columnInfo(gurps.SpellMaintainCostColumn, i18n.Text("Maintenance Cost")),
+				columnInfo(gurps.SpellCastTimeColumn, i18n.Text("Casting Time")),
+				columnInfo(gurps.SpellDurationColumn, i18n.Text("Duration")),
+				columnInfo(gurps.SpellDifficultyColumn, i18n.Text("Difficulty")),
+				columnInfo(gurps.SpellLevelColumn, i18n.Text("Skill Level")),
+				columnInfo(gurps.SpellRelativeLevelColumn, i18n.Text("Relative Skill Level")),
+				columnInfo(gurps.SpellPointsColumn, i18n.Text("Points")),
+				columnInfo(gurps.SpellTLColumn, i18n.Text("Tech Level")),
+				columnInfo(gurps.SpellTagsColumn, i18n.Text("Tags")),
+				columnInfo(gurps.SpellReferenceColumn, i18n.Text("Page Reference")),
+				columnInfo(gurps.SpellVTTNotesColumn, i18n.Text("VTT Notes")),
+				columnInfo(gurps.SpellLibrarySourceColumn, i18n.Text("Library Source")),
+				columnInfo(gurps.SpellIDColumn, i18n.Text("ID")),
+			)
 		} else {
-			p.colMap = spellPageColMap
+			p.columnCustomizer = newColumnCustomizer("spells_template_page", provider, spellPageColMap,
+				columnInfo(gurps.SpellDescriptionForPageColumn, i18n.Text("Name")),
+				columnInfo(gurps.SpellCollegeColumn, i18n.Text("College")),
+				columnInfo(gurps.SpellResistColumn, i18n.Text("Resistance")),
+				columnInfo(gurps.SpellClassColumn, i18n.Text("Class")),
+				columnInfo(gurps.SpellCastCostColumn, i18n.Text("Casting Cost")),
+				columnInfo(gurps.SpellMaintainCostColumn, i18n.Text("Maintenance Cost")),
+				columnInfo(gurps.SpellCastTimeColumn, i18n.Text("Casting Time")),
+				columnInfo(gurps.SpellDurationColumn, i18n.Text("Duration")),
+				columnInfo(gurps.SpellDifficultyColumn, i18n.Text("Difficulty")),
+				columnInfo(gurps.SpellPointsColumn, i18n.Text("Points")),
+				columnInfo(gurps.SpellTLColumn, i18n.Text("Tech Level")),
+				columnInfo(gurps.SpellTagsColumn, i18n.Text("Tags")),
+				columnInfo(gurps.SpellReferenceColumn, i18n.Text("Page Reference")),
+				columnInfo(gurps.SpellVTTNotesColumn, i18n.Text("VTT Notes")),
+				columnInfo(gurps.SpellLibrarySourceColumn, i18n.Text("Library Source")),
+				columnInfo(gurps.SpellIDColumn, i18n.Text("ID")),
+			)
 		}
 	} else {
-		p.colMap = spellListColMap
+		p.columnCustomizer = newColumnCustomizer("spells_list", provider, spellListColMap,
+			columnInfo(gurps.SpellDescriptionColumn, i18n.Text("Name")),
+			columnInfo(gurps.SpellCollegeColumn, i18n.Text("College")),
+			columnInfo(gurps.SpellResistColumn, i18n.Text("Resistance")),
+			columnInfo(gurps.SpellClassColumn, i18n.Text("Class")),
+			columnInfo(gurps.SpellCastCostColumn, i18n.Text("Casting Cost")),
+			columnInfo(gurps.SpellMaintainCostColumn, i18n.Text("Maintenance Cost")),
+			columnInfo(gurps.SpellCastTimeColumn, i18n.Text("Casting Time")),
+			columnInfo(gurps.SpellDurationColumn, i18n.Text("Duration")),
+			columnInfo(gurps.SpellDifficultyColumn, i18n.Text("Difficulty")),
+			columnInfo(gurps.SpellPointsColumn, i18n.Text("Points")),
+			columnInfo(gurps.SpellTLColumn, i18n.Text("Tech Level")),
+			columnInfo(gurps.SpellTagsColumn, i18n.Text("Tags")),
+			columnInfo(gurps.SpellReferenceColumn, i18n.Text("Page Reference")),
+			columnInfo(gurps.SpellVTTNotesColumn, i18n.Text("VTT Notes")),
+			columnInfo(gurps.SpellLibrarySourceColumn, i18n.Text("Library Source")),
+			columnInfo(gurps.SpellIDColumn, i18n.Text("ID")),
+		)
 	}
 	return p
 }
@@ -89,12 +144,7 @@ func (p *spellsProvider) RootRowCount() int {
 }
 
 func (p *spellsProvider) RootRows() []*ntable.Node[*gurps.Spell] {
-	data := p.provider.SpellList()
-	rows := make([]*ntable.Node[*gurps.Spell], 0, len(data))
-	for _, one := range data {
-		rows = append(rows, ntable.NewNode[*gurps.Spell](p.table, nil, p.colMap, one, p.forPage))
-	}
-	return rows
+	return p.nodes.RootRows(p.table, p.colMap, p.provider.SpellList(), p.forPage)
 }
 
 func (p *spellsProvider) SetRootRows(rows []*ntable.Node[*gurps.Spell]) {
@@ -164,6 +214,14 @@ func (p *spellsProvider) Headers() []unison.TableColumnHeader[*ntable.Node[*gurp
 			headers = append(headers, NewHeader[*gurps.Spell](i18n.Text("RSL"), i18n.Text("Relative Skill Level"), p.forPage))
 		case gurps.SpellPointsColumn:
 			headers = append(headers, NewHeader[*gurps.Spell](i18n.Text("Pts"), i18n.Text("Points"), p.forPage))
+		case gurps.SpellTLColumn:
+			headers = append(headers, NewHeader[*gurps.Spell](i18n.Text("TL"), i18n.Text("Tech Level"), p.forPage))
+		case gurps.SpellVTTNotesColumn:
+			headers = append(headers, NewHeader[*gurps.Spell](i18n.Text("VTT Notes"), "", p.forPage))
+		case gurps.SpellLibrarySourceColumn:
+			headers = append(headers, NewHeader[*gurps.Spell](i18n.Text("Source"), i18n.Text("The library file this was copied from"), p.forPage))
+		case gurps.SpellIDColumn:
+			headers = append(headers, NewHeader[*gurps.Spell](i18n.Text("ID"), "", p.forPage))
 		default:
 			jot.Fatalf(1, "invalid spell column: %d", p.colMap[i])
 		}
@@ -23,6 +23,7 @@ import (
 	"github.com/richardwilkes/gcs/ui/widget/ntable"
 	"github.com/richardwilkes/toolbox/i18n"
 	"github.com/richardwilkes/unison"
+	"golang.org/x/exp/slices"
 )
 
 // EditTrait displays the editor for a trait.
@@ -87,13 +88,35 @@ func initTraitEditor(e *editor[*gurps.Trait, *gurps.TraitEditData], content *uni
 	if e.target.Container() {
 		content.AddChild(modifiersPanel)
 	} else {
-		content.AddChild(newPrereqPanel(e.target.Entity, &e.editorData.Prereq))
+		content.AddChild(newPrereqPanel(e.target.Entity, e.target, &e.editorData.Prereq))
 		content.AddChild(newFeaturesPanel(e.target.Entity, e.target, &e.editorData.Features))
 		content.AddChild(modifiersPanel)
 		for _, wt := range weapon.AllType {
 			content.AddChild(newWeaponsPanel(e, e.target, wt, &e.editorData.Weapons))
 		}
 	}
+	addModifiersFromLibraryButton(modifiersPanel.AsPanel(), func() {
+		picker := &modifierPicker[*gurps.TraitModifier]{
+			entity:  e.target.Entity,
+			name:    e.editorData.Name,
+			tags:    e.editorData.Tags,
+			choices: gurps.LibraryTraitModifiers(gurps.SettingsProvider.Libraries()),
+			table:   modifiersPanel.table,
+			list:    modifiersPanel.TraitModifierList,
+			setList: modifiersPanel.SetTraitModifierList,
+		}
+		if !e.target.Container() {
+			picker.preview = func(modifiers []*gurps.TraitModifier) string {
+				cost := func(list []*gurps.TraitModifier) string {
+					return gurps.AdjustedPoints(e.target.Entity, e.editorData.BasePoints, e.editorData.Levels,
+						e.editorData.PointsPerLevel, e.editorData.CR, list, e.editorData.RoundCostDown).String()
+				}
+				return modifierPreview(i18n.Text("Point Cost"), cost(e.editorData.Modifiers),
+					cost(append(slices.Clone(e.editorData.Modifiers), modifiers...)))
+			}
+		}
+		picker.run()
+	})
 	e.InstallCmdHandlers(constants.NewTraitModifierItemID, unison.AlwaysEnabled,
 		func(_ any) { modifiersPanel.provider.CreateItem(e, modifiersPanel.table, ntable.NoItemVariant) })
 	e.InstallCmdHandlers(constants.NewTraitContainerModifierItemID, unison.AlwaysEnabled,
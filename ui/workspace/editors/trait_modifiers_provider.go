@@ -38,12 +38,14 @@ var (
 		4: gurps.TraitModifierReferenceColumn,
 	}
 	_ ntable.TableProvider[*gurps.TraitModifier] = &traitModifierProvider{}
+	_ ntable.ColumnCustomizer                    = &traitModifierProvider{}
 )
 
 type traitModifierProvider struct {
+	columnCustomizer
 	table    *unison.Table[*ntable.Node[*gurps.TraitModifier]]
-	colMap   map[int]int
 	provider gurps.TraitModifierListProvider
+	nodes    ntable.NodeCache[*gurps.TraitModifier]
 }
 
 // NewTraitModifiersProvider creates a new table provider for trait modifiers.
@@ -52,9 +54,16 @@ func NewTraitModifiersProvider(provider gurps.TraitModifierListProvider, forEdit
 		provider: provider,
 	}
 	if forEditor {
-		p.colMap = traitModifierInEditorColMap
+		p.columnCustomizer = newColumnCustomizer("", provider, traitModifierInEditorColMap)
 	} else {
-		p.colMap = traitModifierColMap
+		p.columnCustomizer = newColumnCustomizer("trait_modifiers_list", provider, traitModifierColMap,
+			columnInfo(gurps.TraitModifierDescriptionColumn, i18n.Text("Name")),
+			columnInfo(gurps.TraitModifierCostColumn, i18n.Text("Cost Modifier")),
+			columnInfo(gurps.TraitModifierTagsColumn, i18n.Text("Tags")),
+			columnInfo(gurps.TraitModifierReferenceColumn, i18n.Text("Page Reference")),
+			columnInfo(gurps.TraitModifierVTTNotesColumn, i18n.Text("VTT Notes")),
+			columnInfo(gurps.TraitModifierIDColumn, i18n.Text("ID")),
+		)
 	}
 	return p
 }
@@ -68,12 +77,7 @@ func (p *traitModifierProvider) RootRowCount() int {
 }
 
 func (p *traitModifierProvider) RootRows() []*ntable.Node[*gurps.TraitModifier] {
-	data := p.provider.TraitModifierList()
-	rows := make([]*ntable.Node[*gurps.TraitModifier], 0, len(data))
-	for _, one := range data {
-		rows = append(rows, ntable.NewNode[*gurps.TraitModifier](p.table, nil, p.colMap, one, false))
-	}
-	return rows
+	return p.nodes.RootRows(p.table, p.colMap, p.provider.TraitModifierList(), false)
 }
 
 func (p *traitModifierProvider) SetRootRows(rows []*ntable.Node[*gurps.TraitModifier]) {
@@ -122,6 +126,10 @@ func (p *traitModifierProvider) Headers() []unison.TableColumnHeader[*ntable.Nod
 			headers = append(headers, NewHeader[*gurps.TraitModifier](i18n.Text("Tags"), "", false))
 		case gurps.TraitModifierReferenceColumn:
 			headers = append(headers, NewPageRefHeader[*gurps.TraitModifier](false))
+		case gurps.TraitModifierVTTNotesColumn:
+			headers = append(headers, NewHeader[*gurps.TraitModifier](i18n.Text("VTT Notes"), "", false))
+		case gurps.TraitModifierIDColumn:
+			headers = append(headers, NewHeader[*gurps.TraitModifier](i18n.Text("ID"), "", false))
 		default:
 			jot.Fatalf(1, "invalid trait modifier column: %d", p.colMap[i])
 		}
@@ -36,13 +36,15 @@ var (
 		2: gurps.TraitReferenceColumn,
 	}
 	_ ntable.TableProvider[*gurps.Trait] = &traitsProvider{}
+	_ ntable.ColumnCustomizer            = &traitsProvider{}
 )
 
 type traitsProvider struct {
+	columnCustomizer
 	table    *unison.Table[*ntable.Node[*gurps.Trait]]
-	colMap   map[int]int
 	provider gurps.TraitListProvider
 	forPage  bool
+	nodes    ntable.NodeCache[*gurps.Trait]
 }
 
 // NewTraitsProvider creates a new table provider for traits.
@@ -51,11 +53,21 @@ func NewTraitsProvider(provider gurps.TraitListProvider, forPage bool) ntable.Ta
 		provider: provider,
 		forPage:  forPage,
 	}
+	layoutKey := "traits_list"
+	defaults := traitListColMap
 	if forPage {
-		p.colMap = traitPageColMap
-	} else {
-		p.colMap = traitListColMap
+		layoutKey = "traits_page"
+		defaults = traitPageColMap
 	}
+	p.columnCustomizer = newColumnCustomizer(layoutKey, provider, defaults,
+		columnInfo(gurps.TraitDescriptionColumn, i18n.Text("Name")),
+		columnInfo(gurps.TraitPointsColumn, i18n.Text("Points")),
+		columnInfo(gurps.TraitTagsColumn, i18n.Text("Tags")),
+		columnInfo(gurps.TraitReferenceColumn, i18n.Text("Page Reference")),
+		columnInfo(gurps.TraitVTTNotesColumn, i18n.Text("VTT Notes")),
+		columnInfo(gurps.TraitLibrarySourceColumn, i18n.Text("Library Source")),
+		columnInfo(gurps.TraitIDColumn, i18n.Text("ID")),
+	)
 	return p
 }
 
@@ -68,12 +80,7 @@ func (p *traitsProvider) RootRowCount() int {
 }
 
 func (p *traitsProvider) RootRows() []*ntable.Node[*gurps.Trait] {
-	data := p.provider.TraitList()
-	rows := make([]*ntable.Node[*gurps.Trait], 0, len(data))
-	for _, one := range data {
-		rows = append(rows, ntable.NewNode[*gurps.Trait](p.table, nil, p.colMap, one, p.forPage))
-	}
-	return rows
+	return p.nodes.RootRows(p.table, p.colMap, p.provider.TraitList(), p.forPage)
 }
 
 func (p *traitsProvider) SetRootRows(rows []*ntable.Node[*gurps.Trait]) {
@@ -120,6 +127,12 @@ func (p *traitsProvider) Headers() []unison.TableColumnHeader[*ntable.Node[*gurp
 			headers = append(headers, NewHeader[*gurps.Trait](i18n.Text("Tags"), "", p.forPage))
 		case gurps.TraitReferenceColumn:
 			headers = append(headers, NewPageRefHeader[*gurps.Trait](p.forPage))
+		case gurps.TraitVTTNotesColumn:
+			headers = append(headers, NewHeader[*gurps.Trait](i18n.Text("VTT Notes"), "", p.forPage))
+		case gurps.TraitLibrarySourceColumn:
+			headers = append(headers, NewHeader[*gurps.Trait](i18n.Text("Source"), i18n.Text("The library file this was copied from"), p.forPage))
+		case gurps.TraitIDColumn:
+			headers = append(headers, NewHeader[*gurps.Trait](i18n.Text("ID"), "", p.forPage))
 		default:
 			jot.Fatalf(1, "invalid trait column: %d", p.colMap[i])
 		}
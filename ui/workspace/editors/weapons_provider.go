@@ -76,6 +76,7 @@ type weaponsProvider struct {
 	provider   gurps.WeaponListProvider
 	weaponType weapon.Type
 	forPage    bool
+	nodes      ntable.NodeCache[*gurps.Weapon]
 }
 
 // NewWeaponsProvider creates a new table provider for weapons.
@@ -109,12 +110,7 @@ func (p *weaponsProvider) RootRowCount() int {
 }
 
 func (p *weaponsProvider) RootRows() []*ntable.Node[*gurps.Weapon] {
-	data := p.provider.Weapons(p.weaponType)
-	rows := make([]*ntable.Node[*gurps.Weapon], 0, len(data))
-	for _, one := range data {
-		rows = append(rows, ntable.NewNode[*gurps.Weapon](p.table, nil, p.colMap, one, p.forPage))
-	}
-	return rows
+	return p.nodes.RootRows(p.table, p.colMap, p.provider.Weapons(p.weaponType), p.forPage)
 }
 
 func (p *weaponsProvider) SetRootRows(rows []*ntable.Node[*gurps.Weapon]) {
@@ -29,8 +29,9 @@ const (
 )
 
 var (
-	_ workspace.FileBackedDockable = &ImageDockable{}
-	_ unison.TabCloser             = &ImageDockable{}
+	_ workspace.FileBackedDockable   = &ImageDockable{}
+	_ workspace.RetargetableDockable = &ImageDockable{}
+	_ unison.TabCloser               = &ImageDockable{}
 )
 
 // ImageDockable holds the view for an image file.
@@ -254,6 +255,11 @@ func (d *ImageDockable) BackingFilePath() string {
 	return d.path
 }
 
+// SetBackingFilePath implements workspace.RetargetableDockable
+func (d *ImageDockable) SetBackingFilePath(filePath string) {
+	d.path = filePath
+}
+
 // Modified implements workspace.FileBackedDockable
 func (d *ImageDockable) Modified() bool {
 	return false
@@ -13,10 +13,13 @@ package external
 
 import (
 	"fmt"
+	"os"
 	"strconv"
 	"time"
 
 	"github.com/richardwilkes/gcs/model/library"
+	"github.com/richardwilkes/gcs/model/printing"
+	"github.com/richardwilkes/gcs/model/settings"
 	"github.com/richardwilkes/gcs/model/theme"
 	"github.com/richardwilkes/gcs/pdf"
 	"github.com/richardwilkes/gcs/res"
@@ -37,14 +40,16 @@ const (
 )
 
 var (
-	_ workspace.FileBackedDockable = &PDFDockable{}
-	_ unison.TabCloser             = &PDFDockable{}
+	_ workspace.FileBackedDockable   = &PDFDockable{}
+	_ workspace.RetargetableDockable = &PDFDockable{}
+	_ unison.TabCloser               = &PDFDockable{}
 )
 
 // PDFDockable holds the view for a PDF file.
 type PDFDockable struct {
 	unison.Panel
 	path               string
+	previewData        []byte
 	pdf                *pdf.PDF
 	scroll             *unison.ScrollPanel
 	docPanel           *unison.Panel
@@ -81,6 +86,50 @@ func NewPDFDockable(filePath string) (unison.Dockable, error) {
 	}); err != nil {
 		return nil, err
 	}
+	d.setup()
+	return d, nil
+}
+
+// NewPrintPreviewDockable creates a new unison.Dockable that previews printed output. The title is used in place of a
+// file name until the PDF data is saved.
+func NewPrintPreviewDockable(title string, data []byte) (unison.Dockable, error) {
+	d := &PDFDockable{
+		path:        title + ".pdf",
+		previewData: data,
+		scale:       100,
+		noUpdate:    true,
+	}
+	d.Self = d
+	var err error
+	if d.pdf, err = pdf.NewFromData(data, func() {
+		unison.InvokeTask(d.pageLoaded)
+	}); err != nil {
+		return nil, err
+	}
+	d.setup()
+	return d, nil
+}
+
+// ShowPrintPreview displays a print preview of the document within the workspace of the window.
+func ShowPrintPreview(wnd *unison.Window, title string, doc *printing.Document) {
+	data, err := doc.Bytes()
+	var d unison.Dockable
+	if err == nil {
+		d, err = NewPrintPreviewDockable(title, data)
+	}
+	if err != nil {
+		unison.ErrorDialogWithError(i18n.Text("Unable to create the print preview"), err)
+		return
+	}
+	workspace.DisplayNewDockable(wnd, d)
+	if missing := doc.MissingCharacters(); len(missing) != 0 {
+		unison.WarningDialogWithMessage(i18n.Text("Some characters cannot be printed"),
+			fmt.Sprintf(i18n.Text("The fonts used for printing cannot represent these characters, so they have been replaced with question marks:\n\n%s"),
+				string(missing)))
+	}
+}
+
+func (d *PDFDockable) setup() {
 	d.KeyDownCallback = d.keyDown
 	d.FocusChangeInHierarchyCallback = d.focusChangeInHierarchy
 	d.GainedFocusCallback = d.pdf.RequestRenderPriority
@@ -191,6 +240,13 @@ func NewPDFDockable(filePath string) (unison.Dockable, error) {
 		HAlign: unison.FillAlignment,
 		HGrab:  true,
 	})
+	if d.previewData != nil {
+		saveButton := unison.NewSVGButton(res.DownloadSVG)
+		saveButton.Tooltip = unison.NewTooltipWithText(i18n.Text("Save PDF…"))
+		saveButton.ClickCallback = d.savePreview
+		toolbar.AddChild(saveButton)
+		toolbar.AddChild(unison.NewPanel())
+	}
 	toolbar.AddChild(d.backButton)
 	toolbar.AddChild(d.forwardButton)
 	toolbar.AddChild(unison.NewPanel())
@@ -217,8 +273,19 @@ func NewPDFDockable(filePath string) (unison.Dockable, error) {
 
 	d.noUpdate = false
 	d.LoadPage(0)
+}
 
-	return d, nil
+func (d *PDFDockable) savePreview() {
+	dialog := unison.NewSaveDialog()
+	dialog.SetAllowedExtensions("pdf")
+	if dialog.RunModal() {
+		p := dialog.Path()
+		if err := os.WriteFile(p, d.previewData, 0o640); err != nil {
+			unison.ErrorDialogWithError(fmt.Sprintf(i18n.Text("Unable to save %s"), fs.BaseName(p)), err)
+			return
+		}
+		settings.Global().AddRecentFile(p)
+	}
 }
 
 // ClearHistory clears the existing history.
@@ -519,6 +586,9 @@ func (d *PDFDockable) Title() string {
 
 // Tooltip implements workspace.FileBackedDockable
 func (d *PDFDockable) Tooltip() string {
+	if d.previewData != nil {
+		return i18n.Text("Print Preview")
+	}
 	return d.path
 }
 
@@ -527,6 +597,11 @@ func (d *PDFDockable) BackingFilePath() string {
 	return d.path
 }
 
+// SetBackingFilePath implements workspace.RetargetableDockable
+func (d *PDFDockable) SetBackingFilePath(filePath string) {
+	d.path = filePath
+}
+
 // Modified implements workspace.FileBackedDockable
 func (d *PDFDockable) Modified() bool {
 	return false
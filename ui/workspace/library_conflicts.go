@@ -0,0 +1,375 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package workspace
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/google/uuid"
+	"github.com/richardwilkes/gcs/model/library"
+	"github.com/richardwilkes/gcs/model/settings"
+	"github.com/richardwilkes/gcs/res"
+	"github.com/richardwilkes/toolbox/i18n"
+	"github.com/richardwilkes/unison"
+)
+
+const (
+	conflictNameColumn = iota
+	conflictKindColumn
+	conflictLibraryColumn
+	conflictFileColumn
+	conflictIDColumn
+	conflictColumnCount
+)
+
+var (
+	_ unison.Dockable  = &LibraryConflictsDockable{}
+	_ unison.TabCloser = &LibraryConflictsDockable{}
+)
+
+// LibraryConflictsDockable shows the duplicate and conflicting items found across all libraries.
+type LibraryConflictsDockable struct {
+	unison.Panel
+	summaryLabel      *unison.Label
+	rescanButton      *unison.Button
+	regenerateButton  *unison.Button
+	scroll            *unison.ScrollPanel
+	table             *unison.Table[*libraryConflictRow]
+	report            *library.ConflictReport
+	scanInProgress    bool
+	scanAgainWhenDone bool
+}
+
+type libraryConflictRow struct {
+	id           uuid.UUID
+	conflict     *library.Conflict
+	item         *library.ConflictItem
+	libraryTitle string
+	relativePath string
+	parent       *libraryConflictRow
+	children     []*libraryConflictRow
+	open         bool
+}
+
+// ShowLibraryConflicts shows the Library Conflicts dockable, creating it if necessary.
+func ShowLibraryConflicts() {
+	var existing *LibraryConflictsDockable
+	ws, _, found := Activate(func(d unison.Dockable) bool {
+		var ok bool
+		existing, ok = d.(*LibraryConflictsDockable)
+		return ok
+	})
+	if found {
+		existing.scan()
+	} else if ws != nil {
+		d := newLibraryConflictsDockable()
+		DisplayNewDockable(ws.Window, d)
+		d.scan()
+	}
+}
+
+func newLibraryConflictsDockable() *LibraryConflictsDockable {
+	d := &LibraryConflictsDockable{
+		scroll: unison.NewScrollPanel(),
+		table:  unison.NewTable[*libraryConflictRow](&unison.SimpleTableModel[*libraryConflictRow]{}),
+	}
+	d.Self = d
+	d.SetLayout(&unison.FlexLayout{Columns: 1})
+
+	d.table.ColumnSizes = make([]unison.ColumnSize, conflictColumnCount)
+	d.table.DoubleClickCallback = d.openSelection
+	d.table.KeyDownCallback = func(keyCode unison.KeyCode, mod unison.Modifiers, repeat bool) bool {
+		if keyCode == unison.KeyReturn || keyCode == unison.KeyNumPadEnter {
+			d.openSelection()
+			return true
+		}
+		return d.table.DefaultKeyDown(keyCode, mod, repeat)
+	}
+	header := unison.NewTableHeader[*libraryConflictRow](d.table,
+		unison.NewTableColumnHeader[*libraryConflictRow](i18n.Text("Name"), ""),
+		unison.NewTableColumnHeader[*libraryConflictRow](i18n.Text("Conflict"), ""),
+		unison.NewTableColumnHeader[*libraryConflictRow](i18n.Text("Library"), ""),
+		unison.NewTableColumnHeader[*libraryConflictRow](i18n.Text("File"), ""),
+		unison.NewTableColumnHeader[*libraryConflictRow](i18n.Text("ID"), ""),
+	)
+	d.scroll.SetColumnHeader(header)
+	d.scroll.SetContent(d.table, unison.FillBehavior, unison.FillBehavior)
+	d.scroll.SetLayoutData(&unison.FlexLayoutData{
+		HAlign: unison.FillAlignment,
+		VAlign: unison.FillAlignment,
+		HGrab:  true,
+		VGrab:  true,
+	})
+
+	d.summaryLabel = unison.NewLabel()
+	d.summaryLabel.Text = "-"
+	d.summaryLabel.SetLayoutData(&unison.FlexLayoutData{
+		HAlign: unison.FillAlignment,
+		VAlign: unison.MiddleAlignment,
+		HGrab:  true,
+	})
+
+	d.rescanButton = unison.NewButton()
+	d.rescanButton.Text = i18n.Text("Rescan")
+	d.rescanButton.ClickCallback = d.scan
+
+	d.regenerateButton = unison.NewButton()
+	d.regenerateButton.Text = i18n.Text("Regenerate IDs")
+	d.regenerateButton.Tooltip = unison.NewTooltipWithText(i18n.Text(`Assign new IDs to items whose IDs collide with others.
+One item in each collision keeps its ID, preferring those in libraries that are updated from a release source.`))
+	d.regenerateButton.ClickCallback = d.regenerateIDs
+	d.regenerateButton.SetEnabled(false)
+
+	toolbar := unison.NewPanel()
+	toolbar.SetBorder(unison.NewCompoundBorder(unison.NewLineBorder(unison.DividerColor, 0, unison.Insets{Bottom: 1},
+		false), unison.NewEmptyBorder(unison.StdInsets())))
+	toolbar.SetLayoutData(&unison.FlexLayoutData{
+		HAlign: unison.FillAlignment,
+		HGrab:  true,
+	})
+	toolbar.AddChild(d.summaryLabel)
+	toolbar.AddChild(d.rescanButton)
+	toolbar.AddChild(d.regenerateButton)
+	toolbar.SetLayout(&unison.FlexLayout{
+		Columns:  len(toolbar.Children()),
+		HSpacing: unison.StdHSpacing,
+	})
+
+	d.AddChild(toolbar)
+	d.AddChild(d.scroll)
+	return d
+}
+
+// scan the libraries for conflicts in the background, updating the content once finished.
+func (d *LibraryConflictsDockable) scan() {
+	if d.scanInProgress {
+		d.scanAgainWhenDone = true
+		return
+	}
+	d.scanInProgress = true
+	d.rescanButton.SetEnabled(false)
+	d.regenerateButton.SetEnabled(false)
+	d.setSummary(i18n.Text("Scanning libraries…"))
+	libs := make(library.Libraries)
+	for k, v := range settings.Global().LibrarySet {
+		libs[k] = v
+	}
+	go func() {
+		report := library.FindConflicts(libs)
+		unison.InvokeTask(func() {
+			d.scanInProgress = false
+			d.rescanButton.SetEnabled(true)
+			if d.scanAgainWhenDone {
+				d.scanAgainWhenDone = false
+				d.scan()
+				return
+			}
+			d.setReport(report)
+		})
+	}()
+}
+
+func (d *LibraryConflictsDockable) setReport(report *library.ConflictReport) {
+	d.report = report
+	libs := settings.Global().LibrarySet
+	rows := make([]*libraryConflictRow, 0, len(report.Conflicts))
+	for _, c := range report.Conflicts {
+		row := &libraryConflictRow{
+			id:       uuid.New(),
+			conflict: c,
+		}
+		row.children = make([]*libraryConflictRow, 0, len(c.Items))
+		for _, item := range c.Items {
+			child := &libraryConflictRow{
+				id:           uuid.New(),
+				conflict:     c,
+				item:         item,
+				relativePath: item.RelativePath(libs),
+				parent:       row,
+			}
+			if lib, ok := libs[item.LibraryKey]; ok {
+				child.libraryTitle = lib.Title
+			}
+			row.children = append(row.children, child)
+		}
+		rows = append(rows, row)
+	}
+	d.table.SetRootRows(rows)
+	d.table.SizeColumnsToFit(true)
+	collisions := report.IDCollisions()
+	d.regenerateButton.SetEnabled(collisions != 0)
+	d.setSummary(fmt.Sprintf(i18n.Text("Scanned %d items in %d files; found %d conflicts, %d of which are ID collisions"),
+		report.ItemsScanned, report.FilesScanned, len(report.Conflicts), collisions))
+}
+
+func (d *LibraryConflictsDockable) setSummary(text string) {
+	d.summaryLabel.Text = text
+	d.summaryLabel.Parent().MarkForLayoutAndRedraw()
+}
+
+func (d *LibraryConflictsDockable) regenerateIDs() {
+	if d.report == nil || d.scanInProgress {
+		return
+	}
+	if unison.QuestionDialog(i18n.Text("Regenerate the IDs of colliding items?"),
+		i18n.Text(`The affected library files will be modified in place.
+Sheets that refer to the old IDs will no longer be able to locate those items within the libraries.`)) !=
+		unison.ModalResponseOK {
+		return
+	}
+	if _, err := library.RegenerateCollidingIDs(settings.Global().LibrarySet, d.report); err != nil {
+		unison.ErrorDialogWithError(i18n.Text("Unable to regenerate IDs"), err)
+	}
+	RefreshLibraries()
+	d.scan()
+}
+
+func (d *LibraryConflictsDockable) openSelection() {
+	for _, row := range d.table.SelectedRows(false) {
+		if row.item == nil {
+			continue
+		}
+		if dockable, _ := OpenFile(d.Window(), row.item.Path); dockable != nil {
+			if revealer, ok := dockable.(RowRevealer); ok {
+				revealer.RevealRow(row.item.ID)
+			}
+		}
+	}
+}
+
+// TitleIcon implements unison.Dockable
+func (d *LibraryConflictsDockable) TitleIcon(suggestedSize unison.Size) unison.Drawable {
+	return &unison.DrawableSVG{
+		SVG:  res.StackSVG,
+		Size: suggestedSize,
+	}
+}
+
+// Title implements unison.Dockable
+func (d *LibraryConflictsDockable) Title() string {
+	return i18n.Text("Library Conflicts")
+}
+
+// Tooltip implements unison.Dockable
+func (d *LibraryConflictsDockable) Tooltip() string {
+	return ""
+}
+
+// Modified implements unison.Dockable
+func (d *LibraryConflictsDockable) Modified() bool {
+	return false
+}
+
+// MayAttemptClose implements unison.TabCloser
+func (d *LibraryConflictsDockable) MayAttemptClose() bool {
+	return true
+}
+
+// AttemptClose implements unison.TabCloser
+func (d *LibraryConflictsDockable) AttemptClose() bool {
+	if dc := unison.Ancestor[*unison.DockContainer](d); dc != nil {
+		dc.Close(d)
+	}
+	return true
+}
+
+// CloneForTarget implements unison.TableRowData. Not permitted.
+func (r *libraryConflictRow) CloneForTarget(_ unison.Paneler, _ *libraryConflictRow) *libraryConflictRow {
+	return nil
+}
+
+// UUID implements unison.TableRowData.
+func (r *libraryConflictRow) UUID() uuid.UUID {
+	return r.id
+}
+
+// Parent implements unison.TableRowData.
+func (r *libraryConflictRow) Parent() *libraryConflictRow {
+	return r.parent
+}
+
+// SetParent implements unison.TableRowData.
+func (r *libraryConflictRow) SetParent(parent *libraryConflictRow) {
+	r.parent = parent
+}
+
+// CanHaveChildren implements unison.TableRowData.
+func (r *libraryConflictRow) CanHaveChildren() bool {
+	return r.item == nil
+}
+
+// Children implements unison.TableRowData.
+func (r *libraryConflictRow) Children() []*libraryConflictRow {
+	return r.children
+}
+
+// SetChildren implements unison.TableRowData.
+func (r *libraryConflictRow) SetChildren(children []*libraryConflictRow) {
+	r.children = children
+}
+
+// CellDataForSort implements unison.TableRowData.
+func (r *libraryConflictRow) CellDataForSort(col int) string {
+	switch col {
+	case conflictNameColumn:
+		if r.item != nil {
+			return r.item.Name
+		}
+		return r.conflict.Name
+	case conflictKindColumn:
+		if r.item != nil {
+			return r.item.Type
+		}
+		return r.conflict.Kind.String()
+	case conflictLibraryColumn:
+		return r.libraryTitle
+	case conflictFileColumn:
+		if r.item != nil {
+			return r.relativePath
+		}
+		return fmt.Sprintf(i18n.Text("%d items"), len(r.children))
+	case conflictIDColumn:
+		if r.item != nil {
+			return r.item.ID.String()
+		}
+		return ""
+	default:
+		return ""
+	}
+}
+
+// ColumnCell implements unison.TableRowData.
+func (r *libraryConflictRow) ColumnCell(_, col int, foreground, _ unison.Ink, _, _, _ bool) unison.Paneler {
+	if col == conflictNameColumn && r.item != nil {
+		cell := createNodeCell(filepath.Ext(r.item.Path), r.CellDataForSort(col), foreground)
+		cell.AsPanel().Tooltip = unison.NewTooltipWithText(r.item.Path)
+		return cell
+	}
+	label := unison.NewLabel()
+	label.LabelTheme.OnBackgroundInk = foreground
+	label.Text = r.CellDataForSort(col)
+	if col == conflictKindColumn && r.item == nil {
+		label.Tooltip = unison.NewTooltipWithText(r.conflict.Kind.Description())
+	}
+	return label
+}
+
+// IsOpen implements unison.TableRowData.
+func (r *libraryConflictRow) IsOpen() bool {
+	return r.open
+}
+
+// SetOpen implements unison.TableRowData.
+func (r *libraryConflictRow) SetOpen(open bool) {
+	r.open = open
+}
@@ -0,0 +1,122 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package workspace
+
+import (
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/richardwilkes/gcs/model/jio"
+	"github.com/richardwilkes/gcs/model/library"
+	"github.com/richardwilkes/gcs/model/settings"
+	"github.com/richardwilkes/toolbox/log/jot"
+	"github.com/richardwilkes/unison"
+)
+
+const libraryMonitorDelay = time.Second
+
+var libraryMonitor *library.Monitor
+
+// ReloadableDockable defines the method a FileBackedDockable should implement if it can refresh its content when its
+// backing file is altered outside of the application.
+type ReloadableDockable interface {
+	FileBackedDockable
+	ReloadIfChangedOnDisk()
+}
+
+// DiskStamp holds the information used to determine whether a file has been altered on disk.
+type DiskStamp struct {
+	ModTime time.Time
+	Size    int64
+}
+
+// NewDiskStamp creates a new DiskStamp for the file at the given path. If the file cannot be examined, a zero value will
+// be returned. For a directory holding data in the split format, the stamp reflects the most recent modification time
+// and total size of the files within it.
+func NewDiskStamp(filePath string) DiskStamp {
+	fi, err := os.Stat(filePath)
+	if err != nil {
+		return DiskStamp{}
+	}
+	if fi.IsDir() && jio.IsSplitPath(filePath) {
+		var entries []os.DirEntry
+		if entries, err = os.ReadDir(filePath); err != nil {
+			return DiskStamp{}
+		}
+		var stamp DiskStamp
+		for _, entry := range entries {
+			var info fs.FileInfo
+			if info, err = entry.Info(); err == nil && info.Mode().IsRegular() {
+				if info.ModTime().After(stamp.ModTime) {
+					stamp.ModTime = info.ModTime()
+				}
+				stamp.Size += info.Size()
+			}
+		}
+		return stamp
+	}
+	return DiskStamp{
+		ModTime: fi.ModTime(),
+		Size:    fi.Size(),
+	}
+}
+
+// IsZero returns true if this DiskStamp has no information.
+func (s DiskStamp) IsZero() bool {
+	return s.ModTime.IsZero() && s.Size == 0
+}
+
+// Equal returns true if the two DiskStamps are the same.
+func (s DiskStamp) Equal(other DiskStamp) bool {
+	return s.ModTime.Equal(other.ModTime) && s.Size == other.Size
+}
+
+// MonitorLibraries starts watching the configured libraries for changes on disk. This should be called again whenever
+// the library configuration is altered.
+func MonitorLibraries() {
+	if libraryMonitor == nil {
+		m, err := library.NewMonitor(libraryMonitorDelay, func(changed []*library.Library) {
+			unison.InvokeTask(func() { refreshLibraries(changed) })
+		})
+		if err != nil {
+			jot.Error(err)
+			return
+		}
+		libraryMonitor = m
+	}
+	libraryMonitor.Watch(settings.Global().LibrarySet)
+}
+
+// RefreshLibraries rescans all of the libraries, updating the navigator of each workspace, reloading any open documents
+// whose backing files were changed on disk and bringing the library search index up to date.
+func RefreshLibraries() {
+	library.ClearScanCache()
+	refreshLibraries(nil)
+}
+
+func refreshLibraries(changed []*library.Library) {
+	for _, wnd := range unison.Windows() {
+		if ws := FromWindow(wnd); ws != nil {
+			ws.Navigator.Refresh(changed...)
+			ws.DocumentDock.RootDockLayout().ForEachDockContainer(func(dc *unison.DockContainer) bool {
+				for _, one := range dc.Dockables() {
+					if rd, ok := one.(ReloadableDockable); ok {
+						rd.ReloadIfChangedOnDisk()
+					}
+				}
+				return false
+			})
+		}
+	}
+	UpdateLibraryIndex()
+}
@@ -0,0 +1,399 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package workspace
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/richardwilkes/gcs/model/library"
+	"github.com/richardwilkes/gcs/model/settings"
+	"github.com/richardwilkes/gcs/res"
+	"github.com/richardwilkes/toolbox/i18n"
+	"github.com/richardwilkes/unison"
+)
+
+const (
+	searchNameColumn = iota
+	searchTypeColumn
+	searchLibraryColumn
+	searchTagsColumn
+	searchReferenceColumn
+	searchColumnCount
+)
+
+var (
+	_ unison.Dockable  = &LibrarySearchDockable{}
+	_ unison.TabCloser = &LibrarySearchDockable{}
+)
+
+var (
+	libraryIndexUpdating      bool
+	libraryIndexUpdatePending bool
+)
+
+// LibrarySearchDockable provides full-text searching of the contents of all libraries.
+type LibrarySearchDockable struct {
+	unison.Panel
+	searchField  *unison.Field
+	typePopup    *unison.PopupMenu[searchType]
+	tagPopup     *unison.PopupMenu[string]
+	matchesLabel *unison.Label
+	scroll       *unison.ScrollPanel
+	table        *unison.Table[*librarySearchRow]
+}
+
+type searchType struct {
+	extension string
+	title     string
+}
+
+func (t searchType) String() string {
+	return t.title
+}
+
+type librarySearchRow struct {
+	id           uuid.UUID
+	entry        *library.IndexEntry
+	libraryTitle string
+	typeTitle    string
+}
+
+// ShowLibrarySearch shows the Library Search dockable, creating it if necessary.
+func ShowLibrarySearch() {
+	ws, _, found := Activate(func(d unison.Dockable) bool {
+		_, ok := d.(*LibrarySearchDockable)
+		return ok
+	})
+	if !found && ws != nil {
+		d := newLibrarySearchDockable()
+		DisplayNewDockable(ws.Window, d)
+		d.searchField.RequestFocus()
+	}
+}
+
+// UpdateLibraryIndex brings the library search index up to date in the background, refreshing any open Library Search
+// dockables once it has finished. Must be called on the UI thread.
+func UpdateLibraryIndex() {
+	if libraryIndexUpdating {
+		libraryIndexUpdatePending = true
+		return
+	}
+	libraryIndexUpdating = true
+	libs := make(library.Libraries)
+	for k, v := range settings.Global().LibrarySet {
+		libs[k] = v
+	}
+	go func() {
+		changed := library.GlobalIndex().Update(libs)
+		unison.InvokeTask(func() {
+			libraryIndexUpdating = false
+			if changed {
+				refreshLibrarySearches()
+			}
+			if libraryIndexUpdatePending {
+				libraryIndexUpdatePending = false
+				UpdateLibraryIndex()
+			}
+		})
+	}()
+}
+
+func refreshLibrarySearches() {
+	for _, wnd := range unison.Windows() {
+		if ws := FromWindow(wnd); ws != nil {
+			ws.DocumentDock.RootDockLayout().ForEachDockContainer(func(dc *unison.DockContainer) bool {
+				for _, one := range dc.Dockables() {
+					if d, ok := one.(*LibrarySearchDockable); ok {
+						d.updateTags()
+						d.search()
+					}
+				}
+				return false
+			})
+		}
+	}
+}
+
+func searchTypes() []searchType {
+	return []searchType{
+		{title: i18n.Text("All Types")},
+		{extension: library.TraitsExt, title: i18n.Text("Traits")},
+		{extension: library.TraitModifiersExt, title: i18n.Text("Trait Modifiers")},
+		{extension: library.SkillsExt, title: i18n.Text("Skills")},
+		{extension: library.SpellsExt, title: i18n.Text("Spells")},
+		{extension: library.EquipmentExt, title: i18n.Text("Equipment")},
+		{extension: library.EquipmentModifiersExt, title: i18n.Text("Equipment Modifiers")},
+		{extension: library.NotesExt, title: i18n.Text("Notes")},
+	}
+}
+
+func newLibrarySearchDockable() *LibrarySearchDockable {
+	d := &LibrarySearchDockable{
+		scroll: unison.NewScrollPanel(),
+		table:  unison.NewTable[*librarySearchRow](&unison.SimpleTableModel[*librarySearchRow]{}),
+	}
+	d.Self = d
+	d.SetLayout(&unison.FlexLayout{Columns: 1})
+
+	d.table.ColumnSizes = make([]unison.ColumnSize, searchColumnCount)
+	d.table.DoubleClickCallback = d.openSelection
+	d.table.KeyDownCallback = func(keyCode unison.KeyCode, mod unison.Modifiers, repeat bool) bool {
+		if keyCode == unison.KeyReturn || keyCode == unison.KeyNumPadEnter {
+			d.openSelection()
+			return true
+		}
+		return d.table.DefaultKeyDown(keyCode, mod, repeat)
+	}
+	header := unison.NewTableHeader[*librarySearchRow](d.table,
+		unison.NewTableColumnHeader[*librarySearchRow](i18n.Text("Name"), ""),
+		unison.NewTableColumnHeader[*librarySearchRow](i18n.Text("Type"), ""),
+		unison.NewTableColumnHeader[*librarySearchRow](i18n.Text("Library"), ""),
+		unison.NewTableColumnHeader[*librarySearchRow](i18n.Text("Tags"), ""),
+		unison.NewTableColumnHeader[*librarySearchRow](i18n.Text("Ref"), i18n.Text("Page Reference")),
+	)
+	d.scroll.SetColumnHeader(header)
+	d.scroll.SetContent(d.table, unison.FillBehavior, unison.FillBehavior)
+	d.scroll.SetLayoutData(&unison.FlexLayoutData{
+		HAlign: unison.FillAlignment,
+		VAlign: unison.FillAlignment,
+		HGrab:  true,
+		VGrab:  true,
+	})
+
+	d.searchField = unison.NewField()
+	search := i18n.Text("Search")
+	d.searchField.Watermark = search
+	d.searchField.Tooltip = unison.NewTooltipWithText(i18n.Text("Each word must appear somewhere within the item"))
+	d.searchField.ModifiedCallback = d.search
+	d.searchField.SetLayoutData(&unison.FlexLayoutData{
+		HAlign: unison.FillAlignment,
+		VAlign: unison.MiddleAlignment,
+		HGrab:  true,
+	})
+
+	d.typePopup = unison.NewPopupMenu[searchType]()
+	for _, one := range searchTypes() {
+		d.typePopup.AddItem(one)
+	}
+	d.typePopup.SelectIndex(0)
+	d.typePopup.SelectionCallback = func(_ int, _ searchType) { d.search() }
+
+	d.tagPopup = unison.NewPopupMenu[string]()
+	d.tagPopup.SelectionCallback = func(_ int, _ string) { d.search() }
+	d.updateTags()
+
+	d.matchesLabel = unison.NewLabel()
+	d.matchesLabel.Text = "-"
+	d.matchesLabel.Tooltip = unison.NewTooltipWithText(i18n.Text("Number of matches found"))
+
+	toolbar := unison.NewPanel()
+	toolbar.SetBorder(unison.NewCompoundBorder(unison.NewLineBorder(unison.DividerColor, 0, unison.Insets{Bottom: 1},
+		false), unison.NewEmptyBorder(unison.StdInsets())))
+	toolbar.SetLayoutData(&unison.FlexLayoutData{
+		HAlign: unison.FillAlignment,
+		HGrab:  true,
+	})
+	toolbar.AddChild(d.searchField)
+	toolbar.AddChild(d.typePopup)
+	toolbar.AddChild(d.tagPopup)
+	toolbar.AddChild(d.matchesLabel)
+	toolbar.SetLayout(&unison.FlexLayout{
+		Columns:  len(toolbar.Children()),
+		HSpacing: unison.StdHSpacing,
+	})
+
+	d.AddChild(toolbar)
+	d.AddChild(d.scroll)
+	d.search()
+	return d
+}
+
+func (d *LibrarySearchDockable) updateTags() {
+	current, _ := d.tagPopup.Selected()
+	if d.tagPopup.SelectedIndex() <= 0 {
+		current = ""
+	}
+	d.tagPopup.RemoveAllItems()
+	d.tagPopup.AddItem(i18n.Text("All Tags"))
+	for _, tag := range library.GlobalIndex().Tags() {
+		d.tagPopup.AddItem(tag)
+	}
+	if current != "" && d.tagPopup.IndexOfItem(current) != -1 {
+		d.tagPopup.Select(current)
+	} else {
+		d.tagPopup.SelectIndex(0)
+	}
+}
+
+func (d *LibrarySearchDockable) search() {
+	query := library.IndexQuery{Text: d.searchField.Text()}
+	types := searchTypes()
+	if t, ok := d.typePopup.Selected(); ok && t.extension != "" {
+		query.Extensions = []string{t.extension}
+	}
+	if d.tagPopup.SelectedIndex() > 0 {
+		query.Tag, _ = d.tagPopup.Selected()
+	}
+	libs := settings.Global().LibrarySet
+	entries := library.GlobalIndex().Search(query)
+	rows := make([]*librarySearchRow, 0, len(entries))
+	for _, entry := range entries {
+		row := &librarySearchRow{
+			id:    uuid.New(),
+			entry: entry,
+		}
+		if lib, ok := libs[entry.LibraryKey]; ok {
+			row.libraryTitle = lib.Title
+		}
+		ext := entry.Extension()
+		for _, t := range types {
+			if t.extension == ext {
+				row.typeTitle = t.title
+				break
+			}
+		}
+		rows = append(rows, row)
+	}
+	d.table.SetRootRows(rows)
+	d.table.SizeColumnsToFit(true)
+	if len(rows) == 0 {
+		d.matchesLabel.Text = "-"
+	} else {
+		d.matchesLabel.Text = fmt.Sprintf(i18n.Text("%d found"), len(rows))
+	}
+	d.matchesLabel.Parent().MarkForLayoutAndRedraw()
+}
+
+func (d *LibrarySearchDockable) openSelection() {
+	for _, row := range d.table.SelectedRows(false) {
+		if dockable, _ := OpenFile(d.Window(), row.entry.Path); dockable != nil {
+			if revealer, ok := dockable.(RowRevealer); ok {
+				revealer.RevealRow(row.entry.ID)
+			}
+		}
+	}
+}
+
+// TitleIcon implements unison.Dockable
+func (d *LibrarySearchDockable) TitleIcon(suggestedSize unison.Size) unison.Drawable {
+	return &unison.DrawableSVG{
+		SVG:  res.SearchSVG,
+		Size: suggestedSize,
+	}
+}
+
+// Title implements unison.Dockable
+func (d *LibrarySearchDockable) Title() string {
+	return i18n.Text("Library Search")
+}
+
+// Tooltip implements unison.Dockable
+func (d *LibrarySearchDockable) Tooltip() string {
+	return ""
+}
+
+// Modified implements unison.Dockable
+func (d *LibrarySearchDockable) Modified() bool {
+	return false
+}
+
+// MayAttemptClose implements unison.TabCloser
+func (d *LibrarySearchDockable) MayAttemptClose() bool {
+	return true
+}
+
+// AttemptClose implements unison.TabCloser
+func (d *LibrarySearchDockable) AttemptClose() bool {
+	if dc := unison.Ancestor[*unison.DockContainer](d); dc != nil {
+		dc.Close(d)
+	}
+	return true
+}
+
+// CloneForTarget implements unison.TableRowData. Not permitted.
+func (r *librarySearchRow) CloneForTarget(_ unison.Paneler, _ *librarySearchRow) *librarySearchRow {
+	return nil
+}
+
+// UUID implements unison.TableRowData.
+func (r *librarySearchRow) UUID() uuid.UUID {
+	return r.id
+}
+
+// Parent implements unison.TableRowData.
+func (r *librarySearchRow) Parent() *librarySearchRow {
+	return nil
+}
+
+// SetParent implements unison.TableRowData.
+func (r *librarySearchRow) SetParent(_ *librarySearchRow) {
+}
+
+// CanHaveChildren implements unison.TableRowData.
+func (r *librarySearchRow) CanHaveChildren() bool {
+	return false
+}
+
+// Children implements unison.TableRowData.
+func (r *librarySearchRow) Children() []*librarySearchRow {
+	return nil
+}
+
+// SetChildren implements unison.TableRowData.
+func (r *librarySearchRow) SetChildren(_ []*librarySearchRow) {
+}
+
+// CellDataForSort implements unison.TableRowData.
+func (r *librarySearchRow) CellDataForSort(col int) string {
+	switch col {
+	case searchNameColumn:
+		if r.entry.Name != "" {
+			return r.entry.Name
+		}
+		return strings.TrimSuffix(filepath.Base(r.entry.Path), filepath.Ext(r.entry.Path))
+	case searchTypeColumn:
+		return r.typeTitle
+	case searchLibraryColumn:
+		return r.libraryTitle
+	case searchTagsColumn:
+		return strings.Join(r.entry.Tags, ", ")
+	case searchReferenceColumn:
+		return r.entry.PageRef
+	default:
+		return ""
+	}
+}
+
+// ColumnCell implements unison.TableRowData.
+func (r *librarySearchRow) ColumnCell(_, col int, foreground, _ unison.Ink, _, _, _ bool) unison.Paneler {
+	if col == searchNameColumn {
+		cell := createNodeCell(r.entry.Extension(), r.CellDataForSort(col), foreground)
+		cell.AsPanel().Tooltip = unison.NewTooltipWithText(r.entry.Path)
+		return cell
+	}
+	label := unison.NewLabel()
+	label.LabelTheme.OnBackgroundInk = foreground
+	label.Text = r.CellDataForSort(col)
+	return label
+}
+
+// IsOpen implements unison.TableRowData.
+func (r *librarySearchRow) IsOpen() bool {
+	return false
+}
+
+// SetOpen implements unison.TableRowData.
+func (r *librarySearchRow) SetOpen(_ bool) {
+}
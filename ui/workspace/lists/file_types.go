@@ -12,42 +12,65 @@
 package lists
 
 import (
+	"github.com/richardwilkes/gcs/model/campaign"
+	"github.com/richardwilkes/gcs/model/gurps"
+	"github.com/richardwilkes/gcs/model/gurps/datafile"
 	"github.com/richardwilkes/gcs/model/library"
 	"github.com/richardwilkes/gcs/res"
 	"github.com/richardwilkes/gcs/ui/workspace/sheet"
+	"github.com/richardwilkes/toolbox/i18n"
 	"github.com/richardwilkes/unison"
 )
 
 // RegisterFileTypes registers GCS file types.
 func RegisterFileTypes() {
-	registerExportableGCSFileInfo(library.SheetExt, res.GCSSheetSVG, sheet.NewSheetFromFile)
-	registerGCSFileInfo(library.TemplatesExt, []string{library.TemplatesExt}, res.GCSTemplateSVG, sheet.NewTemplateFromFile)
+	registerExportableGCSFileInfo(library.SheetExt, i18n.Text("Character Sheet"), res.GCSSheetSVG, sheet.NewSheetFromFile,
+		func(filePath string) error { return gurps.NewEntity(datafile.PC).Save(filePath) })
+	registerGCSFileInfo(library.TemplatesExt, i18n.Text("Character Template"), []string{library.TemplatesExt},
+		res.GCSTemplateSVG, sheet.NewTemplateFromFile, func(filePath string) error { return gurps.NewTemplate().Save(filePath) })
+	registerGCSFileInfo(library.EncounterExt, i18n.Text("Encounter"), []string{library.EncounterExt},
+		res.MeleeWeaponSVG, sheet.NewEncounterFromFile, func(filePath string) error { return gurps.NewEncounter().Save(filePath) })
+	registerGCSFileInfo(library.CampaignExt, i18n.Text("Campaign"), []string{library.CampaignExt},
+		res.StackSVG, sheet.NewCampaignFromFile, func(filePath string) error { return campaign.NewCampaign().Save(filePath) })
 	groupWith := []string{library.TraitsExt, library.TraitModifiersExt, library.EquipmentExt, library.EquipmentModifiersExt, library.SkillsExt, library.SpellsExt, library.NotesExt}
-	registerGCSFileInfo(library.TraitsExt, groupWith, res.GCSTraitsSVG, NewTraitTableDockableFromFile)
-	registerGCSFileInfo(library.TraitModifiersExt, groupWith, res.GCSTraitModifiersSVG, NewTraitModifierTableDockableFromFile)
-	registerGCSFileInfo(library.EquipmentExt, groupWith, res.GCSEquipmentSVG, NewEquipmentTableDockableFromFile)
-	registerGCSFileInfo(library.EquipmentModifiersExt, groupWith, res.GCSEquipmentModifiersSVG, NewEquipmentModifierTableDockableFromFile)
-	registerGCSFileInfo(library.SkillsExt, groupWith, res.GCSSkillsSVG, NewSkillTableDockableFromFile)
-	registerGCSFileInfo(library.SpellsExt, groupWith, res.GCSSpellsSVG, NewSpellTableDockableFromFile)
-	registerGCSFileInfo(library.NotesExt, groupWith, res.GCSNotesSVG, NewNoteTableDockableFromFile)
+	registerGCSFileInfo(library.TraitsExt, i18n.Text("Traits Library"), groupWith, res.GCSTraitsSVG,
+		NewTraitTableDockableFromFile, func(filePath string) error { return gurps.SaveTraits(nil, filePath) })
+	registerGCSFileInfo(library.TraitModifiersExt, i18n.Text("Trait Modifiers Library"), groupWith,
+		res.GCSTraitModifiersSVG, NewTraitModifierTableDockableFromFile,
+		func(filePath string) error { return gurps.SaveTraitModifiers(nil, filePath) })
+	registerGCSFileInfo(library.EquipmentExt, i18n.Text("Equipment Library"), groupWith, res.GCSEquipmentSVG,
+		NewEquipmentTableDockableFromFile, func(filePath string) error { return gurps.SaveEquipment(nil, filePath) })
+	registerGCSFileInfo(library.EquipmentModifiersExt, i18n.Text("Equipment Modifiers Library"), groupWith,
+		res.GCSEquipmentModifiersSVG, NewEquipmentModifierTableDockableFromFile,
+		func(filePath string) error { return gurps.SaveEquipmentModifiers(nil, filePath) })
+	registerGCSFileInfo(library.SkillsExt, i18n.Text("Skills Library"), groupWith, res.GCSSkillsSVG,
+		NewSkillTableDockableFromFile, func(filePath string) error { return gurps.SaveSkills(nil, filePath) })
+	registerGCSFileInfo(library.SpellsExt, i18n.Text("Spells Library"), groupWith, res.GCSSpellsSVG,
+		NewSpellTableDockableFromFile, func(filePath string) error { return gurps.SaveSpells(nil, filePath) })
+	registerGCSFileInfo(library.NotesExt, i18n.Text("Notes Library"), groupWith, res.GCSNotesSVG,
+		NewNoteTableDockableFromFile, func(filePath string) error { return gurps.SaveNotes(nil, filePath) })
 }
 
-func registerGCSFileInfo(ext string, groupWith []string, svg *unison.SVG, loader func(filePath string) (unison.Dockable, error)) {
+func registerGCSFileInfo(ext, name string, groupWith []string, svg *unison.SVG, loader func(filePath string) (unison.Dockable, error), creator func(filePath string) error) {
 	library.FileInfo{
 		Extension:             ext,
+		Name:                  name,
 		ExtensionsToGroupWith: groupWith,
 		SVG:                   svg,
 		Load:                  loader,
+		CreateEmpty:           creator,
 		IsGCSData:             true,
 	}.Register()
 }
 
-func registerExportableGCSFileInfo(ext string, svg *unison.SVG, loader func(filePath string) (unison.Dockable, error)) {
+func registerExportableGCSFileInfo(ext, name string, svg *unison.SVG, loader func(filePath string) (unison.Dockable, error), creator func(filePath string) error) {
 	library.FileInfo{
 		Extension:             ext,
+		Name:                  name,
 		ExtensionsToGroupWith: []string{ext},
 		SVG:                   svg,
 		Load:                  loader,
+		CreateEmpty:           creator,
 		IsGCSData:             true,
 		IsExportable:          true,
 	}.Register()
@@ -19,18 +19,22 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/google/uuid"
 	"github.com/richardwilkes/gcs/constants"
 	"github.com/richardwilkes/gcs/model/crc"
+	"github.com/richardwilkes/gcs/model/filter"
 	"github.com/richardwilkes/gcs/model/gurps"
 	gsettings "github.com/richardwilkes/gcs/model/gurps/settings"
 	"github.com/richardwilkes/gcs/model/jio"
 	"github.com/richardwilkes/gcs/model/library"
+	"github.com/richardwilkes/gcs/model/printing"
 	"github.com/richardwilkes/gcs/model/settings"
 	"github.com/richardwilkes/gcs/res"
 	"github.com/richardwilkes/gcs/ui/widget"
 	"github.com/richardwilkes/gcs/ui/widget/ntable"
 	"github.com/richardwilkes/gcs/ui/workspace"
 	"github.com/richardwilkes/gcs/ui/workspace/editors"
+	"github.com/richardwilkes/gcs/ui/workspace/external"
 	"github.com/richardwilkes/toolbox/i18n"
 	"github.com/richardwilkes/toolbox/log/jot"
 	"github.com/richardwilkes/toolbox/xio/fs"
@@ -38,12 +42,16 @@ import (
 )
 
 var (
-	_ workspace.FileBackedDockable = &TableDockable[*gurps.Trait]{}
-	_ unison.UndoManagerProvider   = &TableDockable[*gurps.Trait]{}
-	_ widget.ModifiableRoot        = &TableDockable[*gurps.Trait]{}
-	_ widget.Rebuildable           = &TableDockable[*gurps.Trait]{}
-	_ widget.DockableKind          = &TableDockable[*gurps.Trait]{}
-	_ unison.TabCloser             = &TableDockable[*gurps.Trait]{}
+	_ workspace.FileBackedDockable   = &TableDockable[*gurps.Trait]{}
+	_ workspace.RetargetableDockable = &TableDockable[*gurps.Trait]{}
+	_ workspace.ReloadableDockable   = &TableDockable[*gurps.Trait]{}
+	_ workspace.Recoverable          = &TableDockable[*gurps.Trait]{}
+	_ workspace.RowRevealer          = &TableDockable[*gurps.Trait]{}
+	_ unison.UndoManagerProvider     = &TableDockable[*gurps.Trait]{}
+	_ widget.ModifiableRoot          = &TableDockable[*gurps.Trait]{}
+	_ widget.Rebuildable             = &TableDockable[*gurps.Trait]{}
+	_ widget.DockableKind            = &TableDockable[*gurps.Trait]{}
+	_ unison.TabCloser               = &TableDockable[*gurps.Trait]{}
 )
 
 // TableDockable holds the view for a file that contains a (potentially hierarchical) list of data.
@@ -54,6 +62,7 @@ type TableDockable[T gurps.NodeConstraint[T]] struct {
 	undoMgr           *unison.UndoManager
 	provider          ntable.TableProvider[T]
 	saver             func(path string) error
+	loader            func(path string) ([]T, error)
 	canCreateIDs      map[int]bool
 	hierarchyButton   *unison.Button
 	sizeToFitButton   *unison.Button
@@ -67,8 +76,14 @@ type TableDockable[T gurps.NodeConstraint[T]] struct {
 	tableHeader       *unison.TableHeader[*ntable.Node[T]]
 	table             *unison.Table[*ntable.Node[T]]
 	crc               uint64
+	diskStamp         workspace.DiskStamp
 	searchResult      []*ntable.Node[T]
 	searchIndex       int
+	filterField       *unison.Field
+	filterCountLabel  *unison.Label
+	filter            *filter.Filter
+	filterErr         error
+	filtered          *ntable.FilteredProvider[T]
 	needsSaveAsPrompt bool
 }
 
@@ -104,6 +119,9 @@ func NewTraitTableDockable(filePath string, traits []*gurps.Trait) *TableDockabl
 	provider := &traitListProvider{traits: traits}
 	return NewTableDockable(filePath, library.TraitsExt, editors.NewTraitsProvider(provider, false),
 		func(path string) error { return gurps.SaveTraits(provider.TraitList(), path) },
+		func(path string) ([]*gurps.Trait, error) {
+			return gurps.NewTraitsFromFile(os.DirFS(filepath.Dir(path)), filepath.Base(path))
+		},
 		constants.NewTraitItemID, constants.NewTraitContainerItemID)
 }
 
@@ -141,6 +159,9 @@ func NewTraitModifierTableDockable(filePath string, modifiers []*gurps.TraitModi
 	return NewTableDockable(filePath, library.TraitModifiersExt,
 		editors.NewTraitModifiersProvider(provider, false),
 		func(path string) error { return gurps.SaveTraitModifiers(provider.TraitModifierList(), path) },
+		func(path string) ([]*gurps.TraitModifier, error) {
+			return gurps.NewTraitModifiersFromFile(os.DirFS(filepath.Dir(path)), filepath.Base(path))
+		},
 		constants.NewTraitModifierItemID, constants.NewTraitContainerModifierItemID)
 }
 
@@ -185,6 +206,9 @@ func NewEquipmentTableDockable(filePath string, equipment []*gurps.Equipment) *T
 	provider := &equipmentListProvider{other: equipment}
 	return NewTableDockable(filePath, library.EquipmentExt, editors.NewEquipmentProvider(provider, false, false),
 		func(path string) error { return gurps.SaveEquipment(provider.OtherEquipmentList(), path) },
+		func(path string) ([]*gurps.Equipment, error) {
+			return gurps.NewEquipmentFromFile(os.DirFS(filepath.Dir(path)), filepath.Base(path))
+		},
 		constants.NewCarriedEquipmentItemID, constants.NewCarriedEquipmentContainerItemID)
 }
 
@@ -222,6 +246,9 @@ func NewEquipmentModifierTableDockable(filePath string, modifiers []*gurps.Equip
 	return NewTableDockable(filePath, library.EquipmentModifiersExt,
 		editors.NewEquipmentModifiersProvider(provider, false),
 		func(path string) error { return gurps.SaveEquipmentModifiers(provider.EquipmentModifierList(), path) },
+		func(path string) ([]*gurps.EquipmentModifier, error) {
+			return gurps.NewEquipmentModifiersFromFile(os.DirFS(filepath.Dir(path)), filepath.Base(path))
+		},
 		constants.NewEquipmentModifierItemID, constants.NewEquipmentContainerModifierItemID)
 }
 
@@ -257,6 +284,9 @@ func NewSkillTableDockable(filePath string, skills []*gurps.Skill) *TableDockabl
 	provider := &skillListProvider{skills: skills}
 	return NewTableDockable(filePath, library.SkillsExt, editors.NewSkillsProvider(provider, false),
 		func(path string) error { return gurps.SaveSkills(provider.SkillList(), path) },
+		func(path string) ([]*gurps.Skill, error) {
+			return gurps.NewSkillsFromFile(os.DirFS(filepath.Dir(path)), filepath.Base(path))
+		},
 		constants.NewSkillItemID, constants.NewSkillContainerItemID, constants.NewTechniqueItemID)
 }
 
@@ -292,6 +322,9 @@ func NewSpellTableDockable(filePath string, spells []*gurps.Spell) *TableDockabl
 	provider := &spellListProvider{spells: spells}
 	return NewTableDockable(filePath, library.SpellsExt, editors.NewSpellsProvider(provider, false),
 		func(path string) error { return gurps.SaveSpells(provider.SpellList(), path) },
+		func(path string) ([]*gurps.Spell, error) {
+			return gurps.NewSpellsFromFile(os.DirFS(filepath.Dir(path)), filepath.Base(path))
+		},
 		constants.NewSpellItemID, constants.NewSpellContainerItemID, constants.NewRitualMagicSpellItemID)
 }
 
@@ -327,11 +360,14 @@ func NewNoteTableDockable(filePath string, notes []*gurps.Note) *TableDockable[*
 	provider := &noteListProvider{notes: notes}
 	return NewTableDockable(filePath, library.NotesExt, editors.NewNotesProvider(provider, false),
 		func(path string) error { return gurps.SaveNotes(provider.NoteList(), path) },
+		func(path string) ([]*gurps.Note, error) {
+			return gurps.NewNotesFromFile(os.DirFS(filepath.Dir(path)), filepath.Base(path))
+		},
 		constants.NewNoteItemID, constants.NewNoteContainerItemID)
 }
 
 // NewTableDockable creates a new TableDockable for list data files.
-func NewTableDockable[T gurps.NodeConstraint[T]](filePath, extension string, provider ntable.TableProvider[T], saver func(path string) error, canCreateIDs ...int) *TableDockable[T] {
+func NewTableDockable[T gurps.NodeConstraint[T]](filePath, extension string, provider ntable.TableProvider[T], saver func(path string) error, loader func(path string) ([]T, error), canCreateIDs ...int) *TableDockable[T] {
 	header, table := ntable.NewNodeTable[T](provider, nil)
 	d := &TableDockable[T]{
 		path:              filePath,
@@ -339,6 +375,7 @@ func NewTableDockable[T gurps.NodeConstraint[T]](filePath, extension string, pro
 		undoMgr:           unison.NewUndoManager(200, func(err error) { jot.Error(err) }),
 		provider:          provider,
 		saver:             saver,
+		loader:            loader,
 		canCreateIDs:      make(map[int]bool),
 		scroll:            unison.NewScrollPanel(),
 		tableHeader:       header,
@@ -355,7 +392,9 @@ func NewTableDockable[T gurps.NodeConstraint[T]](filePath, extension string, pro
 
 	d.table.SyncToModel()
 	d.table.SizeColumnsToFit(true)
+	ntable.ApplyColumnWidths(d.table, d.provider)
 	ntable.InstallTableDropSupport(d.table, d.provider)
+	ntable.InstallColumnCustomization(d.tableHeader, d.table, d.provider, nil)
 
 	d.scroll.SetColumnHeader(d.tableHeader)
 	d.scroll.SetContent(d.table, unison.FillBehavior, unison.FillBehavior)
@@ -437,6 +476,9 @@ func NewTableDockable[T gurps.NodeConstraint[T]](filePath, extension string, pro
 	})
 
 	d.AddChild(toolbar)
+	if len(filter.FieldsFor(extension)) != 0 {
+		d.AddChild(d.createFilterBar())
+	}
 	d.AddChild(d.scroll)
 
 	d.applyScale()
@@ -444,6 +486,9 @@ func NewTableDockable[T gurps.NodeConstraint[T]](filePath, extension string, pro
 	d.InstallCmdHandlers(constants.OpenEditorItemID,
 		func(_ any) bool { return d.table.HasSelection() },
 		func(_ any) { d.provider.OpenEditor(d, d.table) })
+	d.InstallCmdHandlers(constants.BulkEditItemID,
+		func(_ any) bool { return d.table.HasSelection() },
+		func(_ any) { editors.BulkEdit(d.table, d.provider) })
 	d.InstallCmdHandlers(constants.OpenOnePageReferenceItemID,
 		func(_ any) bool { return editors.CanOpenPageRef(d.table) },
 		func(_ any) { editors.OpenPageRef(d.table) })
@@ -454,6 +499,9 @@ func NewTableDockable[T gurps.NodeConstraint[T]](filePath, extension string, pro
 		func(_ any) bool { return d.Modified() },
 		func(_ any) { d.save(false) })
 	d.InstallCmdHandlers(constants.SaveAsItemID, unison.AlwaysEnabled, func(_ any) { d.save(true) })
+	d.InstallCmdHandlers(constants.PrintItemID, unison.AlwaysEnabled, func(_ any) {
+		external.ShowPrintPreview(d.Window(), d.Title(), printing.List(d.Title(), d.provider.RootData()))
+	})
 	d.InstallCmdHandlers(unison.DeleteItemID,
 		func(_ any) bool { return d.table.HasSelection() },
 		func(_ any) { ntable.DeleteSelection(d.table) })
@@ -477,6 +525,7 @@ func NewTableDockable[T gurps.NodeConstraint[T]](filePath, extension string, pro
 	}
 
 	d.crc = d.crc64()
+	d.diskStamp = workspace.NewDiskStamp(filePath)
 	return d
 }
 
@@ -524,11 +573,36 @@ func (d *TableDockable[T]) BackingFilePath() string {
 	return d.path
 }
 
+// SetBackingFilePath implements workspace.RetargetableDockable
+func (d *TableDockable[T]) SetBackingFilePath(filePath string) {
+	d.path = filePath
+	d.diskStamp = workspace.NewDiskStamp(filePath)
+}
+
 // Modified implements workspace.FileBackedDockable
 func (d *TableDockable[T]) Modified() bool {
 	return d.crc != d.crc64()
 }
 
+// SaveSnapshot implements workspace.Recoverable
+func (d *TableDockable[T]) SaveSnapshot(filePath string) error {
+	return d.saver(filePath)
+}
+
+// NeedsSaveAs implements workspace.Recoverable
+func (d *TableDockable[T]) NeedsSaveAs() bool {
+	return d.needsSaveAsPrompt
+}
+
+// MarkRecovered implements workspace.Recoverable
+func (d *TableDockable[T]) MarkRecovered(originalPath string, needsSaveAs bool) {
+	d.path = originalPath
+	d.needsSaveAsPrompt = needsSaveAs
+	d.crc = 0
+	d.diskStamp = workspace.NewDiskStamp(originalPath)
+	d.MarkModified()
+}
+
 // MarkModified implements widget.ModifiableRoot.
 func (d *TableDockable[T]) MarkModified() {
 	if dc := unison.Ancestor[*unison.DockContainer](d); dc != nil {
@@ -569,9 +643,13 @@ func (d *TableDockable[T]) save(forceSaveAs bool) bool {
 		success = workspace.SaveDockableAs(d, d.extension, d.saver, func(path string) {
 			d.crc = d.crc64()
 			d.path = path
+			d.diskStamp = workspace.NewDiskStamp(path)
 		})
 	} else {
-		success = workspace.SaveDockable(d, d.saver, func() { d.crc = d.crc64() })
+		success = workspace.SaveDockable(d, d.saver, func() {
+			d.crc = d.crc64()
+			d.diskStamp = workspace.NewDiskStamp(d.path)
+		})
 	}
 	if success {
 		d.needsSaveAsPrompt = false
@@ -579,6 +657,37 @@ func (d *TableDockable[T]) save(forceSaveAs bool) bool {
 	return success
 }
 
+// ReloadIfChangedOnDisk implements workspace.ReloadableDockable
+func (d *TableDockable[T]) ReloadIfChangedOnDisk() {
+	if d.needsSaveAsPrompt || d.loader == nil {
+		return
+	}
+	stamp := workspace.NewDiskStamp(d.path)
+	if stamp.IsZero() || stamp.Equal(d.diskStamp) {
+		return
+	}
+	d.diskStamp = stamp
+	if d.Modified() && unison.QuestionDialog(fmt.Sprintf(i18n.Text("%s has been changed on disk."), d.Title()),
+		i18n.Text("Discard your unsaved changes and reload it?")) != unison.ModalResponseOK {
+		return
+	}
+	if !workspace.CloseGroup(d) {
+		return
+	}
+	data, err := d.loader(d.path)
+	if err != nil {
+		unison.ErrorDialogWithError(fmt.Sprintf(i18n.Text("Unable to reload %s"), d.Title()), err)
+		return
+	}
+	d.provider.SetRootData(data)
+	d.undoMgr.Clear()
+	d.crc = d.crc64()
+	d.Rebuild(false)
+	if d.searchField.Text() != "" {
+		d.searchModified()
+	}
+}
+
 func (d *TableDockable[T]) toggleHierarchy() {
 	first := true
 	open := false
@@ -604,6 +713,7 @@ func setRowOpen[T gurps.NodeConstraint[T]](row *ntable.Node[T], open bool) {
 }
 
 func (d *TableDockable[T]) sizeToFit() {
+	ntable.ClearColumnWidths(d.provider)
 	d.table.SizeColumnsToFit(true)
 	d.table.MarkForRedraw()
 }
@@ -660,12 +770,50 @@ func (d *TableDockable[T]) adjustForMatch() {
 	d.matchesLabel.Parent().MarkForLayoutAndRedraw()
 }
 
+// RevealRow implements workspace.RowRevealer.
+func (d *TableDockable[T]) RevealRow(id uuid.UUID) bool {
+	row := findRowByID(d.table.RootRows(), id)
+	if row == nil && d.filtered != nil {
+		// The row may be hidden by the filter, so look for it within the full set of rows
+		if row = findRowByID(d.provider.RootRows(), id); row != nil {
+			d.filterField.SetText("")
+			row = findRowByID(d.table.RootRows(), id)
+		}
+	}
+	if row == nil {
+		return false
+	}
+	d.table.DiscloseRow(row, false)
+	d.table.ClearSelection()
+	rowIndex := d.table.RowToIndex(row)
+	d.table.SelectByIndex(rowIndex)
+	d.table.ScrollRowIntoView(rowIndex)
+	return true
+}
+
+func findRowByID[T gurps.NodeConstraint[T]](rows []*ntable.Node[T], id uuid.UUID) *ntable.Node[T] {
+	for _, row := range rows {
+		if row.UUID() == id {
+			return row
+		}
+		if row.CanHaveChildren() {
+			if found := findRowByID(row.Children(), id); found != nil {
+				return found
+			}
+		}
+	}
+	return nil
+}
+
 // Rebuild implements widget.Rebuildable.
 func (d *TableDockable[T]) Rebuild(_ bool) {
 	h, v := d.scroll.Position()
 	sel := d.table.CopySelectionMap()
 	d.table.SyncToModel()
 	d.table.SetSelectionMap(sel)
+	if d.filtered != nil {
+		d.updateFilterCount()
+	}
 	if dc := unison.Ancestor[*unison.DockContainer](d); dc != nil {
 		dc.UpdateTitle(d)
 	}
@@ -674,10 +822,10 @@ func (d *TableDockable[T]) Rebuild(_ bool) {
 
 func (d *TableDockable[T]) crc64() uint64 {
 	var buffer bytes.Buffer
-	rows := d.provider.RootRows()
-	data := make([]any, 0, len(rows))
-	for _, row := range rows {
-		data = append(data, row.Data())
+	rootData := d.provider.RootData()
+	data := make([]any, 0, len(rootData))
+	for _, one := range rootData {
+		data = append(data, one)
 	}
 	if err := jio.Save(context.Background(), &buffer, data); err != nil {
 		return 0
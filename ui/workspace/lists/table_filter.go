@@ -0,0 +1,404 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package lists
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/richardwilkes/gcs/model/criteria"
+	"github.com/richardwilkes/gcs/model/filter"
+	"github.com/richardwilkes/gcs/model/settings"
+	"github.com/richardwilkes/gcs/res"
+	"github.com/richardwilkes/gcs/ui/widget/ntable"
+	"github.com/richardwilkes/gcs/ui/workspace"
+	"github.com/richardwilkes/toolbox/i18n"
+	"github.com/richardwilkes/toolbox/log/jot"
+	"github.com/richardwilkes/unison"
+)
+
+// compareChoice holds one of the comparisons offered by the filter builder. Only the comparison appropriate for the
+// kind of field is used.
+type compareChoice struct {
+	text    criteria.StringCompareType
+	numeric filter.NumericCompareType
+}
+
+func (c compareChoice) String() string {
+	if c.numeric != filter.AnyNumber {
+		return c.numeric.String()
+	}
+	return c.text.String()
+}
+
+func compareChoicesFor(kind filter.Kind) []compareChoice {
+	var choices []compareChoice
+	if kind == filter.TextKind {
+		for _, one := range criteria.AllStringCompareTypes {
+			if one != criteria.Any {
+				choices = append(choices, compareChoice{text: one})
+			}
+		}
+	} else {
+		for _, one := range filter.AllNumericCompareTypes {
+			if one != filter.AnyNumber {
+				choices = append(choices, compareChoice{numeric: one})
+			}
+		}
+	}
+	return choices
+}
+
+func (d *TableDockable[T]) createFilterBar() *unison.Panel {
+	d.filterField = unison.NewField()
+	d.filterField.Watermark = i18n.Text("Filter")
+	d.filterField.Tooltip = unison.NewTooltipWithText(d.filterTooltip())
+	d.filterField.ModifiedCallback = d.filterModified
+	d.filterField.ValidateCallback = func() bool { return d.filterErr == nil }
+	d.filterField.SetLayoutData(&unison.FlexLayoutData{
+		HAlign: unison.FillAlignment,
+		VAlign: unison.MiddleAlignment,
+		HGrab:  true,
+	})
+
+	builderButton := unison.NewSVGButton(res.SettingsSVG)
+	builderButton.Tooltip = unison.NewTooltipWithText(i18n.Text("Build Filter"))
+	builderButton.ClickCallback = d.showFilterBuilder
+
+	savedButton := unison.NewSVGButton(res.BookmarkSVG)
+	savedButton.Tooltip = unison.NewTooltipWithText(i18n.Text("Saved Filters"))
+	savedButton.ClickCallback = func() { d.showSavedFiltersMenu(savedButton) }
+
+	d.filterCountLabel = unison.NewLabel()
+	d.filterCountLabel.Text = "-"
+	d.filterCountLabel.Tooltip = unison.NewTooltipWithText(i18n.Text("Number of rows satisfying the filter"))
+
+	bar := unison.NewPanel()
+	bar.SetBorder(unison.NewCompoundBorder(unison.NewLineBorder(unison.DividerColor, 0, unison.Insets{Bottom: 1},
+		false), unison.NewEmptyBorder(unison.StdInsets())))
+	bar.SetLayoutData(&unison.FlexLayoutData{
+		HAlign: unison.FillAlignment,
+		HGrab:  true,
+	})
+	bar.AddChild(d.filterField)
+	bar.AddChild(builderButton)
+	bar.AddChild(savedButton)
+	bar.AddChild(d.filterCountLabel)
+	bar.SetLayout(&unison.FlexLayout{
+		Columns:  len(bar.Children()),
+		HSpacing: unison.StdHSpacing,
+	})
+
+	// Rows can't be placed meaningfully while only some of them are being shown, so refuse drops while filtering
+	dragOver := d.table.DataDragOverCallback
+	d.table.DataDragOverCallback = func(where unison.Point, data map[string]any) bool {
+		if d.filtered != nil {
+			return false
+		}
+		return dragOver(where, data)
+	}
+	return bar
+}
+
+func (d *TableDockable[T]) filterTooltip() string {
+	fields := filter.FieldsFor(d.extension)
+	keys := make([]string, len(fields))
+	for i, f := range fields {
+		keys[i] = f.Key
+	}
+	return fmt.Sprintf(i18n.Text(`Only show rows satisfying each of the comma-separated conditions, e.g. "tl <= 4, tag = Weapon".
+Operators for numbers: = != < <= > >=
+Operators for text: = (is), != (is not), ~ (contains), !~, ^ (starts with), !^, $ (ends with), !$
+Fields: %s`), strings.Join(keys, ", "))
+}
+
+func (d *TableDockable[T]) filterModified() {
+	f, err := filter.Parse(d.extension, d.filterField.Text())
+	d.filterErr = err
+	if err != nil {
+		d.filterField.Tooltip = unison.NewTooltipWithText(err.Error())
+		return
+	}
+	d.filterField.Tooltip = unison.NewTooltipWithText(d.filterTooltip())
+	d.applyFilter(f)
+}
+
+func (d *TableDockable[T]) applyFilter(f *filter.Filter) {
+	sel := d.table.CopySelectionMap()
+	if f.Empty() {
+		d.filter = nil
+		d.filtered = nil
+		d.table.Model = d.provider
+	} else {
+		d.filter = f
+		d.filtered = ntable.NewFilteredProvider[T](d.provider, func(row T) bool { return f.Matches(row) })
+		d.table.Model = d.filtered
+	}
+	d.table.SyncToModel()
+	d.table.SetSelectionMap(sel)
+	d.updateFilterCount()
+	if d.searchField.Text() != "" {
+		d.searchModified()
+	}
+}
+
+func (d *TableDockable[T]) updateFilterCount() {
+	if d.filtered == nil {
+		d.filterCountLabel.Text = "-"
+	} else {
+		total := 0
+		var count func(list []T)
+		count = func(list []T) {
+			for _, one := range list {
+				total++
+				if one.Container() {
+					count(one.NodeChildren())
+				}
+			}
+		}
+		count(d.provider.RootData())
+		d.filterCountLabel.Text = fmt.Sprintf(i18n.Text("%d of %d"), len(d.table.RootRows()), total)
+	}
+	d.filterCountLabel.Parent().MarkForLayoutAndRedraw()
+}
+
+func (d *TableDockable[T]) showSavedFiltersMenu(button *unison.Button) {
+	saved := settings.Global().Filters.For(d.extension)
+	f := unison.DefaultMenuFactory()
+	nextID := unison.PopupMenuTemporaryBaseID
+	addItem := func(m unison.Menu, title string, enabled bool, handler func()) {
+		nextID++
+		m.InsertItem(-1, f.NewItem(nextID, title, unison.KeyBinding{}, func(_ unison.MenuItem) bool { return enabled },
+			func(_ unison.MenuItem) { handler() }))
+	}
+	m := f.NewMenu(nextID, "", nil)
+	defer m.Dispose()
+	for _, one := range saved {
+		savedFilter := one
+		addItem(m, savedFilter.Name, true, func() { d.filterField.SetText(savedFilter.String()) })
+	}
+	if len(saved) != 0 {
+		m.InsertSeparator(-1, false)
+	}
+	addItem(m, i18n.Text("Save Filter…"), d.filter != nil && d.filterErr == nil, d.saveFilter)
+	nextID++
+	deleteMenu := f.NewMenu(nextID, i18n.Text("Delete Saved Filter"), nil)
+	for _, one := range saved {
+		name := one.Name
+		addItem(deleteMenu, name, true, func() {
+			settings.Global().Filters = settings.Global().Filters.Remove(d.extension, name)
+		})
+	}
+	m.InsertMenu(-1, deleteMenu)
+	m.Popup(button.RectToRoot(button.ContentRect(true)), -1)
+}
+
+func (d *TableDockable[T]) saveFilter() {
+	if d.filter == nil {
+		return
+	}
+	name, ok := workspace.PromptForName(i18n.Text("Save Filter"), i18n.Text("Name"), "", func(name string) string {
+		if strings.TrimSpace(name) == "" {
+			return i18n.Text("A name is required")
+		}
+		return ""
+	})
+	if !ok {
+		return
+	}
+	name = strings.TrimSpace(name)
+	if settings.Global().Filters.Lookup(d.extension, name) != nil &&
+		unison.QuestionDialog(fmt.Sprintf(i18n.Text("A filter named %s already exists."), name),
+			i18n.Text("Replace it?")) != unison.ModalResponseOK {
+		return
+	}
+	settings.Global().Filters = settings.Global().Filters.Put(&filter.Filter{
+		Name:       name,
+		Type:       d.filter.Type,
+		Conditions: d.filter.Conditions,
+	})
+}
+
+// conditionEditor holds the widgets for editing a single condition within the filter builder.
+type conditionEditor struct {
+	fieldPopup   *unison.PopupMenu[*filter.Field]
+	comparePopup *unison.PopupMenu[compareChoice]
+	valueField   *unison.Field
+	removeButton *unison.Button
+	err          error
+}
+
+func (d *TableDockable[T]) showFilterBuilder() {
+	fields := filter.FieldsFor(d.extension)
+	if len(fields) == 0 {
+		return
+	}
+	var dialog *unison.Dialog
+	var editors []*conditionEditor
+	panel := unison.NewPanel()
+	panel.SetLayout(&unison.FlexLayout{
+		Columns:  1,
+		VSpacing: unison.StdVSpacing,
+	})
+	rows := unison.NewPanel()
+	rows.SetLayout(&unison.FlexLayout{
+		Columns:  4,
+		HSpacing: unison.StdHSpacing,
+		VSpacing: unison.StdVSpacing,
+	})
+	panel.AddChild(rows)
+	validate := func() {
+		valid := true
+		for _, one := range editors {
+			if one.err != nil {
+				valid = false
+				break
+			}
+		}
+		if dialog != nil {
+			dialog.Button(unison.ModalResponseOK).SetEnabled(valid)
+		}
+	}
+	var addEditor func(c *filter.Condition)
+	layout := func() {
+		rows.RemoveAllChildren()
+		for _, one := range editors {
+			rows.AddChild(one.fieldPopup)
+			rows.AddChild(one.comparePopup)
+			rows.AddChild(one.valueField)
+			rows.AddChild(one.removeButton)
+		}
+		if dialog != nil {
+			dialog.Window().Pack()
+		}
+		validate()
+	}
+	addEditor = func(c *filter.Condition) {
+		e := &conditionEditor{
+			fieldPopup:   unison.NewPopupMenu[*filter.Field](),
+			comparePopup: unison.NewPopupMenu[compareChoice](),
+			valueField:   unison.NewField(),
+			removeButton: unison.NewSVGButton(res.TrashSVG),
+		}
+		for _, one := range fields {
+			e.fieldPopup.AddItem(one)
+		}
+		e.valueField.SetMinimumTextWidthUsing("A reasonably long value")
+		e.valueField.SetLayoutData(&unison.FlexLayoutData{
+			HAlign: unison.FillAlignment,
+			VAlign: unison.MiddleAlignment,
+			HGrab:  true,
+		})
+		check := func() {
+			field, _ := e.fieldPopup.Selected()
+			choice, _ := e.comparePopup.Selected()
+			_, e.err = filter.NewCondition(d.extension, field.Key, choice.text, choice.numeric, e.valueField.Text())
+			validate()
+		}
+		e.valueField.ValidateCallback = func() bool {
+			check()
+			return e.err == nil
+		}
+		fillCompare := func(field *filter.Field, selected compareChoice) {
+			e.comparePopup.RemoveAllItems()
+			choices := compareChoicesFor(field.Kind)
+			for _, one := range choices {
+				e.comparePopup.AddItem(one)
+			}
+			if e.comparePopup.IndexOfItem(selected) == -1 {
+				selected = choices[0]
+			}
+			e.comparePopup.Select(selected)
+		}
+		field := fields[0]
+		var choice compareChoice
+		if c != nil {
+			if field = filter.FieldFor(d.extension, c.Field); field == nil {
+				field = fields[0]
+			}
+			switch field.Kind {
+			case filter.TextKind:
+				choice.text = c.String.Compare
+				e.valueField.SetText(c.String.Qualifier)
+			case filter.WeightKind:
+				choice.numeric = c.Weight.Compare
+				e.valueField.SetText(c.Weight.Qualifier.String())
+			default:
+				choice.numeric = c.Numeric.Compare
+				e.valueField.SetText(c.Numeric.Qualifier.String())
+			}
+		}
+		e.fieldPopup.Select(field)
+		fillCompare(field, choice)
+		e.fieldPopup.SelectionCallback = func(_ int, field *filter.Field) {
+			current, _ := e.comparePopup.Selected()
+			fillCompare(field, current)
+			e.valueField.Validate()
+		}
+		e.comparePopup.SelectionCallback = func(_ int, _ compareChoice) { e.valueField.Validate() }
+		e.removeButton.Tooltip = unison.NewTooltipWithText(i18n.Text("Remove this condition"))
+		e.removeButton.ClickCallback = func() {
+			for i, one := range editors {
+				if one == e {
+					editors = append(editors[:i], editors[i+1:]...)
+					break
+				}
+			}
+			layout()
+		}
+		editors = append(editors, e)
+		check()
+	}
+	if d.filter != nil {
+		for _, c := range d.filter.Conditions {
+			addEditor(c)
+		}
+	}
+	if len(editors) == 0 {
+		addEditor(nil)
+	}
+	layout()
+
+	addButton := unison.NewButton()
+	addButton.Text = i18n.Text("Add Condition")
+	addButton.ClickCallback = func() {
+		addEditor(nil)
+		layout()
+	}
+	panel.AddChild(addButton)
+
+	var err error
+	if dialog, err = unison.NewDialog(nil, nil, panel, []*unison.DialogButtonInfo{
+		unison.NewCancelButtonInfo(),
+		unison.NewOKButtonInfo(),
+	}); err != nil {
+		jot.Error(err)
+		return
+	}
+	dialog.Window().SetTitle(i18n.Text("Build Filter"))
+	validate()
+	if dialog.RunModal() != unison.ModalResponseOK {
+		return
+	}
+	f := &filter.Filter{Type: strings.ToLower(d.extension)}
+	for _, e := range editors {
+		field, _ := e.fieldPopup.Selected()
+		choice, _ := e.comparePopup.Selected()
+		c, condErr := filter.NewCondition(d.extension, field.Key, choice.text, choice.numeric, e.valueField.Text())
+		if condErr != nil {
+			jot.Warn(condErr)
+			return
+		}
+		f.Conditions = append(f.Conditions, c)
+	}
+	d.filterField.SetText(f.String())
+}
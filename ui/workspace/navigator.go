@@ -15,6 +15,7 @@ import (
 	"fmt"
 	"path/filepath"
 
+	"github.com/google/uuid"
 	"github.com/richardwilkes/gcs/model/library"
 	"github.com/richardwilkes/gcs/model/settings"
 	"github.com/richardwilkes/gcs/res"
@@ -22,7 +23,10 @@ import (
 	"github.com/richardwilkes/unison"
 )
 
-var _ unison.Dockable = &Navigator{}
+var (
+	_ unison.Dockable            = &Navigator{}
+	_ unison.UndoManagerProvider = &Navigator{}
+)
 
 // FileBackedDockable defines methods a Dockable that is based on a file should implement.
 type FileBackedDockable interface {
@@ -30,11 +34,27 @@ type FileBackedDockable interface {
 	BackingFilePath() string
 }
 
+// RetargetableDockable defines the method a FileBackedDockable should implement if it can follow its backing file to a
+// new location.
+type RetargetableDockable interface {
+	FileBackedDockable
+	SetBackingFilePath(filePath string)
+}
+
+// RowRevealer defines the method a FileBackedDockable should implement if it can reveal a specific row of its content.
+type RowRevealer interface {
+	FileBackedDockable
+	// RevealRow discloses, selects and scrolls to the row with the given ID, returning false if no such row exists.
+	RevealRow(id uuid.UUID) bool
+}
+
 // Navigator holds the workspace navigation panel.
 type Navigator struct {
 	unison.Panel
-	scroll *unison.ScrollPanel
-	table  *unison.Table[*NavigatorNode]
+	scroll     *unison.ScrollPanel
+	table      *unison.Table[*NavigatorNode]
+	undoMgr    *unison.UndoManager
+	dropTarget *NavigatorNode
 }
 
 // RegisterFileTypes registers special navigator file types.
@@ -61,12 +81,7 @@ func newNavigator() *Navigator {
 
 	n.table.ColumnSizes = make([]unison.ColumnSize, 1)
 	globalSettings := settings.Global()
-	libs := globalSettings.LibrarySet.List()
-	rows := make([]*NavigatorNode, 0, len(libs))
-	for _, one := range libs {
-		rows = append(rows, NewLibraryNode(n, one))
-	}
-	n.table.SetRootRows(rows)
+	n.table.SetRootRows(n.createLibraryNodes(nil))
 	n.ApplyDisclosedPaths(globalSettings.LibraryExplorer.OpenRowKeys)
 	n.table.SizeColumnsToFit(true)
 
@@ -86,9 +101,61 @@ func newNavigator() *Navigator {
 	n.AddChild(n.scroll)
 
 	n.table.DoubleClickCallback = n.handleSelectionDoubleClick
+	n.installFileOperations()
 	return n
 }
 
+func (n *Navigator) createLibraryNodes(reuse map[*library.Library]*NavigatorNode) []*NavigatorNode {
+	libs := settings.Global().LibrarySet.List()
+	rows := make([]*NavigatorNode, 0, len(libs))
+	for _, one := range libs {
+		if node, ok := reuse[one]; ok {
+			rows = append(rows, node)
+		} else {
+			rows = append(rows, NewLibraryNode(n, one))
+		}
+	}
+	return rows
+}
+
+// Refresh rescans the given libraries from disk, or all libraries if none are provided, preserving the disclosure
+// state, selection and scroll position of the rows as much as possible. Libraries that have been added to or removed
+// from the configuration since the last refresh are always reflected.
+func (n *Navigator) Refresh(libs ...*library.Library) {
+	disclosed := n.DisclosedPaths()
+	selected := make(map[string]bool)
+	for _, row := range n.table.SelectedRows(false) {
+		selected[row.Path()] = true
+	}
+	h, v := n.scroll.Position()
+	var reuse map[*library.Library]*NavigatorNode
+	if len(libs) != 0 {
+		reuse = make(map[*library.Library]*NavigatorNode)
+		for _, row := range n.table.RootRows() {
+			reuse[row.library] = row
+		}
+		for _, lib := range libs {
+			delete(reuse, lib)
+		}
+	}
+	n.table.SetRootRows(n.createLibraryNodes(reuse))
+	n.ApplyDisclosedPaths(disclosed)
+	n.adjustTableSize()
+	selMap := make(map[uuid.UUID]bool)
+	n.accumulateRowIDsForPaths(n.table.RootRows(), selected, selMap)
+	n.table.SetSelectionMap(selMap)
+	n.scroll.SetPosition(h, v)
+}
+
+func (n *Navigator) accumulateRowIDsForPaths(rows []*NavigatorNode, paths map[string]bool, ids map[uuid.UUID]bool) {
+	for _, row := range rows {
+		if paths[row.Path()] {
+			ids[row.UUID()] = true
+		}
+		n.accumulateRowIDsForPaths(row.Children(), paths, ids)
+	}
+}
+
 func (n *Navigator) adjustTableSize() {
 	n.table.SyncToModel()
 	n.table.SizeColumnsToFit(true)
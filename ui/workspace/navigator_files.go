@@ -0,0 +1,554 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package workspace
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/richardwilkes/gcs/model/history"
+	"github.com/richardwilkes/gcs/model/jio"
+	"github.com/richardwilkes/gcs/model/library"
+	"github.com/richardwilkes/gcs/model/trash"
+	"github.com/richardwilkes/gcs/res"
+	"github.com/richardwilkes/toolbox"
+	"github.com/richardwilkes/toolbox/errs"
+	"github.com/richardwilkes/toolbox/i18n"
+	"github.com/richardwilkes/toolbox/log/jot"
+	"github.com/richardwilkes/toolbox/xmath"
+	"github.com/richardwilkes/unison"
+)
+
+const (
+	navigatorDragKey = "navigator"
+	trashRetention   = 30 * 24 * time.Hour
+)
+
+type trashedFilesUndoEdit = *unison.UndoEdit[*trashedFiles]
+
+// trashedFiles holds the files that were moved into the trash by a single operation.
+type trashedFiles struct {
+	nav     *Navigator
+	paths   []string
+	entries []*trash.Entry
+}
+
+// PurgeTrash permanently removes items that have been in the trash for longer than the retention period.
+func PurgeTrash() {
+	go trash.Default().Purge(trashRetention)
+}
+
+func (n *Navigator) installFileOperations() {
+	n.undoMgr = unison.NewUndoManager(100, func(err error) { jot.Error(err) })
+	origMouseDown := n.table.MouseDownCallback
+	n.table.MouseDownCallback = func(where unison.Point, button, clickCount int, mod unison.Modifiers) bool {
+		if button == unison.ButtonRight || (button == unison.ButtonLeft && mod.ControlDown() && runtime.GOOS == toolbox.MacOS) {
+			n.table.RequestFocus()
+			if rowIndex := n.table.OverRow(where.Y); rowIndex != -1 {
+				if !n.table.IsRowSelected(rowIndex) {
+					n.table.ClearSelection()
+					n.table.SelectByIndex(rowIndex)
+				}
+			} else {
+				n.table.ClearSelection()
+			}
+			n.showContextMenu(where)
+			return true
+		}
+		return origMouseDown(where, button, clickCount, mod)
+	}
+	origKeyDown := n.table.KeyDownCallback
+	n.table.KeyDownCallback = func(keyCode unison.KeyCode, mod unison.Modifiers, repeat bool) bool {
+		if (keyCode == unison.KeyDelete || keyCode == unison.KeyBackspace) && mod == 0 {
+			if n.canTrashSelection() {
+				n.trashSelection()
+			}
+			return true
+		}
+		return origKeyDown(keyCode, mod, repeat)
+	}
+	n.table.InstallDragSupport(res.GenericFileSVG, navigatorDragKey, i18n.Text("File"), i18n.Text("Files"))
+	n.table.DataDragOverCallback = n.dataDragOver
+	n.table.DataDragExitCallback = n.dataDragExit
+	n.table.DataDragDropCallback = n.dataDragDrop
+	origDrawOver := n.table.DrawOverCallback
+	n.table.DrawOverCallback = func(gc *unison.Canvas, rect unison.Rect) {
+		if origDrawOver != nil {
+			origDrawOver(gc, rect)
+		}
+		n.drawDropTarget(gc)
+	}
+}
+
+// UndoManager implements unison.UndoManagerProvider
+func (n *Navigator) UndoManager() *unison.UndoManager {
+	return n.undoMgr
+}
+
+func (n *Navigator) showContextMenu(where unison.Point) {
+	selection := n.table.SelectedRows(true)
+	var target *NavigatorNode
+	if len(selection) == 1 {
+		target = selection[0]
+	}
+	f := unison.DefaultMenuFactory()
+	nextID := unison.PopupMenuTemporaryBaseID | unison.ContextMenuIDFlag
+	addItem := func(m unison.Menu, title string, enabled bool, handler func()) {
+		nextID++
+		m.InsertItem(-1, f.NewItem(nextID, title, unison.KeyBinding{}, func(_ unison.MenuItem) bool { return enabled },
+			func(_ unison.MenuItem) { handler() }))
+	}
+	m := f.NewMenu(nextID, "", nil)
+	defer m.Dispose()
+	addItem(m, i18n.Text("Open"), n.hasSelectedFile(), n.handleSelectionDoubleClick)
+	m.InsertSeparator(-1, false)
+	dir := n.targetDirectory(target)
+	addItem(m, i18n.Text("New Folder…"), dir != "", func() { n.newFolder(dir) })
+	nextID++
+	newMenu := f.NewMenu(nextID, i18n.Text("New"), nil)
+	for _, one := range library.CreatableFileInfos() {
+		fi := one
+		addItem(newMenu, fi.Name+"…", dir != "", func() { n.newFile(dir, fi) })
+	}
+	m.InsertMenu(-1, newMenu)
+	m.InsertSeparator(-1, false)
+	addItem(m, i18n.Text("Rename…"), target != nil && target.nodeType != libraryNode, func() { n.rename(target) })
+	addItem(m, i18n.Text("Duplicate"), n.canTrashSelection(), n.duplicateSelection)
+	addItem(m, i18n.Text("Move to Trash"), n.canTrashSelection(), n.trashSelection)
+	m.Popup(n.table.RectToRoot(unison.Rect{Point: where, Size: unison.Size{Width: 1, Height: 1}}), -1)
+}
+
+func (n *Navigator) hasSelectedFile() bool {
+	for _, row := range n.table.SelectedRows(false) {
+		if row.nodeType == fileNode {
+			return true
+		}
+	}
+	return false
+}
+
+// targetDirectory returns the directory new items should be placed in when the node is the target of an operation. If
+// no node is provided, the user library is used.
+func (n *Navigator) targetDirectory(target *NavigatorNode) string {
+	if target == nil {
+		for _, row := range n.table.RootRows() {
+			if row.library.IsUser() {
+				return row.Path()
+			}
+		}
+		return ""
+	}
+	if target.nodeType == fileNode {
+		return filepath.Dir(target.Path())
+	}
+	return target.Path()
+}
+
+func (n *Navigator) selectedPaths() []string {
+	selection := n.table.SelectedRows(true)
+	paths := make([]string, 0, len(selection))
+	for _, row := range selection {
+		if row.nodeType != libraryNode {
+			paths = append(paths, row.Path())
+		}
+	}
+	return paths
+}
+
+func (n *Navigator) canTrashSelection() bool {
+	return len(n.selectedPaths()) != 0
+}
+
+func (n *Navigator) newFolder(dir string) {
+	name, ok := PromptForName(i18n.Text("New Folder"), i18n.Text("Folder Name"), i18n.Text("New Folder"),
+		func(name string) string { return checkNewName(dir, name, "") })
+	if !ok {
+		return
+	}
+	p := filepath.Join(dir, name)
+	if err := os.Mkdir(p, 0o750); err != nil {
+		unison.ErrorDialogWithError(i18n.Text("Unable to create folder"), errs.Wrap(err))
+		return
+	}
+	n.refreshAndSelect(p)
+}
+
+func (n *Navigator) newFile(dir string, fi library.FileInfo) {
+	name, ok := PromptForName(fmt.Sprintf(i18n.Text("New %s"), fi.Name), i18n.Text("File Name"),
+		strings.TrimSuffix(filepath.Base(library.UniquePath(filepath.Join(dir, i18n.Text("Untitled")+fi.Extension))),
+			fi.Extension), func(name string) string { return checkNewName(dir, name, fi.Extension) })
+	if !ok {
+		return
+	}
+	p := filepath.Join(dir, name+fi.Extension)
+	if err := fi.CreateEmpty(p); err != nil {
+		unison.ErrorDialogWithError(fmt.Sprintf(i18n.Text("Unable to create %s"), name), err)
+		return
+	}
+	n.refreshAndSelect(p)
+	OpenFile(n.Window(), p)
+}
+
+func (n *Navigator) rename(target *NavigatorNode) {
+	oldPath := target.Path()
+	dir := filepath.Dir(oldPath)
+	var ext string
+	if target.nodeType == fileNode {
+		ext = filepath.Ext(oldPath)
+	}
+	oldName := strings.TrimSuffix(filepath.Base(oldPath), ext)
+	name, ok := PromptForName(i18n.Text("Rename"), i18n.Text("Name"), oldName, func(name string) string {
+		if name == oldName {
+			return ""
+		}
+		if strings.EqualFold(name, oldName) {
+			return checkNewName("", name, ext)
+		}
+		return checkNewName(dir, name, ext)
+	})
+	if !ok || name == oldName {
+		return
+	}
+	newPath := filepath.Join(dir, name+ext)
+	if err := library.MovePath(oldPath, newPath); err != nil {
+		unison.ErrorDialogWithError(fmt.Sprintf(i18n.Text("Unable to rename %s"), filepath.Base(oldPath)), err)
+		return
+	}
+	followMove(oldPath, newPath)
+	n.refreshAndSelect(newPath)
+}
+
+func (n *Navigator) duplicateSelection() {
+	paths := n.selectedPaths()
+	created := make([]string, 0, len(paths))
+	for _, p := range paths {
+		ext := filepath.Ext(p)
+		if fi, err := os.Stat(p); err == nil && fi.IsDir() && !jio.IsSplitPath(p) {
+			ext = ""
+		}
+		dst := library.UniquePath(strings.TrimSuffix(p, ext) + i18n.Text(" copy") + ext)
+		if err := library.CopyPath(p, dst); err != nil {
+			unison.ErrorDialogWithError(fmt.Sprintf(i18n.Text("Unable to duplicate %s"), filepath.Base(p)), err)
+			continue
+		}
+		created = append(created, dst)
+	}
+	n.refreshAndSelect(created...)
+}
+
+func (n *Navigator) trashSelection() {
+	t := &trashedFiles{nav: n}
+	t.discard(n.selectedPaths())
+	if len(t.entries) == 0 {
+		return
+	}
+	n.undoMgr.Add(&unison.UndoEdit[*trashedFiles]{
+		ID:         unison.NextUndoID(),
+		EditName:   i18n.Text("Move to Trash"),
+		UndoFunc:   func(edit trashedFilesUndoEdit) { edit.BeforeData.restore() },
+		RedoFunc:   func(edit trashedFilesUndoEdit) { edit.AfterData.discard(edit.AfterData.paths) },
+		BeforeData: t,
+		AfterData:  t,
+	})
+}
+
+func (t *trashedFiles) discard(paths []string) {
+	t.paths = nil
+	t.entries = nil
+	for _, p := range paths {
+		if !closeDockablesWithin(p) {
+			continue
+		}
+		entry, err := trash.Default().Discard(p)
+		if err != nil {
+			unison.ErrorDialogWithError(fmt.Sprintf(i18n.Text("Unable to move %s to the trash"), filepath.Base(p)), err)
+			continue
+		}
+		t.paths = append(t.paths, p)
+		t.entries = append(t.entries, entry)
+	}
+	t.nav.Refresh()
+}
+
+func (t *trashedFiles) restore() {
+	for i := len(t.entries) - 1; i >= 0; i-- {
+		if err := t.entries[i].Restore(); err != nil {
+			unison.ErrorDialogWithError(fmt.Sprintf(i18n.Text("Unable to restore %s"), filepath.Base(t.paths[i])), err)
+		}
+	}
+	t.entries = nil
+	t.nav.refreshAndSelect(t.paths...)
+}
+
+// moveInto moves the files and directories into the directory.
+func (n *Navigator) moveInto(dir string, paths []string) {
+	moved := make([]string, 0, len(paths))
+	for _, p := range paths {
+		dst := filepath.Join(dir, filepath.Base(p))
+		if dst == p || library.IsWithin(p, dir) {
+			continue
+		}
+		if err := library.MovePath(p, dst); err != nil {
+			unison.ErrorDialogWithError(fmt.Sprintf(i18n.Text("Unable to move %s"), filepath.Base(p)), err)
+			continue
+		}
+		followMove(p, dst)
+		moved = append(moved, dst)
+	}
+	if len(moved) != 0 {
+		n.refreshAndSelect(moved...)
+	}
+}
+
+// refreshAndSelect rescans the libraries, then discloses and selects the rows for the paths.
+func (n *Navigator) refreshAndSelect(paths ...string) {
+	n.Refresh()
+	disclosed := n.DisclosedPaths()
+	libs := make([]string, 0, len(n.table.RootRows()))
+	for _, row := range n.table.RootRows() {
+		libs = append(libs, row.Path())
+	}
+	want := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		want[p] = true
+		for _, lib := range libs {
+			if library.IsWithin(lib, p) {
+				for dir := filepath.Dir(p); library.IsWithin(lib, dir); dir = filepath.Dir(dir) {
+					disclosed = append(disclosed, dir)
+				}
+				break
+			}
+		}
+	}
+	n.ApplyDisclosedPaths(disclosed)
+	n.adjustTableSize()
+	selMap := make(map[uuid.UUID]bool)
+	n.accumulateRowIDsForPaths(n.table.RootRows(), want, selMap)
+	n.table.SetSelectionMap(selMap)
+	if index := n.table.FirstSelectedRowIndex(); index != -1 {
+		n.table.ScrollRowIntoView(index)
+	}
+}
+
+func (n *Navigator) dropTargetFor(where unison.Point, data map[string]any) *NavigatorNode {
+	dd, ok := data[navigatorDragKey].(*unison.TableDragData[*NavigatorNode])
+	if !ok {
+		return nil
+	}
+	rowIndex := n.table.OverRow(where.Y)
+	if rowIndex == -1 {
+		return nil
+	}
+	target := n.table.RowFromIndex(rowIndex)
+	if target.nodeType == fileNode {
+		target = target.parent
+	}
+	if target == nil {
+		return nil
+	}
+	dir := target.Path()
+	for _, row := range dd.Rows {
+		if row.nodeType == libraryNode || library.IsWithin(row.Path(), dir) {
+			return nil
+		}
+	}
+	return target
+}
+
+func (n *Navigator) dataDragOver(where unison.Point, data map[string]any) bool {
+	target := n.dropTargetFor(where, data)
+	if target != n.dropTarget {
+		n.dropTarget = target
+		n.table.MarkForRedraw()
+	}
+	return target != nil
+}
+
+func (n *Navigator) dataDragExit() {
+	n.dropTarget = nil
+	n.table.MarkForRedraw()
+}
+
+func (n *Navigator) dataDragDrop(where unison.Point, data map[string]any) {
+	n.dropTarget = nil
+	n.table.MarkForRedraw()
+	if target := n.dropTargetFor(where, data); target != nil {
+		dd := data[navigatorDragKey].(*unison.TableDragData[*NavigatorNode]) //nolint:errcheck // Checked by dropTargetFor
+		paths := make([]string, 0, len(dd.Rows))
+		for _, row := range dd.Rows {
+			paths = append(paths, row.Path())
+		}
+		n.moveInto(target.Path(), paths)
+	}
+}
+
+func (n *Navigator) drawDropTarget(gc *unison.Canvas) {
+	if n.dropTarget == nil {
+		return
+	}
+	rowIndex := n.table.RowToIndex(n.dropTarget)
+	if rowIndex == -1 {
+		return
+	}
+	r := n.table.RowFrame(rowIndex)
+	r.Inset(unison.NewUniformInsets(1))
+	r.Width = xmath.Max(r.Width, 1)
+	paint := unison.DropAreaColor.Paint(gc, r, unison.Stroke)
+	paint.SetStrokeWidth(2)
+	gc.DrawRect(r, paint)
+}
+
+// followMove retargets open documents and revision histories from the old location to the new one. The paths may
+// refer to either a file or a directory.
+func followMove(oldPath, newPath string) {
+	forEachFileBackedDockable(func(dc *unison.DockContainer, fbd FileBackedDockable) {
+		if rd, ok := fbd.(RetargetableDockable); ok {
+			if p := fbd.BackingFilePath(); library.IsWithin(oldPath, p) {
+				if rel, err := filepath.Rel(oldPath, p); err == nil {
+					rd.SetBackingFilePath(filepath.Join(newPath, rel))
+					dc.UpdateTitle(rd)
+				}
+			}
+		}
+	})
+	store := history.DefaultStore()
+	if err := filepath.WalkDir(newPath, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, relErr := filepath.Rel(newPath, p)
+		if relErr != nil {
+			return errs.Wrap(relErr)
+		}
+		if d.IsDir() && !jio.IsSplitPath(p) {
+			return nil
+		}
+		if moveErr := store.Move(filepath.Join(oldPath, rel), p); moveErr != nil {
+			jot.Warn(moveErr)
+		}
+		if d.IsDir() {
+			return fs.SkipDir
+		}
+		return nil
+	}); err != nil {
+		jot.Warn(errs.NewWithCause("unable to move the revision history for "+newPath, err))
+	}
+}
+
+// closeDockablesWithin attempts to close any open documents backed by the path or, if the path is a directory, by any
+// file within it. Returns false if any of them refused to close.
+func closeDockablesWithin(p string) bool {
+	var list []FileBackedDockable
+	forEachFileBackedDockable(func(_ *unison.DockContainer, fbd FileBackedDockable) {
+		if library.IsWithin(p, fbd.BackingFilePath()) {
+			list = append(list, fbd)
+		}
+	})
+	for _, one := range list {
+		if closer, ok := one.(unison.TabCloser); ok {
+			if !closer.MayAttemptClose() || !closer.AttemptClose() {
+				return false
+			}
+		} else if dc := unison.Ancestor[*unison.DockContainer](one); dc != nil {
+			dc.Close(one)
+		}
+	}
+	return true
+}
+
+func forEachFileBackedDockable(f func(dc *unison.DockContainer, fbd FileBackedDockable)) {
+	for _, wnd := range unison.Windows() {
+		if ws := FromWindow(wnd); ws != nil {
+			ws.DocumentDock.RootDockLayout().ForEachDockContainer(func(dc *unison.DockContainer) bool {
+				for _, one := range dc.Dockables() {
+					if fbd, ok := one.(FileBackedDockable); ok {
+						f(dc, fbd)
+					}
+				}
+				return false
+			})
+		}
+	}
+}
+
+// checkNewName returns a description of the problem with using the name (plus extension) for a new item within the
+// directory, or an empty string if there is none. Pass an empty directory to skip checking for an existing item.
+func checkNewName(dir, name, ext string) string {
+	if !library.ValidFileName(name + ext) {
+		return i18n.Text("Names may not be empty, start with a period or contain any of these characters: ") +
+			`/ \ : * ? " < > |`
+	}
+	if dir != "" && library.Exists(filepath.Join(dir, name+ext)) {
+		return i18n.Text("Something with that name already exists")
+	}
+	return ""
+}
+
+// PromptForName asks the user for a name, using the check function to validate it. The check function should return
+// a description of the problem with the name, or an empty string if there is none.
+func PromptForName(title, label, initial string, check func(name string) string) (string, bool) {
+	panel := unison.NewPanel()
+	panel.SetLayout(&unison.FlexLayout{
+		Columns:  2,
+		HSpacing: unison.StdHSpacing,
+		VSpacing: unison.StdVSpacing,
+	})
+	nameLabel := unison.NewLabel()
+	nameLabel.Text = label
+	panel.AddChild(nameLabel)
+	field := unison.NewField()
+	field.SetText(initial)
+	field.SetMinimumTextWidthUsing("This is a reasonably long file name")
+	field.SetLayoutData(&unison.FlexLayoutData{
+		HAlign: unison.FillAlignment,
+		HGrab:  true,
+	})
+	panel.AddChild(field)
+	status := unison.NewLabel()
+	status.OnBackgroundInk = unison.ErrorColor
+	status.SetLayoutData(&unison.FlexLayoutData{
+		HSpan:  2,
+		HAlign: unison.FillAlignment,
+		HGrab:  true,
+	})
+	panel.AddChild(status)
+	dialog, err := unison.NewDialog(nil, nil, panel, []*unison.DialogButtonInfo{
+		unison.NewCancelButtonInfo(),
+		unison.NewOKButtonInfo(),
+	})
+	if err != nil {
+		jot.Error(err)
+		return "", false
+	}
+	dialog.Window().SetTitle(title)
+	validate := func() bool {
+		problem := check(field.Text())
+		status.Text = problem
+		status.MarkForLayoutAndRedraw()
+		dialog.Button(unison.ModalResponseOK).SetEnabled(problem == "")
+		return problem == ""
+	}
+	field.ValidateCallback = validate
+	validate()
+	field.SelectAll()
+	field.RequestFocus()
+	if dialog.RunModal() != unison.ModalResponseOK {
+		return "", false
+	}
+	return field.Text(), true
+}
@@ -20,6 +20,7 @@ import (
 	"strings"
 
 	"github.com/google/uuid"
+	"github.com/richardwilkes/gcs/model/jio"
 	"github.com/richardwilkes/gcs/model/library"
 	"github.com/richardwilkes/toolbox/errs"
 	"github.com/richardwilkes/toolbox/log/jot"
@@ -211,9 +212,15 @@ func (n *NavigatorNode) refreshChildren(dirPath string, parent *NavigatorNode) [
 					isDir = true
 				}
 			}
+			if isDir && library.FileInfoFor(name).IsGCSData && jio.IsSplitPath(filepath.Join(libPath, p)) {
+				// Directories holding data in the split format are presented as a single file
+				isDir = false
+			}
 			if isDir {
 				dirNode := NewDirectoryNode(n.nav, n.library, p, parent)
-				if dirNode.recursiveFileCount() > 0 {
+				// Empty directories are shown so that newly created ones can be filled, but directories holding only
+				// files we don't present are not
+				if dirNode.recursiveFileCount() > 0 || isEmptyDir(filepath.Join(libPath, p)) {
 					children = append(children, dirNode)
 				}
 			} else if !library.FileInfoFor(name).IsSpecial {
@@ -236,3 +243,8 @@ func (n *NavigatorNode) recursiveFileCount() int {
 	}
 	return count
 }
+
+func isEmptyDir(dirPath string) bool {
+	entries, err := os.ReadDir(dirPath)
+	return err == nil && len(entries) == 0
+}
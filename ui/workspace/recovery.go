@@ -0,0 +1,285 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package workspace
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/richardwilkes/gcs/model/crc"
+	"github.com/richardwilkes/gcs/model/id"
+	"github.com/richardwilkes/gcs/model/jio"
+	"github.com/richardwilkes/gcs/model/library"
+	"github.com/richardwilkes/gcs/model/recovery"
+	"github.com/richardwilkes/toolbox/cmdline"
+	"github.com/richardwilkes/toolbox/errs"
+	"github.com/richardwilkes/toolbox/i18n"
+	"github.com/richardwilkes/toolbox/log/jot"
+	"github.com/richardwilkes/unison"
+)
+
+const (
+	autoSaveInterval = time.Minute
+	// Sessions whose heartbeat is older than this are considered to have ended abnormally.
+	autoSaveStaleAfter = 3 * autoSaveInterval
+)
+
+// Recoverable defines the methods a FileBackedDockable should implement to have its unsaved changes periodically
+// captured, so that they can be recovered should the application terminate unexpectedly.
+type Recoverable interface {
+	FileBackedDockable
+	// SaveSnapshot writes the current content to the path in the same form used when saving, without altering the
+	// state of the dockable.
+	SaveSnapshot(filePath string) error
+	// NeedsSaveAs returns true if the content has never been saved to its backing file path.
+	NeedsSaveAs() bool
+	// MarkRecovered is called on a dockable that was just loaded from a snapshot. It should adopt the original path and
+	// consider itself modified.
+	MarkRecovered(originalPath string, needsSaveAs bool)
+}
+
+type autoSaveEntry struct {
+	key string
+	crc uint64
+}
+
+var (
+	autoSaveSession *recovery.Session
+	autoSaveEntries = make(map[Recoverable]*autoSaveEntry)
+)
+
+// StartAutoSave begins periodically taking snapshots of the unsaved changes in open documents.
+func StartAutoSave() {
+	if autoSaveSession != nil {
+		return
+	}
+	var err error
+	if autoSaveSession, err = recovery.NewSession(recovery.DefaultRoot()); err != nil {
+		jot.Error(errs.NewWithCause("unable to start auto-save", err))
+		return
+	}
+	unison.InvokeTaskAfter(autoSave, autoSaveInterval)
+}
+
+// EndAutoSave stops taking snapshots and removes those taken during this session. This should only be called when the
+// application is exiting normally.
+func EndAutoSave() {
+	if autoSaveSession != nil {
+		autoSaveSession.End()
+		autoSaveSession = nil
+	}
+}
+
+func autoSave() {
+	if autoSaveSession == nil {
+		return
+	}
+	if err := autoSaveSession.Touch(); err != nil {
+		jot.Warn(err)
+	}
+	present := make(map[Recoverable]bool)
+	for _, wnd := range unison.Windows() {
+		if ws := FromWindow(wnd); ws != nil {
+			ws.DocumentDock.RootDockLayout().ForEachDockContainer(func(dc *unison.DockContainer) bool {
+				for _, one := range dc.Dockables() {
+					if r, ok := one.(Recoverable); ok && r.Modified() {
+						present[r] = true
+						takeSnapshot(r)
+					}
+				}
+				return false
+			})
+		}
+	}
+	for r, entry := range autoSaveEntries {
+		if !present[r] {
+			autoSaveSession.Remove(entry.key)
+			delete(autoSaveEntries, r)
+		}
+	}
+	unison.InvokeTaskAfter(autoSave, autoSaveInterval)
+}
+
+func takeSnapshot(r Recoverable) {
+	data, err := snapshotData(r)
+	if err != nil {
+		jot.Warn(errs.NewWithCause("unable to take snapshot of "+r.BackingFilePath(), err))
+		return
+	}
+	entry, ok := autoSaveEntries[r]
+	if !ok {
+		entry = &autoSaveEntry{key: id.NewUUID().String()}
+		autoSaveEntries[r] = entry
+	}
+	sum := crc.Bytes(0, data)
+	if ok && entry.crc == sum {
+		return
+	}
+	if err = autoSaveSession.Write(entry.key, &recovery.Snapshot{
+		Path:      r.BackingFilePath(),
+		Title:     r.Title(),
+		Untitled:  r.NeedsSaveAs(),
+		Timestamp: jio.Now(),
+		Data:      data,
+	}); err != nil {
+		jot.Warn(err)
+		return
+	}
+	entry.crc = sum
+}
+
+func snapshotData(r Recoverable) ([]byte, error) {
+	f, err := os.CreateTemp("", "snapshot-*"+path.Ext(r.BackingFilePath()))
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+	tmpPath := f.Name()
+	defer func() {
+		if removeErr := os.Remove(tmpPath); removeErr != nil {
+			jot.Warn(errs.Wrap(removeErr))
+		}
+	}()
+	if err = f.Close(); err != nil {
+		return nil, errs.Wrap(err)
+	}
+	if err = r.SaveSnapshot(tmpPath); err != nil {
+		return nil, err
+	}
+	var data []byte
+	if data, err = os.ReadFile(tmpPath); err != nil {
+		return nil, errs.Wrap(err)
+	}
+	return data, nil
+}
+
+// OfferRecovery looks for snapshots left behind by prior sessions that ended abnormally and, if any are found, asks the
+// user whether each should be restored or discarded.
+func OfferRecovery(wnd *unison.Window) {
+	snapshots := recovery.Orphaned(recovery.DefaultRoot(), autoSaveStaleAfter, autoSaveSession)
+	if len(snapshots) == 0 {
+		return
+	}
+	restoreChoice := i18n.Text("Restore")
+	discardChoice := i18n.Text("Discard")
+	laterChoice := i18n.Text("Decide Later")
+	content := unison.NewPanel()
+	content.SetLayout(&unison.FlexLayout{
+		Columns:  3,
+		HSpacing: unison.StdHSpacing * 2,
+		VSpacing: unison.StdVSpacing,
+	})
+	popups := make([]*unison.PopupMenu[string], len(snapshots))
+	for i, snapshot := range snapshots {
+		popup := unison.NewPopupMenu[string]()
+		popup.AddItem(restoreChoice)
+		popup.AddItem(discardChoice)
+		popup.AddItem(laterChoice)
+		popup.SelectIndex(0)
+		popups[i] = popup
+		content.AddChild(popup)
+		title := unison.NewLabel()
+		title.Text = snapshot.Title
+		if snapshot.Untitled {
+			title.Tooltip = unison.NewTooltipWithText(i18n.Text("Never saved"))
+		} else {
+			title.Tooltip = unison.NewTooltipWithText(snapshot.Path)
+		}
+		title.SetLayoutData(&unison.FlexLayoutData{
+			HAlign: unison.FillAlignment,
+			VAlign: unison.MiddleAlignment,
+			HGrab:  true,
+		})
+		content.AddChild(title)
+		when := unison.NewLabel()
+		when.Text = snapshot.Timestamp.String()
+		when.SetLayoutData(&unison.FlexLayoutData{VAlign: unison.MiddleAlignment})
+		content.AddChild(when)
+	}
+	scroller := unison.NewScrollPanel()
+	scroller.SetContent(content, unison.HintedFillBehavior, unison.FillBehavior)
+	scroller.SetLayoutData(&unison.FlexLayoutData{
+		MinSize: unison.Size{Width: 400, Height: 100},
+		HAlign:  unison.FillAlignment,
+		VAlign:  unison.FillAlignment,
+		HGrab:   true,
+		VGrab:   true,
+	})
+	panel := unison.NewPanel()
+	panel.SetLayout(&unison.FlexLayout{
+		Columns:  1,
+		HSpacing: unison.StdHSpacing,
+		VSpacing: unison.StdVSpacing,
+	})
+	label := unison.NewLabel()
+	label.Text = fmt.Sprintf(i18n.Text("%s did not exit normally. Unsaved changes were recovered from the following documents:"),
+		cmdline.AppName)
+	panel.AddChild(label)
+	panel.AddChild(scroller)
+	dialog, err := unison.NewDialog(nil, nil, panel, []*unison.DialogButtonInfo{
+		unison.NewCancelButtonInfo(),
+		unison.NewOKButtonInfoWithTitle(i18n.Text("Apply")),
+	})
+	if err != nil {
+		jot.Error(err)
+		return
+	}
+	dialog.Window().SetTitle(i18n.Text("Recover Documents"))
+	if dialog.RunModal() != unison.ModalResponseOK {
+		return
+	}
+	for i, snapshot := range snapshots {
+		switch choice, _ := popups[i].Selected(); choice {
+		case restoreChoice:
+			if restoreErr := restoreSnapshot(wnd, snapshot); restoreErr != nil {
+				unison.ErrorDialogWithError(fmt.Sprintf(i18n.Text("Unable to restore %s"), snapshot.Title), restoreErr)
+				continue
+			}
+			snapshot.Discard()
+		case discardChoice:
+			snapshot.Discard()
+		}
+	}
+}
+
+func restoreSnapshot(wnd *unison.Window, snapshot *recovery.Snapshot) error {
+	fi := library.FileInfoFor(snapshot.Path)
+	if !fi.IsGCSData || fi.Load == nil {
+		return errs.New(i18n.Text("unsupported file type"))
+	}
+	tmpDir, err := os.MkdirTemp("", "restore-*")
+	if err != nil {
+		return errs.Wrap(err)
+	}
+	defer func() {
+		if removeErr := os.RemoveAll(tmpDir); removeErr != nil {
+			jot.Warn(errs.Wrap(removeErr))
+		}
+	}()
+	tmpPath := filepath.Join(tmpDir, filepath.Base(snapshot.Path))
+	if err = os.WriteFile(tmpPath, snapshot.Data, 0o600); err != nil {
+		return errs.Wrap(err)
+	}
+	var d unison.Dockable
+	if d, err = fi.Load(tmpPath); err != nil {
+		return err
+	}
+	r, ok := d.(Recoverable)
+	if !ok {
+		return errs.New(i18n.Text("unsupported file type"))
+	}
+	r.MarkRecovered(snapshot.Path, snapshot.Untitled)
+	DisplayNewDockable(wnd, d)
+	return nil
+}
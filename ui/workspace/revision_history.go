@@ -0,0 +1,520 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package workspace
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/richardwilkes/gcs/model/history"
+	"github.com/richardwilkes/gcs/model/jio"
+	"github.com/richardwilkes/gcs/model/library"
+	"github.com/richardwilkes/gcs/model/recovery"
+	"github.com/richardwilkes/gcs/model/settings"
+	"github.com/richardwilkes/gcs/model/theme"
+	"github.com/richardwilkes/gcs/res"
+	"github.com/richardwilkes/toolbox/i18n"
+	"github.com/richardwilkes/toolbox/log/jot"
+	xfs "github.com/richardwilkes/toolbox/xio/fs"
+	"github.com/richardwilkes/unison"
+)
+
+const (
+	revisionSavedColumn = iota
+	revisionSizeColumn
+	revisionStatusColumn
+	revisionColumnCount
+)
+
+// The number of unchanged lines shown around each change when comparing revisions.
+const revisionDiffContext = 3
+
+var (
+	_ unison.Dockable  = &RevisionHistoryDockable{}
+	_ unison.TabCloser = &RevisionHistoryDockable{}
+)
+
+// RevisionHistoryDockable shows the prior revisions of a file, allowing them to be previewed, compared with the current
+// content and restored.
+type RevisionHistoryDockable struct {
+	unison.Panel
+	path       string
+	scroll     *unison.ScrollPanel
+	table      *unison.Table[*revisionRow]
+	diffLabel  *unison.Label
+	diffScroll *unison.ScrollPanel
+	diffPanel  *unison.Panel
+}
+
+type revisionRow struct {
+	id      uuid.UUID
+	rev     *history.Revision
+	current bool
+}
+
+// CanShowRevisionHistory returns true if revision history is available for the file.
+func CanShowRevisionHistory(filePath string) bool {
+	return library.FileInfoFor(filePath).IsGCSData && (xfs.FileExists(filePath) || jio.IsSplitPath(filePath))
+}
+
+// ShowRevisionHistory shows the Revision History dockable for the file, creating it if necessary.
+func ShowRevisionHistory(filePath string) {
+	var existing *RevisionHistoryDockable
+	ws, _, found := Activate(func(d unison.Dockable) bool {
+		if rhd, ok := d.(*RevisionHistoryDockable); ok && rhd.path == filePath {
+			existing = rhd
+			return true
+		}
+		return false
+	})
+	if found {
+		existing.refresh()
+	} else if ws != nil {
+		d := newRevisionHistoryDockable(filePath)
+		DisplayNewDockable(ws.Window, d)
+		d.refresh()
+	}
+}
+
+// preserveRevision adds the current content of the file to its revision history if it isn't already present, so that
+// content produced outside of this application is not lost when the file is overwritten.
+func preserveRevision(filePath string) {
+	if !library.FileInfoFor(filePath).IsGCSData {
+		return
+	}
+	data, err := jio.ReadFile(filePath)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			jot.Warn(err)
+		}
+		return
+	}
+	store := history.DefaultStore()
+	var revs []*history.Revision
+	if revs, err = store.Revisions(filePath); err != nil {
+		jot.Warn(err)
+		return
+	}
+	key := history.KeyFor(data)
+	for _, rev := range revs {
+		if rev.Key == key {
+			return
+		}
+	}
+	if err = store.Record(filePath, data, settings.Global().General.RevisionsToKeep); err != nil {
+		jot.Warn(err)
+	}
+}
+
+// recordRevision adds the current content of the file to its revision history.
+func recordRevision(filePath string) {
+	if !library.FileInfoFor(filePath).IsGCSData {
+		return
+	}
+	data, err := jio.ReadFile(filePath)
+	if err != nil {
+		jot.Warn(err)
+		return
+	}
+	if err = history.DefaultStore().Record(filePath, data, settings.Global().General.RevisionsToKeep); err != nil {
+		jot.Warn(err)
+	}
+}
+
+func newRevisionHistoryDockable(filePath string) *RevisionHistoryDockable {
+	d := &RevisionHistoryDockable{
+		path:       filePath,
+		scroll:     unison.NewScrollPanel(),
+		table:      unison.NewTable[*revisionRow](&unison.SimpleTableModel[*revisionRow]{}),
+		diffLabel:  unison.NewLabel(),
+		diffScroll: unison.NewScrollPanel(),
+		diffPanel:  unison.NewPanel(),
+	}
+	d.Self = d
+	d.SetLayout(&unison.FlexLayout{Columns: 1})
+
+	d.table.ColumnSizes = make([]unison.ColumnSize, revisionColumnCount)
+	d.table.DoubleClickCallback = d.compare
+	header := unison.NewTableHeader[*revisionRow](d.table,
+		unison.NewTableColumnHeader[*revisionRow](i18n.Text("Saved"), ""),
+		unison.NewTableColumnHeader[*revisionRow](i18n.Text("Size"), ""),
+		unison.NewTableColumnHeader[*revisionRow](i18n.Text("Status"), ""),
+	)
+	d.scroll.SetColumnHeader(header)
+	d.scroll.SetContent(d.table, unison.FillBehavior, unison.FillBehavior)
+	d.scroll.SetLayoutData(&unison.FlexLayoutData{
+		MinSize: unison.Size{Height: 150},
+		HAlign:  unison.FillAlignment,
+		VAlign:  unison.FillAlignment,
+		HGrab:   true,
+	})
+
+	pathLabel := unison.NewLabel()
+	pathLabel.Text = filePath
+	pathLabel.SetLayoutData(&unison.FlexLayoutData{
+		HAlign: unison.FillAlignment,
+		VAlign: unison.MiddleAlignment,
+		HGrab:  true,
+	})
+
+	previewButton := unison.NewButton()
+	previewButton.Text = i18n.Text("Preview")
+	previewButton.Tooltip = unison.NewTooltipWithText(i18n.Text("Open the selected revision as an unsaved copy"))
+	previewButton.ClickCallback = d.preview
+
+	compareButton := unison.NewButton()
+	compareButton.Text = i18n.Text("Compare")
+	compareButton.Tooltip = unison.NewTooltipWithText(i18n.Text("Show the differences between the selected revision and the current content"))
+	compareButton.ClickCallback = d.compare
+
+	restoreButton := unison.NewButton()
+	restoreButton.Text = i18n.Text("Restore")
+	restoreButton.Tooltip = unison.NewTooltipWithText(i18n.Text("Replace the file with the selected revision"))
+	restoreButton.ClickCallback = d.restore
+
+	toolbar := unison.NewPanel()
+	toolbar.SetBorder(unison.NewCompoundBorder(unison.NewLineBorder(unison.DividerColor, 0, unison.Insets{Bottom: 1},
+		false), unison.NewEmptyBorder(unison.StdInsets())))
+	toolbar.SetLayoutData(&unison.FlexLayoutData{
+		HAlign: unison.FillAlignment,
+		HGrab:  true,
+	})
+	toolbar.AddChild(pathLabel)
+	toolbar.AddChild(previewButton)
+	toolbar.AddChild(compareButton)
+	toolbar.AddChild(restoreButton)
+	toolbar.SetLayout(&unison.FlexLayout{
+		Columns:  len(toolbar.Children()),
+		HSpacing: unison.StdHSpacing,
+	})
+
+	d.diffLabel.Text = i18n.Text("Select a revision and press Compare to see how it differs from the current content.")
+	d.diffLabel.SetBorder(unison.NewCompoundBorder(unison.NewLineBorder(unison.DividerColor, 0,
+		unison.Insets{Top: 1, Bottom: 1}, false), unison.NewEmptyBorder(unison.StdInsets())))
+	d.diffLabel.SetLayoutData(&unison.FlexLayoutData{
+		HAlign: unison.FillAlignment,
+		HGrab:  true,
+	})
+
+	d.diffPanel.SetLayout(&unison.FlexLayout{Columns: 1})
+	d.diffPanel.SetBorder(unison.NewEmptyBorder(unison.StdInsets()))
+	d.diffScroll.SetContent(d.diffPanel, unison.HintedFillBehavior, unison.FillBehavior)
+	d.diffScroll.SetLayoutData(&unison.FlexLayoutData{
+		HAlign: unison.FillAlignment,
+		VAlign: unison.FillAlignment,
+		HGrab:  true,
+		VGrab:  true,
+	})
+
+	d.AddChild(toolbar)
+	d.AddChild(d.scroll)
+	d.AddChild(d.diffLabel)
+	d.AddChild(d.diffScroll)
+	return d
+}
+
+func (d *RevisionHistoryDockable) refresh() {
+	revs, err := history.DefaultStore().Revisions(d.path)
+	if err != nil {
+		unison.ErrorDialogWithError(i18n.Text("Unable to load the revision history"), err)
+	}
+	currentKey := ""
+	if data, readErr := jio.ReadFile(d.path); readErr == nil {
+		currentKey = history.KeyFor(data)
+	}
+	rows := make([]*revisionRow, 0, len(revs))
+	for _, rev := range revs {
+		rows = append(rows, &revisionRow{
+			id:      uuid.New(),
+			rev:     rev,
+			current: rev.Key == currentKey,
+		})
+	}
+	d.table.SetRootRows(rows)
+	d.table.SizeColumnsToFit(true)
+}
+
+func (d *RevisionHistoryDockable) selectedRevision() (*history.Revision, []byte) {
+	rows := d.table.SelectedRows(false)
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	data, err := history.DefaultStore().Data(d.path, rows[0].rev)
+	if err != nil {
+		unison.ErrorDialogWithError(i18n.Text("Unable to load the revision"), err)
+		return nil, nil
+	}
+	return rows[0].rev, data
+}
+
+// openDockable returns the dockable that is currently showing the file, if any.
+func (d *RevisionHistoryDockable) openDockable() FileBackedDockable {
+	for _, wnd := range unison.Windows() {
+		if ws := FromWindow(wnd); ws != nil {
+			if fbd := ws.LocateFileBackedDockable(d.path); fbd != nil {
+				return fbd
+			}
+		}
+	}
+	return nil
+}
+
+func (d *RevisionHistoryDockable) preview() {
+	rev, data := d.selectedRevision()
+	if rev == nil {
+		return
+	}
+	name := fmt.Sprintf("%s (%s)%s", xfs.TrimExtension(filepath.Base(d.path)),
+		time.Time(rev.Timestamp).Format("2006-01-02 15.04"), filepath.Ext(d.path))
+	if err := restoreSnapshot(d.Window(), &recovery.Snapshot{
+		Path:     filepath.Join(filepath.Dir(d.path), name),
+		Untitled: true,
+		Data:     data,
+	}); err != nil {
+		unison.ErrorDialogWithError(i18n.Text("Unable to preview the revision"), err)
+	}
+}
+
+func (d *RevisionHistoryDockable) compare() {
+	rev, data := d.selectedRevision()
+	if rev == nil {
+		return
+	}
+	current, what, err := d.currentContent()
+	if err != nil {
+		unison.ErrorDialogWithError(i18n.Text("Unable to load the current content"), err)
+		return
+	}
+	var lines []history.DiffLine
+	if lines, err = history.DiffJSON(data, current); err != nil {
+		unison.ErrorDialogWithError(i18n.Text("Unable to compare the revision"), err)
+		return
+	}
+	d.showDiff(lines, fmt.Sprintf(i18n.Text("Changes from the revision saved %s to the %s:"), rev.Timestamp.String(),
+		what))
+}
+
+// currentContent returns the unsaved content of the dockable showing the file, if there is one with changes, otherwise
+// the content of the file on disk.
+func (d *RevisionHistoryDockable) currentContent() (data []byte, description string, err error) {
+	if r, ok := d.openDockable().(Recoverable); ok && r.Modified() {
+		if data, err = snapshotData(r); err != nil {
+			return nil, "", err
+		}
+		return data, i18n.Text("current unsaved content"), nil
+	}
+	if data, err = jio.ReadFile(d.path); err != nil {
+		return nil, "", err
+	}
+	return data, i18n.Text("current file"), nil
+}
+
+func (d *RevisionHistoryDockable) showDiff(lines []history.DiffLine, title string) {
+	d.diffPanel.RemoveAllChildren()
+	changes := 0
+	show := make([]bool, len(lines))
+	for i, line := range lines {
+		if line.Kind == history.Unchanged {
+			continue
+		}
+		changes++
+		for j := i - revisionDiffContext; j <= i+revisionDiffContext; j++ {
+			if j >= 0 && j < len(lines) {
+				show[j] = true
+			}
+		}
+	}
+	if changes == 0 {
+		d.diffLabel.Text = i18n.Text("The revision is identical to the current content.")
+	} else {
+		d.diffLabel.Text = title
+		skipped := false
+		for i, line := range lines {
+			if !show[i] {
+				skipped = true
+				continue
+			}
+			if skipped {
+				d.diffPanel.AddChild(newRevisionDiffLine("…", unison.OnBackgroundColor))
+				skipped = false
+			}
+			switch line.Kind {
+			case history.Removed:
+				d.diffPanel.AddChild(newRevisionDiffLine("- "+line.Text, unison.ErrorColor))
+			case history.Added:
+				d.diffPanel.AddChild(newRevisionDiffLine("+ "+line.Text, theme.AccentColor))
+			default:
+				d.diffPanel.AddChild(newRevisionDiffLine("  "+line.Text, unison.OnBackgroundColor))
+			}
+		}
+		if skipped {
+			d.diffPanel.AddChild(newRevisionDiffLine("…", unison.OnBackgroundColor))
+		}
+	}
+	d.diffLabel.Parent().MarkForLayoutAndRedraw()
+	d.diffScroll.SetPosition(0, 0)
+}
+
+func newRevisionDiffLine(text string, ink unison.Ink) *unison.Label {
+	label := unison.NewLabel()
+	label.Font = unison.FieldFont
+	label.OnBackgroundInk = ink
+	label.Text = text
+	return label
+}
+
+func (d *RevisionHistoryDockable) restore() {
+	rev, data := d.selectedRevision()
+	if rev == nil {
+		return
+	}
+	open := d.openDockable()
+	if open != nil && open.Modified() {
+		unison.ErrorDialogWithMessage(i18n.Text("Unable to restore the revision"),
+			fmt.Sprintf(i18n.Text("%s has unsaved changes. Save or revert them first."), open.Title()))
+		return
+	}
+	if unison.QuestionDialog(fmt.Sprintf(i18n.Text("Restore the revision saved %s?"), rev.Timestamp.String()),
+		i18n.Text("The current content of the file will be kept in its revision history.")) != unison.ModalResponseOK {
+		return
+	}
+	preserveRevision(d.path)
+	if err := jio.WriteFile(d.path, data, 0o640); err != nil {
+		unison.ErrorDialogWithError(i18n.Text("Unable to restore the revision"), err)
+		return
+	}
+	recordRevision(d.path)
+	if open != nil {
+		if rd, ok := open.(ReloadableDockable); ok {
+			rd.ReloadIfChangedOnDisk()
+		} else if tc, ok2 := open.(unison.TabCloser); ok2 && tc.AttemptClose() {
+			OpenFile(d.Window(), d.path)
+		}
+	}
+	d.refresh()
+}
+
+// TitleIcon implements unison.Dockable
+func (d *RevisionHistoryDockable) TitleIcon(suggestedSize unison.Size) unison.Drawable {
+	svg := library.FileInfoFor(d.path).SVG
+	if svg == nil {
+		svg = res.GenericFileSVG
+	}
+	return &unison.DrawableSVG{
+		SVG:  svg,
+		Size: suggestedSize,
+	}
+}
+
+// Title implements unison.Dockable
+func (d *RevisionHistoryDockable) Title() string {
+	return fmt.Sprintf(i18n.Text("%s History"), xfs.TrimExtension(filepath.Base(d.path)))
+}
+
+// Tooltip implements unison.Dockable
+func (d *RevisionHistoryDockable) Tooltip() string {
+	return d.path
+}
+
+// Modified implements unison.Dockable
+func (d *RevisionHistoryDockable) Modified() bool {
+	return false
+}
+
+// MayAttemptClose implements unison.TabCloser
+func (d *RevisionHistoryDockable) MayAttemptClose() bool {
+	return true
+}
+
+// AttemptClose implements unison.TabCloser
+func (d *RevisionHistoryDockable) AttemptClose() bool {
+	if dc := unison.Ancestor[*unison.DockContainer](d); dc != nil {
+		dc.Close(d)
+	}
+	return true
+}
+
+// CloneForTarget implements unison.TableRowData. Not permitted.
+func (r *revisionRow) CloneForTarget(_ unison.Paneler, _ *revisionRow) *revisionRow {
+	return nil
+}
+
+// UUID implements unison.TableRowData.
+func (r *revisionRow) UUID() uuid.UUID {
+	return r.id
+}
+
+// Parent implements unison.TableRowData.
+func (r *revisionRow) Parent() *revisionRow {
+	return nil
+}
+
+// SetParent implements unison.TableRowData.
+func (r *revisionRow) SetParent(_ *revisionRow) {
+}
+
+// CanHaveChildren implements unison.TableRowData.
+func (r *revisionRow) CanHaveChildren() bool {
+	return false
+}
+
+// Children implements unison.TableRowData.
+func (r *revisionRow) Children() []*revisionRow {
+	return nil
+}
+
+// SetChildren implements unison.TableRowData.
+func (r *revisionRow) SetChildren(_ []*revisionRow) {
+}
+
+// CellDataForSort implements unison.TableRowData.
+func (r *revisionRow) CellDataForSort(col int) string {
+	switch col {
+	case revisionSavedColumn:
+		return r.rev.Timestamp.String()
+	case revisionSizeColumn:
+		return strconv.Itoa(r.rev.Size)
+	case revisionStatusColumn:
+		if r.current {
+			return i18n.Text("Current")
+		}
+		return ""
+	default:
+		return ""
+	}
+}
+
+// ColumnCell implements unison.TableRowData.
+func (r *revisionRow) ColumnCell(_, col int, foreground, _ unison.Ink, _, _, _ bool) unison.Paneler {
+	label := unison.NewLabel()
+	label.LabelTheme.OnBackgroundInk = foreground
+	label.Text = r.CellDataForSort(col)
+	if col == revisionSizeColumn {
+		label.Text = fmt.Sprintf(i18n.Text("%s bytes"), label.Text)
+		label.HAlign = unison.EndAlignment
+	}
+	return label
+}
+
+// IsOpen implements unison.TableRowData.
+func (r *revisionRow) IsOpen() bool {
+	return false
+}
+
+// SetOpen implements unison.TableRowData.
+func (r *revisionRow) SetOpen(_ bool) {
+}
@@ -0,0 +1,354 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package workspace
+
+import (
+	"os"
+
+	"github.com/google/uuid"
+	"github.com/richardwilkes/gcs/model/rolls"
+	"github.com/richardwilkes/gcs/model/theme"
+	"github.com/richardwilkes/gcs/res"
+	"github.com/richardwilkes/gcs/ui/widget"
+	"github.com/richardwilkes/toolbox/errs"
+	"github.com/richardwilkes/toolbox/i18n"
+	"github.com/richardwilkes/toolbox/xmath/rand"
+	"github.com/richardwilkes/unison"
+)
+
+const (
+	rollTimeColumn = iota
+	rollWhoColumn
+	rollWhatColumn
+	rollTargetColumn
+	rollDiceColumn
+	rollResultColumn
+	rollColumnCount
+)
+
+var (
+	_ unison.Dockable  = &RollHistoryDockable{}
+	_ unison.TabCloser = &RollHistoryDockable{}
+)
+
+// RollHistoryDockable shows the rolls made during the current session and holds the modifier to apply to the next roll.
+type RollHistoryDockable struct {
+	unison.Panel
+	modifier      int
+	modifierField *widget.IntegerField
+	scroll        *unison.ScrollPanel
+	table         *unison.Table[*rollRow]
+}
+
+type rollRow struct {
+	id     uuid.UUID
+	result *rolls.Result
+}
+
+// ShowRollHistory shows the Roll History dockable, creating it if necessary.
+func ShowRollHistory() {
+	ws, _, found := Activate(func(d unison.Dockable) bool {
+		_, ok := d.(*RollHistoryDockable)
+		return ok
+	})
+	if !found && ws != nil {
+		d := newRollHistoryDockable()
+		DisplayNewDockable(ws.Window, d)
+		d.modifierField.RequestFocus()
+	}
+}
+
+// Roll makes the requested roll, applying the modifier entered within the Roll History dockable, and records the
+// result in the roll history. The Roll History dockable is displayed if it isn't already present.
+func Roll(wnd *unison.Window, req rolls.Request) {
+	if !req.CanRoll() {
+		return
+	}
+	d := existingRollHistoryDockable()
+	if d == nil {
+		d = newRollHistoryDockable()
+		DisplayNewDockable(wnd, d)
+	}
+	result := req.Roll(rand.NewCryptoRand(), d.modifier)
+	rolls.DefaultHistory().Add(result)
+	if d.modifier != 0 {
+		// Modifiers are temporary and only apply to a single roll
+		d.modifier = 0
+		d.modifierField.Sync()
+	}
+	refreshRollHistories()
+}
+
+func existingRollHistoryDockable() *RollHistoryDockable {
+	var found *RollHistoryDockable
+	for _, wnd := range unison.Windows() {
+		if ws := FromWindow(wnd); ws != nil {
+			ws.DocumentDock.RootDockLayout().ForEachDockContainer(func(dc *unison.DockContainer) bool {
+				for _, one := range dc.Dockables() {
+					if d, ok := one.(*RollHistoryDockable); ok {
+						found = d
+						return true
+					}
+				}
+				return false
+			})
+			if found != nil {
+				return found
+			}
+		}
+	}
+	return nil
+}
+
+func refreshRollHistories() {
+	for _, wnd := range unison.Windows() {
+		if ws := FromWindow(wnd); ws != nil {
+			ws.DocumentDock.RootDockLayout().ForEachDockContainer(func(dc *unison.DockContainer) bool {
+				for _, one := range dc.Dockables() {
+					if d, ok := one.(*RollHistoryDockable); ok {
+						d.refresh()
+					}
+				}
+				return false
+			})
+		}
+	}
+}
+
+func newRollHistoryDockable() *RollHistoryDockable {
+	d := &RollHistoryDockable{
+		scroll: unison.NewScrollPanel(),
+		table:  unison.NewTable[*rollRow](&unison.SimpleTableModel[*rollRow]{}),
+	}
+	d.Self = d
+	d.SetLayout(&unison.FlexLayout{Columns: 1})
+
+	d.table.ColumnSizes = make([]unison.ColumnSize, rollColumnCount)
+	header := unison.NewTableHeader[*rollRow](d.table,
+		unison.NewTableColumnHeader[*rollRow](i18n.Text("Time"), ""),
+		unison.NewTableColumnHeader[*rollRow](i18n.Text("Character"), ""),
+		unison.NewTableColumnHeader[*rollRow](i18n.Text("Roll"), ""),
+		unison.NewTableColumnHeader[*rollRow](i18n.Text("Against"), i18n.Text("The level or damage rolled against, including any modifier")),
+		unison.NewTableColumnHeader[*rollRow](i18n.Text("Dice"), ""),
+		unison.NewTableColumnHeader[*rollRow](i18n.Text("Result"), ""),
+	)
+	d.scroll.SetColumnHeader(header)
+	d.scroll.SetContent(d.table, unison.FillBehavior, unison.FillBehavior)
+	d.scroll.SetLayoutData(&unison.FlexLayoutData{
+		HAlign: unison.FillAlignment,
+		VAlign: unison.FillAlignment,
+		HGrab:  true,
+		VGrab:  true,
+	})
+
+	modifierLabel := widget.NewFieldLeadingLabel(i18n.Text("Modifier for Next Roll"))
+	d.modifierField = widget.NewIntegerField(i18n.Text("Modifier for Next Roll"), func() int { return d.modifier },
+		func(v int) { d.modifier = v }, -99, 99, true, false)
+	d.modifierField.SetMarksModified(false)
+	d.modifierField.Tooltip = unison.NewTooltipWithText(i18n.Text("A temporary modifier that will be applied to the next roll and then reset"))
+
+	spacer := unison.NewPanel()
+	spacer.SetLayoutData(&unison.FlexLayoutData{
+		HAlign: unison.FillAlignment,
+		HGrab:  true,
+	})
+
+	exportButton := unison.NewButton()
+	exportButton.Text = i18n.Text("Export…")
+	exportButton.Tooltip = unison.NewTooltipWithText(i18n.Text("Save the roll history as a session log"))
+	exportButton.ClickCallback = d.export
+
+	clearButton := unison.NewSVGButton(res.TrashSVG)
+	clearButton.Tooltip = unison.NewTooltipWithText(i18n.Text("Clear the roll history"))
+	clearButton.ClickCallback = func() {
+		rolls.DefaultHistory().Clear()
+		refreshRollHistories()
+	}
+
+	toolbar := unison.NewPanel()
+	toolbar.SetBorder(unison.NewCompoundBorder(unison.NewLineBorder(unison.DividerColor, 0, unison.Insets{Bottom: 1},
+		false), unison.NewEmptyBorder(unison.StdInsets())))
+	toolbar.SetLayoutData(&unison.FlexLayoutData{
+		HAlign: unison.FillAlignment,
+		HGrab:  true,
+	})
+	toolbar.AddChild(modifierLabel)
+	toolbar.AddChild(d.modifierField)
+	toolbar.AddChild(spacer)
+	toolbar.AddChild(exportButton)
+	toolbar.AddChild(clearButton)
+	toolbar.SetLayout(&unison.FlexLayout{
+		Columns:  len(toolbar.Children()),
+		HSpacing: unison.StdHSpacing,
+	})
+
+	d.AddChild(toolbar)
+	d.AddChild(d.scroll)
+	d.refresh()
+	return d
+}
+
+func (d *RollHistoryDockable) refresh() {
+	results := rolls.DefaultHistory().Results()
+	rows := make([]*rollRow, len(results))
+	for i, result := range results {
+		// Most recent first
+		rows[len(results)-1-i] = &rollRow{
+			id:     uuid.New(),
+			result: result,
+		}
+	}
+	d.table.SetRootRows(rows)
+	d.table.SizeColumnsToFit(true)
+	d.scroll.SetPosition(0, 0)
+}
+
+func (d *RollHistoryDockable) export() {
+	dialog := unison.NewSaveDialog()
+	dialog.SetAllowedExtensions("txt")
+	if dialog.RunModal() {
+		if err := exportRollHistory(dialog.Path()); err != nil {
+			unison.ErrorDialogWithError(i18n.Text("Unable to export the roll history"), err)
+		}
+	}
+}
+
+func exportRollHistory(filePath string) error {
+	f, err := os.Create(filePath)
+	if err != nil {
+		return errs.Wrap(err)
+	}
+	if err = rolls.DefaultHistory().Export(f); err != nil {
+		_ = f.Close() //nolint:errcheck // The export error is more relevant
+		return err
+	}
+	return errs.Wrap(f.Close())
+}
+
+// TitleIcon implements unison.Dockable
+func (d *RollHistoryDockable) TitleIcon(suggestedSize unison.Size) unison.Drawable {
+	return &unison.DrawableSVG{
+		SVG:  res.RandomizeSVG,
+		Size: suggestedSize,
+	}
+}
+
+// Title implements unison.Dockable
+func (d *RollHistoryDockable) Title() string {
+	return i18n.Text("Roll History")
+}
+
+// Tooltip implements unison.Dockable
+func (d *RollHistoryDockable) Tooltip() string {
+	return ""
+}
+
+// Modified implements unison.Dockable
+func (d *RollHistoryDockable) Modified() bool {
+	return false
+}
+
+// MayAttemptClose implements unison.TabCloser
+func (d *RollHistoryDockable) MayAttemptClose() bool {
+	return true
+}
+
+// AttemptClose implements unison.TabCloser
+func (d *RollHistoryDockable) AttemptClose() bool {
+	if dc := unison.Ancestor[*unison.DockContainer](d); dc != nil {
+		dc.Close(d)
+	}
+	return true
+}
+
+// CloneForTarget implements unison.TableRowData. Not permitted.
+func (r *rollRow) CloneForTarget(_ unison.Paneler, _ *rollRow) *rollRow {
+	return nil
+}
+
+// UUID implements unison.TableRowData.
+func (r *rollRow) UUID() uuid.UUID {
+	return r.id
+}
+
+// Parent implements unison.TableRowData.
+func (r *rollRow) Parent() *rollRow {
+	return nil
+}
+
+// SetParent implements unison.TableRowData.
+func (r *rollRow) SetParent(_ *rollRow) {
+}
+
+// CanHaveChildren implements unison.TableRowData.
+func (r *rollRow) CanHaveChildren() bool {
+	return false
+}
+
+// Children implements unison.TableRowData.
+func (r *rollRow) Children() []*rollRow {
+	return nil
+}
+
+// SetChildren implements unison.TableRowData.
+func (r *rollRow) SetChildren(_ []*rollRow) {
+}
+
+// CellDataForSort implements unison.TableRowData.
+func (r *rollRow) CellDataForSort(col int) string {
+	switch col {
+	case rollTimeColumn:
+		return r.result.When.Format("15:04:05")
+	case rollWhoColumn:
+		return r.result.Who
+	case rollWhatColumn:
+		return r.result.What
+	case rollTargetColumn:
+		return r.result.TargetText()
+	case rollDiceColumn:
+		return r.result.DiceText()
+	case rollResultColumn:
+		return r.result.OutcomeText()
+	default:
+		return ""
+	}
+}
+
+// ColumnCell implements unison.TableRowData.
+func (r *rollRow) ColumnCell(_, col int, foreground, _ unison.Ink, _, _, _ bool) unison.Paneler {
+	label := unison.NewLabel()
+	label.LabelTheme.OnBackgroundInk = foreground
+	label.Text = r.CellDataForSort(col)
+	switch col {
+	case rollTargetColumn, rollDiceColumn:
+		label.HAlign = unison.EndAlignment
+	case rollResultColumn:
+		switch r.result.Outcome {
+		case rolls.CriticalSuccess:
+			label.Font = unison.EmphasizedSystemFont
+			label.LabelTheme.OnBackgroundInk = theme.AccentColor
+		case rolls.CriticalFailure:
+			label.Font = unison.EmphasizedSystemFont
+			label.LabelTheme.OnBackgroundInk = unison.ErrorColor
+		}
+	}
+	return label
+}
+
+// IsOpen implements unison.TableRowData.
+func (r *rollRow) IsOpen() bool {
+	return false
+}
+
+// SetOpen implements unison.TableRowData.
+func (r *rollRow) SetOpen(_ bool) {
+}
@@ -38,6 +38,7 @@ type generalSettingsDockable struct {
 	initialListScaleField               *widget.PercentageField
 	initialSheetScaleField              *widget.PercentageField
 	exportResolutionField               *widget.IntegerField
+	revisionsToKeepField                *widget.IntegerField
 	tooltipDelayField                   *widget.DecimalField
 	tooltipDismissalField               *widget.DecimalField
 	gCalcKeyField                       *widget.StringField
@@ -87,6 +88,7 @@ func (d *generalSettingsDockable) initContent(content *unison.Panel) {
 		gsettings.InitialUIScaleMin, gsettings.InitialUIScaleMax, false, false)
 	content.AddChild(widget.WrapWithSpan(2, d.initialSheetScaleField))
 	d.createImageResolutionField(content)
+	d.createRevisionsToKeepField(content)
 	d.createTooltipDelayField(content)
 	d.createTooltipDismissalField(content)
 	d.createGCalcKeyField(content)
@@ -158,6 +160,17 @@ func (d *generalSettingsDockable) createImageResolutionField(content *unison.Pan
 	content.AddChild(widget.WrapWithSpan(2, d.exportResolutionField, widget.NewFieldTrailingLabel(i18n.Text("ppi"))))
 }
 
+func (d *generalSettingsDockable) createRevisionsToKeepField(content *unison.Panel) {
+	title := i18n.Text("Revisions to Keep")
+	content.AddChild(widget.NewFieldLeadingLabel(title))
+	d.revisionsToKeepField = widget.NewIntegerField(title,
+		func() int { return settings.Global().General.RevisionsToKeep },
+		func(v int) { settings.Global().General.RevisionsToKeep = v },
+		gsettings.RevisionsToKeepMin, gsettings.RevisionsToKeepMax, false, false)
+	d.revisionsToKeepField.Tooltip = unison.NewTooltipWithText(i18n.Text("The number of prior revisions of each file to keep when saving"))
+	content.AddChild(widget.WrapWithSpan(2, d.revisionsToKeepField, widget.NewFieldTrailingLabel(i18n.Text("per file"))))
+}
+
 func (d *generalSettingsDockable) createTooltipDelayField(content *unison.Panel) {
 	title := i18n.Text("Tooltip Delay")
 	content.AddChild(widget.NewFieldLeadingLabel(title))
@@ -222,6 +235,7 @@ func (d *generalSettingsDockable) sync() {
 	widget.SetFieldValue(d.initialListScaleField.Field, d.initialListScaleField.Format(s.InitialListUIScale))
 	widget.SetFieldValue(d.initialSheetScaleField.Field, d.initialSheetScaleField.Format(s.InitialSheetUIScale))
 	d.exportResolutionField.SetText(strconv.Itoa(s.ImageResolution))
+	d.revisionsToKeepField.SetText(strconv.Itoa(s.RevisionsToKeep))
 	d.tooltipDelayField.SetText(s.TooltipDelay.String())
 	d.tooltipDismissalField.SetText(s.TooltipDismissal.String())
 	d.gCalcKeyField.SetText(s.GCalcKey)
@@ -0,0 +1,367 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package settings
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/richardwilkes/gcs/model/library"
+	"github.com/richardwilkes/gcs/model/settings"
+	"github.com/richardwilkes/gcs/res"
+	"github.com/richardwilkes/gcs/ui/workspace"
+	"github.com/richardwilkes/toolbox/errs"
+	"github.com/richardwilkes/toolbox/i18n"
+	"github.com/richardwilkes/toolbox/log/jot"
+	"github.com/richardwilkes/unison"
+	"golang.org/x/exp/slices"
+)
+
+const libraryLocationColumns = 9
+
+type libraryLocationRow struct {
+	lib        *library.Library
+	title      string
+	local      bool
+	sourceType library.ReleaseSourceType
+	repo       string
+	url        string
+	path       string
+}
+
+type libraryLocations struct {
+	dialog  *unison.Dialog
+	content *unison.Panel
+	status  *unison.Label
+	rows    []*libraryLocationRow
+}
+
+// ShowLibraryLocations displays a dialog that allows the user to add, remove, reorder and relocate libraries.
+func ShowLibraryLocations() {
+	d := &libraryLocations{}
+	for _, lib := range settings.Global().LibrarySet.List() {
+		row := &libraryLocationRow{
+			lib:        lib,
+			title:      lib.Title,
+			local:      !lib.HasReleaseSource(),
+			sourceType: lib.SourceType.EnsureValid(),
+			url:        lib.SourceURL,
+			path:       lib.PathOnDisk,
+		}
+		if lib.HasRepoKey() {
+			row.repo = lib.Key()
+		}
+		d.rows = append(d.rows, row)
+	}
+	d.content = unison.NewPanel()
+	d.content.SetBorder(unison.NewEmptyBorder(unison.NewUniformInsets(unison.StdHSpacing)))
+	d.content.SetLayout(&unison.FlexLayout{
+		Columns:  libraryLocationColumns,
+		HSpacing: unison.StdHSpacing,
+		VSpacing: unison.StdVSpacing,
+	})
+	d.fill()
+	scroller := unison.NewScrollPanel()
+	scroller.SetContent(d.content, unison.HintedFillBehavior, unison.FillBehavior)
+	scroller.SetLayoutData(&unison.FlexLayoutData{
+		MinSize: unison.Size{Width: 600, Height: 200},
+		HAlign:  unison.FillAlignment,
+		VAlign:  unison.FillAlignment,
+		HGrab:   true,
+		VGrab:   true,
+	})
+	panel := unison.NewPanel()
+	panel.SetLayout(&unison.FlexLayout{
+		Columns:  1,
+		HSpacing: unison.StdHSpacing,
+		VSpacing: unison.StdVSpacing,
+	})
+	label := unison.NewLabel()
+	label.Text = i18n.Text("Libraries higher in the list take precedence when files with the same name exist in more than one.")
+	panel.AddChild(label)
+	panel.AddChild(scroller)
+	addButton := unison.NewButton()
+	addButton.Text = i18n.Text("Add Library")
+	addButton.ClickCallback = d.addRow
+	panel.AddChild(addButton)
+	d.status = unison.NewLabel()
+	d.status.OnBackgroundInk = unison.ErrorColor
+	d.status.SetLayoutData(&unison.FlexLayoutData{
+		HAlign: unison.FillAlignment,
+		HGrab:  true,
+	})
+	panel.AddChild(d.status)
+	var err error
+	if d.dialog, err = unison.NewDialog(nil, nil, panel, []*unison.DialogButtonInfo{
+		unison.NewCancelButtonInfo(),
+		unison.NewOKButtonInfoWithTitle(i18n.Text("Apply")),
+	}); err != nil {
+		jot.Error(err)
+		return
+	}
+	d.dialog.Window().SetTitle(i18n.Text("Library Locations"))
+	d.validate()
+	if d.dialog.RunModal() == unison.ModalResponseOK {
+		if err = d.apply(); err != nil {
+			unison.ErrorDialogWithError(i18n.Text("Unable to update the library locations"), err)
+		}
+	}
+}
+
+func (d *libraryLocations) fill() {
+	for _, title := range []string{"", "", i18n.Text("Title"), i18n.Text("Source"), i18n.Text("Repository"),
+		i18n.Text("Server URL"), i18n.Text("Location"), "", ""} {
+		label := unison.NewLabel()
+		label.Text = title
+		d.content.AddChild(label)
+	}
+	for i, row := range d.rows {
+		d.addMoveButton(res.CaretUpSVG, i18n.Text("Move up"), i, i-1)
+		d.addMoveButton(res.CaretDownSVG, i18n.Text("Move down"), i, i+1)
+		builtIn := row.lib != nil && (row.lib.IsMaster() || row.lib.IsUser())
+		d.addRowField(&row.title, 150, builtIn)
+		d.addSourcePopup(row, builtIn)
+		d.addRowField(&row.repo, 150, builtIn || row.local || !row.sourceType.UsesRepo())
+		d.addRowField(&row.url, 150, row.local)
+		d.addRowField(&row.path, 250, false)
+		d.addChooseFolderButton(row)
+		d.addRemoveButton(i, builtIn)
+	}
+}
+
+func (d *libraryLocations) sync() {
+	d.content.RemoveAllChildren()
+	d.fill()
+	d.content.MarkForLayoutRecursivelyUpward()
+	d.content.MarkForRedraw()
+	d.validate()
+}
+
+func (d *libraryLocations) addMoveButton(svg *unison.SVG, tooltip string, from, to int) {
+	b := unison.NewSVGButton(svg)
+	b.Tooltip = unison.NewTooltipWithText(tooltip)
+	b.SetEnabled(to >= 0 && to < len(d.rows))
+	b.ClickCallback = func() {
+		d.rows[from], d.rows[to] = d.rows[to], d.rows[from]
+		d.sync()
+	}
+	b.SetLayoutData(&unison.FlexLayoutData{VAlign: unison.MiddleAlignment})
+	d.content.AddChild(b)
+}
+
+func (d *libraryLocations) addRowField(value *string, minWidth float32, disabled bool) {
+	field := unison.NewField()
+	field.SetText(*value)
+	field.SetEnabled(!disabled)
+	field.ModifiedCallback = func() {
+		*value = field.Text()
+		d.validate()
+	}
+	field.SetLayoutData(&unison.FlexLayoutData{
+		MinSize: unison.Size{Width: minWidth},
+		HAlign:  unison.FillAlignment,
+		VAlign:  unison.MiddleAlignment,
+		HGrab:   true,
+	})
+	d.content.AddChild(field)
+}
+
+func (d *libraryLocations) addSourcePopup(row *libraryLocationRow, disabled bool) {
+	p := unison.NewPopupMenu[string]()
+	p.AddItem(i18n.Text("Local Folder"))
+	for _, one := range library.AllReleaseSourceTypes {
+		p.AddItem(one.String())
+	}
+	if row.local {
+		p.SelectIndex(0)
+	} else {
+		p.SelectIndex(1 + slices.Index(library.AllReleaseSourceTypes, row.sourceType))
+	}
+	p.SetEnabled(!disabled)
+	p.SelectionCallback = func(index int, _ string) {
+		row.local = index == 0
+		if !row.local {
+			row.sourceType = library.AllReleaseSourceTypes[index-1]
+		}
+		d.sync()
+	}
+	p.SetLayoutData(&unison.FlexLayoutData{VAlign: unison.MiddleAlignment})
+	d.content.AddChild(p)
+}
+
+func (d *libraryLocations) addChooseFolderButton(row *libraryLocationRow) {
+	b := unison.NewSVGButton(res.ClosedFolderSVG)
+	b.Tooltip = unison.NewTooltipWithText(i18n.Text("Choose a folder"))
+	b.ClickCallback = func() {
+		dialog := unison.NewOpenDialog()
+		dialog.SetResolvesAliases(true)
+		dialog.SetAllowsMultipleSelection(false)
+		dialog.SetCanChooseDirectories(true)
+		dialog.SetCanChooseFiles(false)
+		if row.path != "" {
+			dialog.SetInitialDirectory(row.path)
+		}
+		if dialog.RunModal() {
+			row.path = dialog.Path()
+			d.sync()
+		}
+	}
+	b.SetLayoutData(&unison.FlexLayoutData{VAlign: unison.MiddleAlignment})
+	d.content.AddChild(b)
+}
+
+func (d *libraryLocations) addRemoveButton(index int, disabled bool) {
+	b := unison.NewSVGButton(res.TrashSVG)
+	b.Tooltip = unison.NewTooltipWithText(i18n.Text("Remove this library"))
+	b.SetEnabled(!disabled)
+	b.ClickCallback = func() {
+		d.rows = append(d.rows[:index], d.rows[index+1:]...)
+		d.sync()
+	}
+	b.SetLayoutData(&unison.FlexLayoutData{VAlign: unison.MiddleAlignment})
+	d.content.AddChild(b)
+}
+
+func (d *libraryLocations) addRow() {
+	d.rows = append(d.rows, &libraryLocationRow{
+		title: i18n.Text("New Library"),
+		local: true,
+	})
+	d.sync()
+}
+
+func (d *libraryLocations) validate() {
+	problem := d.check()
+	d.status.Text = problem
+	d.status.MarkForRedraw()
+	if d.dialog != nil {
+		d.dialog.Button(unison.ModalResponseOK).SetEnabled(problem == "")
+	}
+}
+
+// check verifies the rows, returning a description of the first problem found, if any.
+func (d *libraryLocations) check() string {
+	keys := make(map[string]bool)
+	paths := make(map[string]bool)
+	for _, row := range d.rows {
+		title := strings.TrimSpace(row.title)
+		if title == "" {
+			return i18n.Text("Every library must have a title")
+		}
+		if strings.TrimSpace(row.path) == "" {
+			return fmt.Sprintf(i18n.Text("%s must have a location"), title)
+		}
+		p, err := filepath.Abs(row.path)
+		if err != nil {
+			return fmt.Sprintf(i18n.Text("%s has an invalid location"), title)
+		}
+		if paths[p] {
+			return fmt.Sprintf(i18n.Text("%s uses the same location as another library"), title)
+		}
+		paths[p] = true
+		if row.local {
+			continue
+		}
+		if row.sourceType != library.GitHubReleaseSource {
+			if u, err := url.Parse(strings.TrimSpace(row.url)); err != nil || (u.Scheme != "http" && u.Scheme != "https") ||
+				u.Host == "" {
+				return fmt.Sprintf(i18n.Text("%s must specify the URL of its server"), title)
+			}
+		}
+		if row.sourceType.UsesRepo() {
+			key := row.releaseKey()
+			if key == "" {
+				return fmt.Sprintf(i18n.Text("%s must specify its repository as account/repo"), title)
+			}
+			if keys[key] {
+				return fmt.Sprintf(i18n.Text("%s uses the same repository as another library"), title)
+			}
+			keys[key] = true
+		}
+	}
+	return ""
+}
+
+// releaseKey returns the library key for a row tied to a release source, or an empty string if the repository
+// specification is invalid.
+func (r *libraryLocationRow) releaseKey() string {
+	parts := strings.Split(strings.TrimSpace(r.repo), "/")
+	if len(parts) != 2 {
+		return ""
+	}
+	account := strings.TrimSpace(parts[0])
+	repo := strings.TrimSpace(parts[1])
+	if account == "" || account == "*" || repo == "" {
+		return ""
+	}
+	return account + "/" + repo
+}
+
+func (d *libraryLocations) apply() error {
+	if problem := d.check(); problem != "" {
+		return errs.New(problem)
+	}
+	// Rows that keep an established key are placed first, so that keys generated for new local libraries never
+	// collide with them.
+	libs := make(library.Libraries)
+	keys := make([]string, len(d.rows))
+	for i, row := range d.rows {
+		switch {
+		case !row.local && row.sourceType.UsesRepo():
+			keys[i] = row.releaseKey()
+		case row.lib != nil && !row.lib.HasRepoKey():
+			keys[i] = row.lib.Key()
+		default:
+			continue
+		}
+		libs[keys[i]] = row.lib
+	}
+	for i, row := range d.rows {
+		if keys[i] == "" {
+			keys[i] = libs.NewLocalKey(row.title)
+			libs[keys[i]] = nil
+		}
+	}
+	for i, row := range d.rows {
+		lib := row.lib
+		if lib == nil || lib.Key() != keys[i] {
+			lib = &library.Library{}
+			lib.ConfigureForKey(keys[i])
+		}
+		lib.Title = strings.TrimSpace(row.title)
+		lib.Order = i + 1
+		if row.local {
+			lib.SourceType = ""
+			lib.SourceURL = ""
+		} else {
+			lib.SourceType = row.sourceType
+			lib.SourceURL = strings.TrimSpace(row.url)
+		}
+		if err := lib.SetPath(row.path); err != nil {
+			return err
+		}
+		libs[keys[i]] = lib
+	}
+	s := settings.Global()
+	s.LibrarySet = libs
+	s.LibrarySet.Master()
+	s.LibrarySet.User()
+	if err := s.Save(); err != nil {
+		jot.Error(err)
+	}
+	workspace.MonitorLibraries()
+	workspace.RefreshLibraries()
+	go libs.PerformUpdateChecks()
+	return nil
+}
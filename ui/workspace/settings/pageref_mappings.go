@@ -43,13 +43,23 @@ func ExtractPageReferences(s string) []string {
 	return list
 }
 
-// OpenPageReference opens the given page reference in the given window, which should contain a workspace. May pass nil
-// for wnd to let it pick the first such window it discovers. Returns true if the the user asked to cancel further
-// processing.
-func OpenPageReference(wnd *unison.Window, ref, highlight string, promptContext map[string]bool) bool {
+// PageRefScope is implemented by dockables that provide page reference mappings for the files they group together,
+// which take precedence over the global mappings when opening page references from those files.
+type PageRefScope interface {
+	ScopedPageRef(filePath, key string) *settings.PageRef
+}
+
+// OpenPageReference opens the given page reference in the window holding the panel it was requested from, which should
+// contain a workspace. May pass nil for from to let it pick the first such window it discovers. Returns true if the the
+// user asked to cancel further processing.
+func OpenPageReference(from unison.Paneler, ref, highlight string, promptContext map[string]bool) bool {
 	if promptContext == nil {
 		promptContext = make(map[string]bool)
 	}
+	var wnd *unison.Window
+	if from != nil {
+		wnd = from.AsPanel().Window()
+	}
 	i := len(ref) - 1
 	for i >= 0 {
 		ch := ref[i]
@@ -67,7 +77,10 @@ func OpenPageReference(wnd *unison.Window, ref, highlight string, promptContext
 		}
 		key := ref[:i]
 		s := settings.Global()
-		pageRef := s.PageRefs.Lookup(key)
+		pageRef := scopedPageRef(from, key)
+		if pageRef == nil {
+			pageRef = s.PageRefs.Lookup(key)
+		}
 		if pageRef == nil && !promptContext[key] {
 			pdfName := PageRefKeyToName(key)
 			if pdfName != "" {
@@ -109,6 +122,38 @@ Would you like to create one by choosing a PDF to map to this key?`), key), pdfN
 	return false
 }
 
+// scopedPageRef returns the mapping for the key provided by an open PageRefScope for the file the panel belongs to, if
+// any.
+func scopedPageRef(from unison.Paneler, key string) *settings.PageRef {
+	if from == nil {
+		return nil
+	}
+	owner := unison.AncestorOrSelf[workspace.FileBackedDockable](from)
+	if owner == nil {
+		return nil
+	}
+	filePath := owner.BackingFilePath()
+	var found *settings.PageRef
+	for _, wnd := range unison.Windows() {
+		if ws := workspace.FromWindow(wnd); ws != nil {
+			ws.DocumentDock.RootDockLayout().ForEachDockContainer(func(dc *unison.DockContainer) bool {
+				for _, one := range dc.Dockables() {
+					if scope, ok := one.(PageRefScope); ok {
+						if found = scope.ScopedPageRef(filePath, key); found != nil {
+							return true
+						}
+					}
+				}
+				return false
+			})
+			if found != nil {
+				break
+			}
+		}
+	}
+	return found
+}
+
 // RefreshPageRefMappingsView causes the Page References Mappings view to be refreshed if it is open.
 func RefreshPageRefMappingsView() {
 	ws := workspace.Any()
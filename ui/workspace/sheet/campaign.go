@@ -0,0 +1,814 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package sheet
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/google/uuid"
+	"github.com/richardwilkes/gcs/constants"
+	"github.com/richardwilkes/gcs/model/campaign"
+	"github.com/richardwilkes/gcs/model/fxp"
+	"github.com/richardwilkes/gcs/model/gurps"
+	"github.com/richardwilkes/gcs/model/gurps/gid"
+	gsettings "github.com/richardwilkes/gcs/model/gurps/settings"
+	"github.com/richardwilkes/gcs/model/jio"
+	"github.com/richardwilkes/gcs/model/library"
+	"github.com/richardwilkes/gcs/model/settings"
+	"github.com/richardwilkes/gcs/res"
+	"github.com/richardwilkes/gcs/ui/widget"
+	"github.com/richardwilkes/gcs/ui/workspace"
+	wsettings "github.com/richardwilkes/gcs/ui/workspace/settings"
+	"github.com/richardwilkes/toolbox/i18n"
+	"github.com/richardwilkes/toolbox/log/jot"
+	"github.com/richardwilkes/toolbox/xio/fs"
+	"github.com/richardwilkes/unison"
+)
+
+const sheetSettingsExt = ".sheet"
+
+const (
+	memberNameColumn = iota
+	memberPlayerColumn
+	memberSTColumn
+	memberDXColumn
+	memberIQColumn
+	memberHTColumn
+	memberHPColumn
+	memberFPColumn
+	memberSpeedColumn
+	memberMoveColumn
+	memberPointsColumn
+	memberUnspentColumn
+	memberWealthColumn
+	memberOtherWealthColumn
+	memberColumnCount
+)
+
+var (
+	_ workspace.FileBackedDockable   = &Campaign{}
+	_ workspace.RetargetableDockable = &Campaign{}
+	_ workspace.Recoverable          = &Campaign{}
+	_ unison.UndoManagerProvider     = &Campaign{}
+	_ widget.ModifiableRoot          = &Campaign{}
+	_ unison.TabCloser               = &Campaign{}
+	_ wsettings.PageRefScope         = &Campaign{}
+)
+
+// Campaign holds the view for a campaign, which groups character sheets that share settings.
+type Campaign struct {
+	unison.Panel
+	path              string
+	undoMgr           *unison.UndoManager
+	campaign          *campaign.Campaign
+	crc               uint64
+	calendarPopup     *unison.PopupMenu[calendarChoice]
+	totalsLabel       *unison.Label
+	scroll            *unison.ScrollPanel
+	table             *unison.Table[*memberRow]
+	needsSaveAsPrompt bool
+}
+
+type memberRow struct {
+	id     uuid.UUID
+	path   string
+	entity *gurps.Entity
+	err    error
+}
+
+type calendarChoice struct {
+	name  string
+	title string
+}
+
+func (c calendarChoice) String() string {
+	return c.title
+}
+
+// NewCampaignFromFile loads a campaign file and creates a new unison.Dockable for it.
+func NewCampaignFromFile(filePath string) (unison.Dockable, error) {
+	c, err := campaign.NewCampaignFromFile(os.DirFS(filepath.Dir(filePath)), filepath.Base(filePath))
+	if err != nil {
+		return nil, err
+	}
+	d := NewCampaign(filePath, c)
+	d.needsSaveAsPrompt = false
+	return d, nil
+}
+
+// NewCampaign creates a new unison.Dockable for campaign files.
+func NewCampaign(filePath string, c *campaign.Campaign) *Campaign {
+	d := &Campaign{
+		path:              filePath,
+		undoMgr:           unison.NewUndoManager(200, func(err error) { jot.Error(err) }),
+		campaign:          c,
+		crc:               c.CRC64(),
+		totalsLabel:       unison.NewLabel(),
+		scroll:            unison.NewScrollPanel(),
+		table:             unison.NewTable[*memberRow](&unison.SimpleTableModel[*memberRow]{}),
+		needsSaveAsPrompt: true,
+	}
+	d.Self = d
+	d.SetLayout(&unison.FlexLayout{Columns: 1})
+
+	d.table.ColumnSizes = make([]unison.ColumnSize, memberColumnCount)
+	d.table.DoubleClickCallback = d.openSelection
+	header := unison.NewTableHeader[*memberRow](d.table,
+		unison.NewTableColumnHeader[*memberRow](i18n.Text("Name"), ""),
+		unison.NewTableColumnHeader[*memberRow](i18n.Text("Player"), ""),
+		unison.NewTableColumnHeader[*memberRow](i18n.Text("ST"), i18n.Text("Strength")),
+		unison.NewTableColumnHeader[*memberRow](i18n.Text("DX"), i18n.Text("Dexterity")),
+		unison.NewTableColumnHeader[*memberRow](i18n.Text("IQ"), i18n.Text("Intelligence")),
+		unison.NewTableColumnHeader[*memberRow](i18n.Text("HT"), i18n.Text("Health")),
+		unison.NewTableColumnHeader[*memberRow](i18n.Text("HP"), i18n.Text("Current and maximum Hit Points")),
+		unison.NewTableColumnHeader[*memberRow](i18n.Text("FP"), i18n.Text("Current and maximum Fatigue Points")),
+		unison.NewTableColumnHeader[*memberRow](i18n.Text("Speed"), i18n.Text("Basic Speed")),
+		unison.NewTableColumnHeader[*memberRow](i18n.Text("Move"), i18n.Text("Move at the current encumbrance level")),
+		unison.NewTableColumnHeader[*memberRow](i18n.Text("Points"), i18n.Text("Total points")),
+		unison.NewTableColumnHeader[*memberRow](i18n.Text("Unspent"), i18n.Text("Unspent points")),
+		unison.NewTableColumnHeader[*memberRow](i18n.Text("Wealth"), i18n.Text("The value of the equipment being carried")),
+		unison.NewTableColumnHeader[*memberRow](i18n.Text("Other Wealth"), i18n.Text("The value of the equipment not being carried")),
+	)
+	d.scroll.SetColumnHeader(header)
+	d.scroll.SetContent(d.table, unison.FillBehavior, unison.FillBehavior)
+	d.scroll.SetLayoutData(&unison.FlexLayoutData{
+		HAlign: unison.FillAlignment,
+		VAlign: unison.FillAlignment,
+		HGrab:  true,
+		VGrab:  true,
+	})
+
+	footer := unison.NewPanel()
+	footer.SetBorder(unison.NewCompoundBorder(unison.NewLineBorder(unison.DividerColor, 0, unison.Insets{Top: 1},
+		false), unison.NewEmptyBorder(unison.StdInsets())))
+	footer.SetLayoutData(&unison.FlexLayoutData{
+		HAlign: unison.FillAlignment,
+		HGrab:  true,
+	})
+	footer.SetLayout(&unison.FlexLayout{Columns: 1})
+	footer.AddChild(d.totalsLabel)
+
+	d.AddChild(d.createToolbar())
+	d.AddChild(d.scroll)
+	d.AddChild(footer)
+	d.sync()
+
+	d.InstallCmdHandlers(constants.SaveItemID, func(_ any) bool { return d.Modified() }, func(_ any) { d.save(false) })
+	d.InstallCmdHandlers(constants.SaveAsItemID, unison.AlwaysEnabled, func(_ any) { d.save(true) })
+	return d
+}
+
+func (d *Campaign) createToolbar() *unison.Panel {
+	addButton := unison.NewSVGButton(res.CircledAddSVG)
+	addButton.Tooltip = unison.NewTooltipWithText(i18n.Text("Add a member from an open sheet or a sheet file"))
+	addButton.ClickCallback = func() { d.showAddMenu(addButton) }
+
+	removeButton := unison.NewSVGButton(res.TrashSVG)
+	removeButton.Tooltip = unison.NewTooltipWithText(i18n.Text("Remove the selected members from the campaign"))
+	removeButton.ClickCallback = d.removeSelection
+
+	settingsButton := unison.NewSVGButton(res.SettingsSVG)
+	settingsButton.Tooltip = unison.NewTooltipWithText(i18n.Text("Choose the settings shared by the members"))
+	settingsButton.ClickCallback = func() { d.showSettingsMenu(settingsButton) }
+
+	applyButton := unison.NewButton()
+	applyButton.Text = i18n.Text("Apply to Members")
+	applyButton.Tooltip = unison.NewTooltipWithText(i18n.Text("Replace the attributes, body type and damage progression of each member with the shared ones, opening any member sheets that aren't already open"))
+	applyButton.ClickCallback = d.applyToMembers
+
+	refreshButton := unison.NewButton()
+	refreshButton.Text = i18n.Text("Refresh")
+	refreshButton.Tooltip = unison.NewTooltipWithText(i18n.Text("Update the party summary from the member sheets"))
+	refreshButton.ClickCallback = d.refresh
+
+	d.calendarPopup = unison.NewPopupMenu[calendarChoice]()
+	d.calendarPopup.Tooltip = unison.NewTooltipWithText(i18n.Text("The calendar used by the members of the campaign while it is open"))
+	d.calendarPopup.AddItem(calendarChoice{title: i18n.Text("Use Current Calendar")})
+	for _, lib := range gsettings.AvailableCalendarRefs(settings.Global().Libraries()) {
+		d.calendarPopup.AddDisabledItem(calendarChoice{title: lib.Name})
+		for _, one := range lib.List {
+			d.calendarPopup.AddItem(calendarChoice{name: one.Name, title: one.Name})
+		}
+	}
+	d.calendarPopup.SelectionCallback = func(_ int, item calendarChoice) {
+		if item.name != d.campaign.CalendarName {
+			d.performEdit(i18n.Text("Change Calendar"), func() bool {
+				d.campaign.CalendarName = item.name
+				return true
+			})
+		}
+	}
+
+	spacer := unison.NewPanel()
+	spacer.SetLayoutData(&unison.FlexLayoutData{
+		HAlign: unison.FillAlignment,
+		HGrab:  true,
+	})
+
+	toolbar := unison.NewPanel()
+	toolbar.SetBorder(unison.NewCompoundBorder(unison.NewLineBorder(unison.DividerColor, 0, unison.Insets{Bottom: 1},
+		false), unison.NewEmptyBorder(unison.StdInsets())))
+	toolbar.SetLayoutData(&unison.FlexLayoutData{
+		HAlign: unison.FillAlignment,
+		HGrab:  true,
+	})
+	toolbar.AddChild(widget.NewFieldLeadingLabel(i18n.Text("Calendar")))
+	toolbar.AddChild(d.calendarPopup)
+	toolbar.AddChild(settingsButton)
+	toolbar.AddChild(applyButton)
+	toolbar.AddChild(spacer)
+	toolbar.AddChild(refreshButton)
+	toolbar.AddChild(addButton)
+	toolbar.AddChild(removeButton)
+	toolbar.SetLayout(&unison.FlexLayout{
+		Columns:  len(toolbar.Children()),
+		HSpacing: unison.StdHSpacing,
+		VAlign:   unison.MiddleAlignment,
+	})
+	return toolbar
+}
+
+func (d *Campaign) sync() {
+	index := 0
+	if d.campaign.CalendarName != "" {
+		for i := 1; i < d.calendarPopup.ItemCount(); i++ {
+			if item, ok := d.calendarPopup.ItemAt(i); ok && item.name == d.campaign.CalendarName {
+				index = i
+				break
+			}
+		}
+	}
+	d.calendarPopup.SelectIndex(index)
+	d.refresh()
+	d.MarkModified()
+}
+
+// refresh reloads the party summary. Members that are open use the data from their sheet, so unsaved changes are
+// reflected, while the rest are read from disk.
+func (d *Campaign) refresh() {
+	open := make(map[string]*Sheet)
+	for _, s := range openSheets() {
+		open[filepath.Clean(s.BackingFilePath())] = s
+	}
+	paths := d.campaign.MemberPaths(d.path)
+	rows := make([]*memberRow, len(paths))
+	var points, wealth, otherWealth fxp.Int
+	for i, p := range paths {
+		row := &memberRow{
+			id:   uuid.NewSHA1(d.campaign.ID, []byte(filepath.ToSlash(p))),
+			path: p,
+		}
+		if s, ok := open[filepath.Clean(p)]; ok {
+			row.entity = s.Entity()
+		} else {
+			row.entity, row.err = gurps.NewEntityFromFile(os.DirFS(filepath.Dir(p)), filepath.Base(p))
+		}
+		if row.entity != nil {
+			points += row.entity.TotalPoints
+			wealth += row.entity.WealthCarried()
+			otherWealth += row.entity.WealthNotCarried()
+		}
+		rows[i] = row
+	}
+	selMap := d.table.CopySelectionMap()
+	d.table.SetRootRows(rows)
+	d.table.SetSelectionMap(selMap)
+	d.table.SizeColumnsToFit(true)
+	d.totalsLabel.Text = fmt.Sprintf(i18n.Text("Party of %d: %s points, $%s wealth carried, $%s wealth not carried"),
+		len(rows), points.String(), wealth.String(), otherWealth.String())
+	d.totalsLabel.MarkForLayoutAndRedraw()
+}
+
+// performEdit calls 'edit', which should alter the campaign and return true if it did so. If it did, an undo edit with
+// the given name that restores the campaign to its prior state is then registered.
+func (d *Campaign) performEdit(name string, edit func() bool) {
+	before, err := d.snapshot()
+	if err != nil {
+		jot.Error(err)
+		return
+	}
+	if !edit() {
+		return
+	}
+	var after *campaignSnapshot
+	if after, err = d.snapshot(); err != nil {
+		jot.Error(err)
+	} else {
+		d.undoMgr.Add(&unison.UndoEdit[*campaignSnapshot]{
+			ID:         unison.NextUndoID(),
+			EditName:   name,
+			UndoFunc:   func(edit *unison.UndoEdit[*campaignSnapshot]) { edit.BeforeData.apply() },
+			RedoFunc:   func(edit *unison.UndoEdit[*campaignSnapshot]) { edit.AfterData.apply() },
+			BeforeData: before,
+			AfterData:  after,
+		})
+	}
+	d.sync()
+}
+
+type campaignSnapshot struct {
+	owner *Campaign
+	data  []byte
+}
+
+func (d *Campaign) snapshot() (*campaignSnapshot, error) {
+	var buffer bytes.Buffer
+	if err := jio.Save(context.Background(), &buffer, d.campaign); err != nil {
+		return nil, err
+	}
+	return &campaignSnapshot{
+		owner: d,
+		data:  buffer.Bytes(),
+	}, nil
+}
+
+func (s *campaignSnapshot) apply() {
+	var c campaign.Campaign
+	if err := jio.Load(context.Background(), bytes.NewReader(s.data), &c); err != nil {
+		jot.Error(err)
+		return
+	}
+	c.SheetSettings.SetOwningEntity(nil)
+	*s.owner.campaign = c
+	s.owner.sync()
+}
+
+func (d *Campaign) selectedPaths() []string {
+	rows := d.table.SelectedRows(false)
+	list := make([]string, 0, len(rows))
+	for _, row := range rows {
+		list = append(list, row.path)
+	}
+	return list
+}
+
+// memberSheets returns the member sheets that are currently open.
+func (d *Campaign) memberSheets() []*Sheet {
+	var list []*Sheet
+	for _, s := range openSheets() {
+		if !s.needsSaveAsPrompt && d.campaign.IsMember(d.path, s.BackingFilePath()) {
+			list = append(list, s)
+		}
+	}
+	return list
+}
+
+func (d *Campaign) showAddMenu(b *unison.Button) {
+	f := unison.DefaultMenuFactory()
+	id := unison.ContextMenuIDFlag
+	m := f.NewMenu(id, "", nil)
+	id++
+	count := 0
+	for _, one := range openSheets() {
+		s := one
+		if s.needsSaveAsPrompt || d.campaign.IsMember(d.path, s.BackingFilePath()) {
+			// Sheets that have never been saved have no file for the campaign to refer to
+			continue
+		}
+		m.InsertItem(-1, f.NewItem(id, s.Title(), unison.KeyBinding{}, nil, func(_ unison.MenuItem) {
+			d.addMembers([]string{s.BackingFilePath()})
+		}))
+		id++
+		count++
+	}
+	if count != 0 {
+		m.InsertSeparator(-1, false)
+	}
+	m.InsertItem(-1, f.NewItem(id, i18n.Text("From File…"), unison.KeyBinding{}, nil, func(_ unison.MenuItem) {
+		dialog := unison.NewOpenDialog()
+		dialog.SetAllowsMultipleSelection(true)
+		dialog.SetResolvesAliases(true)
+		dialog.SetAllowedExtensions(library.SheetExt)
+		if dialog.RunModal() {
+			d.addMembers(dialog.Paths())
+		}
+	}))
+	m.Popup(b.RectToRoot(b.ContentRect(true)), 0)
+}
+
+func (d *Campaign) addMembers(paths []string) {
+	d.performEdit(i18n.Text("Add Members"), func() bool {
+		added := false
+		for _, p := range paths {
+			if d.campaign.AddMember(d.path, p) {
+				added = true
+			}
+		}
+		return added
+	})
+}
+
+func (d *Campaign) removeSelection() {
+	list := d.selectedPaths()
+	if len(list) == 0 {
+		return
+	}
+	d.performEdit(i18n.Text("Remove Members"), func() bool {
+		for _, p := range list {
+			d.campaign.RemoveMember(d.path, p)
+		}
+		return true
+	})
+}
+
+func (d *Campaign) openSelection() {
+	var list []string
+	for _, row := range d.table.SelectedRows(false) {
+		if row.err == nil {
+			list = append(list, row.path)
+		}
+	}
+	workspace.OpenFiles(list)
+}
+
+func (d *Campaign) showSettingsMenu(b *unison.Button) {
+	f := unison.DefaultMenuFactory()
+	id := unison.ContextMenuIDFlag
+	m := f.NewMenu(id, "", nil)
+	id++
+	for _, one := range d.memberSheets() {
+		s := one
+		m.InsertItem(-1, f.NewItem(id, fmt.Sprintf(i18n.Text("Use Sheet Settings From %s"), s.Title()),
+			unison.KeyBinding{}, nil, func(_ unison.MenuItem) {
+				d.adoptSheetSettings(s.Entity().SheetSettings)
+			}))
+		id++
+	}
+	m.InsertItem(-1, f.NewItem(id, i18n.Text("Use Default Sheet Settings"), unison.KeyBinding{}, nil,
+		func(_ unison.MenuItem) { d.adoptSheetSettings(settings.Global().Sheet) }))
+	id++
+	m.InsertItem(-1, f.NewItem(id, i18n.Text("Import Sheet Settings…"), unison.KeyBinding{}, nil,
+		func(_ unison.MenuItem) { d.importSheetSettings() }))
+	id++
+	m.InsertItem(-1, f.NewItem(id, i18n.Text("Export Sheet Settings…"), unison.KeyBinding{}, nil,
+		func(_ unison.MenuItem) { d.exportSheetSettings() }))
+	id++
+	m.InsertSeparator(-1, false)
+	m.InsertItem(-1, f.NewItem(id, i18n.Text("Use Current Page Reference Mappings"), unison.KeyBinding{}, nil,
+		func(_ unison.MenuItem) {
+			d.performEdit(i18n.Text("Use Current Page Reference Mappings"), func() bool {
+				d.campaign.AdoptPageRefs(&settings.Global().PageRefs)
+				return true
+			})
+		}))
+	m.Popup(b.RectToRoot(b.ContentRect(true)), 0)
+}
+
+func (d *Campaign) adoptSheetSettings(s *gurps.SheetSettings) {
+	d.performEdit(i18n.Text("Change Shared Sheet Settings"), func() bool {
+		d.campaign.AdoptSheetSettings(s)
+		return true
+	})
+}
+
+func (d *Campaign) importSheetSettings() {
+	dialog := unison.NewOpenDialog()
+	dialog.SetResolvesAliases(true)
+	dialog.SetAllowedExtensions(sheetSettingsExt)
+	if !dialog.RunModal() {
+		return
+	}
+	p := dialog.Path()
+	s, err := gurps.NewSheetSettingsFromFile(os.DirFS(filepath.Dir(p)), filepath.Base(p))
+	if err != nil {
+		unison.ErrorDialogWithError(i18n.Text("Unable to load sheet settings"), err)
+		return
+	}
+	d.adoptSheetSettings(s)
+}
+
+func (d *Campaign) exportSheetSettings() {
+	dialog := unison.NewSaveDialog()
+	dialog.SetAllowedExtensions(sheetSettingsExt)
+	if dialog.RunModal() {
+		if err := d.campaign.SheetSettings.Save(dialog.Path()); err != nil {
+			unison.ErrorDialogWithError(i18n.Text("Unable to save sheet settings"), err)
+		}
+	}
+}
+
+// applyToMembers propagates the shared settings to each member sheet. Member sheets are opened so that the changes
+// can be reviewed and saved or undone.
+func (d *Campaign) applyToMembers() {
+	wnd := d.Window()
+	for _, p := range d.campaign.MemberPaths(d.path) {
+		if dockable, _ := workspace.OpenFile(wnd, p); dockable != nil {
+			if s, ok := dockable.(*Sheet); ok {
+				s.applyCampaignSettings(d.campaign)
+			}
+		}
+	}
+	if dc := unison.Ancestor[*unison.DockContainer](d); dc != nil {
+		dc.SetCurrentDockable(d)
+		dc.AcquireFocus()
+	}
+	d.refresh()
+}
+
+// ScopedPageRef implements wsettings.PageRefScope
+func (d *Campaign) ScopedPageRef(filePath, key string) *settings.PageRef {
+	if d.campaign.IsMember(d.path, filePath) {
+		return d.campaign.LookupPageRef(key)
+	}
+	return nil
+}
+
+// campaignCalendarRef returns the calendar of an open campaign the file at the path is a member of, or nil if there is
+// no such campaign or it uses the current calendar.
+func campaignCalendarRef(filePath string) *gsettings.CalendarRef {
+	var ref *gsettings.CalendarRef
+	for _, wnd := range unison.Windows() {
+		if ws := workspace.FromWindow(wnd); ws != nil {
+			ws.DocumentDock.RootDockLayout().ForEachDockContainer(func(dc *unison.DockContainer) bool {
+				for _, one := range dc.Dockables() {
+					if d, ok := one.(*Campaign); ok && d.campaign.IsMember(d.path, filePath) {
+						if ref = d.campaign.CalendarRef(settings.Global().Libraries()); ref != nil {
+							return true
+						}
+					}
+				}
+				return false
+			})
+			if ref != nil {
+				break
+			}
+		}
+	}
+	return ref
+}
+
+// UndoManager implements undo.Provider
+func (d *Campaign) UndoManager() *unison.UndoManager {
+	return d.undoMgr
+}
+
+// TitleIcon implements workspace.FileBackedDockable
+func (d *Campaign) TitleIcon(suggestedSize unison.Size) unison.Drawable {
+	return &unison.DrawableSVG{
+		SVG:  library.FileInfoFor(d.path).SVG,
+		Size: suggestedSize,
+	}
+}
+
+// Title implements workspace.FileBackedDockable
+func (d *Campaign) Title() string {
+	return fs.BaseName(d.path)
+}
+
+func (d *Campaign) String() string {
+	return d.Title()
+}
+
+// Tooltip implements workspace.FileBackedDockable
+func (d *Campaign) Tooltip() string {
+	return d.path
+}
+
+// BackingFilePath implements workspace.FileBackedDockable
+func (d *Campaign) BackingFilePath() string {
+	return d.path
+}
+
+// SetBackingFilePath implements workspace.RetargetableDockable
+func (d *Campaign) SetBackingFilePath(filePath string) {
+	d.campaign.RebaseMembers(d.path, filePath)
+	d.path = filePath
+}
+
+// Modified implements workspace.FileBackedDockable
+func (d *Campaign) Modified() bool {
+	return d.crc != d.campaign.CRC64()
+}
+
+// SaveSnapshot implements workspace.Recoverable
+func (d *Campaign) SaveSnapshot(filePath string) error {
+	return d.campaign.Save(filePath)
+}
+
+// NeedsSaveAs implements workspace.Recoverable
+func (d *Campaign) NeedsSaveAs() bool {
+	return d.needsSaveAsPrompt
+}
+
+// MarkRecovered implements workspace.Recoverable
+func (d *Campaign) MarkRecovered(originalPath string, needsSaveAs bool) {
+	d.path = originalPath
+	d.needsSaveAsPrompt = needsSaveAs
+	d.crc = 0
+	d.MarkModified()
+}
+
+// MarkModified implements widget.ModifiableRoot.
+func (d *Campaign) MarkModified() {
+	if dc := unison.Ancestor[*unison.DockContainer](d); dc != nil {
+		dc.UpdateTitle(d)
+	}
+}
+
+// MayAttemptClose implements unison.TabCloser
+func (d *Campaign) MayAttemptClose() bool {
+	return true
+}
+
+// AttemptClose implements unison.TabCloser
+func (d *Campaign) AttemptClose() bool {
+	if d.Modified() {
+		switch unison.YesNoCancelDialog(fmt.Sprintf(i18n.Text("Save changes made to\n%s?"), d.Title()), "") {
+		case unison.ModalResponseDiscard:
+		case unison.ModalResponseOK:
+			if !d.save(false) {
+				return false
+			}
+		case unison.ModalResponseCancel:
+			return false
+		}
+	}
+	if dc := unison.Ancestor[*unison.DockContainer](d); dc != nil {
+		dc.Close(d)
+	}
+	return true
+}
+
+func (d *Campaign) save(forceSaveAs bool) bool {
+	success := false
+	if forceSaveAs || d.needsSaveAsPrompt {
+		// Members are stored relative to the campaign file, so they must be adjusted before saving to a new location
+		success = workspace.SaveDockableAs(d, library.CampaignExt, func(filePath string) error {
+			original := d.path
+			d.campaign.RebaseMembers(original, filePath)
+			if err := d.campaign.Save(filePath); err != nil {
+				d.campaign.RebaseMembers(filePath, original)
+				return err
+			}
+			return nil
+		}, func(path string) {
+			d.crc = d.campaign.CRC64()
+			d.path = path
+		})
+	} else {
+		success = workspace.SaveDockable(d, d.campaign.Save, func() { d.crc = d.campaign.CRC64() })
+	}
+	if success {
+		d.needsSaveAsPrompt = false
+		d.refresh()
+	}
+	return success
+}
+
+// CloneForTarget implements unison.TableRowData. Not permitted.
+func (r *memberRow) CloneForTarget(_ unison.Paneler, _ *memberRow) *memberRow {
+	return nil
+}
+
+// UUID implements unison.TableRowData.
+func (r *memberRow) UUID() uuid.UUID {
+	return r.id
+}
+
+// Parent implements unison.TableRowData.
+func (r *memberRow) Parent() *memberRow {
+	return nil
+}
+
+// SetParent implements unison.TableRowData.
+func (r *memberRow) SetParent(_ *memberRow) {
+}
+
+// CanHaveChildren implements unison.TableRowData.
+func (r *memberRow) CanHaveChildren() bool {
+	return false
+}
+
+// Children implements unison.TableRowData.
+func (r *memberRow) Children() []*memberRow {
+	return nil
+}
+
+// SetChildren implements unison.TableRowData.
+func (r *memberRow) SetChildren(_ []*memberRow) {
+}
+
+// CellDataForSort implements unison.TableRowData.
+func (r *memberRow) CellDataForSort(col int) string {
+	if r.entity == nil {
+		if col == memberNameColumn {
+			return fs.BaseName(r.path)
+		}
+		return ""
+	}
+	switch col {
+	case memberNameColumn:
+		return r.entity.RollerName()
+	case memberPlayerColumn:
+		return r.entity.Profile.PlayerName
+	case memberSTColumn:
+		return r.entity.ResolveAttributeCurrent(gid.Strength).String()
+	case memberDXColumn:
+		return r.entity.ResolveAttributeCurrent(gid.Dexterity).String()
+	case memberIQColumn:
+		return r.entity.ResolveAttributeCurrent(gid.Intelligence).String()
+	case memberHTColumn:
+		return r.entity.ResolveAttributeCurrent(gid.Health).String()
+	case memberHPColumn:
+		return poolText(r.entity.ResolveAttribute(gid.HitPoints))
+	case memberFPColumn:
+		return poolText(r.entity.ResolveAttribute(gid.FatiguePoints))
+	case memberSpeedColumn:
+		return r.entity.ResolveAttributeCurrent(gid.BasicSpeed).String()
+	case memberMoveColumn:
+		return fmt.Sprint(r.entity.Move(r.entity.EncumbranceLevel(false)))
+	case memberPointsColumn:
+		return r.entity.TotalPoints.String()
+	case memberUnspentColumn:
+		return r.entity.UnspentPoints().String()
+	case memberWealthColumn:
+		return "$" + r.entity.WealthCarried().String()
+	case memberOtherWealthColumn:
+		return "$" + r.entity.WealthNotCarried().String()
+	default:
+		return ""
+	}
+}
+
+// ColumnCell implements unison.TableRowData.
+func (r *memberRow) ColumnCell(_, col int, foreground, _ unison.Ink, _, _, _ bool) unison.Paneler {
+	label := unison.NewLabel()
+	label.LabelTheme.OnBackgroundInk = foreground
+	label.Text = r.CellDataForSort(col)
+	switch col {
+	case memberNameColumn:
+		if r.err != nil {
+			label.LabelTheme.OnBackgroundInk = unison.ErrorColor
+			label.Tooltip = unison.NewTooltipWithText(fmt.Sprintf(i18n.Text("Unable to load %s"), r.path))
+		} else {
+			label.Tooltip = unison.NewTooltipWithText(r.path)
+		}
+	case memberPlayerColumn:
+	default:
+		label.HAlign = unison.EndAlignment
+	}
+	return label
+}
+
+// IsOpen implements unison.TableRowData.
+func (r *memberRow) IsOpen() bool {
+	return false
+}
+
+// SetOpen implements unison.TableRowData.
+func (r *memberRow) SetOpen(_ bool) {
+}
+
+type campaignSettingsUndo struct {
+	owner      *Sheet
+	settings   *gurps.SheetSettings
+	attributes *gurps.Attributes
+}
+
+func newCampaignSettingsUndo(s *Sheet) *campaignSettingsUndo {
+	return &campaignSettingsUndo{
+		owner:      s,
+		settings:   s.entity.SheetSettings.Clone(s.entity),
+		attributes: s.entity.Attributes.Clone(s.entity),
+	}
+}
+
+func (u *campaignSettingsUndo) apply() {
+	entity := u.owner.entity
+	entity.SheetSettings = u.settings.Clone(entity)
+	entity.SheetSettings.SetOwningEntity(entity)
+	entity.Attributes = u.attributes.Clone(entity)
+	u.owner.Rebuild(true)
+}
+
+// applyCampaignSettings replaces the sheet's settings with those shared by the campaign as an undoable edit. A sheet
+// that is being planned must leave planning mode first, so that the change is made to the sheet itself.
+func (s *Sheet) applyCampaignSettings(c *campaign.Campaign) {
+	if s.plan != nil && (!s.plan.confirmDiscard() || !s.endPlan()) {
+		return
+	}
+	before := newCampaignSettingsUndo(s)
+	c.ApplyTo(s.entity)
+	s.undoMgr.Add(&unison.UndoEdit[*campaignSettingsUndo]{
+		ID:         unison.NextUndoID(),
+		EditName:   i18n.Text("Apply Campaign Settings"),
+		UndoFunc:   func(edit *unison.UndoEdit[*campaignSettingsUndo]) { edit.BeforeData.apply() },
+		RedoFunc:   func(edit *unison.UndoEdit[*campaignSettingsUndo]) { edit.AfterData.apply() },
+		BeforeData: before,
+		AfterData:  newCampaignSettingsUndo(s),
+	})
+	s.Rebuild(true)
+}
@@ -100,9 +100,16 @@ func (d *DescriptionPanel) createColumn1() *unison.Panel {
 	birthdayField := widget.NewStringPageField(title, func() string { return d.entity.Profile.Birthday },
 		func(s string) { d.entity.Profile.Birthday = s })
 	column.AddChild(widget.NewPageLabelWithRandomizer(title,
-		i18n.Text("Randomize the birthday using the current calendar"), func() {
+		i18n.Text("Randomize the birthday using the campaign's calendar or, if there isn't one, the current calendar"),
+		func() {
 			global := settings.Global()
-			d.entity.Profile.Birthday = global.General.CalendarRef(global.LibrarySet).RandomBirthday(d.entity.Profile.Birthday)
+			calendar := global.General.CalendarRef(global.LibrarySet)
+			if owner := unison.AncestorOrSelf[*Sheet](d); owner != nil {
+				if ref := campaignCalendarRef(owner.BackingFilePath()); ref != nil {
+					calendar = ref
+				}
+			}
+			d.entity.Profile.Birthday = calendar.RandomBirthday(d.entity.Profile.Birthday)
 			SetTextAndMarkModified(birthdayField.Field, d.entity.Profile.Birthday)
 		}))
 	column.AddChild(birthdayField)
@@ -0,0 +1,757 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package sheet
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/richardwilkes/gcs/constants"
+	"github.com/richardwilkes/gcs/model/fxp"
+	"github.com/richardwilkes/gcs/model/gurps"
+	"github.com/richardwilkes/gcs/model/jio"
+	"github.com/richardwilkes/gcs/model/library"
+	"github.com/richardwilkes/gcs/model/theme"
+	"github.com/richardwilkes/gcs/res"
+	"github.com/richardwilkes/gcs/ui/widget"
+	"github.com/richardwilkes/gcs/ui/workspace"
+	"github.com/richardwilkes/toolbox/i18n"
+	"github.com/richardwilkes/toolbox/log/jot"
+	"github.com/richardwilkes/toolbox/xio/fs"
+	"github.com/richardwilkes/unison"
+)
+
+const (
+	encounterTurnColumn = iota
+	encounterNameColumn
+	encounterSpeedColumn
+	encounterDXColumn
+	encounterHPColumn
+	encounterFPColumn
+	encounterConditionColumn
+	encounterNotesColumn
+	encounterColumnCount
+)
+
+var (
+	_ workspace.FileBackedDockable   = &Encounter{}
+	_ workspace.RetargetableDockable = &Encounter{}
+	_ workspace.Recoverable          = &Encounter{}
+	_ unison.UndoManagerProvider     = &Encounter{}
+	_ widget.ModifiableRoot          = &Encounter{}
+	_ unison.TabCloser               = &Encounter{}
+)
+
+// Encounter holds the view for a combat tracker.
+type Encounter struct {
+	unison.Panel
+	path              string
+	undoMgr           *unison.UndoManager
+	encounter         *gurps.Encounter
+	crc               uint64
+	roundLabel        *unison.Label
+	scroll            *unison.ScrollPanel
+	table             *unison.Table[*combatantRow]
+	lastDamage        map[uuid.UUID]gurps.DamageResult
+	needsSaveAsPrompt bool
+}
+
+type combatantRow struct {
+	owner     *Encounter
+	combatant *gurps.Combatant
+}
+
+type hitLocationChoice struct {
+	id   string
+	name string
+}
+
+func (h hitLocationChoice) String() string {
+	return h.name
+}
+
+// NewEncounterFromFile loads an encounter file and creates a new unison.Dockable for it.
+func NewEncounterFromFile(filePath string) (unison.Dockable, error) {
+	encounter, err := gurps.NewEncounterFromFile(os.DirFS(filepath.Dir(filePath)), filepath.Base(filePath))
+	if err != nil {
+		return nil, err
+	}
+	d := NewEncounter(filePath, encounter)
+	d.needsSaveAsPrompt = false
+	return d, nil
+}
+
+// NewEncounter creates a new unison.Dockable for encounter files.
+func NewEncounter(filePath string, encounter *gurps.Encounter) *Encounter {
+	d := &Encounter{
+		path:              filePath,
+		undoMgr:           unison.NewUndoManager(200, func(err error) { jot.Error(err) }),
+		encounter:         encounter,
+		crc:               encounter.CRC64(),
+		scroll:            unison.NewScrollPanel(),
+		table:             unison.NewTable[*combatantRow](&unison.SimpleTableModel[*combatantRow]{}),
+		lastDamage:        make(map[uuid.UUID]gurps.DamageResult),
+		needsSaveAsPrompt: true,
+	}
+	d.Self = d
+	d.SetLayout(&unison.FlexLayout{Columns: 1})
+
+	d.table.ColumnSizes = make([]unison.ColumnSize, encounterColumnCount)
+	d.table.DoubleClickCallback = d.editSelection
+	header := unison.NewTableHeader[*combatantRow](d.table,
+		unison.NewTableColumnHeader[*combatantRow]("", i18n.Text("Whose turn it is")),
+		unison.NewTableColumnHeader[*combatantRow](i18n.Text("Combatant"), ""),
+		unison.NewTableColumnHeader[*combatantRow](i18n.Text("Speed"), i18n.Text("Basic Speed")),
+		unison.NewTableColumnHeader[*combatantRow](i18n.Text("DX"), i18n.Text("Dexterity, used to break ties in Basic Speed")),
+		unison.NewTableColumnHeader[*combatantRow](i18n.Text("HP"), i18n.Text("Current and maximum Hit Points")),
+		unison.NewTableColumnHeader[*combatantRow](i18n.Text("FP"), i18n.Text("Current and maximum Fatigue Points")),
+		unison.NewTableColumnHeader[*combatantRow](i18n.Text("Condition"), ""),
+		unison.NewTableColumnHeader[*combatantRow](i18n.Text("Notes"), ""),
+	)
+	d.scroll.SetColumnHeader(header)
+	d.scroll.SetContent(d.table, unison.FillBehavior, unison.FillBehavior)
+	d.scroll.SetLayoutData(&unison.FlexLayoutData{
+		HAlign: unison.FillAlignment,
+		VAlign: unison.FillAlignment,
+		HGrab:  true,
+		VGrab:  true,
+	})
+
+	d.AddChild(d.createToolbar())
+	d.AddChild(d.scroll)
+	d.sync()
+
+	d.InstallCmdHandlers(constants.SaveItemID, func(_ any) bool { return d.Modified() }, func(_ any) { d.save(false) })
+	d.InstallCmdHandlers(constants.SaveAsItemID, unison.AlwaysEnabled, func(_ any) { d.save(true) })
+	return d
+}
+
+func (d *Encounter) createToolbar() *unison.Panel {
+	d.roundLabel = unison.NewLabel()
+	d.roundLabel.Font = unison.EmphasizedSystemFont
+
+	addButton := unison.NewSVGButton(res.CircledAddSVG)
+	addButton.Tooltip = unison.NewTooltipWithText(i18n.Text("Add a combatant from an open sheet or a sheet file"))
+	addButton.ClickCallback = func() { d.showAddMenu(addButton) }
+
+	removeButton := unison.NewSVGButton(res.TrashSVG)
+	removeButton.Tooltip = unison.NewTooltipWithText(i18n.Text("Remove the selected combatants"))
+	removeButton.ClickCallback = d.removeSelection
+
+	sortButton := unison.NewButton()
+	sortButton.Text = i18n.Text("Initiative")
+	sortButton.Tooltip = unison.NewTooltipWithText(i18n.Text("Order the combatants by Basic Speed, then DX"))
+	sortButton.ClickCallback = func() {
+		d.performEdit(i18n.Text("Order by Initiative"), func() bool {
+			d.encounter.SortByInitiative()
+			return true
+		})
+	}
+
+	damageButton := unison.NewButton()
+	damageButton.Text = i18n.Text("Damage…")
+	damageButton.Tooltip = unison.NewTooltipWithText(i18n.Text("Apply damage to the selected combatants"))
+	damageButton.ClickCallback = d.applyDamageToSelection
+
+	restartButton := unison.NewSVGButton(res.FirstSVG)
+	restartButton.Tooltip = unison.NewTooltipWithText(i18n.Text("Restart at the first combatant in round 1"))
+	restartButton.ClickCallback = func() {
+		d.performEdit(i18n.Text("Restart Encounter"), func() bool {
+			d.encounter.Restart()
+			return true
+		})
+	}
+
+	previousButton := unison.NewSVGButton(res.PreviousSVG)
+	previousButton.Tooltip = unison.NewTooltipWithText(i18n.Text("Previous Turn"))
+	previousButton.ClickCallback = func() {
+		d.performEdit(i18n.Text("Previous Turn"), func() bool {
+			d.encounter.PreviousTurn()
+			return true
+		})
+	}
+
+	nextButton := unison.NewSVGButton(res.NextSVG)
+	nextButton.Tooltip = unison.NewTooltipWithText(i18n.Text("Next Turn"))
+	nextButton.ClickCallback = func() {
+		d.performEdit(i18n.Text("Next Turn"), func() bool {
+			d.encounter.NextTurn()
+			return true
+		})
+	}
+
+	spacer := unison.NewPanel()
+	spacer.SetLayoutData(&unison.FlexLayoutData{
+		HAlign: unison.FillAlignment,
+		HGrab:  true,
+	})
+
+	toolbar := unison.NewPanel()
+	toolbar.SetBorder(unison.NewCompoundBorder(unison.NewLineBorder(unison.DividerColor, 0, unison.Insets{Bottom: 1},
+		false), unison.NewEmptyBorder(unison.StdInsets())))
+	toolbar.SetLayoutData(&unison.FlexLayoutData{
+		HAlign: unison.FillAlignment,
+		HGrab:  true,
+	})
+	toolbar.AddChild(restartButton)
+	toolbar.AddChild(previousButton)
+	toolbar.AddChild(d.roundLabel)
+	toolbar.AddChild(nextButton)
+	toolbar.AddChild(spacer)
+	toolbar.AddChild(sortButton)
+	toolbar.AddChild(damageButton)
+	toolbar.AddChild(addButton)
+	toolbar.AddChild(removeButton)
+	toolbar.SetLayout(&unison.FlexLayout{
+		Columns:  len(toolbar.Children()),
+		HSpacing: unison.StdHSpacing,
+		VAlign:   unison.MiddleAlignment,
+	})
+	return toolbar
+}
+
+func (d *Encounter) sync() {
+	d.roundLabel.Text = fmt.Sprintf(i18n.Text("Round %d"), d.encounter.Round)
+	d.roundLabel.MarkForLayoutAndRedraw()
+	rows := make([]*combatantRow, len(d.encounter.Combatants))
+	for i, one := range d.encounter.Combatants {
+		rows[i] = &combatantRow{
+			owner:     d,
+			combatant: one,
+		}
+	}
+	d.table.SetRootRows(rows)
+	d.table.SizeColumnsToFit(true)
+	d.MarkModified()
+}
+
+// performEdit calls 'edit', which should alter the encounter and return true if it did so. If it did, an undo edit with
+// the given name that restores the encounter to its prior state is then registered.
+func (d *Encounter) performEdit(name string, edit func() bool) {
+	before, err := d.snapshot()
+	if err != nil {
+		jot.Error(err)
+		return
+	}
+	if !edit() {
+		return
+	}
+	var after *encounterSnapshot
+	if after, err = d.snapshot(); err != nil {
+		jot.Error(err)
+	} else {
+		d.undoMgr.Add(&unison.UndoEdit[*encounterSnapshot]{
+			ID:         unison.NextUndoID(),
+			EditName:   name,
+			UndoFunc:   func(edit *unison.UndoEdit[*encounterSnapshot]) { edit.BeforeData.apply() },
+			RedoFunc:   func(edit *unison.UndoEdit[*encounterSnapshot]) { edit.AfterData.apply() },
+			BeforeData: before,
+			AfterData:  after,
+		})
+	}
+	d.sync()
+}
+
+type encounterSnapshot struct {
+	owner  *Encounter
+	data   []byte
+	selMap map[uuid.UUID]bool
+}
+
+func (d *Encounter) snapshot() (*encounterSnapshot, error) {
+	var buffer bytes.Buffer
+	if err := jio.Save(context.Background(), &buffer, d.encounter); err != nil {
+		return nil, err
+	}
+	return &encounterSnapshot{
+		owner:  d,
+		data:   buffer.Bytes(),
+		selMap: d.table.CopySelectionMap(),
+	}, nil
+}
+
+func (s *encounterSnapshot) apply() {
+	var encounter gurps.Encounter
+	if err := jio.Load(context.Background(), bytes.NewReader(s.data), &encounter); err != nil {
+		jot.Error(err)
+		return
+	}
+	*s.owner.encounter = encounter
+	s.owner.sync()
+	s.owner.table.SetSelectionMap(s.selMap)
+}
+
+func (d *Encounter) selectedCombatants() []*gurps.Combatant {
+	rows := d.table.SelectedRows(false)
+	list := make([]*gurps.Combatant, 0, len(rows))
+	for _, row := range rows {
+		list = append(list, row.combatant)
+	}
+	return list
+}
+
+func (d *Encounter) showAddMenu(b *unison.Button) {
+	f := unison.DefaultMenuFactory()
+	id := unison.ContextMenuIDFlag
+	m := f.NewMenu(id, "", nil)
+	id++
+	sheets := openSheets()
+	for _, one := range sheets {
+		s := one
+		m.InsertItem(-1, f.NewItem(id, s.Title(), unison.KeyBinding{}, nil, func(_ unison.MenuItem) {
+			d.addCombatant(s.Entity(), s.BackingFilePath())
+		}))
+		id++
+	}
+	if len(sheets) != 0 {
+		m.InsertSeparator(-1, false)
+	}
+	m.InsertItem(-1, f.NewItem(id, i18n.Text("From File…"), unison.KeyBinding{}, nil, func(_ unison.MenuItem) {
+		d.addCombatantsFromFiles()
+	}))
+	m.Popup(b.RectToRoot(b.ContentRect(true)), 0)
+}
+
+func openSheets() []*Sheet {
+	var list []*Sheet
+	for _, wnd := range unison.Windows() {
+		if ws := workspace.FromWindow(wnd); ws != nil {
+			ws.DocumentDock.RootDockLayout().ForEachDockContainer(func(dc *unison.DockContainer) bool {
+				for _, one := range dc.Dockables() {
+					if s, ok := one.(*Sheet); ok {
+						list = append(list, s)
+					}
+				}
+				return false
+			})
+		}
+	}
+	return list
+}
+
+func (d *Encounter) addCombatant(entity *gurps.Entity, source string) {
+	d.performEdit(i18n.Text("Add Combatant"), func() bool {
+		if _, err := d.encounter.AddCombatant(entity, source); err != nil {
+			unison.ErrorDialogWithError(i18n.Text("Unable to add combatant"), err)
+			return false
+		}
+		return true
+	})
+}
+
+func (d *Encounter) addCombatantsFromFiles() {
+	dialog := unison.NewOpenDialog()
+	dialog.SetAllowsMultipleSelection(true)
+	dialog.SetResolvesAliases(true)
+	dialog.SetAllowedExtensions(library.SheetExt)
+	if !dialog.RunModal() {
+		return
+	}
+	d.performEdit(i18n.Text("Add Combatants"), func() bool {
+		added := false
+		for _, p := range dialog.Paths() {
+			entity, err := gurps.NewEntityFromFile(os.DirFS(filepath.Dir(p)), filepath.Base(p))
+			if err == nil {
+				_, err = d.encounter.AddCombatant(entity, p)
+			}
+			if err != nil {
+				unison.ErrorDialogWithError(fmt.Sprintf(i18n.Text("Unable to add combatant from\n%s"), p), err)
+				continue
+			}
+			added = true
+		}
+		return added
+	})
+}
+
+func (d *Encounter) removeSelection() {
+	list := d.selectedCombatants()
+	if len(list) == 0 {
+		return
+	}
+	d.performEdit(i18n.Text("Remove Combatants"), func() bool {
+		for _, one := range list {
+			d.encounter.RemoveCombatant(one)
+		}
+		return true
+	})
+}
+
+func (d *Encounter) editSelection() {
+	list := d.selectedCombatants()
+	if len(list) != 1 {
+		return
+	}
+	c := list[0]
+	name := c.Name
+	notes := c.Notes
+	hp := c.HP()
+	fp := c.FP()
+	var hpCurrent, fpCurrent fxp.Int
+	panel := unison.NewPanel()
+	panel.SetLayout(&unison.FlexLayout{
+		Columns:  2,
+		HSpacing: unison.StdHSpacing,
+		VSpacing: unison.StdVSpacing,
+	})
+	panel.AddChild(widget.NewFieldLeadingLabel(i18n.Text("Name")))
+	nameField := widget.NewStringField(i18n.Text("Name"), func() string { return name },
+		func(s string) { name = s })
+	nameField.SetMinimumTextWidthUsing("A reasonably long name")
+	panel.AddChild(nameField)
+	addPool := func(title string, attr *gurps.Attribute, current *fxp.Int) {
+		if attr == nil {
+			return
+		}
+		*current = attr.Current()
+		panel.AddChild(widget.NewFieldLeadingLabel(title))
+		wrapper := unison.NewPanel()
+		wrapper.SetLayout(&unison.FlexLayout{
+			Columns:  2,
+			HSpacing: unison.StdHSpacing,
+		})
+		wrapper.AddChild(widget.NewDecimalField(title, func() fxp.Int { return *current },
+			func(v fxp.Int) { *current = v }, fxp.Min, attr.Maximum(), false, false))
+		wrapper.AddChild(widget.NewFieldTrailingLabel(fmt.Sprintf(i18n.Text("of %s"), attr.Maximum().String())))
+		panel.AddChild(wrapper)
+	}
+	addPool(i18n.Text("HP"), hp, &hpCurrent)
+	addPool(i18n.Text("FP"), fp, &fpCurrent)
+	panel.AddChild(widget.NewFieldLeadingLabel(i18n.Text("Notes")))
+	panel.AddChild(widget.NewMultiLineStringField(i18n.Text("Notes"), func() string { return notes },
+		func(s string) { notes = s }))
+	dialog, err := unison.NewDialog(nil, nil, panel, []*unison.DialogButtonInfo{
+		unison.NewCancelButtonInfo(),
+		unison.NewOKButtonInfo(),
+	})
+	if err != nil {
+		jot.Error(err)
+		return
+	}
+	dialog.Window().SetTitle(fmt.Sprintf(i18n.Text("Edit %s"), c.Name))
+	if dialog.RunModal() != unison.ModalResponseOK {
+		return
+	}
+	d.performEdit(i18n.Text("Edit Combatant"), func() bool {
+		if name = strings.TrimSpace(name); name != "" {
+			c.Name = name
+		}
+		c.Notes = notes
+		if hp != nil {
+			hp.Damage = (hp.Maximum() - hpCurrent).Max(0)
+		}
+		if fp != nil {
+			fp.Damage = (fp.Maximum() - fpCurrent).Max(0)
+		}
+		return true
+	})
+}
+
+func (d *Encounter) applyDamageToSelection() {
+	list := d.selectedCombatants()
+	if len(list) == 0 {
+		return
+	}
+	amount := 0
+	armorDivisor := fxp.One
+	panel := unison.NewPanel()
+	panel.SetLayout(&unison.FlexLayout{
+		Columns:  2,
+		HSpacing: unison.StdHSpacing,
+		VSpacing: unison.StdVSpacing,
+	})
+	panel.AddChild(widget.NewFieldLeadingLabel(i18n.Text("Damage")))
+	amountField := widget.NewIntegerField(i18n.Text("Damage"), func() int { return amount },
+		func(v int) { amount = v }, 0, 99999, false, false)
+	panel.AddChild(amountField)
+
+	panel.AddChild(widget.NewFieldLeadingLabel(i18n.Text("Type")))
+	typePopup := unison.NewPopupMenu[*gurps.WoundingModifier]()
+	for _, one := range gurps.WoundingModifiers {
+		typePopup.AddItem(one)
+	}
+	typePopup.SelectIndex(0)
+	panel.AddChild(typePopup)
+
+	panel.AddChild(widget.NewFieldLeadingLabel(i18n.Text("Hit Location")))
+	locationPopup := unison.NewPopupMenu[hitLocationChoice]()
+	entity := list[0].Entity
+	for _, loc := range gurps.BodyTypeFor(entity).UniqueHitLocations(entity) {
+		choice := hitLocationChoice{id: loc.LocID, name: loc.ChoiceName}
+		locationPopup.AddItem(choice)
+		if loc.LocID == "torso" {
+			locationPopup.Select(choice)
+		}
+	}
+	if locationPopup.SelectedIndex() < 0 && locationPopup.ItemCount() > 0 {
+		locationPopup.SelectIndex(0)
+	}
+	panel.AddChild(locationPopup)
+
+	panel.AddChild(widget.NewFieldLeadingLabel(i18n.Text("Armor Divisor")))
+	panel.AddChild(widget.NewDecimalField(i18n.Text("Armor Divisor"), func() fxp.Int { return armorDivisor },
+		func(v fxp.Int) { armorDivisor = v }, fxp.From(0.1), fxp.Hundred, false, false))
+
+	dialog, err := unison.NewDialog(nil, nil, panel, []*unison.DialogButtonInfo{
+		unison.NewCancelButtonInfo(),
+		unison.NewOKButtonInfo(),
+	})
+	if err != nil {
+		jot.Error(err)
+		return
+	}
+	if len(list) == 1 {
+		dialog.Window().SetTitle(fmt.Sprintf(i18n.Text("Damage %s"), list[0].Name))
+	} else {
+		dialog.Window().SetTitle(fmt.Sprintf(i18n.Text("Damage %d Combatants"), len(list)))
+	}
+	amountField.RequestFocus()
+	if dialog.RunModal() != unison.ModalResponseOK || amount == 0 {
+		return
+	}
+	damage := gurps.Damage{
+		Amount:       amount,
+		ArmorDivisor: armorDivisor,
+	}
+	damage.Type, _ = typePopup.Selected()
+	if choice, ok := locationPopup.Selected(); ok {
+		damage.LocationID = choice.id
+	}
+	d.performEdit(i18n.Text("Apply Damage"), func() bool {
+		for _, one := range list {
+			d.lastDamage[one.ID] = one.ApplyDamage(damage)
+		}
+		return true
+	})
+}
+
+// UndoManager implements undo.Provider
+func (d *Encounter) UndoManager() *unison.UndoManager {
+	return d.undoMgr
+}
+
+// TitleIcon implements workspace.FileBackedDockable
+func (d *Encounter) TitleIcon(suggestedSize unison.Size) unison.Drawable {
+	return &unison.DrawableSVG{
+		SVG:  library.FileInfoFor(d.path).SVG,
+		Size: suggestedSize,
+	}
+}
+
+// Title implements workspace.FileBackedDockable
+func (d *Encounter) Title() string {
+	return fs.BaseName(d.path)
+}
+
+func (d *Encounter) String() string {
+	return d.Title()
+}
+
+// Tooltip implements workspace.FileBackedDockable
+func (d *Encounter) Tooltip() string {
+	return d.path
+}
+
+// BackingFilePath implements workspace.FileBackedDockable
+func (d *Encounter) BackingFilePath() string {
+	return d.path
+}
+
+// SetBackingFilePath implements workspace.RetargetableDockable
+func (d *Encounter) SetBackingFilePath(filePath string) {
+	d.path = filePath
+}
+
+// Modified implements workspace.FileBackedDockable
+func (d *Encounter) Modified() bool {
+	return d.crc != d.encounter.CRC64()
+}
+
+// SaveSnapshot implements workspace.Recoverable
+func (d *Encounter) SaveSnapshot(filePath string) error {
+	return d.encounter.Save(filePath)
+}
+
+// NeedsSaveAs implements workspace.Recoverable
+func (d *Encounter) NeedsSaveAs() bool {
+	return d.needsSaveAsPrompt
+}
+
+// MarkRecovered implements workspace.Recoverable
+func (d *Encounter) MarkRecovered(originalPath string, needsSaveAs bool) {
+	d.path = originalPath
+	d.needsSaveAsPrompt = needsSaveAs
+	d.crc = 0
+	d.MarkModified()
+}
+
+// MarkModified implements widget.ModifiableRoot.
+func (d *Encounter) MarkModified() {
+	if dc := unison.Ancestor[*unison.DockContainer](d); dc != nil {
+		dc.UpdateTitle(d)
+	}
+}
+
+// MayAttemptClose implements unison.TabCloser
+func (d *Encounter) MayAttemptClose() bool {
+	return true
+}
+
+// AttemptClose implements unison.TabCloser
+func (d *Encounter) AttemptClose() bool {
+	if d.Modified() {
+		switch unison.YesNoCancelDialog(fmt.Sprintf(i18n.Text("Save changes made to\n%s?"), d.Title()), "") {
+		case unison.ModalResponseDiscard:
+		case unison.ModalResponseOK:
+			if !d.save(false) {
+				return false
+			}
+		case unison.ModalResponseCancel:
+			return false
+		}
+	}
+	if dc := unison.Ancestor[*unison.DockContainer](d); dc != nil {
+		dc.Close(d)
+	}
+	return true
+}
+
+func (d *Encounter) save(forceSaveAs bool) bool {
+	success := false
+	if forceSaveAs || d.needsSaveAsPrompt {
+		success = workspace.SaveDockableAs(d, library.EncounterExt, d.encounter.Save, func(path string) {
+			d.crc = d.encounter.CRC64()
+			d.path = path
+		})
+	} else {
+		success = workspace.SaveDockable(d, d.encounter.Save, func() { d.crc = d.encounter.CRC64() })
+	}
+	if success {
+		d.needsSaveAsPrompt = false
+	}
+	return success
+}
+
+// CloneForTarget implements unison.TableRowData. Not permitted.
+func (r *combatantRow) CloneForTarget(_ unison.Paneler, _ *combatantRow) *combatantRow {
+	return nil
+}
+
+// UUID implements unison.TableRowData.
+func (r *combatantRow) UUID() uuid.UUID {
+	return r.combatant.ID
+}
+
+// Parent implements unison.TableRowData.
+func (r *combatantRow) Parent() *combatantRow {
+	return nil
+}
+
+// SetParent implements unison.TableRowData.
+func (r *combatantRow) SetParent(_ *combatantRow) {
+}
+
+// CanHaveChildren implements unison.TableRowData.
+func (r *combatantRow) CanHaveChildren() bool {
+	return false
+}
+
+// Children implements unison.TableRowData.
+func (r *combatantRow) Children() []*combatantRow {
+	return nil
+}
+
+// SetChildren implements unison.TableRowData.
+func (r *combatantRow) SetChildren(_ []*combatantRow) {
+}
+
+// CellDataForSort implements unison.TableRowData.
+func (r *combatantRow) CellDataForSort(col int) string {
+	switch col {
+	case encounterTurnColumn:
+		if r.owner.encounter.Current() == r.combatant {
+			return "▶"
+		}
+		return ""
+	case encounterNameColumn:
+		return r.combatant.Name
+	case encounterSpeedColumn:
+		return r.combatant.BasicSpeed().String()
+	case encounterDXColumn:
+		return r.combatant.DX().String()
+	case encounterHPColumn:
+		return poolText(r.combatant.HP())
+	case encounterFPColumn:
+		return poolText(r.combatant.FP())
+	case encounterConditionColumn:
+		var list []string
+		for _, attr := range []*gurps.Attribute{r.combatant.HP(), r.combatant.FP()} {
+			if attr != nil {
+				if threshold := attr.CurrentThreshold(); threshold != nil && threshold.State != "" {
+					list = append(list, threshold.State)
+				}
+			}
+		}
+		return strings.Join(list, ", ")
+	case encounterNotesColumn:
+		return strings.ReplaceAll(r.combatant.Notes, "\n", " ")
+	default:
+		return ""
+	}
+}
+
+func poolText(attr *gurps.Attribute) string {
+	if attr == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s / %s", attr.Current().String(), attr.Maximum().String())
+}
+
+// ColumnCell implements unison.TableRowData.
+func (r *combatantRow) ColumnCell(_, col int, foreground, _ unison.Ink, _, _, _ bool) unison.Paneler {
+	label := unison.NewLabel()
+	label.LabelTheme.OnBackgroundInk = foreground
+	label.Text = r.CellDataForSort(col)
+	if r.owner.encounter.Current() == r.combatant {
+		label.Font = unison.EmphasizedSystemFont
+		if col == encounterTurnColumn {
+			label.LabelTheme.OnBackgroundInk = theme.AccentColor
+		}
+	}
+	switch col {
+	case encounterSpeedColumn, encounterDXColumn, encounterHPColumn, encounterFPColumn:
+		label.HAlign = unison.EndAlignment
+	}
+	switch {
+	case col == encounterNameColumn && r.combatant.Source != "":
+		label.Tooltip = unison.NewTooltipWithText(r.combatant.Source)
+	case col == encounterHPColumn || col == encounterFPColumn:
+		if result, ok := r.owner.lastDamage[r.combatant.ID]; ok && result.Fatigue == (col == encounterFPColumn) {
+			label.Tooltip = unison.NewTooltipWithText(fmt.Sprintf(i18n.Text("Last damage: %s"), result))
+		}
+	}
+	return label
+}
+
+// IsOpen implements unison.TableRowData.
+func (r *combatantRow) IsOpen() bool {
+	return false
+}
+
+// SetOpen implements unison.TableRowData.
+func (r *combatantRow) SetOpen(_ bool) {
+}
@@ -0,0 +1,229 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package sheet
+
+import (
+	"fmt"
+
+	"github.com/richardwilkes/gcs/model/gurps"
+	"github.com/richardwilkes/gcs/model/settings"
+	"github.com/richardwilkes/toolbox/i18n"
+	"github.com/richardwilkes/toolbox/log/jot"
+	"github.com/richardwilkes/unison"
+)
+
+const maxLibraryUpdateValueLength = 60
+
+type libraryUpdateUndoEdit = *unison.UndoEdit[*libraryUpdateList]
+
+type libraryUpdateList struct {
+	Owner *Sheet
+	List  []func()
+}
+
+func (a *libraryUpdateList) Apply() {
+	for _, one := range a.List {
+		one()
+	}
+	a.Owner.Rebuild(true)
+}
+
+type libraryUpdateChoice struct {
+	update *gurps.LibraryUpdate
+	fields map[string]*unison.CheckBox
+}
+
+// CheckForLibraryUpdates compares the items in the sheet that were copied from a library against their current library
+// definitions and offers to update those that have changed.
+func (s *Sheet) CheckForLibraryUpdates() {
+	libs := settings.Global().LibrarySet
+	updates := s.entity.LibraryUpdates(libs)
+	if len(updates) == 0 {
+		showLibraryUpdateMessage(i18n.Text("All items copied from a library are up to date."))
+		return
+	}
+	content := unison.NewPanel()
+	content.SetBorder(unison.NewEmptyBorder(unison.NewUniformInsets(unison.StdHSpacing)))
+	content.SetLayout(&unison.FlexLayout{
+		Columns:  3,
+		HSpacing: unison.StdHSpacing * 2,
+		VSpacing: unison.StdVSpacing,
+	})
+	choices := make([]*libraryUpdateChoice, 0, len(updates))
+	for _, update := range updates {
+		choice := &libraryUpdateChoice{
+			update: update,
+			fields: make(map[string]*unison.CheckBox),
+		}
+		choices = append(choices, choice)
+		title := unison.NewLabel()
+		title.Text = fmt.Sprintf("%s: %s", update.Kind, update.Description)
+		title.Font = unison.LabelFont.Face().Font(unison.LabelFont.Size() + 2)
+		title.SetLayoutData(&unison.FlexLayoutData{
+			HSpan:  3,
+			HAlign: unison.FillAlignment,
+		})
+		content.AddChild(title)
+		info := unison.NewLabel()
+		if update.Problem != "" {
+			info.Text = update.Problem
+			info.OnBackgroundInk = unison.ErrorColor
+		} else {
+			libTitle := update.Source.Library
+			if lib, ok := libs[update.Source.Library]; ok {
+				libTitle = lib.Title
+			}
+			from := update.Source.Version
+			if from == "" {
+				from = "?"
+			}
+			to := update.LibraryVersion
+			if to == "" {
+				to = "?"
+			}
+			info.Text = fmt.Sprintf(i18n.Text("%s: %s (v%s → v%s)"), libTitle, update.Source.Path, from, to)
+		}
+		info.SetLayoutData(&unison.FlexLayoutData{
+			HSpan:  3,
+			HAlign: unison.FillAlignment,
+		})
+		content.AddChild(info)
+		if len(update.Differences) != 0 {
+			content.AddChild(newLibraryUpdateHeader(i18n.Text("Field")))
+			content.AddChild(newLibraryUpdateHeader(i18n.Text("Current")))
+			content.AddChild(newLibraryUpdateHeader(i18n.Text("Library")))
+			for _, diff := range update.Differences {
+				checkbox := unison.NewCheckBox()
+				checkbox.Text = diff.Title
+				checkbox.State = unison.OnCheckState
+				choice.fields[diff.Key] = checkbox
+				content.AddChild(checkbox)
+				content.AddChild(newLibraryUpdateValue(diff.CurrentValue))
+				content.AddChild(newLibraryUpdateValue(diff.LibraryValue))
+			}
+		}
+	}
+	scroller := unison.NewScrollPanel()
+	scroller.SetContent(content, unison.HintedFillBehavior, unison.FillBehavior)
+	scroller.SetLayoutData(&unison.FlexLayoutData{
+		MinSize: unison.Size{Width: 500, Height: 200},
+		HAlign:  unison.FillAlignment,
+		VAlign:  unison.FillAlignment,
+		HGrab:   true,
+		VGrab:   true,
+	})
+	panel := unison.NewPanel()
+	panel.SetLayout(&unison.FlexLayout{
+		Columns:  1,
+		HSpacing: unison.StdHSpacing,
+		VSpacing: unison.StdVSpacing,
+	})
+	label := unison.NewLabel()
+	label.Text = i18n.Text("Choose the fields to update from the library. Points, levels, notes and other choices specific to this character are never changed.")
+	panel.AddChild(label)
+	panel.AddChild(scroller)
+	dialog, err := unison.NewDialog(nil, nil, panel, []*unison.DialogButtonInfo{
+		unison.NewCancelButtonInfo(),
+		unison.NewOKButtonInfoWithTitle(i18n.Text("Update")),
+	})
+	if err != nil {
+		jot.Error(err)
+		return
+	}
+	dialog.Window().SetTitle(i18n.Text("Library Updates"))
+	if dialog.RunModal() != unison.ModalResponseOK {
+		return
+	}
+	s.applyLibraryUpdates(choices)
+}
+
+func (s *Sheet) applyLibraryUpdates(choices []*libraryUpdateChoice) {
+	before := &libraryUpdateList{Owner: s}
+	after := &libraryUpdateList{Owner: s}
+	for _, choice := range choices {
+		keys := make([]string, 0, len(choice.fields))
+		for _, diff := range choice.update.Differences {
+			if checkbox := choice.fields[diff.Key]; checkbox != nil && checkbox.State == unison.OnCheckState {
+				keys = append(keys, diff.Key)
+			}
+		}
+		if len(keys) == 0 {
+			continue
+		}
+		undo, redo, err := choice.update.Apply(keys)
+		if err != nil {
+			unison.ErrorDialogWithError(fmt.Sprintf(i18n.Text("Unable to update %s"), choice.update.Description), err)
+			continue
+		}
+		before.List = append(before.List, undo)
+		after.List = append(after.List, redo)
+	}
+	if len(after.List) == 0 {
+		return
+	}
+	// Undo in the reverse order of application, so that nested items are restored correctly
+	for i, j := 0, len(before.List)-1; i < j; i, j = i+1, j-1 {
+		before.List[i], before.List[j] = before.List[j], before.List[i]
+	}
+	s.undoMgr.Add(&unison.UndoEdit[*libraryUpdateList]{
+		ID:         unison.NextUndoID(),
+		EditName:   i18n.Text("Library Updates"),
+		UndoFunc:   func(edit libraryUpdateUndoEdit) { edit.BeforeData.Apply() },
+		RedoFunc:   func(edit libraryUpdateUndoEdit) { edit.AfterData.Apply() },
+		BeforeData: before,
+		AfterData:  after,
+	})
+	s.Rebuild(true)
+}
+
+func newLibraryUpdateHeader(title string) *unison.Label {
+	label := unison.NewLabel()
+	label.Text = title
+	label.OnBackgroundInk = unison.OnContentColor
+	label.SetBorder(unison.NewLineBorder(unison.DividerColor, 0, unison.Insets{Bottom: 1}, false))
+	label.SetLayoutData(&unison.FlexLayoutData{
+		HAlign: unison.FillAlignment,
+		HGrab:  true,
+	})
+	return label
+}
+
+func newLibraryUpdateValue(value string) *unison.Label {
+	label := unison.NewLabel()
+	if value == "" {
+		label.Text = i18n.Text("(none)")
+	} else {
+		runes := []rune(value)
+		if len(runes) > maxLibraryUpdateValueLength {
+			label.Text = string(runes[:maxLibraryUpdateValueLength-1]) + "…"
+			label.Tooltip = unison.NewTooltipWithText(value)
+		} else {
+			label.Text = value
+		}
+	}
+	label.SetLayoutData(&unison.FlexLayoutData{
+		HAlign: unison.FillAlignment,
+		HGrab:  true,
+	})
+	return label
+}
+
+func showLibraryUpdateMessage(msg string) {
+	label := unison.NewLabel()
+	label.Text = msg
+	dialog, err := unison.NewDialog(nil, nil, label, []*unison.DialogButtonInfo{unison.NewOKButtonInfo()})
+	if err != nil {
+		jot.Error(err)
+		return
+	}
+	dialog.RunModal()
+}
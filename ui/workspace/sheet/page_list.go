@@ -79,6 +79,7 @@ func NewSkillsPageList(owner widget.Rebuildable, provider gurps.ListProvider) *P
 	p.installDecrementSkillHandler(owner)
 	p.installIncrementTechLevelHandler(owner)
 	p.installDecrementTechLevelHandler(owner)
+	p.installSwapDefaultsHandler(owner)
 	return p
 }
 
@@ -156,9 +157,16 @@ func newPageList[T gurps.NodeConstraint[T]](owner widget.Rebuildable, provider n
 	p.AddChild(p.table)
 	if owner != nil {
 		ntable.InstallTableDropSupport(p.table, p.provider)
+		ntable.InstallColumnCustomization(p.tableHeader, p.table, p.provider, func() {
+			widget.MarkModified(p)
+			owner.Rebuild(true)
+		})
 		p.InstallCmdHandlers(constants.OpenEditorItemID,
 			func(_ any) bool { return p.table.HasSelection() },
 			func(_ any) { p.provider.OpenEditor(owner, p.table) })
+		p.InstallCmdHandlers(constants.BulkEditItemID,
+			func(_ any) bool { return p.table.HasSelection() },
+			func(_ any) { editors.BulkEdit(p.table, p.provider) })
 		p.InstallCmdHandlers(unison.DeleteItemID,
 			func(_ any) bool { return p.table.HasSelection() },
 			func(_ any) { ntable.DeleteSelection(p.table) })
@@ -176,6 +184,12 @@ func newPageList[T gurps.NodeConstraint[T]](owner widget.Rebuildable, provider n
 	return p
 }
 
+// Entity implements gurps.EntityProvider. This is the entity the list is displaying, which is the plan rather than the
+// sheet's own entity while the sheet is being planned.
+func (p *PageList[T]) Entity() *gurps.Entity {
+	return p.provider.Entity()
+}
+
 func (p *PageList[T]) installOpenPageReferenceHandlers() {
 	p.InstallCmdHandlers(constants.OpenOnePageReferenceItemID,
 		func(_ any) bool { return editors.CanOpenPageRef(p.table) },
@@ -293,6 +307,14 @@ func (p *PageList[T]) installConvertToContainerHandler(owner widget.Rebuildable)
 	}
 }
 
+func (p *PageList[T]) installSwapDefaultsHandler(owner widget.Rebuildable) {
+	if t, ok := (interface{}(p.table)).(*unison.Table[*ntable.Node[*gurps.Skill]]); ok {
+		p.InstallCmdHandlers(constants.SwapDefaultsItemID,
+			func(_ any) bool { return canSwapDefaults(t) },
+			func(_ any) { swapDefaults(owner, t) })
+	}
+}
+
 // SelectedNodes returns the set of selected nodes. If 'minimal' is true, then children of selected rows that may also
 // be selected are not returned, just the topmost row that is selected in any given hierarchy.
 func (p *PageList[T]) SelectedNodes(minimal bool) []*ntable.Node[T] {
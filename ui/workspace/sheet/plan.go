@@ -0,0 +1,407 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package sheet
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/richardwilkes/gcs/model/fxp"
+	"github.com/richardwilkes/gcs/model/gurps"
+	"github.com/richardwilkes/gcs/model/jio"
+	"github.com/richardwilkes/gcs/ui/widget"
+	"github.com/richardwilkes/gcs/ui/workspace"
+	"github.com/richardwilkes/toolbox/i18n"
+	"github.com/richardwilkes/toolbox/log/jot"
+	"github.com/richardwilkes/unison"
+)
+
+// maxPlanChangesPerLine limits how many changes are listed on each line of the planning bar. The full list is always
+// available via the line's tooltip.
+const maxPlanChangesPerLine = 6
+
+// sheetPlan holds the state of a sheet while it is in planning mode. While planning, the sheet displays and edits a
+// copy of its entity, leaving the original untouched until the plan is applied.
+type sheetPlan struct {
+	sheet        *Sheet
+	original     *gurps.Entity
+	proposal     *gurps.Proposal
+	name         string
+	crc          uint64
+	undoMgr      *unison.UndoManager
+	panel        *unison.Panel
+	nameLabel    *unison.Label
+	changes      *unison.Panel
+	deleteButton *unison.Button
+}
+
+type entitySnapshot struct {
+	owner *Sheet
+	data  []byte
+}
+
+func (s *Sheet) realEntity() *gurps.Entity {
+	if s.plan != nil {
+		return s.plan.original
+	}
+	return s.entity
+}
+
+func (s *Sheet) showPlanMenu(b *unison.Button) {
+	if s.plan != nil {
+		return
+	}
+	f := unison.DefaultMenuFactory()
+	id := unison.ContextMenuIDFlag
+	m := f.NewMenu(id, "", nil)
+	id++
+	m.InsertItem(-1, f.NewItem(id, i18n.Text("New Plan"), unison.KeyBinding{}, nil,
+		func(_ unison.MenuItem) { s.startPlan(nil) }))
+	id++
+	if proposals := s.realEntity().Proposals; len(proposals) != 0 {
+		m.InsertSeparator(-1, false)
+		for _, one := range proposals {
+			proposal := one
+			m.InsertItem(-1, f.NewItem(id, fmt.Sprintf(i18n.Text("Open Proposal: %s"), proposal.Name),
+				unison.KeyBinding{}, nil, func(_ unison.MenuItem) { s.startPlan(proposal) }))
+			id++
+		}
+	}
+	m.Popup(b.RectToRoot(b.ContentRect(true)), 0)
+}
+
+// startPlan switches the sheet into planning mode, working on either a fresh copy of the entity or a copy of the
+// proposal's entity.
+func (s *Sheet) startPlan(proposal *gurps.Proposal) {
+	if s.plan != nil || !workspace.CloseGroup(s) {
+		return
+	}
+	var plan *gurps.Entity
+	var err error
+	if proposal != nil {
+		plan, err = proposal.Plan()
+	} else {
+		plan, err = s.entity.NewPlan()
+	}
+	if err != nil {
+		unison.ErrorDialogWithError(i18n.Text("Unable to start planning"), err)
+		return
+	}
+	p := &sheetPlan{
+		sheet:    s,
+		original: s.entity,
+		proposal: proposal,
+		crc:      plan.CRC64(),
+		undoMgr:  unison.NewUndoManager(200, func(err error) { jot.Error(err) }),
+	}
+	if proposal != nil {
+		p.name = proposal.Name
+	} else {
+		p.name = fmt.Sprintf(i18n.Text("Plan %d"), len(s.entity.Proposals)+1)
+	}
+	p.createPanel()
+	s.plan = p
+	s.entity = plan
+	s.AddChildAtIndex(p.panel, 1)
+	s.rebuildPages()
+	p.refresh()
+}
+
+// endPlan leaves planning mode, discarding the plan. Returns false if planning mode could not be left.
+func (s *Sheet) endPlan() bool {
+	if s.plan == nil {
+		return true
+	}
+	if !workspace.CloseGroup(s) {
+		return false
+	}
+	s.RemoveChild(s.plan.panel)
+	s.entity = s.plan.original
+	s.plan = nil
+	s.rebuildPages()
+	return true
+}
+
+func (s *Sheet) rebuildPages() {
+	s.pages.RemoveAllChildren()
+	s.pages.AddChild(s.createTopBlock())
+	s.createLists()
+	s.applyScale()
+	s.MarkForLayoutAndRedraw()
+	s.MarkModified()
+}
+
+func (p *sheetPlan) createPanel() {
+	p.nameLabel = unison.NewLabel()
+	p.nameLabel.Font = unison.EmphasizedSystemFont
+
+	saveButton := unison.NewButton()
+	saveButton.Text = i18n.Text("Save Proposal…")
+	saveButton.Tooltip = unison.NewTooltipWithText(
+		i18n.Text("Store the plan with the sheet so that it can be revisited or applied later"))
+	saveButton.ClickCallback = func() { p.saveProposal() }
+
+	p.deleteButton = unison.NewButton()
+	p.deleteButton.Text = i18n.Text("Delete Proposal")
+	p.deleteButton.Tooltip = unison.NewTooltipWithText(i18n.Text("Remove the proposal from the sheet and stop planning"))
+	p.deleteButton.ClickCallback = p.deleteProposal
+
+	applyButton := unison.NewButton()
+	applyButton.Text = i18n.Text("Apply")
+	applyButton.Tooltip = unison.NewTooltipWithText(
+		i18n.Text("Make the changes in the plan to the sheet as a single undoable change"))
+	applyButton.ClickCallback = p.apply
+
+	discardButton := unison.NewButton()
+	discardButton.Text = i18n.Text("Stop Planning")
+	discardButton.Tooltip = unison.NewTooltipWithText(i18n.Text("Return to the sheet, leaving it unchanged"))
+	discardButton.ClickCallback = func() {
+		if p.confirmDiscard() {
+			p.sheet.endPlan()
+		}
+	}
+
+	spacer := unison.NewPanel()
+	spacer.SetLayoutData(&unison.FlexLayoutData{
+		HAlign: unison.FillAlignment,
+		HGrab:  true,
+	})
+
+	top := unison.NewPanel()
+	top.SetLayoutData(&unison.FlexLayoutData{
+		HAlign: unison.FillAlignment,
+		HGrab:  true,
+	})
+	top.AddChild(p.nameLabel)
+	top.AddChild(spacer)
+	top.AddChild(saveButton)
+	top.AddChild(p.deleteButton)
+	top.AddChild(applyButton)
+	top.AddChild(discardButton)
+	top.SetLayout(&unison.FlexLayout{
+		Columns:  len(top.Children()),
+		HSpacing: unison.StdHSpacing,
+		VAlign:   unison.MiddleAlignment,
+	})
+
+	p.changes = unison.NewPanel()
+	p.changes.SetLayout(&unison.FlexLayout{
+		Columns:  2,
+		HSpacing: unison.StdHSpacing,
+	})
+	p.changes.SetLayoutData(&unison.FlexLayoutData{
+		HAlign: unison.FillAlignment,
+		HGrab:  true,
+	})
+
+	p.panel = unison.NewPanel()
+	p.panel.SetBorder(unison.NewCompoundBorder(unison.NewLineBorder(unison.DividerColor, 0, unison.Insets{Bottom: 1},
+		false), unison.NewEmptyBorder(unison.StdInsets())))
+	p.panel.SetLayoutData(&unison.FlexLayoutData{
+		HAlign: unison.FillAlignment,
+		HGrab:  true,
+	})
+	p.panel.SetLayout(&unison.FlexLayout{
+		Columns:  1,
+		VSpacing: unison.StdVSpacing,
+	})
+	p.panel.AddChild(top)
+	p.panel.AddChild(p.changes)
+}
+
+// refresh updates the running comparison between the plan and the sheet.
+func (p *sheetPlan) refresh() {
+	p.nameLabel.Text = fmt.Sprintf(i18n.Text("Planning: %s"), p.name)
+	p.deleteButton.SetEnabled(p.proposal != nil)
+	p.changes.RemoveAllChildren()
+	changes := gurps.ComparePlan(p.original, p.sheet.entity)
+	if len(changes) == 0 {
+		label := unison.NewLabel()
+		label.Text = i18n.Text("No changes have been made yet")
+		p.changes.AddChild(label)
+		p.changes.AddChild(unison.NewPanel())
+	}
+	for i := 0; i < len(changes); {
+		kind := changes[i].Kind
+		var list []string
+		for ; i < len(changes) && changes[i].Kind == kind; i++ {
+			list = append(list, planChangeText(changes[i]))
+		}
+		p.changes.AddChild(widget.NewFieldLeadingLabel(kind.String()))
+		label := unison.NewLabel()
+		if len(list) > maxPlanChangesPerLine {
+			label.Text = strings.Join(list[:maxPlanChangesPerLine], ", ") +
+				fmt.Sprintf(i18n.Text(", and %d more"), len(list)-maxPlanChangesPerLine)
+		} else {
+			label.Text = strings.Join(list, ", ")
+		}
+		label.Tooltip = unison.NewTooltipWithText(strings.Join(list, "\n"))
+		label.SetLayoutData(&unison.FlexLayoutData{
+			HAlign: unison.FillAlignment,
+			HGrab:  true,
+		})
+		p.changes.AddChild(label)
+	}
+	p.panel.MarkForLayoutAndRedraw()
+	p.sheet.MarkForLayoutAndRedraw()
+}
+
+func planChangeText(change *gurps.PlanChange) string {
+	switch {
+	case change.Before == "":
+		return fmt.Sprintf(i18n.Text("%s %s (new)"), change.Name, change.After)
+	case change.After == "":
+		return fmt.Sprintf(i18n.Text("%s (removed)"), change.Name)
+	}
+	text := fmt.Sprintf("%s %s → %s", change.Name, change.Before, change.After)
+	if before, err := fxp.FromString(change.Before); err == nil {
+		if after, err2 := fxp.FromString(change.After); err2 == nil {
+			text += " (" + (after - before).StringWithSign() + ")"
+		}
+	}
+	return text
+}
+
+// confirmDiscard asks whether the plan should be saved as a proposal if it has changed since it was last saved.
+// Returns false if the user cancels.
+func (p *sheetPlan) confirmDiscard() bool {
+	if p.crc == p.sheet.entity.CRC64() {
+		return true
+	}
+	switch unison.YesNoCancelDialog(fmt.Sprintf(i18n.Text("Save the plan as the proposal \"%s\"?"), p.name),
+		i18n.Text("Otherwise, the changes made while planning will be lost.")) {
+	case unison.ModalResponseDiscard:
+		return true
+	case unison.ModalResponseOK:
+		return p.saveProposal()
+	default:
+		return false
+	}
+}
+
+// saveProposal stores the plan with the sheet under a name chosen by the user. Returns true if it was saved.
+func (p *sheetPlan) saveProposal() bool {
+	name := p.name
+	panel := unison.NewPanel()
+	panel.SetLayout(&unison.FlexLayout{
+		Columns:  2,
+		HSpacing: unison.StdHSpacing,
+	})
+	panel.AddChild(widget.NewFieldLeadingLabel(i18n.Text("Name")))
+	nameField := widget.NewStringField(i18n.Text("Name"), func() string { return name }, func(s string) { name = s })
+	nameField.SetMinimumTextWidthUsing("A reasonably long proposal name")
+	panel.AddChild(nameField)
+	dialog, err := unison.NewDialog(nil, nil, panel, []*unison.DialogButtonInfo{
+		unison.NewCancelButtonInfo(),
+		unison.NewOKButtonInfo(),
+	})
+	if err != nil {
+		jot.Error(err)
+		return false
+	}
+	dialog.Window().SetTitle(i18n.Text("Save Proposal"))
+	nameField.SelectAll()
+	nameField.RequestFocus()
+	if dialog.RunModal() != unison.ModalResponseOK {
+		return false
+	}
+	if name = strings.TrimSpace(name); name == "" {
+		name = p.name
+	}
+	proposal, err := gurps.NewProposal(name, p.sheet.entity)
+	if err != nil {
+		unison.ErrorDialogWithError(i18n.Text("Unable to save the proposal"), err)
+		return false
+	}
+	if p.proposal != nil {
+		proposal.ID = p.proposal.ID
+		proposal.Created = p.proposal.Created
+	}
+	p.original.SetProposal(proposal)
+	p.proposal = proposal
+	p.name = name
+	p.crc = p.sheet.entity.CRC64()
+	p.refresh()
+	p.sheet.MarkModified()
+	return true
+}
+
+func (p *sheetPlan) deleteProposal() {
+	if p.proposal == nil {
+		return
+	}
+	if unison.QuestionDialog(fmt.Sprintf(i18n.Text("Delete the proposal \"%s\"?"), p.proposal.Name), "") !=
+		unison.ModalResponseOK {
+		return
+	}
+	if p.sheet.endPlan() {
+		p.original.RemoveProposal(p.proposal.ID)
+		p.sheet.MarkModified()
+	}
+}
+
+// apply leaves planning mode and replaces the sheet's data with that of the plan as a single undoable change. A
+// proposal that is applied is removed from the sheet, since its changes are now part of it.
+func (p *sheetPlan) apply() {
+	s := p.sheet
+	planned := s.entity
+	if !s.endPlan() {
+		return
+	}
+	before, err := newEntitySnapshot(s)
+	if err != nil {
+		jot.Error(err)
+		return
+	}
+	if err = s.entity.ApplyPlan(planned); err != nil {
+		unison.ErrorDialogWithError(i18n.Text("Unable to apply the plan"), err)
+		return
+	}
+	if p.proposal != nil {
+		s.entity.RemoveProposal(p.proposal.ID)
+	}
+	var after *entitySnapshot
+	if after, err = newEntitySnapshot(s); err != nil {
+		jot.Error(err)
+	} else {
+		s.undoMgr.Add(&unison.UndoEdit[*entitySnapshot]{
+			ID:         unison.NextUndoID(),
+			EditName:   i18n.Text("Apply Plan"),
+			UndoFunc:   func(edit *unison.UndoEdit[*entitySnapshot]) { edit.BeforeData.apply() },
+			RedoFunc:   func(edit *unison.UndoEdit[*entitySnapshot]) { edit.AfterData.apply() },
+			BeforeData: before,
+			AfterData:  after,
+		})
+	}
+	s.Rebuild(true)
+}
+
+func newEntitySnapshot(s *Sheet) (*entitySnapshot, error) {
+	var buffer bytes.Buffer
+	if err := jio.Save(context.Background(), &buffer, s.entity); err != nil {
+		return nil, err
+	}
+	return &entitySnapshot{
+		owner: s,
+		data:  buffer.Bytes(),
+	}, nil
+}
+
+func (e *entitySnapshot) apply() {
+	if err := jio.Load(context.Background(), bytes.NewReader(e.data), e.owner.entity); err != nil {
+		jot.Error(err)
+		return
+	}
+	e.owner.Rebuild(true)
+}
@@ -17,8 +17,10 @@ import (
 	"github.com/richardwilkes/gcs/model/fxp"
 	"github.com/richardwilkes/gcs/model/gurps"
 	"github.com/richardwilkes/gcs/model/gurps/attribute"
+	"github.com/richardwilkes/gcs/model/rolls"
 	"github.com/richardwilkes/gcs/model/theme"
 	"github.com/richardwilkes/gcs/ui/widget"
+	"github.com/richardwilkes/gcs/ui/workspace"
 	"github.com/richardwilkes/toolbox/i18n"
 	"github.com/richardwilkes/toolbox/log/jot"
 	"github.com/richardwilkes/unison"
@@ -73,10 +75,48 @@ func (p *PrimaryAttrPanel) rebuild(attrs *gurps.AttributeDefs) {
 		p.AddChild(widget.NewDecimalPageField(i18n.Text("Primary Attribute"),
 			func() fxp.Int { return attr.Maximum() },
 			func(v fxp.Int) { attr.SetMaximum(v) }, fxp.Min, fxp.Max, true))
-		p.AddChild(widget.NewPageLabel(def.CombinedName()))
+		p.AddChild(newAttributeLabel(p.entity, attr, def))
 	}
 }
 
+// newAttributeLabel creates the label for an attribute. Clicking the label of an integer attribute makes a success roll
+// against its current value.
+func newAttributeLabel(entity *gurps.Entity, attr *gurps.Attribute, def *gurps.AttributeDef) *unison.Label {
+	label := widget.NewPageLabel(def.CombinedName())
+	if def.Type != attribute.Integer {
+		return label
+	}
+	const isLinkKey = "is_link"
+	label.Tooltip = unison.NewTooltipWithText(fmt.Sprintf(i18n.Text("Click to roll against %s"), def.CombinedName()))
+	label.DrawCallback = func(gc *unison.Canvas, rect unison.Rect) {
+		if _, exists := label.ClientData()[isLinkKey]; exists {
+			gc.DrawRect(rect, theme.LinkColor.Paint(gc, rect, unison.Fill))
+			save := label.OnBackgroundInk
+			label.OnBackgroundInk = theme.OnLinkColor
+			label.DefaultDraw(gc, rect)
+			label.OnBackgroundInk = save
+		} else {
+			label.DefaultDraw(gc, rect)
+		}
+	}
+	label.MouseEnterCallback = func(where unison.Point, mod unison.Modifiers) bool {
+		label.ClientData()[isLinkKey] = true
+		label.MarkForRedraw()
+		return true
+	}
+	label.MouseExitCallback = func() bool {
+		delete(label.ClientData(), isLinkKey)
+		label.MarkForRedraw()
+		return true
+	}
+	label.MouseDownCallback = func(where unison.Point, button, clickCount int, mod unison.Modifiers) bool {
+		workspace.Roll(label.Window(), rolls.NewSuccessRequest(entity.RollerName(), def.CombinedName(),
+			fxp.As[int](attr.Current().Trunc())))
+		return true
+	}
+	return label
+}
+
 func (p *PrimaryAttrPanel) createPointsField(attr *gurps.Attribute) *widget.NonEditablePageField {
 	field := widget.NewNonEditablePageFieldEnd(func(f *widget.NonEditablePageField) {
 		if text := "[" + attr.PointCost().String() + "]"; text != f.Text {
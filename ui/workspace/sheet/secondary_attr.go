@@ -75,7 +75,7 @@ func (p *SecondaryAttrPanel) rebuild(attrs *gurps.AttributeDefs) {
 		p.AddChild(widget.NewDecimalPageField(i18n.Text("Secondary Attribute"),
 			func() fxp.Int { return attr.Maximum() },
 			func(v fxp.Int) { attr.SetMaximum(v) }, fxp.Min, fxp.Max, true))
-		p.AddChild(widget.NewPageLabel(def.CombinedName()))
+		p.AddChild(newAttributeLabel(p.entity, attr, def))
 	}
 }
 
@@ -21,12 +21,14 @@ import (
 	"github.com/richardwilkes/gcs/model/gurps"
 	gsettings "github.com/richardwilkes/gcs/model/gurps/settings"
 	"github.com/richardwilkes/gcs/model/library"
+	"github.com/richardwilkes/gcs/model/printing"
 	"github.com/richardwilkes/gcs/model/settings"
 	"github.com/richardwilkes/gcs/model/theme"
 	"github.com/richardwilkes/gcs/res"
 	"github.com/richardwilkes/gcs/ui/widget"
 	"github.com/richardwilkes/gcs/ui/widget/ntable"
 	"github.com/richardwilkes/gcs/ui/workspace"
+	"github.com/richardwilkes/gcs/ui/workspace/external"
 	wsettings "github.com/richardwilkes/gcs/ui/workspace/settings"
 	"github.com/richardwilkes/toolbox/i18n"
 	"github.com/richardwilkes/toolbox/log/jot"
@@ -35,12 +37,14 @@ import (
 )
 
 var (
-	_ workspace.FileBackedDockable = &Sheet{}
-	_ unison.UndoManagerProvider   = &Sheet{}
-	_ widget.ModifiableRoot        = &Sheet{}
-	_ widget.Rebuildable           = &Sheet{}
-	_ widget.DockableKind          = &Sheet{}
-	_ unison.TabCloser             = &Sheet{}
+	_ workspace.FileBackedDockable   = &Sheet{}
+	_ workspace.RetargetableDockable = &Sheet{}
+	_ workspace.Recoverable          = &Sheet{}
+	_ unison.UndoManagerProvider     = &Sheet{}
+	_ widget.ModifiableRoot          = &Sheet{}
+	_ widget.Rebuildable             = &Sheet{}
+	_ widget.DockableKind            = &Sheet{}
+	_ unison.TabCloser               = &Sheet{}
 )
 
 type itemCreator interface {
@@ -80,6 +84,7 @@ type Sheet struct {
 	CarriedEquipment     *PageList[*gurps.Equipment]
 	OtherEquipment       *PageList[*gurps.Equipment]
 	Notes                *PageList[*gurps.Note]
+	plan                 *sheetPlan
 	awaitingUpdate       bool
 	needsSaveAsPrompt    bool
 }
@@ -145,7 +150,16 @@ func NewSheet(filePath string, entity *gurps.Entity) *Sheet {
 
 	sheetSettingsButton := unison.NewSVGButton(res.SettingsSVG)
 	sheetSettingsButton.Tooltip = unison.NewTooltipWithText(i18n.Text("Sheet Settings"))
-	sheetSettingsButton.ClickCallback = func() { wsettings.ShowSheetSettings(s) }
+	sheetSettingsButton.ClickCallback = func() {
+		if s.plan == nil {
+			wsettings.ShowSheetSettings(s)
+		}
+	}
+
+	planButton := unison.NewButton()
+	planButton.Text = i18n.Text("Plan")
+	planButton.Tooltip = unison.NewTooltipWithText(i18n.Text("Try out advancement options without altering the sheet"))
+	planButton.ClickCallback = func() { s.showPlanMenu(planButton) }
 
 	scaleTitle := i18n.Text("Scale")
 	s.scaleField = widget.NewPercentageField(scaleTitle, func() int { return s.scale }, func(v int) {
@@ -164,6 +178,7 @@ func NewSheet(filePath string, entity *gurps.Entity) *Sheet {
 	})
 	toolbar.AddChild(sheetSettingsButton)
 	toolbar.AddChild(s.scaleField)
+	toolbar.AddChild(planButton)
 	toolbar.SetLayout(&unison.FlexLayout{
 		Columns:  len(toolbar.Children()),
 		HSpacing: unison.StdHSpacing,
@@ -176,6 +191,9 @@ func NewSheet(filePath string, entity *gurps.Entity) *Sheet {
 
 	s.InstallCmdHandlers(constants.SaveItemID, func(_ any) bool { return s.Modified() }, func(_ any) { s.save(false) })
 	s.InstallCmdHandlers(constants.SaveAsItemID, unison.AlwaysEnabled, func(_ any) { s.save(true) })
+	s.InstallCmdHandlers(constants.PrintItemID, unison.AlwaysEnabled, func(_ any) {
+		external.ShowPrintPreview(s.Window(), s.Title(), printing.Sheet(s.entity))
+	})
 	s.installNewItemCmdHandlers(constants.NewTraitItemID, constants.NewTraitContainerItemID, s.Traits)
 	s.installNewItemCmdHandlers(constants.NewSkillItemID, constants.NewSkillContainerItemID, s.Skills)
 	s.installNewItemCmdHandlers(constants.NewTechniqueItemID, -1, s.Skills)
@@ -193,6 +211,8 @@ func NewSheet(filePath string, entity *gurps.Entity) *Sheet {
 				return s.Traits.provider.RootRows()
 			})
 	})
+	s.InstallCmdHandlers(constants.CheckForLibraryUpdatesItemID, unison.AlwaysEnabled,
+		func(_ any) { s.CheckForLibraryUpdates() })
 
 	return s
 }
@@ -213,13 +233,22 @@ func (s *Sheet) DockableKind() string {
 	return widget.SheetDockableKind
 }
 
-// Entity returns the entity this is displaying information for.
+// Entity returns the entity this is displaying information for. While planning, this is still the sheet's own entity
+// and not the plan being displayed.
 func (s *Sheet) Entity() *gurps.Entity {
-	return s.entity
+	return s.realEntity()
+}
+
+// Planning returns true if the sheet is currently in planning mode.
+func (s *Sheet) Planning() bool {
+	return s.plan != nil
 }
 
 // UndoManager implements undo.Provider
 func (s *Sheet) UndoManager() *unison.UndoManager {
+	if s.plan != nil {
+		return s.plan.undoMgr
+	}
 	return s.undoMgr
 }
 
@@ -238,6 +267,9 @@ func (s *Sheet) TitleIcon(suggestedSize unison.Size) unison.Drawable {
 
 // Title implements workspace.FileBackedDockable
 func (s *Sheet) Title() string {
+	if s.plan != nil {
+		return fmt.Sprintf(i18n.Text("%s (Planning)"), fs.BaseName(s.path))
+	}
 	return fs.BaseName(s.path)
 }
 
@@ -255,9 +287,32 @@ func (s *Sheet) BackingFilePath() string {
 	return s.path
 }
 
+// SetBackingFilePath implements workspace.RetargetableDockable
+func (s *Sheet) SetBackingFilePath(filePath string) {
+	s.path = filePath
+}
+
 // Modified implements workspace.FileBackedDockable
 func (s *Sheet) Modified() bool {
-	return s.crc != s.entity.CRC64()
+	return s.crc != s.realEntity().CRC64()
+}
+
+// SaveSnapshot implements workspace.Recoverable
+func (s *Sheet) SaveSnapshot(filePath string) error {
+	return s.realEntity().Save(filePath)
+}
+
+// NeedsSaveAs implements workspace.Recoverable
+func (s *Sheet) NeedsSaveAs() bool {
+	return s.needsSaveAsPrompt
+}
+
+// MarkRecovered implements workspace.Recoverable
+func (s *Sheet) MarkRecovered(originalPath string, needsSaveAs bool) {
+	s.path = originalPath
+	s.needsSaveAsPrompt = needsSaveAs
+	s.crc = 0
+	s.MarkModified()
 }
 
 // MarkModified implements widget.ModifiableRoot.
@@ -266,10 +321,12 @@ func (s *Sheet) MarkModified() {
 		s.awaitingUpdate = true
 		unison.InvokeTaskAfter(func() {
 			s.MiscPanel.UpdateModified()
-			// TODO: This is still too slow when the lists have more than a few rows of content.
-			//       It impinges on interactive typing. Looks like most of the time is spent in updating the tables.
-			//       Unfortunately, there isn't a fast way to determine that the content doesn't need to be refreshed.
+			// The tables retain their rows between syncs and only rebuild the cells of rows whose content has changed,
+			// so this remains fast even when the lists hold many rows.
 			widget.DeepSync(s)
+			if s.plan != nil {
+				s.plan.refresh()
+			}
 			if dc := unison.Ancestor[*unison.DockContainer](s); dc != nil {
 				dc.UpdateTitle(s)
 			}
@@ -285,6 +342,9 @@ func (s *Sheet) MayAttemptClose() bool {
 
 // AttemptClose implements unison.TabCloser
 func (s *Sheet) AttemptClose() bool {
+	if s.plan != nil && !s.plan.confirmDiscard() {
+		return false
+	}
 	if !workspace.CloseGroup(s) {
 		return false
 	}
@@ -306,14 +366,15 @@ func (s *Sheet) AttemptClose() bool {
 }
 
 func (s *Sheet) save(forceSaveAs bool) bool {
+	entity := s.realEntity()
 	success := false
 	if forceSaveAs || s.needsSaveAsPrompt {
-		success = workspace.SaveDockableAs(s, library.SheetExt, s.entity.Save, func(path string) {
-			s.crc = s.entity.CRC64()
+		success = workspace.SaveDockableAs(s, library.SheetExt, entity.Save, func(path string) {
+			s.crc = entity.CRC64()
 			s.path = path
 		})
 	} else {
-		success = workspace.SaveDockable(s, s.entity.Save, func() { s.crc = s.entity.CRC64() })
+		success = workspace.SaveDockable(s, entity.Save, func() { s.crc = entity.CRC64() })
 	}
 	if success {
 		s.needsSaveAsPrompt = false
@@ -0,0 +1,117 @@
+/*
+ * Copyright ©1998-2022 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package sheet
+
+import (
+	"github.com/richardwilkes/gcs/model/fxp"
+	"github.com/richardwilkes/gcs/model/gurps"
+	"github.com/richardwilkes/gcs/ui/widget"
+	"github.com/richardwilkes/gcs/ui/widget/ntable"
+	"github.com/richardwilkes/toolbox/i18n"
+	"github.com/richardwilkes/unison"
+)
+
+type skillDefaultsListUndoEdit = *unison.UndoEdit[*skillDefaultsList]
+
+// skillDefaultsList holds the points and current default of every skill in an entity. Since swapping defaults can
+// alter the default chosen by skills other than the two being swapped, the whole set is captured.
+type skillDefaultsList struct {
+	Owner  widget.Rebuildable
+	Entity *gurps.Entity
+	List   []*skillDefaults
+}
+
+func newSkillDefaultsList(owner widget.Rebuildable, entity *gurps.Entity) *skillDefaultsList {
+	list := &skillDefaultsList{
+		Owner:  owner,
+		Entity: entity,
+	}
+	gurps.Traverse(func(s *gurps.Skill) bool {
+		list.List = append(list.List, newSkillDefaults(s))
+		return false
+	}, true, false, entity.Skills...)
+	return list
+}
+
+func (s *skillDefaultsList) Apply() {
+	for _, one := range s.List {
+		one.Apply()
+	}
+	s.Finish()
+}
+
+func (s *skillDefaultsList) Finish() {
+	s.Entity.Recalculate()
+	widget.MarkModified(s.Owner)
+}
+
+type skillDefaults struct {
+	Target        *gurps.Skill
+	Points        fxp.Int
+	DefaultedFrom *gurps.SkillDefault
+}
+
+func newSkillDefaults(target *gurps.Skill) *skillDefaults {
+	s := &skillDefaults{
+		Target: target,
+		Points: target.Points,
+	}
+	if target.DefaultedFrom != nil {
+		def := *target.DefaultedFrom
+		s.DefaultedFrom = &def
+	}
+	return s
+}
+
+func (s *skillDefaults) Apply() {
+	s.Target.Points = s.Points
+	if s.DefaultedFrom != nil {
+		def := *s.DefaultedFrom
+		s.Target.DefaultedFrom = &def
+	} else {
+		s.Target.DefaultedFrom = nil
+	}
+}
+
+func canSwapDefaults(table *unison.Table[*ntable.Node[*gurps.Skill]]) bool {
+	for _, row := range table.SelectedRows(false) {
+		if sk := row.Data(); sk != nil && sk.CanSwapDefaults() {
+			return true
+		}
+	}
+	return false
+}
+
+func swapDefaults(owner widget.Rebuildable, table *unison.Table[*ntable.Node[*gurps.Skill]]) {
+	var before *skillDefaultsList
+	for _, row := range table.SelectedRows(false) {
+		if sk := row.Data(); sk != nil && sk.CanSwapDefaults() {
+			if before == nil {
+				before = newSkillDefaultsList(owner, sk.Entity)
+			}
+			sk.SwapDefaults()
+		}
+	}
+	if before != nil {
+		if mgr := unison.UndoManagerFor(table); mgr != nil {
+			mgr.Add(&unison.UndoEdit[*skillDefaultsList]{
+				ID:         unison.NextUndoID(),
+				EditName:   i18n.Text("Swap Defaults"),
+				UndoFunc:   func(edit skillDefaultsListUndoEdit) { edit.BeforeData.Apply() },
+				RedoFunc:   func(edit skillDefaultsListUndoEdit) { edit.AfterData.Apply() },
+				BeforeData: before,
+				AfterData:  newSkillDefaultsList(owner, before.Entity),
+			})
+		}
+		before.Finish()
+	}
+}
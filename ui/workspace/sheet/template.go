@@ -20,11 +20,13 @@ import (
 	"github.com/richardwilkes/gcs/model/gurps"
 	gsettings "github.com/richardwilkes/gcs/model/gurps/settings"
 	"github.com/richardwilkes/gcs/model/library"
+	"github.com/richardwilkes/gcs/model/printing"
 	"github.com/richardwilkes/gcs/model/settings"
 	"github.com/richardwilkes/gcs/model/theme"
 	"github.com/richardwilkes/gcs/ui/widget"
 	"github.com/richardwilkes/gcs/ui/widget/ntable"
 	"github.com/richardwilkes/gcs/ui/workspace"
+	"github.com/richardwilkes/gcs/ui/workspace/external"
 	"github.com/richardwilkes/toolbox/i18n"
 	"github.com/richardwilkes/toolbox/log/jot"
 	"github.com/richardwilkes/toolbox/xio/fs"
@@ -32,12 +34,14 @@ import (
 )
 
 var (
-	_ workspace.FileBackedDockable = &Template{}
-	_ unison.UndoManagerProvider   = &Template{}
-	_ widget.ModifiableRoot        = &Template{}
-	_ widget.Rebuildable           = &Template{}
-	_ widget.DockableKind          = &Template{}
-	_ unison.TabCloser             = &Template{}
+	_ workspace.FileBackedDockable   = &Template{}
+	_ workspace.RetargetableDockable = &Template{}
+	_ workspace.Recoverable          = &Template{}
+	_ unison.UndoManagerProvider     = &Template{}
+	_ widget.ModifiableRoot          = &Template{}
+	_ widget.Rebuildable             = &Template{}
+	_ widget.DockableKind            = &Template{}
+	_ unison.TabCloser               = &Template{}
 )
 
 // Template holds the view for a GURPS character template.
@@ -127,6 +131,9 @@ func NewTemplate(filePath string, template *gurps.Template) *Template {
 
 	d.InstallCmdHandlers(constants.SaveItemID, func(_ any) bool { return d.Modified() }, func(_ any) { d.save(false) })
 	d.InstallCmdHandlers(constants.SaveAsItemID, unison.AlwaysEnabled, func(_ any) { d.save(true) })
+	d.InstallCmdHandlers(constants.PrintItemID, unison.AlwaysEnabled, func(_ any) {
+		external.ShowPrintPreview(d.Window(), d.Title(), printing.Template(d.template, d.Title()))
+	})
 	d.installNewItemCmdHandlers(constants.NewTraitItemID, constants.NewTraitContainerItemID, d.Traits)
 	d.installNewItemCmdHandlers(constants.NewSkillItemID, constants.NewSkillContainerItemID, d.Skills)
 	d.installNewItemCmdHandlers(constants.NewTechniqueItemID, -1, d.Skills)
@@ -199,11 +206,34 @@ func (d *Template) BackingFilePath() string {
 	return d.path
 }
 
+// SetBackingFilePath implements workspace.RetargetableDockable
+func (d *Template) SetBackingFilePath(filePath string) {
+	d.path = filePath
+}
+
 // Modified implements workspace.FileBackedDockable
 func (d *Template) Modified() bool {
 	return d.crc != d.template.CRC64()
 }
 
+// SaveSnapshot implements workspace.Recoverable
+func (d *Template) SaveSnapshot(filePath string) error {
+	return d.template.Save(filePath)
+}
+
+// NeedsSaveAs implements workspace.Recoverable
+func (d *Template) NeedsSaveAs() bool {
+	return d.needsSaveAsPrompt
+}
+
+// MarkRecovered implements workspace.Recoverable
+func (d *Template) MarkRecovered(originalPath string, needsSaveAs bool) {
+	d.path = originalPath
+	d.needsSaveAsPrompt = needsSaveAs
+	d.crc = 0
+	d.MarkModified()
+}
+
 // MarkModified implements widget.ModifiableRoot.
 func (d *Template) MarkModified() {
 	if dc := unison.Ancestor[*unison.DockContainer](d); dc != nil {
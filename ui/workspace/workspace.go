@@ -311,10 +311,12 @@ func traverseGroup(d unison.Dockable, f func(target widget.GroupedCloser) bool)
 // SaveDockable attempts to save the contents of the dockable using its existing path.
 func SaveDockable(d FileBackedDockable, saver func(path string) error, setUnmodified func()) bool {
 	path := d.BackingFilePath()
+	preserveRevision(path)
 	if err := saver(path); err != nil {
 		unison.ErrorDialogWithError(fmt.Sprintf(i18n.Text("Unable to save %s"), fs.BaseName(path)), err)
 		return false
 	}
+	recordRevision(path)
 	setUnmodified()
 	if dc := unison.Ancestor[*unison.DockContainer](d); dc != nil {
 		dc.UpdateTitle(d)
@@ -333,10 +335,12 @@ func SaveDockableAs(d FileBackedDockable, extension string, saver func(path stri
 	dialog.SetAllowedExtensions(extension)
 	if dialog.RunModal() {
 		path := dialog.Path()
+		preserveRevision(path)
 		if err := saver(path); err != nil {
 			unison.ErrorDialogWithError(fmt.Sprintf(i18n.Text("Unable to save as %s"), fs.BaseName(path)), err)
 			return false
 		}
+		recordRevision(path)
 		setUnmodifiedAndNewPath(path)
 		settings.Global().AddRecentFile(path)
 		if dc := unison.Ancestor[*unison.DockContainer](d); dc != nil {